@@ -8,14 +8,17 @@ package ansiblenavigatorlocal
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 )
 
 func needsGeneratedAnsibleCfg(ansibleCfg *AnsibleConfig) bool {
 	if ansibleCfg == nil {
 		return false
 	}
-	return ansibleCfg.Defaults != nil || ansibleCfg.SSHConnection != nil
+	return ansibleCfg.Defaults != nil || ansibleCfg.SSHConnection != nil || len(ansibleCfg.Sections) > 0
 }
 
 func formatAnsibleCfgBool(v bool) string {
@@ -25,52 +28,369 @@ func formatAnsibleCfgBool(v bool) string {
 	return "False"
 }
 
+// ansibleCfgOption is a single ansible.cfg option.
+type ansibleCfgOption struct {
+	section string
+	key     string
+	value   string
+	// field is the typed navigator_config field that sets the option, if any.
+	field string
+	// overridable typed options hold a plain bool default that a generic section
+	// option replaces instead of conflicting with.
+	overridable bool
+}
+
+// typedAnsibleCfgOptions returns the options written for the typed defaults and
+// ssh_connection blocks, in output order.
+func typedAnsibleCfgOptions(ansibleCfg *AnsibleConfig) []ansibleCfgOption {
+	var opts []ansibleCfgOption
+	if d := ansibleCfg.Defaults; d != nil {
+		if d.RemoteTmp != "" {
+			opts = append(opts, ansibleCfgOption{section: "defaults", key: "remote_tmp", value: d.RemoteTmp,
+				field: "navigator_config.ansible_config.defaults.remote_tmp"})
+		}
+		if d.LocalTmp != "" {
+			opts = append(opts, ansibleCfgOption{section: "defaults", key: "local_tmp", value: d.LocalTmp,
+				field: "navigator_config.ansible_config.defaults.local_tmp"})
+		}
+		opts = append(opts, ansibleCfgOption{section: "defaults", key: "host_key_checking", value: formatAnsibleCfgBool(d.HostKeyChecking),
+			field: "navigator_config.ansible_config.defaults.host_key_checking", overridable: !d.HostKeyChecking})
+	}
+	if c := ansibleCfg.SSHConnection; c != nil {
+		if c.SSHTimeout > 0 {
+			opts = append(opts, ansibleCfgOption{section: "ssh_connection", key: "ssh_timeout", value: fmt.Sprintf("%d", c.SSHTimeout),
+				field: "navigator_config.ansible_config.ssh_connection.ssh_timeout"})
+		}
+		opts = append(opts, ansibleCfgOption{section: "ssh_connection", key: "pipelining", value: formatAnsibleCfgBool(c.Pipelining),
+			field: "navigator_config.ansible_config.ssh_connection.pipelining", overridable: !c.Pipelining})
+	}
+	return opts
+}
+
+// ansibleCfgSectionOption reports whether a generic section block sets key in section.
+func ansibleCfgSectionOption(ansibleCfg *AnsibleConfig, section, key string) bool {
+	if ansibleCfg == nil {
+		return false
+	}
+	for _, s := range ansibleCfg.Sections {
+		if s.Name != section {
+			continue
+		}
+		for k := range s.Options {
+			if strings.EqualFold(k, key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateAnsibleCfgSections validates the generic ansible_config section blocks:
+// names that cannot be written to INI, duplicate options, and options that a
+// typed field also sets. Option names are compared case-insensitively, like
+// ansible's config parser does.
+func validateAnsibleCfgSections(ansibleCfg *AnsibleConfig) []error {
+	if ansibleCfg == nil {
+		return nil
+	}
+	var errs []error
+
+	typed := make(map[string]ansibleCfgOption)
+	for _, opt := range typedAnsibleCfgOptions(ansibleCfg) {
+		typed[opt.section+"."+opt.key] = opt
+	}
+
+	seen := make(map[string]bool)
+	for i, s := range ansibleCfg.Sections {
+		field := fmt.Sprintf("navigator_config.ansible_config.section[%d]", i)
+		if strings.TrimSpace(s.Name) == "" {
+			errs = append(errs, fmt.Errorf("%s.name must not be empty", field))
+			continue
+		}
+		if s.Name != strings.TrimSpace(s.Name) || strings.ContainsAny(s.Name, "[]\r\n") {
+			errs = append(errs, fmt.Errorf("%s.name %q must not contain brackets, line breaks or surrounding spaces", field, s.Name))
+			continue
+		}
+
+		keys := make([]string, 0, len(s.Options))
+		for key := range s.Options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := s.Name + "." + key
+			if key == "" || key != strings.TrimSpace(key) || strings.ContainsAny(key, "=:[]#;\r\n") {
+				errs = append(errs, fmt.Errorf("%s.options: invalid ansible.cfg option name %q", field, key))
+				continue
+			}
+			if strings.ContainsAny(s.Options[key], "\r\n") {
+				errs = append(errs, fmt.Errorf("%s.options: value of %s must not contain line breaks", field, name))
+			}
+			id := s.Name + "." + strings.ToLower(key)
+			if seen[id] {
+				errs = append(errs, fmt.Errorf("%s.options: ansible.cfg option %s is set more than once", field, name))
+				continue
+			}
+			seen[id] = true
+			if opt, ok := typed[id]; ok && !opt.overridable {
+				errs = append(errs, fmt.Errorf("%s.options: ansible.cfg option %s conflicts with %s", field, name, opt.field))
+			}
+		}
+	}
+	return errs
+}
+
+// escapeAnsibleCfgValue escapes a value for ansible's INI parser, which treats
+// "%" as the start of an interpolation.
+func escapeAnsibleCfgValue(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// ansibleCfgSection is an ansible.cfg section and its options, in output order.
+type ansibleCfgSection struct {
+	name    string
+	options []ansibleCfgOption
+}
+
+// option returns the index of key in the section (case-insensitive), or -1.
+func (s *ansibleCfgSection) option(key string) int {
+	for i, opt := range s.options {
+		if strings.EqualFold(opt.key, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// generateAnsibleCfgContent renders the ansible.cfg for the typed blocks and the
+// generic section blocks.
 func generateAnsibleCfgContent(ansibleCfg *AnsibleConfig) (string, error) {
+	sections, err := generatedAnsibleCfgSections(ansibleCfg)
+	if err != nil {
+		return "", err
+	}
+	return renderAnsibleCfg(sections), nil
+}
+
+// generatedAnsibleCfgSections returns the sections generated for the typed
+// blocks and the generic section blocks. The typed [defaults] and
+// [ssh_connection] sections come first, followed by the remaining sections in
+// name order. Within a section, typed options keep their fixed order and generic
+// options follow sorted by name.
+func generatedAnsibleCfgSections(ansibleCfg *AnsibleConfig) ([]*ansibleCfgSection, error) {
 	if ansibleCfg == nil {
-		return "", nil
+		return nil, nil
+	}
+	if errs := validateAnsibleCfgSections(ansibleCfg); len(errs) > 0 {
+		return nil, errs[0]
 	}
 
-	var b strings.Builder
-	wroteAny := false
+	var sections []*ansibleCfgSection
+	byName := make(map[string]*ansibleCfgSection)
+	section := func(name string) *ansibleCfgSection {
+		s, ok := byName[name]
+		if !ok {
+			s = &ansibleCfgSection{name: name}
+			byName[name] = s
+			sections = append(sections, s)
+		}
+		return s
+	}
 
+	// Even if the user didn't set individual fields, the presence of a typed
+	// block implies they expect its section to be generated.
 	if ansibleCfg.Defaults != nil {
-		b.WriteString("[defaults]\n")
-		wroteAny = true
+		section("defaults")
+	}
+	if ansibleCfg.SSHConnection != nil {
+		section("ssh_connection")
+	}
+	for _, opt := range typedAnsibleCfgOptions(ansibleCfg) {
+		if opt.overridable && ansibleCfgSectionOption(ansibleCfg, opt.section, opt.key) {
+			continue
+		}
+		s := section(opt.section)
+		s.options = append(s.options, opt)
+	}
 
-		if ansibleCfg.Defaults.RemoteTmp != "" {
-			b.WriteString(fmt.Sprintf("remote_tmp = %s\n", ansibleCfg.Defaults.RemoteTmp))
+	generic := make(map[string][]ansibleCfgOption)
+	var genericNames []string
+	for _, s := range ansibleCfg.Sections {
+		if _, ok := generic[s.Name]; !ok {
+			genericNames = append(genericNames, s.Name)
 		}
-		if ansibleCfg.Defaults.LocalTmp != "" {
-			b.WriteString(fmt.Sprintf("local_tmp = %s\n", ansibleCfg.Defaults.LocalTmp))
+		for key, value := range s.Options {
+			generic[s.Name] = append(generic[s.Name], ansibleCfgOption{section: s.Name, key: key, value: escapeAnsibleCfgValue(value)})
 		}
-		b.WriteString(fmt.Sprintf("host_key_checking = %s\n", formatAnsibleCfgBool(ansibleCfg.Defaults.HostKeyChecking)))
-		b.WriteString("\n")
+	}
+	sort.Strings(genericNames)
+	for _, name := range genericNames {
+		opts := generic[name]
+		sort.Slice(opts, func(i, j int) bool { return opts[i].key < opts[j].key })
+		s := section(name)
+		s.options = append(s.options, opts...)
 	}
 
-	if ansibleCfg.SSHConnection != nil {
-		b.WriteString("[ssh_connection]\n")
-		wroteAny = true
+	return sections, nil
+}
 
-		if ansibleCfg.SSHConnection.SSHTimeout > 0 {
-			b.WriteString(fmt.Sprintf("ssh_timeout = %d\n", ansibleCfg.SSHConnection.SSHTimeout))
+// renderAnsibleCfg writes sections in INI format. Multi-line values are written
+// as indented continuation lines.
+func renderAnsibleCfg(sections []*ansibleCfgSection) string {
+	var b strings.Builder
+	for _, s := range sections {
+		b.WriteString(fmt.Sprintf("[%s]\n", s.name))
+		for _, opt := range s.options {
+			b.WriteString(fmt.Sprintf("%s = %s\n", opt.key, strings.ReplaceAll(opt.value, "\n", "\n    ")))
 		}
-		b.WriteString(fmt.Sprintf("pipelining = %s\n", formatAnsibleCfgBool(ansibleCfg.SSHConnection.Pipelining)))
 		b.WriteString("\n")
 	}
+	return b.String()
+}
+
+// parseAnsibleCfg parses ansible.cfg content the way ansible's INI parser reads
+// it: "key = value" or "key: value" options grouped under "[section]" headers,
+// "#" and ";" comment lines, and indented continuation lines. Comments are not
+// preserved. Values are kept verbatim (including "%%" escapes).
+func parseAnsibleCfg(content string) ([]*ansibleCfgSection, error) {
+	var sections []*ansibleCfgSection
+	var current *ansibleCfgSection
+	var last *ansibleCfgOption
+
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			last = nil
+			continue
+		}
+
+		// Indented lines continue the previous option's value.
+		if last != nil && (line[0] == ' ' || line[0] == '\t') {
+			last.value += "\n" + trimmed
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			if !strings.HasSuffix(trimmed, "]") {
+				return nil, fmt.Errorf("line %d: invalid section header %q", i+1, trimmed)
+			}
+			name := strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			current = nil
+			for _, s := range sections {
+				if s.name == name {
+					current = s
+				}
+			}
+			if current == nil {
+				current = &ansibleCfgSection{name: name}
+				sections = append(sections, current)
+			}
+			last = nil
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("line %d: option %q is not in a section", i+1, trimmed)
+		}
+		sep := strings.IndexAny(trimmed, "=:")
+		if sep <= 0 {
+			return nil, fmt.Errorf("line %d: expected \"key = value\", got %q", i+1, trimmed)
+		}
+		key := strings.TrimSpace(trimmed[:sep])
+		value := strings.TrimSpace(trimmed[sep+1:])
+		if idx := current.option(key); idx >= 0 {
+			// Later definitions win, as in ansible's parser.
+			current.options[idx].value = value
+			last = &current.options[idx]
+			continue
+		}
+		current.options = append(current.options, ansibleCfgOption{section: current.name, key: key, value: value})
+		last = &current.options[len(current.options)-1]
+	}
+	return sections, nil
+}
+
+// mergeAnsibleCfgContent overlays the generated settings for ansibleCfg on base,
+// the content of the user's ansible.cfg. The user's sections and options keep
+// their order; generated options replace user options with the same name and
+// new options and sections are appended. Options whose user value was replaced
+// are returned as "section.key".
+//
+// Typed booleans that were never set (host_key_checking, pipelining) do not
+// replace a value from the user's file.
+func mergeAnsibleCfgContent(base string, ansibleCfg *AnsibleConfig) (string, []string, error) {
+	sections, err := parseAnsibleCfg(base)
+	if err != nil {
+		return "", nil, err
+	}
+	generated, err := generatedAnsibleCfgSections(ansibleCfg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var overridden []string
+	for _, g := range generated {
+		var target *ansibleCfgSection
+		for _, s := range sections {
+			if s.name == g.name {
+				target = s
+				break
+			}
+		}
+		if target == nil {
+			sections = append(sections, g)
+			continue
+		}
+		for _, opt := range g.options {
+			idx := target.option(opt.key)
+			switch {
+			case idx < 0:
+				target.options = append(target.options, opt)
+			case opt.overridable:
+				// Keep the user's value for an unset typed boolean.
+			default:
+				if target.options[idx].value != opt.value {
+					overridden = append(overridden, g.name+"."+target.options[idx].key)
+				}
+				target.options[idx] = opt
+			}
+		}
+	}
+
+	return renderAnsibleCfg(sections), overridden, nil
+}
 
-	if !wroteAny {
-		return "", nil
+// buildAnsibleCfgContent returns the ansible.cfg content for ansibleCfg. When
+// ansible_config.config names an existing file, the generated settings are
+// merged into it and the overridden options are reported on ui.
+func buildAnsibleCfgContent(ui packersdk.Ui, ansibleCfg *AnsibleConfig) (string, error) {
+	if ansibleCfg.Config == "" {
+		return generateAnsibleCfgContent(ansibleCfg)
 	}
 
-	return b.String(), nil
+	base, err := os.ReadFile(ansibleCfg.Config)
+	if err != nil {
+		return "", fmt.Errorf("failed to read navigator_config.ansible_config.config: %w", err)
+	}
+	content, overridden, err := mergeAnsibleCfgContent(string(base), ansibleCfg)
+	if err != nil {
+		return "", fmt.Errorf("failed to merge %s: %w", ansibleCfg.Config, err)
+	}
+	ui.Message(fmt.Sprintf("Merging generated ansible.cfg settings into %s", ansibleCfg.Config))
+	if len(overridden) > 0 {
+		ui.Message(fmt.Sprintf("ansible.cfg settings overridden by the plugin: %s", strings.Join(overridden, ", ")))
+	}
+	return content, nil
 }
 
-func createTempAnsibleCfgFile(content string) (string, error) {
+// createTempAnsibleCfgFile writes content to a new file in dir, or in the
+// system temporary directory when dir is empty.
+func createTempAnsibleCfgFile(dir, content string) (string, error) {
 	if content == "" {
 		return "", fmt.Errorf("ansible.cfg content cannot be empty")
 	}
 
-	tmpFile, err := os.CreateTemp("", "packer-ansible-cfg-*.ini")
+	tmpFile, err := os.CreateTemp(dir, "packer-ansible-cfg-*.ini")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temporary ansible.cfg file: %w", err)
 	}
@@ -0,0 +1,221 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+)
+
+// artifactsDirName is the directory in the staging directory that
+// ansible-navigator writes its log and artifacts to with download_artifacts.
+const artifactsDirName = "artifacts"
+
+// validateDownloadArtifacts validates the download_artifacts block.
+func validateDownloadArtifacts(d *DownloadArtifactsConfig) []error {
+	if d == nil {
+		return nil
+	}
+	var errs []error
+	if d.Destination == "" {
+		errs = append(errs, fmt.Errorf("download_artifacts.destination is required"))
+	}
+	seen := make(map[string]bool)
+	for _, p := range d.Paths {
+		if !path.IsAbs(p) || path.Clean(p) == "/" {
+			errs = append(errs, fmt.Errorf(
+				"download_artifacts.paths: %q must be an absolute path on the target other than /", p))
+			continue
+		}
+		base := path.Base(p)
+		if seen[base] {
+			errs = append(errs, fmt.Errorf("download_artifacts.paths: more than one path is named %q", base))
+		}
+		seen[base] = true
+	}
+	return errs
+}
+
+func (p *Provisioner) artifactsDir() string {
+	return p.stagingDir + "/" + artifactsDirName
+}
+
+// applyArtifactDefaults points ansible-navigator's log, playbook artifacts and
+// ansible-runner artifacts, with job events, at the artifacts directory unless
+// they are configured in navigator_config.
+func (p *Provisioner) applyArtifactDefaults() {
+	dir := p.artifactsDir()
+	if p.config.NavigatorConfig == nil {
+		p.config.NavigatorConfig = &NavigatorConfig{}
+	}
+	nc := p.config.NavigatorConfig
+
+	if nc.Logging == nil {
+		nc.Logging = &LoggingConfig{}
+	}
+	if nc.Logging.File == "" {
+		// Every play appends to the same log
+		nc.Logging.File = dir + "/ansible-navigator.log"
+		nc.Logging.Append = true
+	}
+	if nc.PlaybookArtifact == nil {
+		nc.PlaybookArtifact = &PlaybookArtifact{Enable: true}
+	}
+	if nc.PlaybookArtifact.Enable && nc.PlaybookArtifact.SaveAs == "" {
+		nc.PlaybookArtifact.SaveAs = dir + "/{playbook_name}-artifact-{time_stamp}.json"
+	}
+	if nc.AnsibleRunner == nil {
+		nc.AnsibleRunner = &AnsibleRunnerConfig{}
+	}
+	if nc.AnsibleRunner.ArtifactDir == "" {
+		nc.AnsibleRunner.ArtifactDir = dir + "/runner"
+	}
+	if nc.AnsibleRunner.JobEvents == nil {
+		jobEvents := true
+		nc.AnsibleRunner.JobEvents = &jobEvents
+	}
+}
+
+// artifactSources returns the paths on the target that download_artifacts
+// downloads: the artifacts directory, the log file and ansible-runner artifact
+// directory when they are configured elsewhere, and the listed paths.
+func (p *Provisioner) artifactSources() []string {
+	dir := p.artifactsDir()
+	sources := []string{dir}
+	nc := p.config.NavigatorConfig
+	for _, configured := range []string{nc.Logging.File, nc.AnsibleRunner.ArtifactDir} {
+		if path.IsAbs(configured) && !strings.HasPrefix(configured, dir+"/") {
+			sources = append(sources, configured)
+		}
+	}
+	return append(sources, p.config.DownloadArtifacts.Paths...)
+}
+
+// resetArtifactsDir creates an empty artifacts directory, so that only this
+// run's artifacts are downloaded from a kept staging directory.
+func (p *Provisioner) resetArtifactsDir(ui packersdk.Ui, comm packersdk.Communicator) error {
+	if err := p.removeDir(ui, comm, p.artifactsDir()); err != nil {
+		return err
+	}
+	return p.createDir(ui, comm, p.artifactsDir())
+}
+
+// downloadArtifacts downloads the artifact sources into the destination.
+// Failures are reported but do not fail the build.
+func (p *Provisioner) downloadArtifacts(ui packersdk.Ui, comm packersdk.Communicator) {
+	dest := p.config.DownloadArtifacts.Destination
+	ui.Say(fmt.Sprintf("Downloading artifacts to %s...", dest))
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		ui.Error(fmt.Sprintf("Error creating artifacts destination: %s", err))
+		return
+	}
+	for _, src := range p.artifactSources() {
+		if err := p.downloadArtifact(ui, comm, src, dest); err != nil {
+			ui.Error(fmt.Sprintf("Error downloading %s: %s", src, err))
+		}
+	}
+}
+
+// downloadArtifact packs src into a tarball on the target, downloads it and
+// unpacks it into dest: the contents of the artifacts directory, other paths
+// by base name. Like uploadTree this behaves the same with every communicator.
+func (p *Provisioner) downloadArtifact(ui packersdk.Ui, comm packersdk.Communicator, src, dest string) error {
+	ctx := context.TODO()
+	cmd := &packersdk.RemoteCmd{Command: "test -e " + shellEscapePOSIX(src)}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		return err
+	}
+	if cmd.ExitStatus() != 0 {
+		ui.Message(fmt.Sprintf("Not found on the target, skipping: %s", src))
+		return nil
+	}
+
+	parent, name := path.Dir(src), path.Base(src)
+	if src == p.artifactsDir() {
+		parent, name = src, "."
+	}
+	remoteArchive := p.stagingDir + "/.download.tar.gz"
+	cmd = &packersdk.RemoteCmd{
+		Command: fmt.Sprintf("tar -czf %s -C %s %s",
+			shellEscapePOSIX(remoteArchive), shellEscapePOSIX(parent), shellEscapePOSIX(name)),
+	}
+	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+		return err
+	}
+	if cmd.ExitStatus() != 0 {
+		return fmt.Errorf("failed to archive on target: exit code %d", cmd.ExitStatus())
+	}
+	defer func() {
+		cmd := &packersdk.RemoteCmd{Command: "rm -f " + shellEscapePOSIX(remoteArchive)}
+		_ = cmd.RunWithUi(ctx, comm, ui)
+	}()
+
+	tf, err := tmp.File("packer-ansible-download")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+
+	if err := comm.Download(remoteArchive, tf); err != nil {
+		return err
+	}
+	if _, err := tf.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	ui.Message(fmt.Sprintf("Downloaded: %s", src))
+	return extractTarGz(tf, dest)
+}
+
+// extractTarGz unpacks the directories and regular files of a gzipped tarball
+// into dst. Entries that would land outside dst are rejected.
+func extractTarGz(r io.Reader, dst string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		if name == "." {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("refusing to extract %q outside %s", hdr.Name, dst)
+		}
+		target := filepath.Join(dst, filepath.FromSlash(name))
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeLocalFile(target, tr, hdr.FileInfo().Mode().Perm()|0o600); err != nil {
+				return err
+			}
+		}
+	}
+}
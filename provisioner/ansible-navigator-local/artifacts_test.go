@@ -0,0 +1,139 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDownloadArtifacts(t *testing.T) {
+	require.Empty(t, validateDownloadArtifacts(nil))
+	require.Empty(t, validateDownloadArtifacts(&DownloadArtifactsConfig{
+		Destination: "artifacts",
+		Paths:       []string{"/var/log/cloud-init.log", "/etc/app"},
+	}))
+
+	tests := []struct {
+		name   string
+		config *DownloadArtifactsConfig
+		errMsg string
+	}{
+		{"missing destination", &DownloadArtifactsConfig{}, "download_artifacts.destination is required"},
+		{"relative path", &DownloadArtifactsConfig{Destination: "out", Paths: []string{"var/log"}}, "must be an absolute path"},
+		{"root", &DownloadArtifactsConfig{Destination: "out", Paths: []string{"/"}}, "must be an absolute path"},
+		{"duplicate base name", &DownloadArtifactsConfig{Destination: "out", Paths: []string{"/a/app.log", "/b/app.log"}}, `more than one path is named "app.log"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateDownloadArtifacts(tt.config)
+			require.Len(t, errs, 1)
+			require.Contains(t, errs[0].Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestApplyArtifactDefaults(t *testing.T) {
+	p := &Provisioner{stagingDir: "/tmp/staging"}
+	p.config.DownloadArtifacts = &DownloadArtifactsConfig{Destination: "out"}
+	p.applyArtifactDefaults()
+
+	nc := p.config.NavigatorConfig
+	require.Equal(t, "/tmp/staging/artifacts/ansible-navigator.log", nc.Logging.File)
+	require.True(t, nc.Logging.Append)
+	require.True(t, nc.PlaybookArtifact.Enable)
+	require.Equal(t, "/tmp/staging/artifacts/{playbook_name}-artifact-{time_stamp}.json", nc.PlaybookArtifact.SaveAs)
+	require.Equal(t, "/tmp/staging/artifacts/runner", nc.AnsibleRunner.ArtifactDir)
+	require.True(t, *nc.AnsibleRunner.JobEvents)
+	require.Equal(t, []string{"/tmp/staging/artifacts"}, p.artifactSources())
+
+	// Configured locations are kept, and downloaded when absolute.
+	jobEvents := false
+	p = &Provisioner{stagingDir: "/tmp/staging"}
+	p.config.DownloadArtifacts = &DownloadArtifactsConfig{Destination: "out", Paths: []string{"/var/log/app.log"}}
+	p.config.NavigatorConfig = &NavigatorConfig{
+		Logging:          &LoggingConfig{Level: "debug", File: "/var/log/navigator.log"},
+		PlaybookArtifact: &PlaybookArtifact{Replay: "old.json"},
+		AnsibleRunner:    &AnsibleRunnerConfig{ArtifactDir: "runner", JobEvents: &jobEvents},
+	}
+	p.applyArtifactDefaults()
+
+	nc = p.config.NavigatorConfig
+	require.Equal(t, "/var/log/navigator.log", nc.Logging.File)
+	require.False(t, nc.Logging.Append)
+	require.Empty(t, nc.PlaybookArtifact.SaveAs)
+	require.Equal(t, "runner", nc.AnsibleRunner.ArtifactDir)
+	require.False(t, *nc.AnsibleRunner.JobEvents)
+	require.Equal(t, []string{"/tmp/staging/artifacts", "/var/log/navigator.log", "/var/log/app.log"}, p.artifactSources())
+}
+
+func TestProvisioner_DownloadArtifactsOnFailure(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+	dest := filepath.Join(t.TempDir(), "artifacts")
+	extra := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(extra, []byte("app\n"), 0o644))
+
+	// A failing ansible-navigator that writes its log and runner job events.
+	navigator := filepath.Join(t.TempDir(), "ansible-navigator")
+	require.NoError(t, os.WriteFile(navigator, []byte(`#!/bin/sh
+[ "$1" = run ] || exit 0
+for arg in "$@"; do
+  case "$arg" in
+    --log-file=*) echo "navigator log" >> "${arg#--log-file=}" ;;
+    --ansible-runner-artifact-dir=*) mkdir -p "${arg#*=}/job/job_events" && echo '{}' > "${arg#*=}/job/job_events/1.json" ;;
+  esac
+done
+exit 2
+`), 0o755))
+
+	var p Provisioner
+	config := testConfig()
+	config["command"] = navigator
+	config["download_artifacts"] = map[string]interface{}{
+		"destination": dest,
+		"paths":       []string{extra, "/nonexistent/packer-artifact"},
+	}
+	config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+	require.NoError(t, p.Prepare(config))
+
+	ui := newMockUi()
+	err := p.Provision(context.Background(), ui, &localCommunicator{}, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"})
+	require.Error(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dest, "ansible-navigator.log"))
+	require.NoError(t, err)
+	require.Equal(t, "navigator log\n", string(data))
+	require.FileExists(t, filepath.Join(dest, "runner", "job", "job_events", "1.json"))
+	require.FileExists(t, filepath.Join(dest, "app.log"))
+	require.Contains(t, ui.(*mockUi).messageMessages, "Not found on the target, skipping: /nonexistent/packer-artifact")
+
+	// The staging directory is still removed afterwards.
+	require.NoDirExists(t, p.stagingDir)
+}
+
+func TestExtractTarGz_RejectsPathsOutsideDestination(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0o644, Size: 1, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("x"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+
+	dst := t.TempDir()
+	err = extractTarGz(&buf, dst)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "outside")
+	require.NoFileExists(t, filepath.Join(filepath.Dir(dst), "escape"))
+}
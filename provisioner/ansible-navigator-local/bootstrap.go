@@ -0,0 +1,180 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// Bootstrap methods
+const (
+	BootstrapMethodPip     = "pip"
+	BootstrapMethodPipx    = "pipx"
+	BootstrapMethodPackage = "package"
+)
+
+// Defaults for bootstrap
+const DefaultBootstrapPython = "python3"
+
+var defaultBootstrapPackages = []string{"ansible-core", "ansible-navigator"}
+
+// validateBootstrap validates the bootstrap block.
+func validateBootstrap(b *BootstrapConfig) []error {
+	if b == nil {
+		return nil
+	}
+	var errs []error
+	switch b.Method {
+	case "", BootstrapMethodPip, BootstrapMethodPipx:
+		if len(b.Packages) > 0 {
+			errs = append(errs, fmt.Errorf("bootstrap.packages requires method %q", BootstrapMethodPackage))
+		}
+	case BootstrapMethodPackage:
+		if b.NavigatorVersion != "" || b.AnsibleCoreVersion != "" {
+			errs = append(errs, fmt.Errorf(
+				"bootstrap.navigator_version and ansible_core_version are not supported with method %q", BootstrapMethodPackage))
+		}
+		if b.Wheelhouse != "" || len(b.ExtraPackages) > 0 || b.Path != "" || b.Keep {
+			errs = append(errs, fmt.Errorf(
+				"bootstrap.wheelhouse, extra_packages, path and keep are not supported with method %q", BootstrapMethodPackage))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid bootstrap.method: %q (must be %q, %q or %q)",
+			b.Method, BootstrapMethodPip, BootstrapMethodPipx, BootstrapMethodPackage))
+	}
+	if b.Wheelhouse != "" {
+		if err := validateDirConfig(b.Wheelhouse, "bootstrap.wheelhouse"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if b.Keep && b.Path == "" {
+		errs = append(errs, fmt.Errorf("bootstrap.keep requires bootstrap.path"))
+	}
+	return errs
+}
+
+// pipRequirement returns the pip requirement for name at version: an exact
+// version is pinned with ==, a version specifier is used as given.
+func pipRequirement(name, version string) string {
+	if version == "" {
+		return name
+	}
+	if strings.ContainsAny(version[:1], "<>=!~") {
+		return name + version
+	}
+	return name + "==" + version
+}
+
+// bootstrapDir returns the directory the pip and pipx methods install into.
+func (p *Provisioner) bootstrapDir() string {
+	if p.config.Bootstrap.Path != "" {
+		return p.config.Bootstrap.Path
+	}
+	return filepath.ToSlash(filepath.Join(p.stagingDir, "ansible-bootstrap"))
+}
+
+// buildBootstrapShell returns the shell command that installs ansible-navigator
+// into dir (pip, pipx) or with the package manager. wheelhouse is the remote
+// wheelhouse directory, or empty.
+func buildBootstrapShell(b *BootstrapConfig, dir, wheelhouse string) string {
+	python := b.Python
+	if python == "" {
+		python = DefaultBootstrapPython
+	}
+	var pipArgs []string
+	if wheelhouse != "" {
+		pipArgs = []string{"--no-index", "--find-links=" + wheelhouse}
+	}
+	navigator := pipRequirement("ansible-navigator", b.NavigatorVersion)
+	var injected []string
+	if b.AnsibleCoreVersion != "" {
+		injected = append(injected, pipRequirement("ansible-core", b.AnsibleCoreVersion))
+	}
+	injected = append(injected, b.ExtraPackages...)
+
+	requirePython := fmt.Sprintf("command -v %[1]s >/dev/null 2>&1 || { echo 'bootstrap: %[1]s not found on the target' >&2; exit 127; }",
+		shellEscapePOSIX(python))
+
+	switch b.Method {
+	case BootstrapMethodPipx:
+		env := fmt.Sprintf("PIPX_HOME=%s PIPX_BIN_DIR=%s PIPX_DEFAULT_PYTHON=%s",
+			shellEscapePOSIX(dir+"/pipx"), shellEscapePOSIX(dir+"/bin"), shellEscapePOSIX(python))
+		pipxPipArgs := ""
+		if len(pipArgs) > 0 {
+			pipxPipArgs = " " + shellEscapePOSIX("--pip-args="+strings.Join(pipArgs, " "))
+		}
+		command := fmt.Sprintf("%s && %s pipx install --include-deps%s %s",
+			requirePython, env, pipxPipArgs, shellEscapePOSIX(navigator))
+		if len(injected) > 0 {
+			command += fmt.Sprintf(" && %s pipx inject --include-apps%s ansible-navigator %s",
+				env, pipxPipArgs, strings.Join(shellEscapeAllPOSIX(injected), " "))
+		}
+		return command
+
+	case BootstrapMethodPackage:
+		packages := b.Packages
+		if len(packages) == 0 {
+			packages = defaultBootstrapPackages
+		}
+		pkgs := strings.Join(shellEscapeAllPOSIX(packages), " ")
+		return sudoIfNotRootShell +
+			`if command -v dnf >/dev/null 2>&1; then $SUDO dnf install -y ` + pkgs + `; ` +
+			`elif command -v yum >/dev/null 2>&1; then $SUDO yum install -y ` + pkgs + `; ` +
+			`elif command -v apt-get >/dev/null 2>&1; then $SUDO apt-get update && $SUDO env DEBIAN_FRONTEND=noninteractive apt-get install -y ` + pkgs + `; ` +
+			`elif command -v zypper >/dev/null 2>&1; then $SUDO zypper --non-interactive install ` + pkgs + `; ` +
+			`elif command -v apk >/dev/null 2>&1; then $SUDO apk add ` + pkgs + `; ` +
+			`else echo 'bootstrap: no supported package manager (dnf, yum, apt-get, zypper, apk) found on the target' >&2; exit 1; fi`
+
+	default:
+		install := append(append([]string{"install", "--disable-pip-version-check"}, pipArgs...), navigator)
+		install = append(install, injected...)
+		return fmt.Sprintf("%s && %s -m venv %s && %s %s",
+			requirePython, shellEscapePOSIX(python), shellEscapePOSIX(dir),
+			shellEscapePOSIX(dir+"/bin/pip"), strings.Join(shellEscapeAllPOSIX(install), " "))
+	}
+}
+
+// bootstrapAnsible installs ansible-navigator and ansible-core on the target as
+// configured by the bootstrap block, and prepends the installation's bin
+// directory to ansible_navigator_path.
+func (p *Provisioner) bootstrapAnsible(ui packersdk.Ui, comm packersdk.Communicator) error {
+	b := p.config.Bootstrap
+	method := b.Method
+	if method == "" {
+		method = BootstrapMethodPip
+	}
+
+	var wheelhouse string
+	if b.Wheelhouse != "" {
+		ui.Message("Uploading bootstrap wheelhouse...")
+		wheelhouse = filepath.ToSlash(filepath.Join(p.stagingDir, "wheelhouse"))
+		if err := p.uploadTree(ui, comm, b.Wheelhouse, wheelhouse, nil); err != nil {
+			return fmt.Errorf("Error uploading bootstrap wheelhouse: %s", err)
+		}
+	}
+
+	dir := p.bootstrapDir()
+	ui.Say(fmt.Sprintf("Bootstrapping ansible-navigator on the target (%s)...", method))
+	cmd := &packersdk.RemoteCmd{
+		Command: buildBootstrapShell(b, dir, wheelhouse),
+	}
+	if err := cmd.RunWithUi(context.TODO(), comm, ui); err != nil {
+		return fmt.Errorf("Error bootstrapping ansible-navigator: %s", err)
+	}
+	if cmd.ExitStatus() != 0 {
+		return fmt.Errorf("Error bootstrapping ansible-navigator: exit status %d. See output above for more information.", cmd.ExitStatus())
+	}
+
+	if method != BootstrapMethodPackage {
+		p.config.AnsibleNavigatorPath = append([]string{dir + "/bin"}, p.config.AnsibleNavigatorPath...)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildBootstrapShell_Pip(t *testing.T) {
+	b := &BootstrapConfig{NavigatorVersion: "24.2.0", AnsibleCoreVersion: ">=2.16,<2.17", ExtraPackages: []string{"jmespath"}}
+	require.Equal(t,
+		"command -v python3 >/dev/null 2>&1 || { echo 'bootstrap: python3 not found on the target' >&2; exit 127; } && "+
+			"python3 -m venv /stage/venv && /stage/venv/bin/pip install --disable-pip-version-check "+
+			"ansible-navigator==24.2.0 'ansible-core>=2.16,<2.17' jmespath",
+		buildBootstrapShell(b, "/stage/venv", ""))
+
+	b = &BootstrapConfig{Python: "/usr/bin/python3.11"}
+	require.Contains(t, buildBootstrapShell(b, "/stage/venv", "/stage/wheelhouse"),
+		"/usr/bin/python3.11 -m venv /stage/venv && /stage/venv/bin/pip install --disable-pip-version-check --no-index --find-links=/stage/wheelhouse ansible-navigator")
+}
+
+func TestBuildBootstrapShell_Pipx(t *testing.T) {
+	b := &BootstrapConfig{Method: BootstrapMethodPipx, AnsibleCoreVersion: "2.16.3"}
+	require.Contains(t, buildBootstrapShell(b, "/stage/venv", "/stage/wheelhouse"),
+		"PIPX_HOME=/stage/venv/pipx PIPX_BIN_DIR=/stage/venv/bin PIPX_DEFAULT_PYTHON=python3 pipx install --include-deps "+
+			"'--pip-args=--no-index --find-links=/stage/wheelhouse' ansible-navigator && "+
+			"PIPX_HOME=/stage/venv/pipx PIPX_BIN_DIR=/stage/venv/bin PIPX_DEFAULT_PYTHON=python3 pipx inject --include-apps "+
+			"'--pip-args=--no-index --find-links=/stage/wheelhouse' ansible-navigator ansible-core==2.16.3")
+
+	b.AnsibleCoreVersion = ""
+	require.NotContains(t, buildBootstrapShell(b, "/stage/venv", ""), "pipx inject")
+}
+
+func TestBuildBootstrapShell_Package(t *testing.T) {
+	shell := buildBootstrapShell(&BootstrapConfig{Method: BootstrapMethodPackage}, "/stage/venv", "")
+	require.Contains(t, shell, "$SUDO dnf install -y ansible-core ansible-navigator;")
+	require.Contains(t, shell, "$SUDO env DEBIAN_FRONTEND=noninteractive apt-get install -y ansible-core ansible-navigator;")
+	require.NotContains(t, shell, "/stage/venv")
+
+	shell = buildBootstrapShell(&BootstrapConfig{Method: BootstrapMethodPackage, Packages: []string{"ansible-core"}}, "", "")
+	require.Contains(t, shell, "$SUDO apk add ansible-core;")
+}
+
+func TestValidateBootstrap(t *testing.T) {
+	wheelhouse := t.TempDir()
+	require.Empty(t, validateBootstrap(nil))
+	require.Empty(t, validateBootstrap(&BootstrapConfig{NavigatorVersion: "24.2.0", Wheelhouse: wheelhouse}))
+	require.Empty(t, validateBootstrap(&BootstrapConfig{Method: BootstrapMethodPipx, Path: "/opt/ansible", Keep: true}))
+	require.Empty(t, validateBootstrap(&BootstrapConfig{Method: BootstrapMethodPackage, Packages: []string{"ansible"}}))
+
+	tests := []struct {
+		name   string
+		b      *BootstrapConfig
+		errMsg string
+	}{
+		{"invalid method", &BootstrapConfig{Method: "conda"}, "invalid bootstrap.method"},
+		{"packages with pip", &BootstrapConfig{Packages: []string{"ansible"}}, `bootstrap.packages requires method "package"`},
+		{"package with version", &BootstrapConfig{Method: BootstrapMethodPackage, NavigatorVersion: "24.2.0"}, "not supported with method"},
+		{"package with extra_packages", &BootstrapConfig{Method: BootstrapMethodPackage, ExtraPackages: []string{"jmespath"}}, "not supported with method"},
+		{"missing wheelhouse", &BootstrapConfig{Wheelhouse: filepath.Join(wheelhouse, "missing")}, "bootstrap.wheelhouse"},
+		{"keep without path", &BootstrapConfig{Keep: true}, "bootstrap.keep requires bootstrap.path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateBootstrap(tt.b)
+			require.Len(t, errs, 1)
+			require.Contains(t, errs[0].Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestProvisioner_Bootstrap(t *testing.T) {
+	playbook := filepath.Join(t.TempDir(), "site.yml")
+	require.NoError(t, os.WriteFile(playbook, []byte("- hosts: all\n  tasks: []\n"), 0o644))
+
+	var p Provisioner
+	config := testConfig()
+	config["play"] = []map[string]interface{}{{"target": playbook}}
+	config["ansible_navigator_path"] = []string{"/opt/bin"}
+	config["bootstrap"] = map[string]interface{}{"path": "/opt/ansible"}
+	require.NoError(t, p.Prepare(config))
+
+	comm := &communicatorMock{}
+	require.NoError(t, p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+
+	require.Contains(t, comm.startCommand[1], "python3 -m venv /opt/ansible && /opt/ansible/bin/pip install")
+	var run string
+	for _, cmd := range comm.startCommand {
+		if strings.Contains(cmd, "ansible-navigator run") {
+			run = cmd
+		}
+	}
+	require.Contains(t, run, `PATH="/opt/ansible/bin:/opt/bin:$PATH"`)
+	// The installation is removed before the staging directory.
+	require.Equal(t, "rm -rf '/opt/ansible'", comm.startCommand[len(comm.startCommand)-2])
+	require.Equal(t, "rm -rf '"+p.stagingDir+"'", comm.startCommand[len(comm.startCommand)-1])
+}
@@ -0,0 +1,101 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"fmt"
+)
+
+// containerEngine is the container engine (docker or podman) that runs execution
+// environment containers. The local provisioner runs ansible-navigator on the
+// target, so engine features are detected there at run time: the probes return
+// POSIX shell snippets instead of results.
+//
+// prefix is prepended to every engine CLI invocation; it is either empty or a
+// PATH assignment followed by a space (see buildPathPrefixForRemoteShell).
+type containerEngine interface {
+	// Name returns the engine executable: "docker" or "podman".
+	Name() string
+	// HostEnvVar returns the environment variable that selects the engine endpoint.
+	HostEnvVar() string
+	// HostGateway returns the hostname that resolves to the target from inside
+	// containers started by the engine.
+	HostGateway() string
+	// SocketShell prints the engine's local API socket path, or nothing when the
+	// engine is reached over the network. It respects the active Docker context.
+	SocketShell(prefix string) string
+	// RootlessShell succeeds when the engine runs containers without root privileges.
+	RootlessShell(prefix string) string
+	// ImagePresentShell succeeds when image is available in the local image store.
+	ImagePresentShell(prefix, image string) string
+	// SELinuxRelabelShell succeeds when bind mounts need the ":Z" option.
+	SELinuxRelabelShell(prefix string) string
+}
+
+// containerEnginesFor returns the engines ansible-navigator may use for the
+// execution_environment.container_engine setting, in its detection order.
+func containerEnginesFor(setting string) []containerEngine {
+	switch setting {
+	case "docker":
+		return []containerEngine{dockerEngine{}}
+	case "podman":
+		return []containerEngine{podmanEngine{}}
+	default:
+		return []containerEngine{podmanEngine{}, dockerEngine{}}
+	}
+}
+
+// dockerEngine is Docker Engine or Docker Desktop, rootful or rootless.
+type dockerEngine struct{}
+
+func (dockerEngine) Name() string        { return "docker" }
+func (dockerEngine) HostEnvVar() string  { return "DOCKER_HOST" }
+func (dockerEngine) HostGateway() string { return "host.docker.internal" }
+
+func (dockerEngine) SocketShell(prefix string) string {
+	// DOCKER_HOST wins over the active context, as in the docker CLI.
+	return fmt.Sprintf(`{ _pk_host="${DOCKER_HOST:-$(%sdocker context inspect --format '{{.Endpoints.docker.Host}}' 2>/dev/null)}"; `+
+		`case "$_pk_host" in unix://*) echo "${_pk_host#unix://}";; "") echo /var/run/docker.sock;; esac; }`, prefix)
+}
+
+func (dockerEngine) RootlessShell(prefix string) string {
+	return fmt.Sprintf(`%sdocker info --format '{{json .SecurityOptions}}' 2>/dev/null | grep -q name=rootless`, prefix)
+}
+
+func (dockerEngine) ImagePresentShell(prefix, image string) string {
+	return fmt.Sprintf(`%sdocker image inspect --format '{{.Id}}' %s >/dev/null 2>&1`, prefix, shellEscapePOSIX(image))
+}
+
+func (dockerEngine) SELinuxRelabelShell(prefix string) string {
+	return fmt.Sprintf(`%sdocker info --format '{{json .SecurityOptions}}' 2>/dev/null | grep -q name=selinux`, prefix)
+}
+
+// podmanEngine is Podman, rootful or rootless.
+type podmanEngine struct{}
+
+func (podmanEngine) Name() string       { return "podman" }
+func (podmanEngine) HostEnvVar() string { return "CONTAINER_HOST" }
+
+// HostGateway is added to /etc/hosts of every podman container.
+func (podmanEngine) HostGateway() string { return "host.containers.internal" }
+
+func (podmanEngine) SocketShell(prefix string) string {
+	return fmt.Sprintf(`{ _pk_host="${CONTAINER_HOST:-$(%spodman info --format '{{.Host.RemoteSocket.Path}}' 2>/dev/null)}"; `+
+		`case "$_pk_host" in unix://*) echo "${_pk_host#unix://}";; /*) echo "$_pk_host";; esac; }`, prefix)
+}
+
+func (podmanEngine) RootlessShell(prefix string) string {
+	// Fall back to the user id when podman cannot be queried.
+	return fmt.Sprintf(`[ "$(%spodman info --format '{{.Host.Security.Rootless}}' 2>/dev/null || { [ "$(id -u)" = 0 ] && echo false || echo true; })" = true ]`, prefix)
+}
+
+func (podmanEngine) ImagePresentShell(prefix, image string) string {
+	return fmt.Sprintf(`%spodman image exists %s >/dev/null 2>&1`, prefix, shellEscapePOSIX(image))
+}
+
+func (podmanEngine) SELinuxRelabelShell(prefix string) string {
+	return fmt.Sprintf(`[ "$(%spodman info --format '{{.Host.Security.SELinuxEnabled}}' 2>/dev/null)" = true ]`, prefix)
+}
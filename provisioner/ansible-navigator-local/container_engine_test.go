@@ -0,0 +1,83 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runPreflightWithStub runs the preflight snippet with an engine CLI stub that
+// is found first in PATH.
+func runPreflightWithStub(t *testing.T, engine, stub string, ee *ExecutionEnvironment) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, engine), []byte("#!/bin/sh\n"+stub), 0o755))
+
+	cmd := exec.Command("/bin/sh", "-c", buildEEPreflightShell(`PATH="`+dir+`:$PATH"`, ee))
+	cmd.Env = []string{"PATH=/usr/bin:/bin"}
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+func TestContainerEnginesFor(t *testing.T) {
+	names := func(engines []containerEngine) []string {
+		var out []string
+		for _, e := range engines {
+			out = append(out, e.Name())
+		}
+		return out
+	}
+	require.Equal(t, []string{"docker"}, names(containerEnginesFor("docker")))
+	require.Equal(t, []string{"podman"}, names(containerEnginesFor("podman")))
+	require.Equal(t, []string{"podman", "docker"}, names(containerEnginesFor("auto")))
+	require.Equal(t, []string{"podman", "docker"}, names(containerEnginesFor("")))
+
+	require.Equal(t, "host.docker.internal", dockerEngine{}.HostGateway())
+	require.Equal(t, "host.containers.internal", podmanEngine{}.HostGateway())
+}
+
+func TestEEPreflightShell_AutoPrefersRootlessPodman(t *testing.T) {
+	stub := `case "$*" in
+  *Rootless*) echo true ;;
+  *SELinuxEnabled*) echo true ;;
+  *RemoteSocket*) echo /run/user/1000/podman/podman.sock ;;
+  "image exists quay.io/ansible/creator-ee:v1") exit 0 ;;
+  *) exit 1 ;;
+esac
+`
+	out := runPreflightWithStub(t, "podman", stub, &ExecutionEnvironment{Image: "quay.io/ansible/creator-ee:v1"})
+
+	require.Contains(t, out, "[DEBUG] [EE preflight] container engine: podman (rootless), SELinux relabel: yes")
+	require.Contains(t, out, "[DEBUG] [EE preflight] CONTAINER_HOST: unset")
+	require.Contains(t, out, "[DEBUG] [EE preflight] /run/user/1000/podman/podman.sock: missing")
+	require.Contains(t, out, "[DEBUG] [EE preflight] podman client: found in PATH")
+	require.Contains(t, out, "[DEBUG] [EE preflight] image quay.io/ansible/creator-ee:v1: present locally")
+	require.NotContains(t, out, "DOCKER_HOST")
+}
+
+func TestEEPreflightShell_DockerContextAndMissingImage(t *testing.T) {
+	stub := `case "$*" in
+  "context inspect"*) echo unix:///run/user/1000/docker.sock ;;
+  info*) echo '["name=seccomp,profile=builtin","name=rootless"]' ;;
+  *) exit 1 ;;
+esac
+`
+	out := runPreflightWithStub(t, "docker", stub, &ExecutionEnvironment{
+		ContainerEngine: "docker",
+		Image:           "quay.io/ansible/creator-ee:v1",
+		PullPolicy:      "never",
+	})
+
+	require.Contains(t, out, "[DEBUG] [EE preflight] container engine: docker (rootless), SELinux relabel: no")
+	require.Contains(t, out, "[DEBUG] [EE preflight] /run/user/1000/docker.sock: missing")
+	require.Contains(t, out, `[DEBUG][WARN] [EE preflight] image quay.io/ansible/creator-ee:v1: not present locally and pull_policy is "never"`)
+}
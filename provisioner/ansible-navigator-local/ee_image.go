@@ -0,0 +1,54 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// imageDigestPattern matches the image_digest setting.
+var imageDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// imageRepository returns image without its tag or digest.
+func imageRepository(image string) string {
+	repo, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo
+}
+
+// pinImageDigest points the execution environment at image_digest. The image is
+// pulled on the target by ansible-navigator, and the registry only serves it
+// when its content matches the digest.
+func pinImageDigest(ee *ExecutionEnvironment) {
+	if ee == nil || !ee.Enabled || ee.Image == "" || ee.ImageDigest == "" {
+		return
+	}
+	ee.Image = imageRepository(ee.Image) + "@" + ee.ImageDigest
+}
+
+// validateImageDigest validates execution_environment.image_digest.
+func validateImageDigest(ee *ExecutionEnvironment) []error {
+	if ee.ImageDigest == "" {
+		return nil
+	}
+	if !imageDigestPattern.MatchString(ee.ImageDigest) {
+		return []error{fmt.Errorf(
+			"navigator_config.execution_environment.image_digest must be a sha256 digest (sha256:<64 hex characters>), got %q", ee.ImageDigest)}
+	}
+	if ee.Image == "" {
+		return []error{fmt.Errorf(
+			"navigator_config.execution_environment.image_digest requires navigator_config.execution_environment.image")}
+	}
+	if _, digest, ok := strings.Cut(ee.Image, "@"); ok && digest != ee.ImageDigest {
+		return []error{fmt.Errorf(
+			"navigator_config.execution_environment.image is pinned to %s, which does not match image_digest %s", digest, ee.ImageDigest)}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testDigest = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+func TestPinImageDigest(t *testing.T) {
+	ee := &ExecutionEnvironment{Enabled: true, Image: "localhost:5000/ee:v1", ImageDigest: testDigest}
+	pinImageDigest(ee)
+	require.Equal(t, "localhost:5000/ee@"+testDigest, ee.Image)
+
+	// Pinning is idempotent across repeated Provision calls.
+	pinImageDigest(ee)
+	require.Equal(t, "localhost:5000/ee@"+testDigest, ee.Image)
+
+	ee = &ExecutionEnvironment{Enabled: true, Image: "quay.io/ansible/creator-ee:v1"}
+	pinImageDigest(ee)
+	require.Equal(t, "quay.io/ansible/creator-ee:v1", ee.Image)
+}
+
+func TestValidateNavigatorSettings_ImageDigest(t *testing.T) {
+	config := &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: true, Image: "ee:v1", ImageDigest: "sha256:ABC"}}
+	errs := validateNavigatorSettings(config)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "navigator_config.execution_environment.image_digest must be a sha256 digest")
+
+	config.ExecutionEnvironment.ImageDigest = testDigest
+	require.Empty(t, validateNavigatorSettings(config))
+}
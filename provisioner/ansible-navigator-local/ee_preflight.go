@@ -1,8 +1,11 @@
 package ansiblenavigatorlocal
 
 import (
+	"context"
 	"fmt"
 	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
 )
 
 func isExecutionEnvironmentEnabled(nc *NavigatorConfig) bool {
@@ -10,26 +13,185 @@ func isExecutionEnvironmentEnabled(nc *NavigatorConfig) bool {
 }
 
 // buildEEDockerPreflightShell returns a POSIX-shell snippet that prints
-// debug-only EE/docker diagnostics and always succeeds.
+// debug-only diagnostics for the docker engine and always succeeds.
 //
 // pathPrefixAssignment should be the raw PATH assignment returned by
 // buildPathPrefixForRemoteShell (e.g. PATH="/opt/bin:$PATH"), or empty.
 func buildEEDockerPreflightShell(pathPrefixAssignment string) string {
+	return buildEEPreflightShell(pathPrefixAssignment, &ExecutionEnvironment{ContainerEngine: "docker"})
+}
+
+// buildEEPreflightShell returns a POSIX-shell snippet that prints debug-only
+// diagnostics for the container engine that runs the execution environment and
+// always succeeds. With container_engine "auto" (or unset) the engine is picked
+// on the target in ansible-navigator's detection order.
+func buildEEPreflightShell(pathPrefixAssignment string, ee *ExecutionEnvironment) string {
 	prefix := ""
 	if strings.TrimSpace(pathPrefixAssignment) != "" {
 		prefix = pathPrefixAssignment + " "
 	}
+	if ee == nil {
+		ee = &ExecutionEnvironment{}
+	}
+
+	// The image signature was verified before the first play; a failure aborts the build.
+	verified := ""
+	if ee.Verify != nil && ee.Image != "" {
+		method := "policy"
+		if ee.Verify.CosignKey != "" {
+			method = "cosign"
+		}
+		verified = fmt.Sprintf(`; echo "[DEBUG] [EE preflight] image %s: signature verified (%s)"`, strings.ReplaceAll(ee.Image, `"`, `\"`), method)
+	}
+
+	engines := containerEnginesFor(ee.ContainerEngine)
+	if len(engines) == 1 {
+		return buildEnginePreflightShell(prefix, engines[0], ee) + verified
+	}
+
+	// Pick the first engine found in PATH; the last one is reported when none is.
+	var b strings.Builder
+	for i, engine := range engines {
+		switch {
+		case i == 0:
+			fmt.Fprintf(&b, "if %scommand -v %s >/dev/null 2>&1; then ", prefix, engine.Name())
+		case i < len(engines)-1:
+			fmt.Fprintf(&b, "elif %scommand -v %s >/dev/null 2>&1; then ", prefix, engine.Name())
+		default:
+			b.WriteString("else ")
+		}
+		b.WriteString(buildEnginePreflightShell(prefix, engine, ee))
+		b.WriteString("; ")
+	}
+	b.WriteString("fi")
+	b.WriteString(verified)
+	return b.String()
+}
+
+func buildEnginePreflightShell(prefix string, engine containerEngine, ee *ExecutionEnvironment) string {
+	name := engine.Name()
+	hostVar := engine.HostEnvVar()
 
 	parts := []string{
-		// DOCKER_HOST (redacted)
-		`if [ -z "${DOCKER_HOST+x}" ] || [ -z "$DOCKER_HOST" ]; then echo "[DEBUG] [EE preflight] DOCKER_HOST: unset"; else echo "[DEBUG] [EE preflight] DOCKER_HOST: set (redacted)"; fi`,
-		// docker.sock visibility
-		`if [ -S /var/run/docker.sock ]; then echo "[DEBUG] [EE preflight] /var/run/docker.sock: present (socket)"; elif [ -e /var/run/docker.sock ]; then echo "[DEBUG] [EE preflight] /var/run/docker.sock: present (not a socket)"; else echo "[DEBUG] [EE preflight] /var/run/docker.sock: missing"; fi`,
-		// docker client in PATH (using the same PATH prefix semantics as ansible-navigator execution)
-		fmt.Sprintf(`if %scommand -v docker >/dev/null 2>&1; then echo "[DEBUG] [EE preflight] docker client: found in PATH"; else echo "[DEBUG] [EE preflight] docker client: missing in PATH"; fi`, prefix),
+		// engine mode and SELinux relabel needs
+		fmt.Sprintf(`if %s; then _pk_mode=rootless; else _pk_mode=rootful; fi; if %s; then _pk_relabel=yes; else _pk_relabel=no; fi; echo "[DEBUG] [EE preflight] container engine: %s ($_pk_mode), SELinux relabel: $_pk_relabel"`,
+			engine.RootlessShell(prefix), engine.SELinuxRelabelShell(prefix), name),
+		// endpoint variable (redacted)
+		fmt.Sprintf(`if [ -z "${%[1]s+x}" ] || [ -z "$%[1]s" ]; then echo "[DEBUG] [EE preflight] %[1]s: unset"; else echo "[DEBUG] [EE preflight] %[1]s: set (redacted)"; fi`, hostVar),
+		// engine socket visibility
+		fmt.Sprintf(`_pk_sock="$(%s)"; if [ -z "$_pk_sock" ]; then echo "[DEBUG] [EE preflight] %s socket: remote endpoint"; elif [ -S "$_pk_sock" ]; then echo "[DEBUG] [EE preflight] $_pk_sock: present (socket)"; elif [ -e "$_pk_sock" ]; then echo "[DEBUG] [EE preflight] $_pk_sock: present (not a socket)"; else echo "[DEBUG] [EE preflight] $_pk_sock: missing"; fi`,
+			engine.SocketShell(prefix), name),
+		// engine client in PATH (using the same PATH prefix semantics as ansible-navigator execution)
+		fmt.Sprintf(`if %[1]scommand -v %[2]s >/dev/null 2>&1; then echo "[DEBUG] [EE preflight] %[2]s client: found in PATH"; else echo "[DEBUG] [EE preflight] %[2]s client: missing in PATH"; fi`, prefix, name),
+	}
+
+	if name == "docker" {
 		// warning-only “likely DinD” heuristic when dockerd process is detected
-		fmt.Sprintf(`if %scommand -v ps >/dev/null 2>&1 && %scommand -v grep >/dev/null 2>&1; then if ps -eo comm 2>/dev/null | grep -qx dockerd; then echo "[DEBUG][WARN] [EE preflight] detected dockerd process (possible DinD); ensure DOCKER_HOST or /var/run/docker.sock is correctly wired"; fi; fi`, prefix, prefix),
+		parts = append(parts, fmt.Sprintf(`if %scommand -v ps >/dev/null 2>&1 && %scommand -v grep >/dev/null 2>&1; then if ps -eo comm 2>/dev/null | grep -qx dockerd; then echo "[DEBUG][WARN] [EE preflight] detected dockerd process (possible DinD); ensure DOCKER_HOST or /var/run/docker.sock is correctly wired"; fi; fi`, prefix, prefix))
+	}
+
+	if ee.Image != "" {
+		image := strings.ReplaceAll(ee.Image, `"`, `\"`)
+		missing := fmt.Sprintf(`echo "[DEBUG] [EE preflight] image %s: not present locally (will be pulled)"`, image)
+		if ee.PullPolicy == "never" {
+			missing = fmt.Sprintf(`echo "[DEBUG][WARN] [EE preflight] image %s: not present locally and pull_policy is \"never\""`, image)
+		}
+		parts = append(parts, fmt.Sprintf(`if %s; then echo "[DEBUG] [EE preflight] image %s: present locally"; else %s; fi`,
+			engine.ImagePresentShell(prefix, ee.Image), image, missing))
 	}
 
 	return strings.Join(parts, "; ")
 }
+
+// execution_environment.preflight modes
+const (
+	EEPreflightWarn = "warn"
+	EEPreflightFail = "fail"
+	EEPreflightOff  = "off"
+)
+
+// eePreflightMode returns the effective execution_environment.preflight mode.
+func eePreflightMode(ee *ExecutionEnvironment) string {
+	if ee == nil || ee.Preflight == "" {
+		return EEPreflightWarn
+	}
+	return ee.Preflight
+}
+
+// buildEEPreflightCheckShell returns a POSIX-shell script that checks, on the
+// target, that the container engine responds, the image exists or can be pulled
+// and the volume mount sources exist. Each problem is printed as an
+// "[EE preflight] problem:" line; in "fail" mode the script exits 1 when any
+// problem is found.
+func buildEEPreflightCheckShell(pathPrefixAssignment string, ee *ExecutionEnvironment) string {
+	prefix := ""
+	if strings.TrimSpace(pathPrefixAssignment) != "" {
+		prefix = pathPrefixAssignment + " "
+	}
+
+	parts := []string{
+		`_pk_problems=0`,
+		`_pk_problem() { echo "[EE preflight] problem: $*"; _pk_problems=$((_pk_problems+1)); }`,
+	}
+
+	// Pick the engine in ansible-navigator's detection order.
+	var pick strings.Builder
+	for i, engine := range containerEnginesFor(ee.ContainerEngine) {
+		keyword := "elif"
+		if i == 0 {
+			keyword = "if"
+		}
+		fmt.Fprintf(&pick, "%s %scommand -v %s >/dev/null 2>&1; then _pk_engine=%s; ", keyword, prefix, engine.Name(), engine.Name())
+	}
+	pick.WriteString("else _pk_engine=; fi")
+	parts = append(parts, pick.String())
+
+	imageCheck := "true"
+	if ee.Image != "" {
+		image := shellEscapePOSIX(ee.Image)
+		display := strings.ReplaceAll(ee.Image, `"`, `\"`)
+		missing := fmt.Sprintf(`if ! %s"$_pk_engine" manifest inspect %s >/dev/null 2>&1; then _pk_problem "image %s is not present locally and cannot be pulled; check the image name and registry credentials ($_pk_engine login)"; fi`,
+			prefix, image, display)
+		if ee.PullPolicy == "never" {
+			missing = fmt.Sprintf(`_pk_problem "image %s is not present locally and pull_policy is \"never\"; pull it first or change pull_policy"`, display)
+		}
+		imageCheck = fmt.Sprintf(`if ! %s"$_pk_engine" image inspect %s >/dev/null 2>&1; then %s; fi`, prefix, image, missing)
+	}
+	parts = append(parts, fmt.Sprintf(`if [ -z "$_pk_engine" ]; then _pk_problem "no usable container engine on the target; install podman or docker, or set ansible_navigator_path"; `+
+		`elif ! %[1]s"$_pk_engine" --version >/dev/null 2>&1; then _pk_problem "the $_pk_engine client does not respond ($_pk_engine --version)"; `+
+		`elif ! %[1]s"$_pk_engine" info >/dev/null 2>&1; then _pk_problem "cannot reach the $_pk_engine endpoint ($_pk_engine info); start the engine service, or check DOCKER_HOST or CONTAINER_HOST"; `+
+		`else %[2]s; fi`, prefix, imageCheck))
+
+	for i, mount := range ee.VolumeMounts {
+		parts = append(parts, fmt.Sprintf(`[ -e %s ] || _pk_problem "execution_environment.volume_mounts[%d].src %s does not exist on the target"`,
+			shellEscapePOSIX(mount.Src), i, strings.ReplaceAll(mount.Src, `"`, `\"`)))
+	}
+
+	parts = append(parts, `if [ "$_pk_problems" -eq 0 ]; then echo "[EE preflight] checks passed"; fi`)
+	if eePreflightMode(ee) == EEPreflightFail {
+		parts = append(parts, `[ "$_pk_problems" -eq 0 ]`)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// runEEPreflight runs the execution environment preflight checks on the target.
+// Problems are warnings in "warn" mode and fail the build in "fail" mode.
+func (p *Provisioner) runEEPreflight(ui packersdk.Ui, comm packersdk.Communicator) error {
+	ee := p.config.NavigatorConfig.ExecutionEnvironment
+	if p.config.TargetShell == TargetShellPowerShell {
+		ui.Message("Skipping execution environment preflight checks: they need a POSIX shell on the target")
+		return nil
+	}
+	ui.Message("Running execution environment preflight checks on the target...")
+	cmd := &packersdk.RemoteCmd{
+		Command: buildEEPreflightCheckShell(buildPathPrefixForRemoteShell(p.config.AnsibleNavigatorPath), ee),
+	}
+	if err := cmd.RunWithUi(context.TODO(), comm, ui); err != nil {
+		return err
+	}
+	if cmd.ExitStatus() != 0 {
+		return fmt.Errorf("execution environment preflight failed (see the [EE preflight] problems above; " +
+			"set execution_environment.preflight = \"warn\" to continue anyway)")
+	}
+	return nil
+}
@@ -127,3 +127,65 @@ func TestEEDockerPreflightShell_DockerdDetected_EmitsWarningOnly(t *testing.T) {
 
 	require.Contains(t, string(out), "[DEBUG][WARN] [EE preflight] detected dockerd process")
 }
+
+// runPreflightCheck runs the preflight check script with engine CLI stubs
+// (name -> script body) that are the only engines found in PATH.
+func runPreflightCheck(t *testing.T, stubs map[string]string, ee *ExecutionEnvironment) (string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, stub := range stubs {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+stub), 0o755))
+	}
+	cmd := exec.Command("/bin/sh", "-c", buildEEPreflightCheckShell(`PATH="`+dir+`:$PATH"`, ee))
+	cmd.Env = []string{"PATH=/usr/bin:/bin"}
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestEEPreflightCheckShell_Passes(t *testing.T) {
+	mountSrc := t.TempDir()
+	out, err := runPreflightCheck(t, map[string]string{"podman": "exit 0\n"}, &ExecutionEnvironment{
+		Image:        "quay.io/ansible/creator-ee:v1",
+		VolumeMounts: []VolumeMount{{Src: mountSrc, Dest: "/data"}},
+		Preflight:    EEPreflightFail,
+	})
+	require.NoError(t, err, out)
+	require.Equal(t, "[EE preflight] checks passed\n", out)
+}
+
+func TestEEPreflightCheckShell_ReportsProblems(t *testing.T) {
+	stub := `case "$1" in
+  --version|info) exit 0 ;;
+  *) exit 1 ;;
+esac
+`
+	ee := &ExecutionEnvironment{
+		ContainerEngine: "docker",
+		Image:           "quay.io/ansible/creator-ee:v1",
+		PullPolicy:      "never",
+		VolumeMounts:    []VolumeMount{{Src: "/nonexistent/src", Dest: "/data"}},
+		Preflight:       EEPreflightFail,
+	}
+	out, err := runPreflightCheck(t, map[string]string{"docker": stub}, ee)
+	require.Error(t, err, "fail mode exits non-zero")
+	require.Contains(t, out, `[EE preflight] problem: image quay.io/ansible/creator-ee:v1 is not present locally and pull_policy is "never"`)
+	require.Contains(t, out, "[EE preflight] problem: execution_environment.volume_mounts[0].src /nonexistent/src does not exist on the target")
+	require.NotContains(t, out, "checks passed")
+
+	// Pullable images pass; warn mode never fails the command.
+	ee.PullPolicy, ee.VolumeMounts, ee.Preflight = "", nil, EEPreflightWarn
+	out, err = runPreflightCheck(t, map[string]string{"docker": strings.Replace(stub, "--version|info", "--version|info|manifest", 1)}, ee)
+	require.NoError(t, err, out)
+	require.Equal(t, "[EE preflight] checks passed\n", out)
+
+	out, err = runPreflightCheck(t, map[string]string{"podman": "exit 0\n"}, ee)
+	require.NoError(t, err, out)
+	require.Contains(t, out, "[EE preflight] problem: no usable container engine on the target")
+}
+
+func TestValidateNavigatorSettings_Preflight(t *testing.T) {
+	config := &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: true, Preflight: "strict"}}
+	errs := validateNavigatorSettings(config)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "navigator_config.execution_environment.preflight must be")
+}
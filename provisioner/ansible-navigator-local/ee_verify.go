@@ -0,0 +1,109 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// isCosignKMSKey reports whether a cosign key is a KMS or provider URI rather
+// than a file (awskms://, gcpkms://, hashivault://, k8s://, ...).
+func isCosignKMSKey(key string) bool {
+	return strings.Contains(key, "://")
+}
+
+// validateImageVerify validates execution_environment.verify.
+func validateImageVerify(ee *ExecutionEnvironment) []error {
+	v := ee.Verify
+	if v == nil {
+		return nil
+	}
+	var errs []error
+	switch {
+	case v.CosignKey == "" && v.Policy == "":
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.verify requires cosign_key or policy"))
+	case v.CosignKey != "" && v.Policy != "":
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.verify: cosign_key and policy are mutually exclusive"))
+	case v.CosignKey != "" && !isCosignKMSKey(v.CosignKey):
+		if err := validateFileConfig(v.CosignKey, "navigator_config.execution_environment.verify.cosign_key", true); err != nil {
+			errs = append(errs, err)
+		}
+	case v.Policy != "":
+		if err := validateFileConfig(v.Policy, "navigator_config.execution_environment.verify.policy", true); err != nil {
+			errs = append(errs, err)
+		}
+		if ee.ContainerEngine == "docker" {
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.execution_environment.verify.policy requires the podman container engine"))
+		}
+	}
+	if ee.Image == "" {
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.verify requires navigator_config.execution_environment.image"))
+	}
+	return errs
+}
+
+// buildImageVerifyShell returns the command that verifies the signature of the
+// execution environment image on the target. file is the uploaded cosign key or
+// policy (a cosign KMS URI is passed through as is).
+//
+// pathPrefixAssignment should be the raw PATH assignment returned by
+// buildPathPrefixForRemoteShell (e.g. PATH="/opt/bin:$PATH"), or empty.
+func buildImageVerifyShell(pathPrefixAssignment string, ee *ExecutionEnvironment, file string) string {
+	prefix := ""
+	if strings.TrimSpace(pathPrefixAssignment) != "" {
+		prefix = pathPrefixAssignment + " "
+	}
+	if ee.Verify.CosignKey != "" {
+		return fmt.Sprintf("%scosign verify --key %s %s", prefix, shellEscapePOSIX(file), shellEscapePOSIX(ee.Image))
+	}
+	// podman evaluates the signature policy on every pull, including pulls of
+	// images that are already in the local store.
+	args := append(append([]string{"pull"}, ee.PullArguments...), "--signature-policy="+file, ee.Image)
+	return fmt.Sprintf("%spodman %s", prefix, strings.Join(shellEscapeAllPOSIX(args), " "))
+}
+
+// verifyExecutionEnvironmentImage uploads the cosign key or signature policy to
+// the staging directory and verifies the execution environment image with it on
+// the target.
+func (p *Provisioner) verifyExecutionEnvironmentImage(ui packersdk.Ui, comm packersdk.Communicator) error {
+	ee := p.config.NavigatorConfig.ExecutionEnvironment
+	method, local := "policy", ee.Verify.Policy
+	if ee.Verify.CosignKey != "" {
+		method, local = "cosign", ee.Verify.CosignKey
+	}
+
+	file := local
+	if !isCosignKMSKey(local) {
+		file = filepath.ToSlash(filepath.Join(p.stagingDir, "ee-verify-"+filepath.Base(local)))
+		ui.Message(fmt.Sprintf("Uploading execution environment %s verification file...", method))
+		if err := p.uploadFile(ui, comm, file, local); err != nil {
+			return fmt.Errorf("Error uploading navigator_config.execution_environment.verify file: %s", err)
+		}
+	}
+
+	pathPrefixAssignment := buildPathPrefixForRemoteShell(p.config.AnsibleNavigatorPath)
+	command := buildImageVerifyShell(pathPrefixAssignment, ee, file)
+	ui.Message(fmt.Sprintf("Verifying execution environment image %s (%s): %s", ee.Image, method, command))
+
+	cmd := &packersdk.RemoteCmd{Command: command}
+	if err := cmd.RunWithUi(context.TODO(), comm, ui); err != nil {
+		return err
+	}
+	if cmd.ExitStatus() != 0 {
+		return fmt.Errorf("execution environment image %s failed signature verification (%s): exit code %d", ee.Image, method, cmd.ExitStatus())
+	}
+	ui.Message(fmt.Sprintf("Execution environment image %s signature verified (%s)", ee.Image, method))
+	return nil
+}
@@ -0,0 +1,68 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildImageVerifyShell(t *testing.T) {
+	ee := &ExecutionEnvironment{
+		Image:  "quay.io/ansible/creator-ee@" + testDigest,
+		Verify: &ImageVerifyConfig{CosignKey: "/home/me/cosign.pub"},
+	}
+	require.Equal(t,
+		`PATH="/opt/bin:$PATH" cosign verify --key /tmp/stage/ee-verify-cosign.pub quay.io/ansible/creator-ee@`+testDigest,
+		buildImageVerifyShell(`PATH="/opt/bin:$PATH"`, ee, "/tmp/stage/ee-verify-cosign.pub"))
+
+	ee = &ExecutionEnvironment{
+		Image:         "quay.io/ansible/creator-ee:v1",
+		PullArguments: []string{"--tls-verify=false"},
+		Verify:        &ImageVerifyConfig{Policy: "/etc/packer/policy.json"},
+	}
+	require.Equal(t,
+		`podman pull --tls-verify=false --signature-policy=/tmp/stage/ee-verify-policy.json quay.io/ansible/creator-ee:v1`,
+		buildImageVerifyShell("", ee, "/tmp/stage/ee-verify-policy.json"))
+}
+
+func TestBuildImageVerifyShell_RunsCosign(t *testing.T) {
+	dir := t.TempDir()
+	stub := "#!/bin/sh\n[ \"$*\" = \"verify --key /stage/cosign.pub ee:v1\" ] || { echo \"unexpected: $*\" >&2; exit 1; }\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cosign"), []byte(stub), 0o755))
+	ee := &ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{CosignKey: "cosign.pub"}}
+
+	cmd := exec.Command("/bin/sh", "-c", buildImageVerifyShell(`PATH="`+dir+`:$PATH"`, ee, "/stage/cosign.pub"))
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestValidateImageVerify(t *testing.T) {
+	policy := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(policy, []byte(`{"default":[{"type":"reject"}]}`), 0o600))
+
+	require.Empty(t, validateImageVerify(&ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{Policy: policy}}))
+	require.Empty(t, validateImageVerify(&ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{CosignKey: "gcpkms://projects/p/keys/k"}}))
+
+	errs := validateImageVerify(&ExecutionEnvironment{Image: "ee:v1", ContainerEngine: "docker", Verify: &ImageVerifyConfig{Policy: policy}})
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "requires the podman container engine")
+
+	errs = validateImageVerify(&ExecutionEnvironment{Verify: &ImageVerifyConfig{}})
+	require.Len(t, errs, 2)
+}
+
+func TestEEPreflightShell_ReportsImageVerification(t *testing.T) {
+	ee := &ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{CosignKey: "cosign.pub"}}
+	require.Contains(t, buildEEPreflightShell("", ee), `echo "[DEBUG] [EE preflight] image ee:v1: signature verified (cosign)"`)
+
+	ee.ContainerEngine = "podman"
+	require.Contains(t, buildEEPreflightShell("", ee), "signature verified (cosign)")
+}
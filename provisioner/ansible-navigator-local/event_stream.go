@@ -0,0 +1,150 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// newSummary returns an empty structured logging summary.
+func (p *Provisioner) newSummary() *Summary {
+	return &Summary{
+		FailedTasks: make([]NavigatorEvent, 0),
+		// Collection verification ran before the plays; carry it into every summary.
+		GalaxyVerification: p.galaxyVerification,
+	}
+}
+
+// decodeNavigatorEvents reads ansible-navigator's stdout and handles each JSON
+// event. An event may span several lines. Lines that are not part of an event
+// are shown as they are, so plain output is not lost.
+func decodeNavigatorEvents(ui packersdk.Ui, r io.Reader, summary *Summary, verbose bool) {
+	reader := bufio.NewReader(r)
+	var pending []byte
+	decode := func(atEOF bool) {
+		var event NavigatorEvent
+		err := json.Unmarshal(pending, &event)
+		var syntaxErr *json.SyntaxError
+		if !atEOF && errors.As(err, &syntaxErr) && syntaxErr.Offset == int64(len(pending)) {
+			// The event continues on the next line
+			return
+		}
+		if err != nil {
+			// Skip malformed JSON with a warning
+			if verbose {
+				ui.Message(fmt.Sprintf("[Warning] Skipped malformed JSON event: %v", err))
+			}
+		} else {
+			handleNavigatorEvent(ui, &event, summary, verbose)
+		}
+		pending = pending[:0]
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if trimmed := strings.TrimRightFunc(line, unicode.IsSpace); trimmed != "" {
+			if len(pending) > 0 || strings.HasPrefix(strings.TrimSpace(trimmed), "{") {
+				pending = append(pending, line...)
+				decode(false)
+			} else {
+				ui.Message(trimmed)
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				ui.Error(err.Error())
+			}
+			break
+		}
+	}
+	if len(pending) > 0 {
+		decode(true)
+	}
+}
+
+// runWithEvents runs cmd like RemoteCmd.RunWithUi, but decodes stdout as
+// ansible-navigator JSON events into summary instead of printing it.
+func runWithEvents(ctx context.Context, ui packersdk.Ui, comm packersdk.Communicator, cmd *packersdk.RemoteCmd, summary *Summary, verbose bool) error {
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		decodeNavigatorEvents(ui, stdoutR, summary, verbose)
+		_, _ = io.Copy(io.Discard, stdoutR)
+	}()
+	go func() {
+		defer wg.Done()
+		scanner := bufio.NewScanner(stderrR)
+		for scanner.Scan() {
+			if line := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace); line != "" {
+				ui.Error(line)
+			}
+		}
+		_, _ = io.Copy(io.Discard, stderrR)
+	}()
+
+	err := comm.Start(ctx, cmd)
+	if err == nil {
+		cmd.Wait()
+	}
+	stdoutW.Close()
+	stderrW.Close()
+	wg.Wait()
+	return err
+}
+
+// reportSummary reports the structured logging summary of a play and writes it
+// to log_output_path.
+func (p *Provisioner) reportSummary(ui packersdk.Ui, summary *Summary) {
+	if summary.TasksFailed > 0 {
+		ui.Error(fmt.Sprintf("[Error] %d task(s) failed during play execution.", summary.TasksFailed))
+		for _, failedTask := range summary.FailedTasks {
+			ui.Error(fmt.Sprintf("  - Task '%s' on host '%s'", failedTask.Task, failedTask.Host))
+		}
+	}
+
+	if summary.TasksTotal == 0 && summary.PlaysRun == 0 {
+		ui.Message("[Warning] No valid events parsed from ansible-navigator output.")
+	} else {
+		ui.Message(fmt.Sprintf("Summary: %d play(s) executed, %d task(s) total, %d failed",
+			summary.PlaysRun, summary.TasksTotal, summary.TasksFailed))
+	}
+
+	// Write summary JSON if path is specified
+	if p.config.LogOutputPath != "" {
+		if err := writeSummaryJSON(summary, p.config.LogOutputPath); err != nil {
+			ui.Message(fmt.Sprintf("[Warning] Could not write structured log to %s: %v", p.config.LogOutputPath, err))
+		} else {
+			ui.Message(fmt.Sprintf("Structured log written to: %s", p.config.LogOutputPath))
+		}
+	}
+}
+
+// writeEarlySummary writes the structured summary when the build fails before
+// any play runs, so that verification results are still recorded.
+func (p *Provisioner) writeEarlySummary(ui packersdk.Ui) {
+	if !p.config.StructuredLogging || p.config.LogOutputPath == "" {
+		return
+	}
+	if err := writeSummaryJSON(p.newSummary(), p.config.LogOutputPath); err != nil {
+		ui.Message(fmt.Sprintf("[Warning] Could not write structured log to %s: %v", p.config.LogOutputPath, err))
+	}
+}
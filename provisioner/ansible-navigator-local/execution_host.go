@@ -0,0 +1,198 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+const (
+	// ExecutionHostTarget runs ansible-navigator on the target via the communicator.
+	ExecutionHostTarget = "target"
+	// ExecutionHostBuildHost runs ansible-navigator on the build host and reaches
+	// the target with target_connection.
+	ExecutionHostBuildHost = "build_host"
+)
+
+// buildHostInventoryAlias is the inventory name of the target in build_host mode.
+const buildHostInventoryAlias = "default"
+
+// validateExecutionHost validates execution_host and its target_* settings.
+func validateExecutionHost(c *Config) []error {
+	var errs []error
+	switch c.ExecutionHost {
+	case "", ExecutionHostTarget:
+		if c.TargetConnection != "" || c.TargetHost != "" {
+			errs = append(errs, fmt.Errorf(
+				"target_connection and target_host require execution_host = %q", ExecutionHostBuildHost))
+		}
+	case ExecutionHostBuildHost:
+		if c.TargetConnection == "" {
+			errs = append(errs, fmt.Errorf(
+				"target_connection must be set when execution_host is %q (for example community.general.chroot)", ExecutionHostBuildHost))
+		} else if c.TargetConnection == "local" || strings.ContainsAny(c.TargetConnection, " \t\n\r") {
+			errs = append(errs, fmt.Errorf(
+				"invalid target_connection: %q (must be an Ansible connection plugin name other than local)", c.TargetConnection))
+		}
+		if c.TargetHost == "" {
+			errs = append(errs, fmt.Errorf(
+				"target_host must be set when execution_host is %q (the chroot directory or container name)", ExecutionHostBuildHost))
+		} else if strings.ContainsAny(c.TargetHost, " \t\n\r") {
+			errs = append(errs, fmt.Errorf(
+				"invalid target_host: %q (must not contain whitespace)", c.TargetHost))
+		}
+	default:
+		errs = append(errs, fmt.Errorf(
+			"invalid execution_host: %q (must be %q or %q)",
+			c.ExecutionHost, ExecutionHostTarget, ExecutionHostBuildHost))
+	}
+	return errs
+}
+
+// runsOnBuildHost reports whether ansible-navigator runs on the build host.
+func (c *Config) runsOnBuildHost() bool {
+	return c.ExecutionHost == ExecutionHostBuildHost
+}
+
+// ansibleConnection returns the connection plugin used for plays.
+func (c *Config) ansibleConnection() string {
+	if c.runsOnBuildHost() {
+		return c.TargetConnection
+	}
+	return "local"
+}
+
+// localCommunicator is a packersdk.Communicator for the build host. In
+// build_host mode the provisioner stages files and runs ansible-navigator
+// through it instead of the target's communicator.
+type localCommunicator struct{}
+
+var _ packersdk.Communicator = (*localCommunicator)(nil)
+
+func (c *localCommunicator) Start(ctx context.Context, cmd *packersdk.RemoteCmd) error {
+	localCmd := exec.CommandContext(ctx, "/bin/sh", "-c", cmd.Command)
+	localCmd.Stdin = cmd.Stdin
+	localCmd.Stdout = cmd.Stdout
+	localCmd.Stderr = cmd.Stderr
+	if err := localCmd.Start(); err != nil {
+		return err
+	}
+
+	go func() {
+		exitStatus := 0
+		if err := localCmd.Wait(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				exitStatus = exitErr.ExitCode()
+			} else {
+				exitStatus = 1
+			}
+		}
+		cmd.SetExited(exitStatus)
+	}()
+	return nil
+}
+
+func (c *localCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	mode := os.FileMode(0o644)
+	if fi != nil {
+		mode = (*fi).Mode().Perm()
+	}
+	return writeLocalFile(dst, r, mode)
+}
+
+// UploadDir follows the communicator convention: a src with a trailing slash
+// copies its contents into dst, otherwise src itself is copied into dst.
+func (c *localCommunicator) UploadDir(dst, src string, exclude []string) error {
+	if !strings.HasSuffix(src, "/") {
+		dst = filepath.Join(dst, filepath.Base(src))
+	}
+	return copyLocalDir(dst, src, exclude)
+}
+
+func (c *localCommunicator) Download(src string, w io.Writer) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (c *localCommunicator) DownloadDir(src, dst string, exclude []string) error {
+	return copyLocalDir(dst, src, exclude)
+}
+
+func writeLocalFile(dst string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyLocalDir copies the tree at src into dst, skipping paths (relative to
+// src) that match an exclude pattern.
+func copyLocalDir(dst, src string, exclude []string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		for _, pattern := range exclude {
+			if matched, _ := filepath.Match(pattern, rel); matched {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0o700)
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return writeLocalFile(target, f, info.Mode().Perm())
+		}
+	})
+}
@@ -0,0 +1,126 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateExecutionHost(t *testing.T) {
+	require.Empty(t, validateExecutionHost(&Config{}))
+	require.Empty(t, validateExecutionHost(&Config{
+		ExecutionHost: ExecutionHostBuildHost, TargetConnection: "community.general.chroot", TargetHost: "/mnt/chroot",
+	}))
+
+	tests := []struct {
+		name   string
+		config *Config
+		errMsg string
+	}{
+		{"invalid", &Config{ExecutionHost: "controller"}, "invalid execution_host"},
+		{"target with connection", &Config{TargetConnection: "community.docker.docker"}, `require execution_host = "build_host"`},
+		{"no connection", &Config{ExecutionHost: ExecutionHostBuildHost, TargetHost: "/mnt/chroot"}, "target_connection must be set"},
+		{"local connection", &Config{ExecutionHost: ExecutionHostBuildHost, TargetConnection: "local", TargetHost: "/mnt/chroot"}, "invalid target_connection"},
+		{"no host", &Config{ExecutionHost: ExecutionHostBuildHost, TargetConnection: "community.general.chroot"}, "target_host must be set"},
+		{"host with space", &Config{ExecutionHost: ExecutionHostBuildHost, TargetConnection: "community.general.chroot", TargetHost: "/mnt/my chroot"}, "invalid target_host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateExecutionHost(tt.config)
+			require.Len(t, errs, 1)
+			require.Contains(t, errs[0].Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestLocalCommunicator(t *testing.T) {
+	comm := &localCommunicator{}
+	dir := t.TempDir()
+
+	var stdout bytes.Buffer
+	cmd := &packersdk.RemoteCmd{Command: "echo hello; exit 3", Stdout: &stdout}
+	require.NoError(t, cmd.RunWithUi(context.Background(), comm, newMockUi()))
+	require.Equal(t, 3, cmd.ExitStatus())
+
+	dst := filepath.Join(dir, "staging", "inventory.ini")
+	require.NoError(t, comm.Upload(dst, strings.NewReader("127.0.0.1"), nil))
+	var downloaded bytes.Buffer
+	require.NoError(t, comm.Download(dst, &downloaded))
+	require.Equal(t, "127.0.0.1", downloaded.String())
+
+	src := filepath.Join(dir, "roles")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "web", "tasks"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "web", "tasks", "main.yml"), []byte("---\n"), 0o644))
+
+	// A trailing slash copies the contents, otherwise the directory itself.
+	require.NoError(t, comm.UploadDir(filepath.Join(dir, "contents"), src+"/", nil))
+	require.FileExists(t, filepath.Join(dir, "contents", "web", "tasks", "main.yml"))
+	require.NoError(t, comm.UploadDir(filepath.Join(dir, "dir"), src, nil))
+	require.FileExists(t, filepath.Join(dir, "dir", "roles", "web", "tasks", "main.yml"))
+}
+
+func TestProvisioner_BuildHostConnection(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["play"] = []map[string]interface{}{{"target": "geerlingguy.docker"}}
+	config["execution_host"] = ExecutionHostBuildHost
+	config["target_connection"] = "community.general.chroot"
+	config["target_host"] = "/mnt/packer-chroot"
+	require.NoError(t, p.Prepare(config))
+	p.generatedData = map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}
+
+	require.Equal(t, "default ansible_host=/mnt/packer-chroot ansible_connection=community.general.chroot\n", p.config.inventoryContent())
+
+	args, _, err := p.buildPluginArgsForPlay(newMockUi(), p.config.Plays[0], "/staging/inventory.ini")
+	require.NoError(t, err)
+	require.Contains(t, args, "-c=community.general.chroot")
+	require.NotContains(t, args, "-c=local")
+
+	playbook, err := p.createRolePlaybook("geerlingguy.docker", p.config.Plays[0])
+	require.NoError(t, err)
+	defer os.Remove(playbook)
+	content, err := os.ReadFile(playbook)
+	require.NoError(t, err)
+	require.Contains(t, string(content), "connection: community.general.chroot\n")
+}
+
+func TestProvisioner_BuildHostRunsLocally(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	navigator := filepath.Join(dir, "ansible-navigator")
+	require.NoError(t, os.WriteFile(navigator, []byte("#!/bin/sh\necho \"$@\" >> "+argsFile+"\n"), 0o755))
+	playbook := filepath.Join(dir, "site.yml")
+	require.NoError(t, os.WriteFile(playbook, []byte("- hosts: all\n  tasks: []\n"), 0o644))
+
+	var p Provisioner
+	config := testConfig()
+	config["command"] = navigator
+	config["play"] = []map[string]interface{}{{"target": playbook}}
+	config["execution_host"] = ExecutionHostBuildHost
+	config["target_connection"] = "community.docker.docker"
+	config["target_host"] = "packer-build"
+	require.NoError(t, p.Prepare(config))
+
+	// The target communicator is never used.
+	comm := &communicatorMock{}
+	require.NoError(t, p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+	require.Empty(t, comm.startCommand)
+	require.Empty(t, comm.uploadDestination)
+
+	data, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	require.Contains(t, string(data), filepath.Join(p.stagingDir, "site.yml"))
+	require.Contains(t, string(data), "-c=community.docker.docker")
+	require.NoDirExists(t, p.stagingDir)
+}
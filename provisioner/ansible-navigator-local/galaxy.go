@@ -6,14 +6,28 @@
 package ansiblenavigatorlocal
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
+	"unicode"
 
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+)
+
+const (
+	// GalaxyInstallLocationTarget runs ansible-galaxy on the target via the communicator.
+	GalaxyInstallLocationTarget = "target"
+	// GalaxyInstallLocationHost runs ansible-galaxy on the build host and ships the
+	// installed content to the target staging directory.
+	GalaxyInstallLocationHost = "host"
 )
 
 // GalaxyManager handles all Ansible Galaxy operations for roles and collections
@@ -25,10 +39,24 @@ type GalaxyManager struct {
 	stagingDir            string
 	galaxyRolesPath       string
 	galaxyCollectionsPath string
+	// verification holds the collection verification result when galaxy_keyring is set.
+	verification *GalaxyVerification
+	// hostInstallDir is the build's own install directory on the build host when
+	// galaxy_install_location is "host".
+	hostInstallDir string
 }
 
 // NewGalaxyManager creates a new GalaxyManager instance
 func NewGalaxyManager(config *Config, ui packersdk.Ui, comm packersdk.Communicator, stagingDir string, galaxyRolesPath string, galaxyCollectionsPath string) *GalaxyManager {
+	// Galaxy server tokens are redacted from everything the manager prints.
+	// Commands echo tokens shell-quoted, so redact the quoted form first.
+	if secrets := galaxyServerSecrets(config.GalaxyServers); len(secrets) > 0 {
+		var redact []string
+		for _, secret := range secrets {
+			redact = append(redact, config.remoteShell().Quote(secret), secret)
+		}
+		ui = &redactingUi{Ui: ui, secrets: redact}
+	}
 	return &GalaxyManager{
 		config:                config,
 		ui:                    ui,
@@ -47,6 +75,16 @@ func (gm *GalaxyManager) InstallRequirements() error {
 	}
 
 	gm.ui.Message(fmt.Sprintf("Installing dependencies from requirements file: %s", gm.config.RequirementsFile))
+	// Install into a directory of this build only, so nothing else in the
+	// roles_path/collections_path caches is shipped to the target.
+	if gm.installOnHost() {
+		dir, err := os.MkdirTemp("", "packer-ansible-galaxy-")
+		if err != nil {
+			return fmt.Errorf("failed to create install directory on the build host: %w", err)
+		}
+		defer os.RemoveAll(dir)
+		gm.hostInstallDir = dir
+	}
 	if err := gm.installFromFile(gm.config.RequirementsFile); err != nil {
 		return fmt.Errorf("failed to install requirements: %w", err)
 	}
@@ -72,19 +110,23 @@ func (gm *GalaxyManager) installFromFile(filePath string) error {
 	hasRoles := regexp.MustCompile(`(?m)^roles:`).Match(content)
 	hasCollections := regexp.MustCompile(`(?m)^collections:`).Match(content)
 
-	// Construct remote path for requirements file
-	remoteReqFile := filepath.ToSlash(filepath.Join(gm.stagingDir, filepath.Base(filePath)))
+	// Construct remote path for requirements file. Host installs read the
+	// requirements file directly from the build host.
+	reqFile := filepath.ToSlash(filepath.Join(gm.stagingDir, filepath.Base(filePath)))
+	if gm.installOnHost() {
+		reqFile = filePath
+	}
 
 	// Install roles if present (or if it's v1 format without collections)
 	if hasRoles || !hasCollections {
-		if err := gm.installRolesFromFile(remoteReqFile); err != nil {
+		if err := gm.installRolesFromFile(reqFile); err != nil {
 			return err
 		}
 	}
 
 	// Install collections if present
 	if hasCollections {
-		if err := gm.installCollectionsFromFile(remoteReqFile); err != nil {
+		if err := gm.installCollectionsFromFile(reqFile); err != nil {
 			return err
 		}
 	}
@@ -102,16 +144,13 @@ func (gm *GalaxyManager) installRolesFromFile(remoteFilePath string) error {
 	args := []string{"install", fmt.Sprintf("-r=%s", remoteFilePath)}
 
 	// Add roles path
-	rolesPath := gm.galaxyRolesPath
-	if gm.config.RolesPath != "" {
-		rolesPath = gm.config.RolesPath
-	}
+	rolesPath := gm.installPath("roles", gm.config.RolesPath, gm.galaxyRolesPath)
 	if rolesPath != "" {
 		args = append(args, fmt.Sprintf("-p=%s", filepath.ToSlash(rolesPath)))
 	}
 
 	// Add offline option
-	if gm.config.OfflineMode {
+	if gm.offline() {
 		args = append(args, "--offline")
 	}
 
@@ -125,7 +164,13 @@ func (gm *GalaxyManager) installRolesFromFile(remoteFilePath string) error {
 	// Append user-provided args last
 	args = append(args, gm.config.GalaxyArgs...)
 
-	return gm.executeGalaxyCommand(args, "roles")
+	if err := gm.executeGalaxyCommand(args, "roles"); err != nil {
+		return err
+	}
+	if gm.installOnHost() {
+		return gm.uploadInstalledContent(rolesPath, gm.galaxyRolesPath, "roles")
+	}
+	return nil
 }
 
 // installCollectionsFromFile installs collections from a requirements file
@@ -134,16 +179,16 @@ func (gm *GalaxyManager) installCollectionsFromFile(remoteFilePath string) error
 	args := []string{"collection", "install", fmt.Sprintf("-r=%s", remoteFilePath)}
 
 	// Add collections path
-	collectionsPath := gm.galaxyCollectionsPath
-	if gm.config.CollectionsPath != "" {
-		collectionsPath = gm.config.CollectionsPath
-	}
+	collectionsPath := gm.installPath("collections", gm.config.CollectionsPath, gm.galaxyCollectionsPath)
 	if collectionsPath != "" {
 		args = append(args, fmt.Sprintf("-p=%s", filepath.ToSlash(collectionsPath)))
 	}
 
+	// Add signature verification options
+	args = append(args, gm.signatureArgs()...)
+
 	// Add offline option
-	if gm.config.OfflineMode {
+	if gm.offline() {
 		args = append(args, "--offline")
 	}
 
@@ -157,28 +202,183 @@ func (gm *GalaxyManager) installCollectionsFromFile(remoteFilePath string) error
 	// Append user-provided args last
 	args = append(args, gm.config.GalaxyArgs...)
 
-	return gm.executeGalaxyCommand(args, "collections")
+	if err := gm.executeGalaxyCommand(args, "collections"); err != nil {
+		return err
+	}
+	// Host installs are verified before the content is shipped to the target.
+	if gm.verifySignatures() {
+		if err := gm.verifyCollections(remoteFilePath); err != nil {
+			return err
+		}
+	}
+	if gm.installOnHost() {
+		return gm.uploadInstalledContent(collectionsPath, gm.galaxyCollectionsPath, "collections")
+	}
+	return nil
 }
 
 // NOTE: legacy inline collections and legacy galaxy_file paths are intentionally removed.
 
-// executeGalaxyCommand executes an ansible-galaxy command via communicator
+// installOnHost reports whether ansible-galaxy runs on the build host.
+func (gm *GalaxyManager) installOnHost() bool {
+	return gm.config.GalaxyInstallLocation == GalaxyInstallLocationHost
+}
+
+// offline reports whether ansible-galaxy runs with --offline. offline_mode
+// applies to the target; installs on the build host need its network access.
+func (gm *GalaxyManager) offline() bool {
+	return gm.config.OfflineMode && !gm.installOnHost()
+}
+
+// installPath returns where ansible-galaxy installs the roles or collections
+// (kind): the build's own directory on the build host, or the configured path,
+// or the staging path, on the target.
+func (gm *GalaxyManager) installPath(kind, configured, staging string) string {
+	if gm.installOnHost() {
+		return filepath.Join(gm.hostInstallDir, kind)
+	}
+	if configured != "" {
+		return configured
+	}
+	return staging
+}
+
+// executeGalaxyCommand executes an ansible-galaxy command via communicator,
+// or on the build host when galaxy_install_location is "host".
 func (gm *GalaxyManager) executeGalaxyCommand(args []string, target string) error {
+	if gm.installOnHost() {
+		return gm.executeHostGalaxyCommand(args, target)
+	}
+
 	ctx := context.TODO()
-	command := fmt.Sprintf("cd %s && %s %s",
-		gm.stagingDir, gm.config.GalaxyCommand, strings.Join(args, " "))
-	gm.ui.Message(fmt.Sprintf("Executing Ansible Galaxy: %s", command))
+	sh := gm.config.remoteShell()
+	// ansible-galaxy is installed next to ansible-navigator (for example by bootstrap)
+	script := sh.Run(gm.stagingDir, gm.config.AnsibleNavigatorPath, nil, gm.config.GalaxyCommand, args)
+	gm.ui.Message(fmt.Sprintf("Executing Ansible Galaxy: %s", script))
+
+	// The Galaxy servers are set from an uploaded file, not in the command:
+	// communicators log the command and it is visible in the target's process
+	// list. The file is removed as soon as it is loaded.
+	removeEnvFile := ""
+	if env := galaxyServerEnv(gm.config.GalaxyServers); len(env) > 0 {
+		name := sh.ScriptName(galaxyServerEnvFileName)
+		envFile := filepath.ToSlash(filepath.Join(gm.stagingDir, name))
+		content := sh.EnvScript(env)
+		fi := os.FileInfo(&uploadFileInfo{name: name, size: int64(len(content)), mode: 0o600})
+		if err := gm.comm.Upload(envFile, strings.NewReader(content), &fi); err != nil {
+			return fmt.Errorf("failed to upload Galaxy server settings: %w", err)
+		}
+		removeEnvFile = sh.RemoveFiles(gm.stagingDir, []string{name})
+		script = sh.Join(sh.Source(envFile), removeEnvFile, script)
+	}
+
+	cmd := &packersdk.RemoteCmd{
+		Command: sh.Command(script),
+	}
+	err := cmd.RunWithUi(ctx, gm.comm, gm.ui)
+	if err == nil && cmd.ExitStatus() != 0 {
+		err = fmt.Errorf("ansible-galaxy failed for %s: exit code %d", target, cmd.ExitStatus())
+	}
+	// The command may have failed before it loaded and removed the file.
+	if err != nil && removeEnvFile != "" {
+		cleanup := &packersdk.RemoteCmd{Command: sh.Command(removeEnvFile)}
+		_ = cleanup.RunWithUi(ctx, gm.comm, gm.ui)
+	}
+	return err
+}
+
+// executeHostGalaxyCommand executes an ansible-galaxy command on the build host,
+// streaming its output to the UI.
+func (gm *GalaxyManager) executeHostGalaxyCommand(args []string, target string) error {
+	gm.ui.Message(fmt.Sprintf("Executing Ansible Galaxy on build host: %s %s",
+		gm.config.GalaxyCommand, strings.Join(args, " ")))
 
+	cmd := exec.Command(gm.config.GalaxyCommand, args...)
+	cmd.Env = append(os.Environ(), galaxyServerEnv(gm.config.GalaxyServers)...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	outputHandler := func(r io.ReadCloser) {
+		defer wg.Done()
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				gm.ui.Message(strings.TrimRightFunc(line, unicode.IsSpace))
+			}
+			if err != nil {
+				if err != io.EOF {
+					gm.ui.Error(err.Error())
+				}
+				break
+			}
+		}
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ansible-galaxy: %w", err)
+	}
+
+	wg.Add(2)
+	go outputHandler(stdout)
+	go outputHandler(stderr)
+	wg.Wait()
+
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ansible-galaxy failed for %s: %w", target, err)
+	}
+	return nil
+}
+
+// uploadInstalledContent packs content installed on the build host into a
+// tarball, uploads it to the staging directory and unpacks it into remoteDir.
+func (gm *GalaxyManager) uploadInstalledContent(localDir, remoteDir, target string) error {
+	if _, err := os.Stat(localDir); os.IsNotExist(err) {
+		gm.ui.Message(fmt.Sprintf("No %s were installed on the build host; nothing to upload", target))
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error checking installed %s: %w", target, err)
+	}
+
+	tf, err := tmp.File("packer-ansible-galaxy-" + target)
+	if err != nil {
+		return fmt.Errorf("failed to create %s bundle: %w", target, err)
+	}
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+
+	if err := writeTarGz(tf, localDir, nil); err != nil {
+		return fmt.Errorf("failed to create %s bundle: %w", target, err)
+	}
+	if _, err := tf.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to create %s bundle: %w", target, err)
+	}
+
+	remoteArchive := filepath.ToSlash(filepath.Join(gm.stagingDir, fmt.Sprintf("galaxy_%s.tar.gz", target)))
+	gm.ui.Message(fmt.Sprintf("Uploading %s installed on the build host...", target))
+	if err := gm.comm.Upload(remoteArchive, tf, nil); err != nil {
+		return fmt.Errorf("failed to upload %s bundle: %w", target, err)
+	}
+
+	ctx := context.TODO()
+	sh := gm.config.remoteShell()
 	cmd := &packersdk.RemoteCmd{
-		Command: command,
+		Command: sh.Command(sh.Unpack(remoteArchive, remoteDir)),
 	}
 	if err := cmd.RunWithUi(ctx, gm.comm, gm.ui); err != nil {
 		return err
 	}
 	if cmd.ExitStatus() != 0 {
-		return fmt.Errorf("ansible-galaxy failed for %s: exit code %d", target, cmd.ExitStatus())
+		return fmt.Errorf("failed to unpack %s bundle on target: exit code %d", target, cmd.ExitStatus())
 	}
-
 	return nil
 }
 
@@ -187,6 +387,13 @@ func (gm *GalaxyManager) executeGalaxyCommand(args []string, target string) erro
 func (gm *GalaxyManager) SetupEnvironmentPaths() []string {
 	envVars := []string{}
 
+	// Content installed on the build host is unpacked into the staging directory.
+	if gm.installOnHost() {
+		return append(envVars,
+			fmt.Sprintf("ANSIBLE_COLLECTIONS_PATH=%s", gm.galaxyCollectionsPath),
+			fmt.Sprintf("ANSIBLE_ROLES_PATH=%s", gm.galaxyRolesPath))
+	}
+
 	// Set collections path
 	if gm.config.CollectionsPath != "" {
 		envVars = append(envVars, fmt.Sprintf("ANSIBLE_COLLECTIONS_PATH=%s", gm.config.CollectionsPath))
@@ -0,0 +1,116 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// writeTarGz writes the contents of srcDir as a gzip-compressed tar stream,
+// leaving out paths that match an exclude pattern (see isExcludedPath).
+// Entry names are relative to srcDir so the archive can be unpacked with
+// `tar -xzf <archive> -C <dest>`.
+func writeTarGz(w io.Writer, srcDir string, exclude []string) error {
+	return writeTarGzFunc(w, srcDir, func(rel string, _ fs.DirEntry) bool {
+		return isExcludedPath(rel, exclude)
+	})
+}
+
+// writeTarGzFunc is writeTarGz with a skip function, called with the
+// slash-separated path relative to srcDir. Skipping a directory skips its contents.
+func writeTarGzFunc(w io.Writer, srcDir string, skip func(rel string, d fs.DirEntry) bool) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	err := filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if skip(filepath.ToSlash(rel), d) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// isExcludedPath reports whether rel, a slash-separated path relative to the
+// archived directory, matches an exclude pattern. Patterns containing a slash
+// match the whole relative path, other patterns match the file or directory name.
+func isExcludedPath(rel string, exclude []string) bool {
+	for _, pattern := range exclude {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), rel); matched {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+			return true
+		}
+	}
+	return false
+}
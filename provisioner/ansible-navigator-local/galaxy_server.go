@@ -0,0 +1,123 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+var galaxyServerNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// galaxyServerEnvFileName is the file in the staging directory that sets the
+// Galaxy server environment for ansible-galaxy on the target.
+const galaxyServerEnvFileName = ".packer-galaxy-servers"
+
+// validateGalaxyServers validates galaxy_server blocks.
+func validateGalaxyServers(servers []GalaxyServer) []error {
+	var errs []error
+	seen := map[string]bool{}
+	for i, server := range servers {
+		if server.Name == "" {
+			errs = append(errs, fmt.Errorf("galaxy_server %d: name must be specified", i))
+		} else if !galaxyServerNamePattern.MatchString(server.Name) {
+			errs = append(errs, fmt.Errorf("galaxy_server %d: invalid name %q (only letters, digits and underscores are allowed)", i, server.Name))
+		} else {
+			key := strings.ToUpper(server.Name)
+			if seen[key] {
+				errs = append(errs, fmt.Errorf("galaxy_server %d: duplicate name %q", i, server.Name))
+			}
+			seen[key] = true
+		}
+		if server.URL == "" {
+			errs = append(errs, fmt.Errorf("galaxy_server %d: url must be specified", i))
+		}
+	}
+	return errs
+}
+
+// galaxyServerEnv returns the ANSIBLE_GALAXY_SERVER_* environment variables
+// describing the configured Galaxy servers, as KEY=value pairs.
+func galaxyServerEnv(servers []GalaxyServer) []string {
+	if len(servers) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(servers))
+	env := []string{}
+	for _, server := range servers {
+		names = append(names, server.Name)
+		prefix := "ANSIBLE_GALAXY_SERVER_" + strings.ToUpper(server.Name)
+		env = append(env, fmt.Sprintf("%s_URL=%s", prefix, server.URL))
+		if server.AuthURL != "" {
+			env = append(env, fmt.Sprintf("%s_AUTH_URL=%s", prefix, server.AuthURL))
+		}
+		if server.Token != "" {
+			env = append(env, fmt.Sprintf("%s_TOKEN=%s", prefix, server.Token))
+		}
+		if server.ValidateCerts != nil {
+			env = append(env, fmt.Sprintf("%s_VALIDATE_CERTS=%t", prefix, *server.ValidateCerts))
+		}
+	}
+	return append([]string{"ANSIBLE_GALAXY_SERVER_LIST=" + strings.Join(names, ",")}, env...)
+}
+
+// uploadFileInfo describes content uploaded from memory, so communicators
+// create the file with mode instead of their default.
+type uploadFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+}
+
+func (fi *uploadFileInfo) Name() string       { return fi.name }
+func (fi *uploadFileInfo) Size() int64        { return fi.size }
+func (fi *uploadFileInfo) Mode() os.FileMode  { return fi.mode }
+func (fi *uploadFileInfo) ModTime() time.Time { return time.Now() }
+func (fi *uploadFileInfo) IsDir() bool        { return false }
+func (fi *uploadFileInfo) Sys() interface{}   { return nil }
+
+// galaxyServerSecrets returns the secret values of the configured Galaxy servers.
+func galaxyServerSecrets(servers []GalaxyServer) []string {
+	var secrets []string
+	for _, server := range servers {
+		if server.Token != "" {
+			secrets = append(secrets, server.Token)
+		}
+	}
+	return secrets
+}
+
+// redactSecrets replaces every occurrence of the given secrets with "*****".
+func redactSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, "*****")
+	}
+	return s
+}
+
+// redactingUi wraps a packersdk.Ui and redacts secrets from all output.
+type redactingUi struct {
+	packersdk.Ui
+	secrets []string
+}
+
+func (u *redactingUi) Say(s string)     { u.Ui.Say(redactSecrets(s, u.secrets)) }
+func (u *redactingUi) Message(s string) { u.Ui.Message(redactSecrets(s, u.secrets)) }
+func (u *redactingUi) Error(s string)   { u.Ui.Error(redactSecrets(s, u.secrets)) }
+
+func (u *redactingUi) Sayf(format string, args ...any) {
+	u.Say(fmt.Sprintf(format, args...))
+}
+
+func (u *redactingUi) Errorf(format string, args ...any) {
+	u.Error(fmt.Sprintf(format, args...))
+}
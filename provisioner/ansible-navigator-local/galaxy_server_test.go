@@ -0,0 +1,157 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGalaxyServerEnv_RendersServerListInOrder(t *testing.T) {
+	validate := false
+	env := galaxyServerEnv([]GalaxyServer{
+		{Name: "hub", URL: "https://hub.example.com/", Token: "s3cr3t", ValidateCerts: &validate},
+		{Name: "galaxy", URL: "https://galaxy.ansible.com/"},
+	})
+
+	require.Equal(t, []string{
+		"ANSIBLE_GALAXY_SERVER_LIST=hub,galaxy",
+		"ANSIBLE_GALAXY_SERVER_HUB_URL=https://hub.example.com/",
+		"ANSIBLE_GALAXY_SERVER_HUB_TOKEN=s3cr3t",
+		"ANSIBLE_GALAXY_SERVER_HUB_VALIDATE_CERTS=false",
+		"ANSIBLE_GALAXY_SERVER_GALAXY_URL=https://galaxy.ansible.com/",
+	}, env)
+}
+
+// uploadRecordingCommunicator is a communicatorMock that keeps uploaded content and modes.
+type uploadRecordingCommunicator struct {
+	communicatorMock
+	content map[string]string
+	modes   map[string]os.FileMode
+}
+
+func (c *uploadRecordingCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	c.content[dst] = string(data)
+	if fi != nil {
+		c.modes[dst] = (*fi).Mode()
+	}
+	return c.communicatorMock.Upload(dst, bytes.NewReader(data), fi)
+}
+
+func TestGalaxyManager_GalaxyServers_KeptOutOfRemoteCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+
+	tests := []struct {
+		shell, stagingDir, envFile, envScript, source string
+	}{
+		{
+			shell:      TargetShellPOSIX,
+			stagingDir: "/tmp/packer-provisioner-ansible-local-test",
+			envFile:    "/tmp/packer-provisioner-ansible-local-test/.packer-galaxy-servers",
+			envScript: "export ANSIBLE_GALAXY_SERVER_LIST='hub'\n" +
+				"export ANSIBLE_GALAXY_SERVER_HUB_URL='https://hub.example.com/api/galaxy/'\n" +
+				"export ANSIBLE_GALAXY_SERVER_HUB_TOKEN='tok'\"'\"'en'\n",
+			source: ". '/tmp/packer-provisioner-ansible-local-test/.packer-galaxy-servers' && " +
+				"cd /tmp/packer-provisioner-ansible-local-test && rm -f -- .packer-galaxy-servers && " +
+				"cd /tmp/packer-provisioner-ansible-local-test && ansible-galaxy collection install",
+		},
+		{
+			shell:      TargetShellPowerShell,
+			stagingDir: "C:/packer/staging",
+			envFile:    "C:/packer/staging/.packer-galaxy-servers.ps1",
+			envScript: "$env:ANSIBLE_GALAXY_SERVER_LIST = 'hub'\r\n" +
+				"$env:ANSIBLE_GALAXY_SERVER_HUB_URL = 'https://hub.example.com/api/galaxy/'\r\n" +
+				"$env:ANSIBLE_GALAXY_SERVER_HUB_TOKEN = 'tok''en'\r\n",
+			source: ". 'C:/packer/staging/.packer-galaxy-servers.ps1'; " +
+				"Remove-Item -LiteralPath 'C:/packer/staging/.packer-galaxy-servers.ps1' -Force -ErrorAction SilentlyContinue; " +
+				"Set-Location -LiteralPath 'C:/packer/staging'; & 'ansible-galaxy' 'collection' 'install'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			cfg := &Config{
+				RequirementsFile: reqFile,
+				GalaxyCommand:    "ansible-galaxy",
+				TargetShell:      tt.shell,
+				GalaxyServers: []GalaxyServer{
+					{Name: "hub", URL: "https://hub.example.com/api/galaxy/", Token: "tok'en"},
+				},
+			}
+
+			comm := &uploadRecordingCommunicator{content: map[string]string{}, modes: map[string]os.FileMode{}}
+			out := new(bytes.Buffer)
+			ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: out}
+			gm := NewGalaxyManager(cfg, ui, comm, tt.stagingDir, tt.stagingDir+"/galaxy_roles", tt.stagingDir+"/galaxy_collections")
+
+			require.NoError(t, gm.InstallRequirements())
+			require.Equal(t, tt.envScript, comm.content[tt.envFile])
+			require.Equal(t, os.FileMode(0o600), comm.modes[tt.envFile])
+
+			require.Len(t, comm.startCommand, 1)
+			command := comm.startCommand[0]
+			script := command
+			if tt.shell == TargetShellPowerShell {
+				script = decodePowerShellCommand(t, command)
+			}
+			require.Contains(t, script, tt.source)
+			for _, s := range []string{command, script, out.String()} {
+				require.NotContains(t, s, "tok")
+				require.NotContains(t, s, "ANSIBLE_GALAXY_SERVER")
+			}
+		})
+	}
+}
+
+func TestGalaxyManager_GalaxyServers_EnvFileLoadedAndRemoved(t *testing.T) {
+	staging := t.TempDir()
+	reqFile := filepath.Join(t.TempDir(), "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+	seen := filepath.Join(t.TempDir(), "seen")
+	galaxy := filepath.Join(t.TempDir(), "ansible-galaxy")
+	require.NoError(t, os.WriteFile(galaxy, []byte("#!/bin/sh\n"+
+		`echo "$ANSIBLE_GALAXY_SERVER_LIST $ANSIBLE_GALAXY_SERVER_HUB_TOKEN" > `+seen+"\n"+
+		`if [ -e .packer-galaxy-servers ]; then echo "env file still present" >> `+seen+"; fi\n"), 0o755))
+
+	cfg := &Config{
+		RequirementsFile: reqFile,
+		GalaxyCommand:    galaxy,
+		GalaxyServers:    []GalaxyServer{{Name: "hub", URL: "https://hub.example.com/", Token: "s3cr3t"}},
+	}
+	gm := NewGalaxyManager(cfg, newMockUi(), &localCommunicator{}, staging, staging+"/galaxy_roles", staging+"/galaxy_collections")
+	require.NoError(t, gm.InstallRequirements())
+
+	data, err := os.ReadFile(seen)
+	require.NoError(t, err)
+	require.Equal(t, "hub s3cr3t\n", string(data))
+	require.NoFileExists(t, filepath.Join(staging, galaxyServerEnvFileName))
+}
+
+func TestProvisionerPrepare_GalaxyServerRequiresURL(t *testing.T) {
+	playbookFile, err := os.CreateTemp("", "playbook-*.yml")
+	require.NoError(t, err)
+	defer os.Remove(playbookFile.Name())
+
+	var p Provisioner
+	config := testConfig()
+	config["play"] = []map[string]interface{}{{"target": playbookFile.Name()}}
+	config["galaxy_server"] = []map[string]interface{}{{"name": "hub"}}
+
+	err = p.Prepare(config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "galaxy_server 0: url must be specified")
+}
@@ -1,8 +1,13 @@
 package ansiblenavigatorlocal
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
@@ -35,6 +40,26 @@ func TestGalaxyManager_InstallRequirements_RunsRolesAndCollectionsInstalls(t *te
 	require.Contains(t, comm.startCommand[1], "cd "+stagingDir+" && ansible-galaxy collection install -r="+remoteReq)
 }
 
+func TestGalaxyManager_InstallRequirements_UsesAnsibleNavigatorPath(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+
+	cfg := &Config{
+		RequirementsFile:     reqFile,
+		GalaxyCommand:        "ansible-galaxy",
+		AnsibleNavigatorPath: []string{"/stage/ansible-bootstrap/bin"},
+	}
+
+	comm := &communicatorMock{}
+	stagingDir := "/tmp/packer-provisioner-ansible-local-test"
+	gm := NewGalaxyManager(cfg, packersdk.TestUi(t), comm, stagingDir, stagingDir+"/galaxy_roles", stagingDir+"/galaxy_collections")
+
+	require.NoError(t, gm.InstallRequirements())
+	require.Len(t, comm.startCommand, 1)
+	require.Contains(t, comm.startCommand[0], "cd "+stagingDir+` && PATH="/stage/ansible-bootstrap/bin:$PATH" ansible-galaxy collection install`)
+}
+
 func TestGalaxyManager_SetupEnvironmentPaths_ReturnsExpectedEnvVars(t *testing.T) {
 	ui := packersdk.TestUi(t)
 	comm := &communicatorMock{}
@@ -88,3 +113,168 @@ func TestGalaxyManager_InstallRequirements_UsesConfiguredCommandArgsAndForcePrec
 	require.Contains(t, joined, " -p=/tmp/roles")
 	require.Contains(t, joined, " -p=/tmp/collections")
 }
+
+func writeHostGalaxyStub(t *testing.T, dir string) string {
+	t.Helper()
+	script := filepath.Join(dir, "ansible-galaxy")
+	// The stub installs a marker file into the -p destination and logs its
+	// arguments next to itself.
+	content := `#!/bin/sh
+for a in "$@"; do
+  case "$a" in
+    -p=*) dest="${a#-p=}" ;;
+  esac
+done
+mkdir -p "$dest/installed"
+echo "$@" > "$dest/installed/args"
+echo "$@" >> "$(dirname "$0")/galaxy.log"
+`
+	require.NoError(t, os.WriteFile(script, []byte(content), 0o755))
+	return script
+}
+
+func TestGalaxyManager_InstallRequirements_HostLocation_InstallsLocallyAndUploadsBundles(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("roles:\n  - src: test.role\ncollections:\n  - name: community.general\n"), 0o644))
+
+	rolesPath := filepath.Join(tmpDir, "roles")
+	collectionsPath := filepath.Join(tmpDir, "collections")
+	cfg := &Config{
+		RequirementsFile:      reqFile,
+		GalaxyCommand:         writeHostGalaxyStub(t, tmpDir),
+		GalaxyInstallLocation: GalaxyInstallLocationHost,
+		OfflineMode:           true,
+		RolesPath:             rolesPath,
+		CollectionsPath:       collectionsPath,
+	}
+
+	comm := &communicatorMock{}
+	ui := packersdk.TestUi(t)
+	stagingDir := "/tmp/packer-provisioner-ansible-local-test"
+	gm := NewGalaxyManager(cfg, ui, comm, stagingDir, stagingDir+"/galaxy_roles", stagingDir+"/galaxy_collections")
+
+	require.NoError(t, gm.InstallRequirements())
+
+	// ansible-galaxy ran on the build host against the local requirements file,
+	// with network access and into a directory of this build, not the caches.
+	data, err := os.ReadFile(filepath.Join(tmpDir, "galaxy.log"))
+	require.NoError(t, err)
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, calls, 2)
+	require.Contains(t, calls[0], "install -r="+reqFile)
+	require.Contains(t, calls[1], "collection install -r="+reqFile)
+	for _, call := range calls {
+		require.NotContains(t, call, "--offline")
+		require.NotContains(t, call, "-p="+rolesPath)
+		require.NotContains(t, call, "-p="+collectionsPath)
+	}
+	require.NoDirExists(t, rolesPath)
+	require.NoDirExists(t, collectionsPath)
+
+	// Bundles are uploaded and unpacked into the staging directory; nothing else runs remotely.
+	require.Equal(t, []string{
+		stagingDir + "/galaxy_roles.tar.gz",
+		stagingDir + "/galaxy_collections.tar.gz",
+	}, comm.uploadDestination)
+	require.Len(t, comm.startCommand, 2)
+	require.Equal(t, "mkdir -p '"+stagingDir+"/galaxy_roles' && tar -xzf '"+stagingDir+"/galaxy_roles.tar.gz' -C '"+stagingDir+"/galaxy_roles' && rm -f '"+stagingDir+"/galaxy_roles.tar.gz'", comm.startCommand[0])
+	require.Contains(t, comm.startCommand[1], "tar -xzf '"+stagingDir+"/galaxy_collections.tar.gz' -C '"+stagingDir+"/galaxy_collections'")
+}
+
+func TestGalaxyManager_InstallRequirements_HostLocation_UploadsOnlyThisBuild(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("roles:\n  - src: test.role\n"), 0o644))
+
+	// Content another build left in the shared cache.
+	rolesPath := filepath.Join(tmpDir, "roles")
+	require.NoError(t, os.MkdirAll(filepath.Join(rolesPath, "other.role"), 0o755))
+
+	cfg := &Config{
+		RequirementsFile:      reqFile,
+		GalaxyCommand:         writeHostGalaxyStub(t, tmpDir),
+		GalaxyInstallLocation: GalaxyInstallLocationHost,
+		RolesPath:             rolesPath,
+	}
+	stagingDir := t.TempDir()
+	gm := NewGalaxyManager(cfg, packersdk.TestUi(t), &localCommunicator{}, stagingDir, stagingDir+"/galaxy_roles", stagingDir+"/galaxy_collections")
+	require.NoError(t, gm.InstallRequirements())
+
+	require.FileExists(t, filepath.Join(stagingDir, "galaxy_roles", "installed", "args"))
+	require.NoDirExists(t, filepath.Join(stagingDir, "galaxy_roles", "other.role"))
+	require.DirExists(t, filepath.Join(rolesPath, "other.role"))
+}
+
+func TestGalaxyManager_InstallRequirements_HostLocation_FailingCommandReturnsError(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+
+	script := filepath.Join(tmpDir, "ansible-galaxy")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nexit 3\n"), 0o755))
+
+	cfg := &Config{
+		RequirementsFile:      reqFile,
+		GalaxyCommand:         script,
+		GalaxyInstallLocation: GalaxyInstallLocationHost,
+		RolesPath:             filepath.Join(tmpDir, "roles"),
+		CollectionsPath:       filepath.Join(tmpDir, "collections"),
+	}
+
+	comm := &communicatorMock{}
+	gm := NewGalaxyManager(cfg, packersdk.TestUi(t), comm, "/tmp/stage", "/tmp/stage/galaxy_roles", "/tmp/stage/galaxy_collections")
+
+	err := gm.InstallRequirements()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ansible-galaxy failed for collections")
+	require.Empty(t, comm.uploadDestination)
+	require.Empty(t, comm.startCommand)
+}
+
+func TestGalaxyManager_SetupEnvironmentPaths_HostLocationUsesStagingPaths(t *testing.T) {
+	cfg := &Config{
+		GalaxyCommand:         "ansible-galaxy",
+		GalaxyInstallLocation: GalaxyInstallLocationHost,
+		RolesPath:             "/home/builder/roles",
+		CollectionsPath:       "/home/builder/collections",
+	}
+
+	gm := NewGalaxyManager(cfg, packersdk.TestUi(t), &communicatorMock{}, "/tmp/stage", "/tmp/stage/galaxy_roles", "/tmp/stage/galaxy_collections")
+	got := gm.SetupEnvironmentPaths()
+
+	require.Equal(t, []string{
+		"ANSIBLE_COLLECTIONS_PATH=/tmp/stage/galaxy_collections",
+		"ANSIBLE_ROLES_PATH=/tmp/stage/galaxy_roles",
+	}, got)
+}
+
+func TestWriteTarGz_PreservesRelativeLayout(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "ansible_collections", "ns", "coll"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "ansible_collections", "ns", "coll", "MANIFEST.json"), []byte("{}"), 0o644))
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTarGz(&buf, src, nil))
+
+	gr, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+
+	require.Equal(t, []string{
+		"ansible_collections/",
+		"ansible_collections/ns/",
+		"ansible_collections/ns/coll/",
+		"ansible_collections/ns/coll/MANIFEST.json",
+	}, names)
+}
@@ -0,0 +1,97 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGalaxyManager_InstallRequirements_TargetLocation_VerifiesWithUploadedKeyring(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+
+	cfg := &Config{
+		RequirementsFile:                  reqFile,
+		GalaxyCommand:                     "ansible-galaxy",
+		GalaxyKeyring:                     filepath.Join(tmpDir, "pubring.kbx"),
+		GalaxyRequiredValidSignatureCount: "all",
+		GalaxyIgnoreSignatureStatusCodes:  []string{"NO_PUBKEY"},
+	}
+
+	comm := &communicatorMock{}
+	stagingDir := "/tmp/packer-provisioner-ansible-local-test"
+	gm := NewGalaxyManager(cfg, packersdk.TestUi(t), comm, stagingDir, stagingDir+"/galaxy_roles", stagingDir+"/galaxy_collections")
+
+	require.NoError(t, gm.InstallRequirements())
+
+	remoteReq := stagingDir + "/requirements.yml"
+	signatureArgs := "--keyring=" + stagingDir + "/pubring.kbx --required-valid-signature-count=all --ignore-signature-status-code=NO_PUBKEY"
+	require.Len(t, comm.startCommand, 2)
+	require.Equal(t, "cd "+stagingDir+" && ansible-galaxy collection install -r="+remoteReq+" -p="+stagingDir+"/galaxy_collections "+signatureArgs, comm.startCommand[0])
+	require.Equal(t, "cd "+stagingDir+" && ansible-galaxy collection verify -r="+remoteReq+" -p="+stagingDir+"/galaxy_collections "+signatureArgs, comm.startCommand[1])
+
+	require.NotNil(t, gm.verification)
+	require.True(t, gm.verification.Passed)
+}
+
+func TestGalaxyManager_InstallRequirements_HostLocation_FailedVerificationSkipsUpload(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+
+	stub := filepath.Join(tmpDir, "ansible-galaxy")
+	require.NoError(t, os.WriteFile(stub, []byte(`#!/bin/sh
+if [ "$2" = "verify" ]; then
+  echo "Signature verification failed for 'community.general' (return code 1):"
+  exit 1
+fi
+exit 0
+`), 0o755))
+
+	cfg := &Config{
+		RequirementsFile:      reqFile,
+		GalaxyCommand:         stub,
+		GalaxyInstallLocation: GalaxyInstallLocationHost,
+		RolesPath:             filepath.Join(tmpDir, "roles"),
+		CollectionsPath:       filepath.Join(tmpDir, "collections"),
+		GalaxyKeyring:         filepath.Join(tmpDir, "pubring.kbx"),
+	}
+
+	comm := &communicatorMock{}
+	stagingDir := "/tmp/packer-provisioner-ansible-local-test"
+	gm := NewGalaxyManager(cfg, packersdk.TestUi(t), comm, stagingDir, stagingDir+"/galaxy_roles", stagingDir+"/galaxy_collections")
+
+	err := gm.InstallRequirements()
+	require.ErrorContains(t, err, "collection verification failed for: community.general")
+	require.Equal(t, []CollectionVerification{
+		{Name: "community.general", Status: CollectionVerificationFailed, Message: "signature verification failed"},
+	}, gm.verification.Collections)
+
+	// Unverified content never reaches the target.
+	require.Empty(t, comm.uploadDestination)
+	require.Empty(t, comm.startCommand)
+}
+
+func TestConfigValidate_GalaxySignatureOptions(t *testing.T) {
+	cfg := &Config{Plays: []Play{{Target: "ns.coll.role"}}}
+	cfg.GalaxyIgnoreSignatureStatusCodes = []string{"NO_PUBKEY"}
+	require.ErrorContains(t, cfg.Validate(), "require galaxy_keyring")
+
+	keyring := filepath.Join(t.TempDir(), "pubring.kbx")
+	require.NoError(t, os.WriteFile(keyring, []byte("keyring"), 0o600))
+	cfg.GalaxyKeyring = keyring
+	cfg.GalaxyRequiredValidSignatureCount = "+all"
+	require.NoError(t, cfg.Validate())
+
+	cfg.GalaxyRequiredValidSignatureCount = "none"
+	require.ErrorContains(t, cfg.Validate(), "invalid galaxy_required_valid_signature_count")
+}
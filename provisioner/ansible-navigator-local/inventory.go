@@ -0,0 +1,234 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+)
+
+// Defaults for the generated inventory
+const (
+	DefaultTargetInventoryName = "127.0.0.1"
+	DefaultInventoryConnection = "ssh"
+)
+
+var (
+	inventoryNamePattern = regexp.MustCompile(`^[^\s\[\]=#,]+$`)
+	inventoryVarPattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// targetGroups returns the groups of the target: groups followed by inventory_groups.
+func (c *Config) targetGroups() []string {
+	groups := append([]string{}, c.Groups...)
+	for _, g := range strings.Split(c.InventoryGroups, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// targetInventoryName returns the inventory name of the target.
+func (c *Config) targetInventoryName() string {
+	switch {
+	case c.HostAlias != "":
+		return c.HostAlias
+	case c.runsOnBuildHost():
+		return buildHostInventoryAlias
+	default:
+		return DefaultTargetInventoryName
+	}
+}
+
+// validateInventory validates the inventory options.
+func validateInventory(c *Config) []error {
+	var errs []error
+	checkName := func(setting, name string) {
+		if !inventoryNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf(
+				"invalid %s: %q (must not be empty or contain whitespace, brackets, =, # or ,)", setting, name))
+		}
+	}
+
+	if c.HostAlias != "" {
+		checkName("host_alias", c.HostAlias)
+	}
+	for _, g := range c.targetGroups() {
+		checkName("group", g)
+	}
+	for _, g := range c.EmptyGroups {
+		checkName("empty_groups entry", g)
+	}
+
+	names := map[string]bool{c.targetInventoryName(): true}
+	for i, h := range c.InventoryHosts {
+		if h.Name == "" {
+			errs = append(errs, fmt.Errorf("inventory_host %d: name must be set", i))
+		} else {
+			checkName(fmt.Sprintf("inventory_host %d name", i), h.Name)
+			if names[h.Name] {
+				errs = append(errs, fmt.Errorf("inventory_host %d: duplicate host name %q", i, h.Name))
+			}
+			names[h.Name] = true
+		}
+		if strings.ContainsAny(h.Address+h.Connection+h.User, " \t\n\r") {
+			errs = append(errs, fmt.Errorf("inventory_host %d: address, connection and user must not contain whitespace", i))
+		}
+		if h.Port < 0 || h.Port > 65535 {
+			errs = append(errs, fmt.Errorf("inventory_host %d: invalid port %d", i, h.Port))
+		}
+		for _, g := range h.Groups {
+			checkName(fmt.Sprintf("inventory_host %d group", i), g)
+		}
+		for k := range h.Vars {
+			if !inventoryVarPattern.MatchString(k) {
+				errs = append(errs, fmt.Errorf("inventory_host %d: invalid variable name %q", i, k))
+			}
+		}
+	}
+
+	if c.InventoryFile != "" {
+		if _, err := os.Stat(c.InventoryFile); err != nil {
+			errs = append(errs, fmt.Errorf("inventory_file: %s is invalid: %s", c.InventoryFile, err))
+		}
+		if c.HostAlias != "" || len(c.targetGroups()) > 0 || len(c.EmptyGroups) > 0 || len(c.InventoryHosts) > 0 {
+			errs = append(errs, fmt.Errorf(
+				"host_alias, groups, inventory_groups, empty_groups and inventory_host cannot be used with inventory_file"))
+		}
+	}
+	return errs
+}
+
+// inventoryValue quotes an INI inventory variable value when needed.
+func inventoryValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, " \t\"'#\\") {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
+
+// inventoryHostLine returns the inventory line of an inventory_host.
+func inventoryHostLine(h InventoryHost) string {
+	connection := h.Connection
+	if connection == "" {
+		connection = DefaultInventoryConnection
+	}
+	address := h.Address
+	if address == "" {
+		address = h.Name
+	}
+	vars := []string{
+		"ansible_host=" + inventoryValue(address),
+		"ansible_connection=" + inventoryValue(connection),
+	}
+	if h.User != "" {
+		vars = append(vars, "ansible_user="+inventoryValue(h.User))
+	}
+	if h.Port != 0 {
+		vars = append(vars, fmt.Sprintf("ansible_port=%d", h.Port))
+	}
+	keys := make([]string, 0, len(h.Vars))
+	for k := range h.Vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		vars = append(vars, k+"="+inventoryValue(h.Vars[k]))
+	}
+	return h.Name + " " + strings.Join(vars, " ")
+}
+
+// globalConnection reports whether the target's connection is passed to
+// ansible-navigator as the default for every host, with -c and the connection
+// of generated role playbooks. With inventory_file or inventory_host it is not:
+// it would override the connection of the other hosts, so each host sets its
+// own in the inventory, and the generated target line sets the target's.
+func (c *Config) globalConnection() bool {
+	return c.InventoryFile == "" && len(c.InventoryHosts) == 0
+}
+
+// inventoryContent returns the generated inventory: the target itself with its
+// connection (local, or target_connection in build_host mode), the
+// inventory_host hosts and the groups.
+func (c *Config) inventoryContent() string {
+	var b strings.Builder
+	target := c.targetInventoryName()
+	if c.runsOnBuildHost() {
+		fmt.Fprintf(&b, "%s ansible_host=%s ansible_connection=%s\n", target, c.TargetHost, c.TargetConnection)
+	} else {
+		fmt.Fprintf(&b, "%s ansible_connection=local\n", target)
+	}
+
+	members := make(map[string][]string)
+	var groups []string
+	addMember := func(group, host string) {
+		if _, ok := members[group]; !ok {
+			groups = append(groups, group)
+		}
+		members[group] = append(members[group], host)
+	}
+	for _, g := range c.targetGroups() {
+		addMember(g, target)
+	}
+	for _, h := range c.InventoryHosts {
+		b.WriteString(inventoryHostLine(h) + "\n")
+		for _, g := range h.Groups {
+			addMember(g, h.Name)
+		}
+	}
+
+	for _, g := range groups {
+		fmt.Fprintf(&b, "[%s]\n%s\n", g, strings.Join(members[g], "\n"))
+	}
+	for _, g := range c.EmptyGroups {
+		if _, ok := members[g]; !ok {
+			fmt.Fprintf(&b, "[%s]\n", g)
+		}
+	}
+	return b.String()
+}
+
+// uploadInventory uploads inventory_file, or the generated inventory, to the
+// staging directory and returns its remote path. An inventory directory keeps
+// its group_vars and host_vars.
+func (p *Provisioner) uploadInventory(ui packersdk.Ui, comm packersdk.Communicator) (string, error) {
+	if local := p.config.InventoryFile; local != "" {
+		info, err := os.Stat(local)
+		if err != nil {
+			return "", err
+		}
+		remote := filepath.ToSlash(filepath.Join(p.stagingDir, filepath.Base(local)))
+		ui.Message(fmt.Sprintf("Uploading inventory: %s", local))
+		if info.IsDir() {
+			return remote, p.uploadTree(ui, comm, local, remote, nil)
+		}
+		return remote, p.uploadFile(ui, comm, remote, local)
+	}
+
+	tf, err := tmp.File("packer-provisioner-ansible-navigator-local-inventory")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tf.Name())
+	if _, err := tf.Write([]byte(p.config.inventoryContent())); err != nil {
+		tf.Close()
+		return "", err
+	}
+	if err := tf.Close(); err != nil {
+		return "", err
+	}
+	remote := filepath.ToSlash(filepath.Join(p.stagingDir, "inventory.ini"))
+	ui.Message("Uploading inventory file...")
+	return remote, p.uploadFile(ui, comm, remote, tf.Name())
+}
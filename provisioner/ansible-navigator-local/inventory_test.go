@@ -0,0 +1,144 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryContent(t *testing.T) {
+	require.Equal(t, "127.0.0.1 ansible_connection=local\n", (&Config{}).inventoryContent())
+
+	c := &Config{
+		HostAlias:       "builder",
+		Groups:          []string{"packer", "guests"},
+		InventoryGroups: "web, db",
+		EmptyGroups:     []string{"unused", "web"},
+		InventoryHosts: []InventoryHost{
+			{Name: "guest1", Address: "192.168.122.10", User: "root", Port: 2222, Groups: []string{"guests"},
+				Vars: map[string]string{"role_name": "app server", "zone": "a"}},
+			{Name: "nested", Connection: "community.docker.docker"},
+		},
+	}
+	require.Equal(t, `builder ansible_connection=local
+guest1 ansible_host=192.168.122.10 ansible_connection=ssh ansible_user=root ansible_port=2222 role_name="app server" zone=a
+nested ansible_host=nested ansible_connection=community.docker.docker
+[packer]
+builder
+[guests]
+builder
+guest1
+[web]
+builder
+[db]
+builder
+[unused]
+`, c.inventoryContent())
+
+	// In build_host mode the target keeps target_connection.
+	c = &Config{
+		ExecutionHost:    ExecutionHostBuildHost,
+		TargetConnection: "community.general.chroot",
+		TargetHost:       "/mnt/chroot",
+		Groups:           []string{"chroots"},
+	}
+	require.Equal(t, "default ansible_host=/mnt/chroot ansible_connection=community.general.chroot\n[chroots]\ndefault\n", c.inventoryContent())
+}
+
+func TestValidateInventory(t *testing.T) {
+	inventory := filepath.Join(t.TempDir(), "hosts.ini")
+	require.NoError(t, os.WriteFile(inventory, []byte("localhost\n"), 0o644))
+
+	require.Empty(t, validateInventory(&Config{
+		HostAlias:      "builder",
+		Groups:         []string{"packer"},
+		InventoryHosts: []InventoryHost{{Name: "guest1", Port: 22, Vars: map[string]string{"ansible_python_interpreter": "/usr/bin/python3"}}},
+	}))
+	require.Empty(t, validateInventory(&Config{InventoryFile: inventory, Limit: "web"}))
+
+	tests := []struct {
+		name   string
+		config *Config
+		errMsg string
+	}{
+		{"host_alias with whitespace", &Config{HostAlias: "my host"}, "invalid host_alias"},
+		{"group with brackets", &Config{InventoryGroups: "a,[b]"}, "invalid group"},
+		{"host without name", &Config{InventoryHosts: []InventoryHost{{Address: "10.0.0.1"}}}, "inventory_host 0: name must be set"},
+		{"host named like the target", &Config{InventoryHosts: []InventoryHost{{Name: "127.0.0.1"}}}, `duplicate host name "127.0.0.1"`},
+		{"invalid port", &Config{InventoryHosts: []InventoryHost{{Name: "guest", Port: 70000}}}, "invalid port 70000"},
+		{"invalid variable", &Config{InventoryHosts: []InventoryHost{{Name: "guest", Vars: map[string]string{"a-b": "1"}}}}, `invalid variable name "a-b"`},
+		{"missing inventory_file", &Config{InventoryFile: inventory + ".missing"}, "inventory_file"},
+		{"inventory_file with groups", &Config{InventoryFile: inventory, Groups: []string{"packer"}}, "cannot be used with inventory_file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateInventory(tt.config)
+			require.Len(t, errs, 1)
+			require.Contains(t, errs[0].Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestProvisioner_InventoryFileAndLimit(t *testing.T) {
+	inventoryDir := writeProject(t, "inventory", "hosts.ini", "group_vars/all.yml")
+
+	var p Provisioner
+	config := testConfig()
+	config["inventory_file"] = inventoryDir
+	config["limit"] = "web:&production"
+	config["play"] = []map[string]interface{}{{"target": "web"}}
+	require.NoError(t, p.Prepare(config))
+
+	comm := &communicatorMock{}
+	require.NoError(t, p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+	require.NotContains(t, comm.uploadDestination, p.stagingDir+"/inventory.ini")
+	require.Contains(t, comm.uploadDestination, p.stagingDir+"/.upload-inventory.tar.gz")
+
+	var run string
+	for _, cmd := range comm.startCommand {
+		if strings.Contains(cmd, "ansible-navigator run") {
+			run = cmd
+		}
+	}
+	require.Contains(t, run, "--limit=web:&production")
+	require.Contains(t, run, "-i="+p.stagingDir+"/inventory")
+	// Hosts in a custom inventory keep their own connection.
+	require.NotContains(t, run, "-c=local")
+}
+
+func TestProvisioner_InventoryHostConnection(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["play"] = []map[string]interface{}{{"target": "web"}}
+	require.NoError(t, p.Prepare(config))
+	p.generatedData = map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}
+
+	// With only the target, its connection is the default for the run.
+	args, _, err := p.buildPluginArgsForPlay(newMockUi(), p.config.Plays[0], "/staging/inventory.ini")
+	require.NoError(t, err)
+	require.Contains(t, args, "-c=local")
+
+	// With additional hosts, -c would override their connection.
+	p.config.InventoryHosts = []InventoryHost{{Name: "guest1", Address: "192.168.122.10"}}
+	args, _, err = p.buildPluginArgsForPlay(newMockUi(), p.config.Plays[0], "/staging/inventory.ini")
+	require.NoError(t, err)
+	require.NotContains(t, args, "-c=local")
+	require.Equal(t, "127.0.0.1 ansible_connection=local\nguest1 ansible_host=192.168.122.10 ansible_connection=ssh\n",
+		p.config.inventoryContent())
+
+	playbook, err := p.createRolePlaybook("web", p.config.Plays[0])
+	require.NoError(t, err)
+	defer os.Remove(playbook)
+	content, err := os.ReadFile(playbook)
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "connection:")
+}
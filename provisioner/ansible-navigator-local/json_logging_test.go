@@ -0,0 +1,109 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeNavigatorEvents(t *testing.T) {
+	output := strings.Join([]string{
+		`Using /etc/ansible/ansible.cfg as config file`,
+		`{"event": "playbook_on_start", "uuid": "1", "counter": 1, "play": "Setup"}`,
+		`{`,
+		`  "event": "runner_on_ok",`,
+		`  "uuid": "2",`,
+		`  "counter": 2,`,
+		`  "task": "Install nginx",`,
+		`  "host": "default"`,
+		`}`,
+		`{not json`,
+		`{"event": "runner_on_failed", "uuid": "3", "counter": 3, "task": "Start nginx", "host": "default"}`,
+	}, "\n") + "\n"
+
+	ui := newMockUi()
+	summary := &Summary{FailedTasks: make([]NavigatorEvent, 0)}
+	decodeNavigatorEvents(ui, strings.NewReader(output), summary, true)
+
+	messages := ui.(*mockUi).messageMessages
+	require.Contains(t, messages, "Using /etc/ansible/ansible.cfg as config file")
+	require.Contains(t, messages, "==> ansible-navigator: Play 'Setup' started")
+	require.Contains(t, messages, "==> ansible-navigator: Task 'Install nginx' [default]: OK")
+	require.Len(t, filterPrefix(messages, "[Warning] Skipped malformed JSON event"), 1)
+	for _, m := range messages {
+		require.NotContains(t, m, `"event"`)
+	}
+
+	require.Equal(t, 1, summary.PlaysRun)
+	require.Equal(t, 2, summary.TasksTotal)
+	require.Equal(t, 1, summary.TasksFailed)
+	require.Equal(t, "Start nginx", summary.FailedTasks[0].Task)
+}
+
+func TestDecodeNavigatorEvents_TruncatedEvent(t *testing.T) {
+	ui := newMockUi()
+	summary := &Summary{FailedTasks: make([]NavigatorEvent, 0)}
+	decodeNavigatorEvents(ui, strings.NewReader("{\"event\": \"playbook_on_start\",\n"), summary, true)
+	require.Zero(t, summary.PlaysRun)
+	require.Len(t, filterPrefix(ui.(*mockUi).messageMessages, "[Warning] Skipped malformed JSON event"), 1)
+}
+
+func TestProvisioner_StructuredLogging(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+	logOutput := filepath.Join(t.TempDir(), "summary.json")
+	navigator := filepath.Join(t.TempDir(), "ansible-navigator")
+	require.NoError(t, os.WriteFile(navigator, []byte(`#!/bin/sh
+[ "$1" = run ] || exit 0
+echo '{"event": "playbook_on_start", "uuid": "1", "counter": 1, "play": "Setup"}'
+echo '{"event": "runner_on_ok", "uuid": "2", "counter": 2, "task": "Install nginx", "host": "default"}'
+echo '{"event": "runner_on_failed", "uuid": "3", "counter": 3, "task": "Start nginx", "host": "default", "stderr": "unit not found"}'
+exit 2
+`), 0o755))
+
+	var p Provisioner
+	config := testConfig()
+	config["command"] = navigator
+	config["structured_logging"] = true
+	config["log_output_path"] = logOutput
+	config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+	require.NoError(t, p.Prepare(config))
+
+	ui := newMockUi()
+	err := p.Provision(context.Background(), ui, &localCommunicator{}, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed with exit code 2")
+
+	messages := ui.(*mockUi).messageMessages
+	require.Contains(t, messages, "==> ansible-navigator: Task 'Install nginx' [default]: OK")
+	require.Contains(t, messages, "Summary: 1 play(s) executed, 2 task(s) total, 1 failed")
+	require.Contains(t, messages, "Structured log written to: "+logOutput)
+
+	data, err := os.ReadFile(logOutput)
+	require.NoError(t, err)
+	var summary Summary
+	require.NoError(t, json.Unmarshal(data, &summary))
+	require.Equal(t, 1, summary.PlaysRun)
+	require.Equal(t, 1, summary.TasksFailed)
+	require.Equal(t, "Start nginx", summary.FailedTasks[0].Task)
+	require.Equal(t, "unit not found", summary.FailedTasks[0].Stderr)
+}
+
+func filterPrefix(messages []string, prefix string) []string {
+	var matched []string
+	for _, m := range messages {
+		if strings.HasPrefix(m, prefix) {
+			matched = append(matched, m)
+		}
+	}
+	return matched
+}
@@ -51,16 +51,21 @@ func applyAutomaticEEDefaults(config *NavigatorConfig, collectionsPath string) {
 	if config.AnsibleConfig == nil {
 		config.AnsibleConfig = &AnsibleConfig{}
 	}
-	if config.AnsibleConfig.Defaults == nil {
-		config.AnsibleConfig.Defaults = &AnsibleConfigDefaults{}
-	}
 
-	// Set temp directory defaults to prevent permission errors
-	if config.AnsibleConfig.Defaults.RemoteTmp == "" {
-		config.AnsibleConfig.Defaults.RemoteTmp = "/tmp/.ansible/tmp"
-	}
-	if config.AnsibleConfig.Defaults.LocalTmp == "" {
-		config.AnsibleConfig.Defaults.LocalTmp = "/tmp/.ansible-local"
+	// Set temp directory defaults to prevent permission errors, unless a generic
+	// [defaults] section block already sets them. An ansible_config.config without
+	// generated settings is a file on the target that cannot be merged; the
+	// ANSIBLE_REMOTE_TMP / ANSIBLE_LOCAL_TMP variables below cover it.
+	if config.AnsibleConfig.Config == "" || needsGeneratedAnsibleCfg(config.AnsibleConfig) {
+		if config.AnsibleConfig.Defaults == nil {
+			config.AnsibleConfig.Defaults = &AnsibleConfigDefaults{}
+		}
+		if config.AnsibleConfig.Defaults.RemoteTmp == "" && !ansibleCfgSectionOption(config.AnsibleConfig, "defaults", "remote_tmp") {
+			config.AnsibleConfig.Defaults.RemoteTmp = "/tmp/.ansible/tmp"
+		}
+		if config.AnsibleConfig.Defaults.LocalTmp == "" && !ansibleCfgSectionOption(config.AnsibleConfig, "defaults", "local_tmp") {
+			config.AnsibleConfig.Defaults.LocalTmp = "/tmp/.ansible-local"
+		}
 	}
 
 	// Set environment variables for EE container
@@ -280,12 +285,213 @@ func convertToYAMLStructure(config *NavigatorConfig) map[string]interface{} {
 		}
 	}
 
+	if colorMap := colorYAML(config.Color); len(colorMap) > 0 {
+		result["color"] = colorMap
+	}
+	if editorMap := editorYAML(config.Editor); len(editorMap) > 0 {
+		result["editor"] = editorMap
+	}
+	if imagesMap := imagesYAML(config.Images); len(imagesMap) > 0 {
+		result["images"] = imagesMap
+	}
+	if len(config.InventoryColumns) > 0 {
+		result["inventory-columns"] = config.InventoryColumns
+	}
+	if config.TimeZone != "" {
+		result["time-zone"] = config.TimeZone
+	}
+	if config.Format != "" {
+		result["format"] = config.Format
+	}
+	if settingsMap := settingsYAML(config.Settings); len(settingsMap) > 0 {
+		result["settings"] = settingsMap
+	}
+	if config.EnablePrompts != nil {
+		result["enable-prompts"] = *config.EnablePrompts
+	}
+	if runnerMap := ansibleRunnerYAML(config.AnsibleRunner); len(runnerMap) > 0 {
+		result["ansible-runner"] = runnerMap
+	}
+
 	// Wrap everything under the ansible-navigator root key
 	return map[string]interface{}{
 		"ansible-navigator": result,
 	}
 }
 
+// validateNavigatorSettings validates navigator_config settings that the
+// ansible-navigator schema constrains beyond their types.
+func validateNavigatorSettings(config *NavigatorConfig) []error {
+	var errs []error
+	if config == nil {
+		return nil
+	}
+
+	enumErrs := []error{
+		validateEnumSetting("navigator_config.mode", config.Mode, "mode"),
+		validateEnumSetting("navigator_config.format", config.Format, "format"),
+	}
+	if ee := config.ExecutionEnvironment; ee != nil {
+		enumErrs = append(enumErrs,
+			validateEnumSetting("navigator_config.execution_environment.pull_policy", ee.PullPolicy, "execution-environment", "pull", "policy"),
+			validateEnumSetting("navigator_config.execution_environment.container_engine", ee.ContainerEngine, "execution-environment", "container-engine"))
+	}
+	if config.Logging != nil {
+		enumErrs = append(enumErrs,
+			validateEnumSetting("navigator_config.logging.level", config.Logging.Level, "logging", "level"))
+	}
+	if config.Settings != nil {
+		enumErrs = append(enumErrs,
+			validateEnumSetting("navigator_config.settings.schema", config.Settings.Schema, "settings", "schema"))
+	}
+	if config.Images != nil {
+		for i, detail := range config.Images.Details {
+			enumErrs = append(enumErrs,
+				validateEnumSetting(fmt.Sprintf("navigator_config.images.details[%d]", i), detail, "images", "details"))
+		}
+	}
+	for _, err := range enumErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if ee := config.ExecutionEnvironment; ee != nil {
+		// Each pull argument is passed to the container engine as a single argv entry.
+		for i, arg := range ee.PullArguments {
+			if strings.TrimSpace(arg) == "" {
+				errs = append(errs, fmt.Errorf(
+					"navigator_config.execution_environment.pull_arguments[%d] must not be empty", i))
+			} else if strings.ContainsAny(arg, " \t\n\r") {
+				errs = append(errs, fmt.Errorf(
+					"navigator_config.execution_environment.pull_arguments[%d] must be a single argument without whitespace (use \"--flag=value\" or separate entries): %q", i, arg))
+			}
+		}
+		errs = append(errs, validateImageDigest(ee)...)
+		errs = append(errs, validateImageVerify(ee)...)
+		switch ee.Preflight {
+		case "", EEPreflightWarn, EEPreflightFail, EEPreflightOff:
+		default:
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.execution_environment.preflight must be %q, %q or %q (got %q)",
+				EEPreflightWarn, EEPreflightFail, EEPreflightOff, ee.Preflight))
+		}
+	}
+
+	if ar := config.AnsibleRunner; ar != nil {
+		if ar.RotateArtifactsCount < 0 {
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.ansible_runner.rotate_artifacts_count must not be negative (got %d)", ar.RotateArtifactsCount))
+		}
+		if ar.Timeout < 0 {
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.ansible_runner.timeout must not be negative (got %d)", ar.Timeout))
+		}
+	}
+
+	return errs
+}
+
+// validateNavigatorConfigDocument validates the ansible-navigator.yml generated
+// from config against the embedded ansible-navigator settings schema.
+func validateNavigatorConfigDocument(config *NavigatorConfig) []error {
+	// Round-trip through YAML so values have the types ansible-navigator will read.
+	data, err := yaml.Marshal(convertToYAMLStructure(config))
+	if err != nil {
+		return []error{fmt.Errorf("failed to marshal navigator_config to YAML: %w", err)}
+	}
+	var decoded interface{}
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		return []error{fmt.Errorf("failed to parse generated ansible-navigator.yml: %w", err)}
+	}
+
+	return validateSchema(navigatorSettingsSchema, decoded, nil, navigatorConfigHCLPath)
+}
+
+// colorYAML converts color settings to the ansible-navigator.yml "color" object.
+func colorYAML(color *ColorConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if color == nil {
+		return m
+	}
+	if color.Enable != nil {
+		m["enable"] = *color.Enable
+	}
+	if color.Osc4 != nil {
+		m["osc4"] = *color.Osc4
+	}
+	return m
+}
+
+// editorYAML converts editor settings to the ansible-navigator.yml "editor" object.
+func editorYAML(editor *EditorConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if editor == nil {
+		return m
+	}
+	if editor.Command != "" {
+		m["command"] = editor.Command
+	}
+	if editor.Console != nil {
+		m["console"] = *editor.Console
+	}
+	return m
+}
+
+// imagesYAML converts images settings to the ansible-navigator.yml "images" object.
+func imagesYAML(images *ImagesConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if images == nil {
+		return m
+	}
+	if len(images.Details) > 0 {
+		m["details"] = images.Details
+	}
+	return m
+}
+
+// settingsYAML converts settings subcommand options to the ansible-navigator.yml "settings" object.
+func settingsYAML(settings *SettingsConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if settings == nil {
+		return m
+	}
+	if settings.Effective != nil {
+		m["effective"] = *settings.Effective
+	}
+	if settings.Sample != nil {
+		m["sample"] = *settings.Sample
+	}
+	if settings.Schema != "" {
+		m["schema"] = settings.Schema
+	}
+	if settings.Sources != nil {
+		m["sources"] = *settings.Sources
+	}
+	return m
+}
+
+// ansibleRunnerYAML converts ansible-runner settings to the ansible-navigator.yml "ansible-runner" object.
+func ansibleRunnerYAML(runner *AnsibleRunnerConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if runner == nil {
+		return m
+	}
+	if runner.ArtifactDir != "" {
+		m["artifact-dir"] = runner.ArtifactDir
+	}
+	if runner.RotateArtifactsCount > 0 {
+		m["rotate-artifacts-count"] = runner.RotateArtifactsCount
+	}
+	if runner.Timeout > 0 {
+		m["timeout"] = runner.Timeout
+	}
+	if runner.JobEvents != nil {
+		m["job-events"] = *runner.JobEvents
+	}
+	return m
+}
+
 // createNavigatorConfigFile creates a temporary ansible-navigator.yml file
 // Returns the absolute path to the file
 func createNavigatorConfigFile(content string) (string, error) {
@@ -327,9 +533,22 @@ func createNavigatorConfigFile(content string) (string, error) {
 //	logging.file                              -> --log-file <value>
 //	logging.append (true)                     -> --log-append true
 //	ansible_config.config                     -> --ansible-config <path>
+//	color.enable                              -> --display-color <bool>
+//	color.osc4                                -> --osc4 <bool>
+//	editor.command                            -> --editor-command <value>
+//	editor.console                            -> --editor-console <bool>
+//	enable_prompts                            -> --enable-prompts <bool>
+//	time_zone                                 -> --time-zone <value>
+//	ansible_runner.artifact_dir               -> --ansible-runner-artifact-dir <path>
+//	ansible_runner.rotate_artifacts_count     -> --ansible-runner-rotate-artifacts-count <n>
+//	ansible_runner.timeout                    -> --ansible-runner-timeout <n>
+//	ansible_runner.job_events                 -> --ansible-runner-write-job-events <bool>
+//
+// The execution environment flags use the names the ansible-navigator version
+// in caps accepts; caps is nil when the version check was skipped.
 //
 // Returns a slice of CLI flags ready to append to ansible-navigator command.
-func buildNavigatorCLIFlags(config *NavigatorConfig) []string {
+func buildNavigatorCLIFlags(config *NavigatorConfig, caps *targetCapabilities) []string {
 	if config == nil {
 		return nil
 	}
@@ -345,14 +564,14 @@ func buildNavigatorCLIFlags(config *NavigatorConfig) []string {
 	if config.ExecutionEnvironment != nil {
 		// Explicit disable takes precedence
 		if !config.ExecutionEnvironment.Enabled {
-			flags = append(flags, "--execution-environment=false")
+			flags = append(flags, caps.flagName("--execution-environment")+"=false")
 		} else {
 			// When enabled, specify other EE flags
 			if config.ExecutionEnvironment.Image != "" {
-				flags = append(flags, fmt.Sprintf("--execution-environment-image=%s", config.ExecutionEnvironment.Image))
+				flags = append(flags, fmt.Sprintf("%s=%s", caps.flagName("--execution-environment-image"), config.ExecutionEnvironment.Image))
 			}
 			if config.ExecutionEnvironment.ContainerEngine != "" {
-				flags = append(flags, fmt.Sprintf("--execution-environment-container-engine=%s", config.ExecutionEnvironment.ContainerEngine))
+				flags = append(flags, fmt.Sprintf("%s=%s", caps.flagName("--execution-environment-container-engine"), config.ExecutionEnvironment.ContainerEngine))
 			}
 			if config.ExecutionEnvironment.PullPolicy != "" {
 				flags = append(flags, fmt.Sprintf("--pull-policy=%s", config.ExecutionEnvironment.PullPolicy))
@@ -417,6 +636,49 @@ func buildNavigatorCLIFlags(config *NavigatorConfig) []string {
 		flags = append(flags, fmt.Sprintf("--ansible-config=%s", config.AnsibleConfig.Config))
 	}
 
+	// Color flags
+	if config.Color != nil {
+		if config.Color.Enable != nil {
+			flags = append(flags, fmt.Sprintf("--display-color=%t", *config.Color.Enable))
+		}
+		if config.Color.Osc4 != nil {
+			flags = append(flags, fmt.Sprintf("--osc4=%t", *config.Color.Osc4))
+		}
+	}
+
+	// Editor flags
+	if config.Editor != nil {
+		if config.Editor.Command != "" {
+			flags = append(flags, fmt.Sprintf("--editor-command=%s", config.Editor.Command))
+		}
+		if config.Editor.Console != nil {
+			flags = append(flags, fmt.Sprintf("--editor-console=%t", *config.Editor.Console))
+		}
+	}
+
+	if config.EnablePrompts != nil {
+		flags = append(flags, fmt.Sprintf("--enable-prompts=%t", *config.EnablePrompts))
+	}
+	if config.TimeZone != "" {
+		flags = append(flags, fmt.Sprintf("--time-zone=%s", config.TimeZone))
+	}
+
+	// ansible-runner flags
+	if config.AnsibleRunner != nil {
+		if config.AnsibleRunner.ArtifactDir != "" {
+			flags = append(flags, fmt.Sprintf("--ansible-runner-artifact-dir=%s", config.AnsibleRunner.ArtifactDir))
+		}
+		if config.AnsibleRunner.RotateArtifactsCount > 0 {
+			flags = append(flags, fmt.Sprintf("--ansible-runner-rotate-artifacts-count=%d", config.AnsibleRunner.RotateArtifactsCount))
+		}
+		if config.AnsibleRunner.Timeout > 0 {
+			flags = append(flags, fmt.Sprintf("--ansible-runner-timeout=%d", config.AnsibleRunner.Timeout))
+		}
+		if config.AnsibleRunner.JobEvents != nil {
+			flags = append(flags, fmt.Sprintf("--ansible-runner-write-job-events=%t", *config.AnsibleRunner.JobEvents))
+		}
+	}
+
 	return flags
 }
 
@@ -426,6 +688,9 @@ func buildNavigatorCLIFlags(config *NavigatorConfig) []string {
 // Currently unmapped settings (require YAML):
 //   - playbook_artifact.*  (enable, replay, save-as)
 //   - collection_doc_cache.* (path, timeout)
+//   - execution_environment.pull_arguments
+//   - images.details, inventory_columns, format, settings.* (subcommand settings
+//     that `ansible-navigator run` does not accept as flags)
 //
 // Returns true if minimal YAML generation is needed.
 func hasUnmappedSettings(config *NavigatorConfig) bool {
@@ -447,11 +712,22 @@ func hasUnmappedSettings(config *NavigatorConfig) bool {
 		}
 	}
 
+	// Check for pull arguments (only honored when EE is enabled)
+	if config.ExecutionEnvironment != nil && config.ExecutionEnvironment.Enabled && len(config.ExecutionEnvironment.PullArguments) > 0 {
+		return true
+	}
+
+	// Check for subcommand settings
+	if len(imagesYAML(config.Images)) > 0 || len(config.InventoryColumns) > 0 ||
+		config.Format != "" || len(settingsYAML(config.Settings)) > 0 {
+		return true
+	}
+
 	return false
 }
 
 // generateMinimalYAML generates a minimal ansible-navigator.yml containing ONLY
-// settings that cannot be expressed via CLI flags (playbook-artifact, collection-doc-cache).
+// settings that cannot be expressed via CLI flags (see hasUnmappedSettings).
 //
 // This function is only called when hasUnmappedSettings() returns true.
 // Returns empty string if no unmapped settings exist.
@@ -492,6 +768,29 @@ func generateMinimalYAML(config *NavigatorConfig) (string, error) {
 		}
 	}
 
+	// Include execution-environment.pull.arguments if configured
+	if config.ExecutionEnvironment != nil && config.ExecutionEnvironment.Enabled && len(config.ExecutionEnvironment.PullArguments) > 0 {
+		ansibleNavigator["execution-environment"] = map[string]interface{}{
+			"pull": map[string]interface{}{
+				"arguments": config.ExecutionEnvironment.PullArguments,
+			},
+		}
+	}
+
+	// Include subcommand settings if configured
+	if imagesMap := imagesYAML(config.Images); len(imagesMap) > 0 {
+		ansibleNavigator["images"] = imagesMap
+	}
+	if len(config.InventoryColumns) > 0 {
+		ansibleNavigator["inventory-columns"] = config.InventoryColumns
+	}
+	if config.Format != "" {
+		ansibleNavigator["format"] = config.Format
+	}
+	if settingsMap := settingsYAML(config.Settings); len(settingsMap) > 0 {
+		ansibleNavigator["settings"] = settingsMap
+	}
+
 	// If no unmapped settings, return empty
 	if len(ansibleNavigator) == 0 {
 		return "", nil
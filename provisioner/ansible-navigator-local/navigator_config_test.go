@@ -565,7 +565,7 @@ func TestBuildNavigatorCLIFlags_Basic(t *testing.T) {
 		},
 	}
 
-	flags := buildNavigatorCLIFlags(config)
+	flags := buildNavigatorCLIFlags(config, nil)
 
 	expectedFlags := []string{
 		"--mode=stdout",
@@ -603,7 +603,7 @@ func TestBuildNavigatorCLIFlags_Complex(t *testing.T) {
 		},
 	}
 
-	flags := buildNavigatorCLIFlags(config)
+	flags := buildNavigatorCLIFlags(config, nil)
 
 	// Check for repeatable flags
 	foundEEV := false
@@ -693,3 +693,99 @@ func TestGenerateMinimalYAML(t *testing.T) {
 		t.Error("Minimal YAML should contain save-as value")
 	}
 }
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestBuildNavigatorCLIFlags_ExtendedSettings(t *testing.T) {
+	config := &NavigatorConfig{
+		Color:         &ColorConfig{Enable: boolPtr(false), Osc4: boolPtr(true)},
+		Editor:        &EditorConfig{Command: "vi +{line_number} {filename}", Console: boolPtr(true)},
+		EnablePrompts: boolPtr(false),
+		TimeZone:      "UTC",
+		AnsibleRunner: &AnsibleRunnerConfig{
+			ArtifactDir:          "/tmp/artifacts",
+			RotateArtifactsCount: 5,
+			Timeout:              600,
+			JobEvents:            boolPtr(true),
+		},
+		// Subcommand settings are not run flags.
+		Images:           &ImagesConfig{Details: []string{"everything"}},
+		InventoryColumns: []string{"ansible_host"},
+		Format:           "json",
+		Settings:         &SettingsConfig{Effective: boolPtr(true)},
+	}
+
+	got := strings.Join(buildNavigatorCLIFlags(config, nil), " ")
+	want := "--display-color=false --osc4=true" +
+		" --editor-command=vi +{line_number} {filename} --editor-console=true" +
+		" --enable-prompts=false --time-zone=UTC" +
+		" --ansible-runner-artifact-dir=/tmp/artifacts --ansible-runner-rotate-artifacts-count=5" +
+		" --ansible-runner-timeout=600 --ansible-runner-write-job-events=true"
+	if got != want {
+		t.Errorf("unexpected flags:\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestGenerateMinimalYAML_SubcommandSettingsAndPullArguments(t *testing.T) {
+	config := &NavigatorConfig{
+		TimeZone: "UTC", // Mapped
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Enabled:       true,
+			Image:         "quay.io/ansible/creator-ee:latest", // Mapped
+			PullArguments: []string{"--tls-verify=false"},
+		},
+		Images:           &ImagesConfig{Details: []string{"everything"}},
+		InventoryColumns: []string{"ansible_host"},
+		Format:           "json",
+		Settings:         &SettingsConfig{Schema: "json"},
+	}
+
+	if !hasUnmappedSettings(config) {
+		t.Fatal("Expected hasUnmappedSettings to be true for subcommand settings and pull arguments")
+	}
+
+	yamlStr, err := generateMinimalYAML(config)
+	if err != nil {
+		t.Fatalf("generateMinimalYAML failed: %v", err)
+	}
+
+	var parsed map[string]map[string]interface{}
+	if err := yaml.Unmarshal([]byte(yamlStr), &parsed); err != nil {
+		t.Fatalf("generated YAML is invalid: %v", err)
+	}
+	nav := parsed["ansible-navigator"]
+	for _, key := range []string{"images", "inventory-columns", "format", "settings", "execution-environment"} {
+		if _, ok := nav[key]; !ok {
+			t.Errorf("Minimal YAML should contain %s, got: %s", key, yamlStr)
+		}
+	}
+	for _, unexpected := range []string{"time-zone", "image:", "enabled"} {
+		if strings.Contains(yamlStr, unexpected) {
+			t.Errorf("Minimal YAML should NOT contain mapped setting %s, got: %s", unexpected, yamlStr)
+		}
+	}
+	if !strings.Contains(yamlStr, "--tls-verify=false") {
+		t.Errorf("Minimal YAML should contain pull arguments, got: %s", yamlStr)
+	}
+}
+
+func TestConfigValidate_NavigatorPullArguments(t *testing.T) {
+	cfg := &Config{
+		Plays: []Play{{Target: "ns.coll.role"}},
+		NavigatorConfig: &NavigatorConfig{
+			ExecutionEnvironment: &ExecutionEnvironment{
+				Enabled:       true,
+				PullArguments: []string{"--creds user:pass"},
+			},
+		},
+	}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "pull_arguments[0] must be a single argument without whitespace") {
+		t.Fatalf("expected pull_arguments validation error, got: %v", err)
+	}
+
+	cfg.NavigatorConfig.ExecutionEnvironment.PullArguments = []string{"--creds=user:pass"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expected valid config, got: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// playbookDirFor returns the playbook_dir that applies to play, or empty.
+func (c *Config) playbookDirFor(play Play) string {
+	if play.PlaybookDir != "" {
+		return play.PlaybookDir
+	}
+	return c.PlaybookDir
+}
+
+// validatePlaybookDirs validates playbook_dir, playbook_dir_exclude and the
+// play-level playbook_dir overrides.
+func validatePlaybookDirs(c *Config) []error {
+	var errs []error
+	if c.PlaybookDir != "" {
+		if err := validateDirConfig(c.PlaybookDir, "playbook_dir"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, pattern := range c.PlaybookDirExclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("invalid playbook_dir_exclude pattern %q: %w", pattern, err))
+		}
+	}
+	if len(c.PlaybookDirExclude) > 0 && c.PlaybookDir == "" && !anyPlayHasPlaybookDir(c.Plays) {
+		errs = append(errs, fmt.Errorf("playbook_dir_exclude requires playbook_dir"))
+	}
+
+	for i, play := range c.Plays {
+		if play.PlaybookDir != "" {
+			if err := validateDirConfig(play.PlaybookDir, fmt.Sprintf("play %d playbook_dir", i)); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		dir := c.playbookDirFor(play)
+		if dir == "" || !isPlaybookTarget(play.Target) {
+			continue
+		}
+		if _, err := playbookPathInDir(dir, play.Target); err != nil {
+			errs = append(errs, fmt.Errorf("play %d: %w", i, err))
+		}
+	}
+	return errs
+}
+
+func anyPlayHasPlaybookDir(plays []Play) bool {
+	for _, play := range plays {
+		if play.PlaybookDir != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func isPlaybookTarget(target string) bool {
+	return strings.HasSuffix(target, ".yml") || strings.HasSuffix(target, ".yaml")
+}
+
+// playbookPathInDir returns the slash-separated path of playbook relative to
+// dir, or an error when playbook is not inside dir.
+func playbookPathInDir(dir, playbook string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPlaybook, err := filepath.Abs(playbook)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absDir, absPlaybook)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("target %s must be inside playbook_dir %s", playbook, dir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// stagePlaybookDir uploads dir to the staging directory, once per build, and
+// returns its remote path.
+func (p *Provisioner) stagePlaybookDir(ui packersdk.Ui, comm packersdk.Communicator, dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if remoteDir, ok := p.playbookDirs[absDir]; ok {
+		return remoteDir, nil
+	}
+
+	// Keep the directory name, made unique when two directories share it.
+	name := filepath.Base(absDir)
+	remoteDir := filepath.ToSlash(filepath.Join(p.stagingDir, "playbooks", name))
+	for n := 2; p.isStagedPlaybookDir(remoteDir); n++ {
+		remoteDir = filepath.ToSlash(filepath.Join(p.stagingDir, "playbooks", fmt.Sprintf("%s-%d", name, n)))
+	}
+
+	ui.Message(fmt.Sprintf("Uploading playbook directory: %s", dir))
+	if err := p.uploadTree(ui, comm, absDir, remoteDir, p.config.PlaybookDirExclude); err != nil {
+		return "", err
+	}
+	if p.playbookDirs == nil {
+		p.playbookDirs = make(map[string]string)
+	}
+	p.playbookDirs[absDir] = remoteDir
+	return remoteDir, nil
+}
+
+// ansibleCfgRemotePath returns where the generated ansible.cfg is uploaded.
+// Relative paths in the ansible_config.config it was merged from resolve
+// against that file's directory, so when the file is part of a playbook_dir the
+// merged copy is uploaded next to it in the uploaded tree. Otherwise it goes to
+// the staging directory.
+func (p *Provisioner) ansibleCfgRemotePath(ui packersdk.Ui, comm packersdk.Communicator, original string) (string, error) {
+	if original != "" {
+		absCfgDir, err := filepath.Abs(filepath.Dir(original))
+		if err != nil {
+			return "", err
+		}
+		for _, play := range p.config.Plays {
+			dir := p.config.playbookDirFor(play)
+			if dir == "" {
+				continue
+			}
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				return "", err
+			}
+			rel, err := filepath.Rel(absDir, absCfgDir)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			remoteDir, err := p.stagePlaybookDir(ui, comm, dir)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(remoteDir, filepath.ToSlash(rel), "packer-ansible.cfg"), nil
+		}
+	}
+	return filepath.ToSlash(filepath.Join(p.stagingDir, "ansible.cfg")), nil
+}
+
+func (p *Provisioner) isStagedPlaybookDir(remoteDir string) bool {
+	for _, staged := range p.playbookDirs {
+		if staged == remoteDir {
+			return true
+		}
+	}
+	return false
+}
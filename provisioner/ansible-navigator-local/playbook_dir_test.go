@@ -0,0 +1,187 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeProject creates a playbook project with the given files under a
+// directory named name and returns its path.
+func writeProject(t *testing.T, name string, files ...string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("---\n"), 0o644))
+	}
+	return dir
+}
+
+func TestWriteTarGz_Exclude(t *testing.T) {
+	dir := writeProject(t, "project",
+		"site.yml", "site.retry", "roles/web/tasks/main.yml", "group_vars/all.yml",
+		".git/config", "molecule/default/molecule.yml", "roles/web/molecule/default/molecule.yml")
+
+	var buf bytes.Buffer
+	require.NoError(t, writeTarGz(&buf, dir, []string{".git", "*.retry", "/molecule"}))
+
+	gr, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	var files []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if hdr.Typeflag == tar.TypeReg {
+			files = append(files, hdr.Name)
+		}
+	}
+	require.ElementsMatch(t, []string{
+		"site.yml", "roles/web/tasks/main.yml", "group_vars/all.yml", "roles/web/molecule/default/molecule.yml",
+	}, files)
+}
+
+func TestValidatePlaybookDirs(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+	other := writeProject(t, "other", "other.yml")
+
+	require.Empty(t, validatePlaybookDirs(&Config{
+		PlaybookDir:        dir,
+		PlaybookDirExclude: []string{".git"},
+		Plays: []Play{
+			{Target: filepath.Join(dir, "site.yml")},
+			{Target: filepath.Join(other, "other.yml"), PlaybookDir: other},
+			{Target: "geerlingguy.docker"},
+		},
+	}))
+
+	tests := []struct {
+		name   string
+		config *Config
+		errMsg string
+	}{
+		{"missing dir", &Config{PlaybookDir: filepath.Join(dir, "missing")}, "playbook_dir"},
+		{"target outside", &Config{PlaybookDir: dir, Plays: []Play{{Target: filepath.Join(other, "other.yml")}}}, "play 0: target " + filepath.Join(other, "other.yml") + " must be inside playbook_dir"},
+		{"play dir is a file", &Config{Plays: []Play{{Target: "site.yml", PlaybookDir: filepath.Join(dir, "site.yml")}}}, "play 0 playbook_dir"},
+		{"bad pattern", &Config{PlaybookDir: dir, PlaybookDirExclude: []string{"[a-"}}, "invalid playbook_dir_exclude pattern"},
+		{"exclude without dir", &Config{PlaybookDirExclude: []string{".git"}}, "playbook_dir_exclude requires playbook_dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validatePlaybookDirs(tt.config)
+			require.Len(t, errs, 1)
+			require.Contains(t, errs[0].Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestProvisioner_PlaybookDir(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml", "playbooks/db.yml", "roles/web/tasks/main.yml")
+	override := writeProject(t, "project", "extra.yml")
+
+	var p Provisioner
+	config := testConfig()
+	config["playbook_dir"] = dir
+	config["playbook_dir_exclude"] = []string{".git"}
+	config["play"] = []map[string]interface{}{
+		{"target": filepath.Join(dir, "site.yml")},
+		{"target": filepath.Join(dir, "playbooks", "db.yml")},
+		{"target": "web"},
+		{"target": filepath.Join(override, "extra.yml"), "playbook_dir": override},
+	}
+	require.NoError(t, p.Prepare(config))
+
+	comm := &communicatorMock{}
+	require.NoError(t, p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+
+	remoteDir := p.stagingDir + "/playbooks/project"
+	overrideDir := p.stagingDir + "/playbooks/project-2"
+
+	// Each directory is uploaded once, as an archive.
+	require.Contains(t, comm.uploadDestination, p.stagingDir+"/.upload-project.tar.gz")
+	require.Contains(t, comm.uploadDestination, p.stagingDir+"/.upload-project-2.tar.gz")
+	require.NotContains(t, comm.uploadDestination, p.stagingDir+"/site.yml")
+
+	var runs []string
+	for _, cmd := range comm.startCommand {
+		if strings.Contains(cmd, "ansible-navigator run") {
+			runs = append(runs, cmd)
+		}
+	}
+	require.Len(t, runs, 4)
+	require.True(t, strings.HasPrefix(runs[0], "cd "+remoteDir+" && "))
+	require.True(t, strings.HasSuffix(runs[0], " "+remoteDir+"/site.yml"))
+	require.True(t, strings.HasSuffix(runs[1], " "+remoteDir+"/playbooks/db.yml"))
+	// The generated role playbook sits next to the project's roles/.
+	require.Contains(t, runs[2], " "+remoteDir+"/packer-role-playbook-")
+	require.True(t, strings.HasPrefix(runs[3], "cd "+overrideDir+" && "))
+	require.True(t, strings.HasSuffix(runs[3], " "+overrideDir+"/extra.yml"))
+}
+
+func TestProvisioner_MergedAnsibleCfgNextToOriginal(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml", "config/ansible.cfg")
+	outside := writeProject(t, "other", "ansible.cfg")
+	for _, cfg := range []string{filepath.Join(dir, "config", "ansible.cfg"), filepath.Join(outside, "ansible.cfg")} {
+		require.NoError(t, os.WriteFile(cfg, []byte("[defaults]\nroles_path = ../roles\n"), 0o644))
+	}
+
+	tests := []struct {
+		name   string
+		config string
+		want   func(p *Provisioner) string
+	}{
+		{
+			name:   "inside playbook_dir",
+			config: filepath.Join(dir, "config", "ansible.cfg"),
+			want:   func(p *Provisioner) string { return p.stagingDir + "/playbooks/project/config/packer-ansible.cfg" },
+		},
+		{
+			name:   "outside playbook_dir",
+			config: filepath.Join(outside, "ansible.cfg"),
+			want:   func(p *Provisioner) string { return p.stagingDir + "/ansible.cfg" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Provisioner
+			config := testConfig()
+			config["playbook_dir"] = dir
+			config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+			config["navigator_config"] = map[string]interface{}{
+				"ansible_config": map[string]interface{}{
+					"config":  tt.config,
+					"section": []map[string]interface{}{{"name": "defaults", "options": map[string]string{"forks": "20"}}},
+				},
+			}
+			require.NoError(t, p.Prepare(config))
+
+			comm := &communicatorMock{}
+			require.NoError(t, p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+
+			want := tt.want(&p)
+			require.Contains(t, comm.uploadDestination, want)
+			require.Equal(t, want, p.config.NavigatorConfig.AnsibleConfig.Config)
+			// The playbook_dir is still uploaded once.
+			require.Contains(t, comm.uploadDestination, p.stagingDir+"/.upload-project.tar.gz")
+			require.NotContains(t, comm.uploadDestination, p.stagingDir+"/.upload-project-2.tar.gz")
+		})
+	}
+}
@@ -3,7 +3,7 @@
 // You may obtain a copy of the License at
 // http://www.apache.org/licenses/LICENSE-2.0
 
-//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Play,PathEntry,NavigatorConfig,ExecutionEnvironment,EnvironmentVariablesConfig,VolumeMount,AnsibleConfig,AnsibleConfigDefaults,AnsibleConfigConnection,LoggingConfig,PlaybookArtifact,CollectionDocCache
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Play,PathEntry,NavigatorConfig,ExecutionEnvironment,ImageVerifyConfig,BootstrapConfig,DownloadArtifactsConfig,InventoryHost,EnvironmentVariablesConfig,VolumeMount,AnsibleConfig,AnsibleConfigDefaults,AnsibleConfigConnection,AnsibleConfigSection,LoggingConfig,PlaybookArtifact,CollectionDocCache,ColorConfig,EditorConfig,ImagesConfig,SettingsConfig,AnsibleRunnerConfig,GalaxyServer
 //go:generate packer-sdc struct-markdown
 
 package ansiblenavigatorlocal
@@ -47,6 +47,24 @@ type NavigatorConfig struct {
 	PlaybookArtifact *PlaybookArtifact `mapstructure:"playbook_artifact"`
 	// Collection documentation cache settings
 	CollectionDocCache *CollectionDocCache `mapstructure:"collection_doc_cache"`
+	// Color output settings
+	Color *ColorConfig `mapstructure:"color"`
+	// Editor settings
+	Editor *EditorConfig `mapstructure:"editor"`
+	// Image details settings (images subcommand)
+	Images *ImagesConfig `mapstructure:"images"`
+	// Columns shown by the inventory subcommand
+	InventoryColumns []string `mapstructure:"inventory_columns"`
+	// Time zone used for timestamps (e.g., "UTC", "America/New_York", "local")
+	TimeZone string `mapstructure:"time_zone"`
+	// Output format for subcommands that support it (json, yaml)
+	Format string `mapstructure:"format"`
+	// Settings subcommand options
+	Settings *SettingsConfig `mapstructure:"settings"`
+	// Enable prompts for passwords and playbook user input
+	EnablePrompts *bool `mapstructure:"enable_prompts"`
+	// ansible-runner settings
+	AnsibleRunner *AnsibleRunnerConfig `mapstructure:"ansible_runner"`
 }
 
 // isPluginDebugEnabled returns true iff plugin debug output should be enabled.
@@ -73,6 +91,9 @@ type ExecutionEnvironment struct {
 	Enabled bool `mapstructure:"enabled"`
 	// Container image to use
 	Image string `mapstructure:"image"`
+	// Expected registry digest of the image (sha256:...). The image is run by
+	// this digest, and the build fails if the image resolves to a different one.
+	ImageDigest string `mapstructure:"image_digest"`
 	// Pull policy for the container image
 	PullPolicy string `mapstructure:"pull_policy"`
 	// Container engine to use (auto, podman, docker)
@@ -85,6 +106,22 @@ type ExecutionEnvironment struct {
 	EnvironmentVariables *EnvironmentVariablesConfig `mapstructure:"environment_variables"`
 	// Volume mounts for the execution environment container
 	VolumeMounts []VolumeMount `mapstructure:"volume_mounts"`
+	// Signature verification of the image before the first play
+	Verify *ImageVerifyConfig `mapstructure:"verify"`
+	// What to do when the preflight checks of the container engine, image and
+	// mounts find a problem before the first play: "warn" (default), "fail" or "off".
+	Preflight string `mapstructure:"preflight"`
+}
+
+// ImageVerifyConfig configures signature verification of the execution
+// environment image. Exactly one of cosign_key and policy must be set.
+type ImageVerifyConfig struct {
+	// Path to a cosign public key (or a cosign KMS URI such as awskms://...).
+	// The image is checked with `cosign verify --key`.
+	CosignKey string `mapstructure:"cosign_key"`
+	// Path to a containers-policy.json file. The image is pulled with
+	// `podman pull --signature-policy`, so podman enforces the policy.
+	Policy string `mapstructure:"policy"`
 }
 
 // VolumeMount represents a volume mount for the execution environment
@@ -113,6 +150,22 @@ type AnsibleConfig struct {
 	Defaults *AnsibleConfigDefaults `mapstructure:"defaults"`
 	// SSH connection section
 	SSHConnection *AnsibleConfigConnection `mapstructure:"ssh_connection"`
+	// Arbitrary ansible.cfg sections. Each block adds options to one section;
+	// repeat the block for more sections:
+	//   section {
+	//     name    = "defaults"
+	//     options = { forks = "20", callbacks_enabled = "ansible.posix.profile_tasks" }
+	//   }
+	// Setting an option that a typed defaults/ssh_connection field also sets is an error.
+	Sections []AnsibleConfigSection `mapstructure:"section"`
+}
+
+// AnsibleConfigSection is a generic ansible.cfg section
+type AnsibleConfigSection struct {
+	// Section name without brackets (e.g., "defaults", "privilege_escalation", "inventory")
+	Name string `mapstructure:"name" required:"true"`
+	// Option names and values written to the section
+	Options map[string]string `mapstructure:"options"`
 }
 
 // AnsibleConfigDefaults represents ansible defaults configuration
@@ -161,6 +214,138 @@ type CollectionDocCache struct {
 	Timeout int `mapstructure:"timeout"`
 }
 
+// ColorConfig represents color output settings
+type ColorConfig struct {
+	// Enable the use of color in output
+	Enable *bool `mapstructure:"enable"`
+	// Enable OSC 4 terminal color support
+	Osc4 *bool `mapstructure:"osc4"`
+}
+
+// EditorConfig represents editor settings
+type EditorConfig struct {
+	// Command used to open files in an editor (e.g., "vi +{line_number} {filename}")
+	Command string `mapstructure:"command"`
+	// Whether the editor is console based
+	Console *bool `mapstructure:"console"`
+}
+
+// ImagesConfig represents images subcommand settings
+type ImagesConfig struct {
+	// Image details to collect (ansible_collections, ansible_version, everything,
+	// os_release, python_packages, python_version, redhat_release, system_packages)
+	Details []string `mapstructure:"details"`
+}
+
+// SettingsConfig represents settings subcommand settings
+type SettingsConfig struct {
+	// Show the effective settings
+	Effective *bool `mapstructure:"effective"`
+	// Generate a sample settings file
+	Sample *bool `mapstructure:"sample"`
+	// Generate a schema for the settings file (json)
+	Schema string `mapstructure:"schema"`
+	// Show the source of each setting
+	Sources *bool `mapstructure:"sources"`
+}
+
+// AnsibleRunnerConfig represents ansible-runner settings
+type AnsibleRunnerConfig struct {
+	// Directory for ansible-runner artifacts
+	ArtifactDir string `mapstructure:"artifact_dir"`
+	// Number of artifact directories to keep
+	RotateArtifactsCount int `mapstructure:"rotate_artifacts_count"`
+	// Timeout in seconds for ansible-runner to finish
+	Timeout int `mapstructure:"timeout"`
+	// Write ansible-runner job events to the artifact directory
+	JobEvents *bool `mapstructure:"job_events"`
+}
+
+// GalaxyServer defines an Ansible Galaxy server used by ansible-galaxy when
+// installing requirements_file content. Servers are passed to ansible-galaxy via
+// ANSIBLE_GALAXY_SERVER_* environment variables, in declaration order.
+type GalaxyServer struct {
+	// Server identifier. Referenced from requirements.yml `source` entries and used
+	// to build the ANSIBLE_GALAXY_SERVER_<NAME>_* environment variable names.
+	// Must contain only letters, digits and underscores.
+	Name string `mapstructure:"name" required:"true"`
+	// Galaxy API URL of the server.
+	URL string `mapstructure:"url" required:"true"`
+	// Token endpoint used to exchange an offline token (Red Hat SSO / Keycloak).
+	AuthURL string `mapstructure:"auth_url"`
+	// API token. Treated as a secret: it is redacted from all output.
+	Token string `mapstructure:"token"`
+	// Whether to verify the server TLS certificate. Defaults to true.
+	ValidateCerts *bool `mapstructure:"validate_certs"`
+}
+
+// BootstrapConfig installs ansible-navigator and ansible-core on the target
+// before the first play, so the target does not need them preinstalled.
+type BootstrapConfig struct {
+	// How to install: "pip" (default) creates a virtualenv and installs with pip,
+	// "pipx" installs with pipx, and "package" installs distro packages with the
+	// target's package manager (dnf, yum, apt-get, zypper or apk).
+	Method string `mapstructure:"method"`
+	// ansible-navigator version to install, either an exact version ("24.2.0") or a
+	// pip version specifier (">=24,<25"). Defaults to the latest version.
+	// Not supported with method "package".
+	NavigatorVersion string `mapstructure:"navigator_version"`
+	// ansible-core version to install, in the same format as navigator_version.
+	// Defaults to the version ansible-navigator depends on.
+	// Not supported with method "package".
+	AnsibleCoreVersion string `mapstructure:"ansible_core_version"`
+	// Additional Python packages installed next to ansible-navigator
+	// (pip and pipx), for example ["jmespath", "ansible-lint"].
+	ExtraPackages []string `mapstructure:"extra_packages"`
+	// Distro packages installed with method "package".
+	// Defaults to ["ansible-core", "ansible-navigator"].
+	Packages []string `mapstructure:"packages"`
+	// Python interpreter on the target used to create the virtualenv (pip) or
+	// run the installed applications (pipx). Defaults to "python3".
+	Python string `mapstructure:"python"`
+	// Directory of wheels on the build host. It is uploaded to the staging directory
+	// and packages are installed from it only (pip --no-index), for offline targets.
+	Wheelhouse string `mapstructure:"wheelhouse"`
+	// Directory on the target to install into (pip and pipx).
+	// Defaults to a directory in the staging directory.
+	Path string `mapstructure:"path"`
+	// Leave the installation at path in place after provisioning instead of
+	// removing it. Requires path. Distro packages are never removed.
+	Keep bool `mapstructure:"keep"`
+}
+
+// DownloadArtifactsConfig configures downloading ansible-navigator's log,
+// playbook artifacts and ansible-runner job events from the target.
+type DownloadArtifactsConfig struct {
+	// Local directory to download into. Created if it does not exist.
+	Destination string `mapstructure:"destination"`
+	// Additional absolute paths on the target, files or directories, downloaded
+	// into destination by base name. Paths that do not exist are skipped.
+	Paths []string `mapstructure:"paths"`
+}
+
+// InventoryHost is an additional host in the generated inventory that the
+// target reaches with its own connection, such as a nested container or a
+// libvirt guest.
+type InventoryHost struct {
+	// Inventory name of the host.
+	Name string `mapstructure:"name"`
+	// Address to connect to, set as ansible_host. Defaults to name.
+	Address string `mapstructure:"address"`
+	// Connection plugin, set as ansible_connection, for example "ssh",
+	// "community.docker.docker" or "community.libvirt.libvirt_qemu".
+	// Defaults to "ssh".
+	Connection string `mapstructure:"connection"`
+	// User to connect as, set as ansible_user.
+	User string `mapstructure:"user"`
+	// Port to connect to, set as ansible_port.
+	Port int `mapstructure:"port"`
+	// Groups the host is placed in.
+	Groups []string `mapstructure:"groups"`
+	// Additional host variables.
+	Vars map[string]string `mapstructure:"vars"`
+}
+
 // Play represents a single Ansible play execution with its configuration.
 // It supports both traditional playbook files and Ansible Collection role FQDNs.
 // Each play can have its own variables, tags, and privilege escalation settings.
@@ -177,6 +362,9 @@ type Play struct {
 	VarsFiles []string `mapstructure:"vars_files"`
 	// Whether to use privilege escalation (become) for this play
 	Become bool `mapstructure:"become"`
+	// Directory on the build host uploaded with this play's playbook, overriding
+	// the top-level playbook_dir. See playbook_dir.
+	PlaybookDir string `mapstructure:"playbook_dir"`
 	// Extra arguments to pass verbatim to ansible-navigator for this play.
 	// These are appended after `ansible-navigator run` (and enforced `--mode`),
 	// and before plugin-generated inventory/extra-vars/etc.
@@ -195,8 +383,85 @@ type Config struct {
 	// Entries are HOME-expanded locally and prepended to PATH in the remote shell command.
 	// Example: ["~/bin", "/opt/ansible/bin"]
 	AnsibleNavigatorPath []string `mapstructure:"ansible_navigator_path"`
-
-	// Maximum time to wait for ansible-navigator version check to complete.
+	// Install ansible-navigator and ansible-core on the target before the first play.
+	// The installation's bin directory is prepended to PATH like ansible_navigator_path.
+	Bootstrap *BootstrapConfig `mapstructure:"bootstrap"`
+	// Shell the commands on the target are written for: "posix" or "powershell".
+	// Defaults to "powershell" with the WinRM communicator and to "posix"
+	// otherwise. With "powershell" commands run through powershell.exe, PATH
+	// entries are separated with ";" and bootstrap, download_artifacts,
+	// staging_directory_mode, staging_owner and execution environment image
+	// verification are not available.
+	TargetShell string `mapstructure:"target_shell"`
+
+	// Directory on the target to stage files in, instead of a new
+	// /tmp/packer-provisioner-ansible-local/<uuid> directory for every run.
+	// It is kept after provisioning, so later ansible-navigator-local provisioners
+	// in the same build reuse it, and uploads to it are incremental: a manifest
+	// of the staged files (path, size, SHA-256) is kept in it and only new and
+	// changed files are uploaded. Use it to stage outside a noexec /tmp.
+	// Windows-style paths such as C:\Temp\packer are accepted and converted to
+	// forward slashes.
+	StagingDirectory string `mapstructure:"staging_directory"`
+
+	// Octal mode applied to the staging directory after it is created, such as
+	// "0700". By default the directory is created with the target's umask.
+	StagingDirectoryMode string `mapstructure:"staging_directory_mode"`
+
+	// Owner of the staging directory, as "user" or "user:group". The directory is
+	// then created and removed with sudo (unless connected as root) and handed to
+	// the owner.
+	StagingOwner string `mapstructure:"staging_owner"`
+
+	// When to remove the staging directory after provisioning: "always",
+	// "on_success" (keep it for debugging when provisioning fails) or "never".
+	// Defaults to "always", or "never" when staging_directory is set; set it to
+	// "always" on the last provisioner using a staging_directory so the directory
+	// does not end up in the image.
+	CleanStagingDirectory string `mapstructure:"clean_staging_directory"`
+
+	// Where ansible-navigator runs.
+	// "target" (default) runs it on the machine being provisioned over the communicator.
+	// "build_host" runs it on the build host and reaches the target through
+	// target_connection, so nothing is installed on or uploaded to the target.
+	// The staging directory, ansible-galaxy and ansible_navigator_path then apply
+	// to the build host.
+	ExecutionHost string `mapstructure:"execution_host"`
+	// Ansible connection plugin used to reach the target when execution_host is
+	// "build_host", for example "community.general.chroot",
+	// "community.docker.docker" or "containers.podman.podman".
+	TargetConnection string `mapstructure:"target_connection"`
+	// The target as addressed by target_connection when execution_host is
+	// "build_host": the chroot directory or the container name or ID.
+	// Set as ansible_host in the generated inventory.
+	TargetHost string `mapstructure:"target_host"`
+
+	// The groups into which the target should be placed in the generated
+	// inventory. When unspecified, the target is not associated with any groups.
+	Groups []string `mapstructure:"groups"`
+	// A comma-separated list of groups to place the target in, like groups.
+	InventoryGroups string `mapstructure:"inventory_groups"`
+	// The groups which should be present in the generated inventory but remain
+	// empty.
+	EmptyGroups []string `mapstructure:"empty_groups"`
+	// The inventory name of the target. Defaults to 127.0.0.1, or "default"
+	// when execution_host is "build_host".
+	HostAlias string `mapstructure:"host_alias"`
+	// Additional hosts in the generated inventory, each with its own
+	// connection. Plays reach them from the target (or, with execution_host =
+	// "build_host", from the build host).
+	InventoryHosts []InventoryHost `mapstructure:"inventory_host"`
+	// An inventory file or directory on the build host to use instead of the
+	// generated inventory. It is uploaded to the staging directory. Hosts without
+	// ansible_connection use the target's connection (local, or
+	// target_connection).
+	InventoryFile string `mapstructure:"inventory_file"`
+	// Limit playbook execution to specific hosts or groups.
+	// This corresponds to ansible-playbook's --limit flag.
+	// Example: "webservers:&production" or "host1,host2"
+	Limit string `mapstructure:"limit"`
+
+	// Maximum time to wait for the version check on the target to complete.
 	// Defaults to "60s". This prevents indefinite hangs when ansible-navigator
 	// is not properly configured or cannot be found.
 	// Format: duration string (e.g., "30s", "1m", "90s").
@@ -208,8 +473,10 @@ type Config struct {
 
 	// Check if ansible-navigator is installed prior to running.
 	// Set this to `true`, for example, if you're going to install it during the packer run.
-	// Note: the local provisioner does not currently perform a version check, but this is
-	// kept for configuration parity and for warning when it makes version_check_timeout ineffective.
+	// The check runs `ansible-navigator --version` and `ansible --version` on the target
+	// and looks for a container engine there; without one, execution environments are
+	// disabled unless navigator_config.execution_environment is set. Versions too old for
+	// the configuration fail the build.
 	SkipVersionCheck bool `mapstructure:"skip_version_check"`
 
 	// Modern Ansible Navigator fields (aligned with remote provisioner)
@@ -228,12 +495,28 @@ type Config struct {
 	// Only effective when structured_logging is true.
 	// Default: false
 	VerboseTaskOutput bool `mapstructure:"verbose_task_output"`
+	// Download ansible-navigator's log, playbook artifacts and ansible-runner
+	// job events, plus any listed paths, from the target before the staging
+	// directory is removed, whether or not provisioning succeeded.
+	DownloadArtifacts *DownloadArtifactsConfig `mapstructure:"download_artifacts"`
 
 	// Play-based execution (aligned with remote provisioner)
 
 	// Array of play definitions supporting both playbooks and role FQDNs.
 	// Mutually exclusive with playbook_file.
 	Plays []Play `mapstructure:"play"`
+	// Project directory on the build host that is uploaded, with its relative
+	// structure, to the staging directory before the plays run. Playbook targets
+	// must be inside it, so that roles/, templates/, files/, group_vars/,
+	// host_vars/ and included task files next to the playbook are available on
+	// the target. Generated role playbooks are placed in it too, and
+	// ansible-navigator runs from it so that its ansible.cfg is used.
+	PlaybookDir string `mapstructure:"playbook_dir"`
+	// Glob patterns of files and directories under playbook_dir that are not
+	// uploaded, for example [".git", "*.retry", "molecule"]. Patterns containing a
+	// slash match the path relative to playbook_dir, other patterns match the
+	// file or directory name.
+	PlaybookDirExclude []string `mapstructure:"playbook_dir_exclude"`
 	// Path to a unified requirements.yml file containing both roles and collections.
 	// Alternative to galaxy_file with enhanced support for modern Ansible requirements format.
 	RequirementsFile string `mapstructure:"requirements_file"`
@@ -246,7 +529,8 @@ type Config struct {
 	// Defaults to ~/.packer.d/ansible_collections_cache if not specified.
 	CollectionsPath string `mapstructure:"collections_path"`
 	// When true, skip network operations for both collections and roles.
-	// This maps to ansible-galaxy --offline.
+	// This maps to ansible-galaxy --offline on the target; it is not passed when
+	// galaxy_install_location is "host", which installs with the build host's network.
 	OfflineMode bool `mapstructure:"offline_mode"`
 	// The ansible-galaxy executable to invoke.
 	// Defaults to "ansible-galaxy".
@@ -258,6 +542,32 @@ type Config struct {
 	GalaxyForce bool `mapstructure:"galaxy_force"`
 	// When true, pass --force-with-deps to ansible-galaxy.
 	GalaxyForceWithDeps bool `mapstructure:"galaxy_force_with_deps"`
+	// Galaxy servers used by ansible-galaxy, in priority order.
+	// Equivalent to `[galaxy] server_list` and `[galaxy_server.<name>]` sections in
+	// ansible.cfg, but without requiring a hand-written ansible.cfg.
+	GalaxyServers []GalaxyServer `mapstructure:"galaxy_server"`
+	// Where ansible-galaxy runs when installing requirements_file content.
+	// "target" (default) runs ansible-galaxy on the machine being provisioned.
+	// "host" runs ansible-galaxy on the build host, installing into a temporary
+	// directory of this build (not roles_path or collections_path), then uploads the
+	// installed content as a tarball and unpacks it into the staging directory on the
+	// target. Use "host" for air-gapped targets.
+	GalaxyInstallLocation string `mapstructure:"galaxy_install_location"`
+	// Path to a GPG keyring on the build host used to verify collection signatures.
+	// Passed to `ansible-galaxy collection install` as --keyring (the keyring is uploaded
+	// to the staging directory unless galaxy_install_location is "host"). When set, the
+	// installed collections are also checked with `ansible-galaxy collection verify` and
+	// the build fails on any checksum mismatch or signature failure.
+	GalaxyKeyring string `mapstructure:"galaxy_keyring"`
+	// Number of valid signatures required for each collection, passed as
+	// --required-valid-signature-count. A positive number or "all", optionally prefixed
+	// with "+" to also fail when no valid signatures are found.
+	// Requires galaxy_keyring.
+	GalaxyRequiredValidSignatureCount string `mapstructure:"galaxy_required_valid_signature_count"`
+	// GnuPG status codes to ignore during signature verification (for example
+	// "NO_PUBKEY"), each passed as --ignore-signature-status-code.
+	// Requires galaxy_keyring.
+	GalaxyIgnoreSignatureStatusCodes []string `mapstructure:"galaxy_ignore_signature_status_codes"`
 
 	// Display extra vars JSON content in output for debugging.
 	// When enabled, logs the extra vars JSON passed to ansible-navigator with sensitive values redacted.
@@ -331,6 +641,11 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate playbook_dir
+	for _, err := range validatePlaybookDirs(c) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
 	// Validate galaxy_file
 	// Validate requirements_file
 	if c.RequirementsFile != "" {
@@ -339,6 +654,55 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate galaxy_server blocks
+	for _, err := range validateGalaxyServers(c.GalaxyServers) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate collection signature verification options
+	for _, err := range validateGalaxySignatureOptions(c) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate galaxy_install_location
+	switch c.GalaxyInstallLocation {
+	case "", GalaxyInstallLocationTarget, GalaxyInstallLocationHost:
+	default:
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"invalid galaxy_install_location: %q (must be %q or %q)",
+			c.GalaxyInstallLocation, GalaxyInstallLocationTarget, GalaxyInstallLocationHost))
+	}
+
+	// Validate bootstrap
+	for _, err := range validateBootstrap(c.Bootstrap) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate the inventory options
+	for _, err := range validateInventory(c) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate download_artifacts
+	for _, err := range validateDownloadArtifacts(c.DownloadArtifacts) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate staging_directory and its options
+	for _, err := range validateStaging(c) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate execution_host
+	for _, err := range validateExecutionHost(c) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate target_shell
+	for _, err := range validateTargetShell(c) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
 	// Validate version_check_timeout format
 	if c.VersionCheckTimeout != nil {
 		if _, err := time.ParseDuration(*c.VersionCheckTimeout); err != nil {
@@ -357,21 +721,40 @@ func (c *Config) Validate() error {
 			c.NavigatorConfig.AnsibleConfig == nil &&
 			c.NavigatorConfig.Logging == nil &&
 			c.NavigatorConfig.PlaybookArtifact == nil &&
-			c.NavigatorConfig.CollectionDocCache == nil
+			c.NavigatorConfig.CollectionDocCache == nil &&
+			c.NavigatorConfig.Color == nil &&
+			c.NavigatorConfig.Editor == nil &&
+			c.NavigatorConfig.Images == nil &&
+			len(c.NavigatorConfig.InventoryColumns) == 0 &&
+			c.NavigatorConfig.TimeZone == "" &&
+			c.NavigatorConfig.Format == "" &&
+			c.NavigatorConfig.Settings == nil &&
+			c.NavigatorConfig.EnablePrompts == nil &&
+			c.NavigatorConfig.AnsibleRunner == nil
 		if isEmpty {
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
 				"navigator_config block cannot be empty. Either provide configuration or omit the block"))
 		}
 
-		// Schema compliance: ansible_config.config (path) is mutually exclusive with
-		// the nested defaults/ssh_connection blocks (which map to a generated ansible.cfg).
+		// When ansible_config.config is combined with the nested defaults/ssh_connection/section
+		// blocks, the generated settings are merged into that file, which is read on
+		// the machine running Packer, so it must exist there. On its own it is a path
+		// on the target.
 		if c.NavigatorConfig.AnsibleConfig != nil {
 			ac := c.NavigatorConfig.AnsibleConfig
-			if ac.Config != "" && (ac.Defaults != nil || ac.SSHConnection != nil) {
-				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
-					"navigator_config.ansible_config.config is mutually exclusive with navigator_config.ansible_config.defaults and navigator_config.ansible_config.ssh_connection"))
+			if ac.Config != "" && needsGeneratedAnsibleCfg(ac) {
+				if err := validateFileConfig(ac.Config, "navigator_config.ansible_config.config", true); err != nil {
+					errs = packersdk.MultiErrorAppend(errs, err)
+				}
+			}
+			for _, err := range validateAnsibleCfgSections(ac) {
+				errs = packersdk.MultiErrorAppend(errs, err)
 			}
 		}
+
+		for _, err := range validateNavigatorSettings(c.NavigatorConfig) {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
@@ -387,6 +770,14 @@ type Provisioner struct {
 	galaxyRolesPath       string
 	galaxyCollectionsPath string
 	generatedData         map[string]interface{}
+	// Uploaded playbook_dir directories, by absolute path on the build host.
+	playbookDirs map[string]string
+	// Files in the persistent staging_directory; nil when uploads are not incremental.
+	stagingManifest *stagingManifest
+	// galaxyVerification is the collection verification result, reported in the structured summary.
+	galaxyVerification *GalaxyVerification
+	// capabilities is what the version check found on the target; nil when it was skipped.
+	capabilities *targetCapabilities
 }
 
 func (p *Provisioner) ConfigSpec() hcldec.ObjectSpec { return p.config.FlatMapstructure().HCL2Spec() }
@@ -422,14 +813,32 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 	p.config.CollectionsPath = expandUserPath(p.config.CollectionsPath)
 	p.config.RolesPath = expandUserPath(p.config.RolesPath)
 	p.config.GalaxyCommand = expandUserPath(p.config.GalaxyCommand)
+	p.config.GalaxyKeyring = expandUserPath(p.config.GalaxyKeyring)
+	if p.config.Bootstrap != nil {
+		p.config.Bootstrap.Wheelhouse = expandUserPath(p.config.Bootstrap.Wheelhouse)
+	}
+
+	p.config.PlaybookDir = expandUserPath(p.config.PlaybookDir)
+	p.config.InventoryFile = expandUserPath(p.config.InventoryFile)
+	if p.config.DownloadArtifacts != nil {
+		p.config.DownloadArtifacts.Destination = expandUserPath(p.config.DownloadArtifacts.Destination)
+	}
 
 	// Apply HOME expansion to plays
 	for i := range p.config.Plays {
 		p.config.Plays[i].Target = expandUserPath(p.config.Plays[i].Target)
+		p.config.Plays[i].PlaybookDir = expandUserPath(p.config.Plays[i].PlaybookDir)
 		for j := range p.config.Plays[i].VarsFiles {
 			p.config.Plays[i].VarsFiles[j] = expandUserPath(p.config.Plays[i].VarsFiles[j])
 		}
 	}
+
+	// Apply HOME expansion to execution environment signature verification files
+	if nc := p.config.NavigatorConfig; nc != nil && nc.ExecutionEnvironment != nil && nc.ExecutionEnvironment.Verify != nil {
+		nc.ExecutionEnvironment.Verify.CosignKey = expandUserPath(nc.ExecutionEnvironment.Verify.CosignKey)
+		nc.ExecutionEnvironment.Verify.Policy = expandUserPath(nc.ExecutionEnvironment.Verify.Policy)
+	}
+
 	// Detect explicit timeout setting before defaulting
 	p.config.versionCheckTimeoutWasSet = p.config.VersionCheckTimeout != nil
 
@@ -438,7 +847,11 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		p.config.VersionCheckTimeout = stringPtr("60s")
 	}
 
-	p.stagingDir = filepath.ToSlash(filepath.Join(DefaultStagingDir, uuid.TimeOrderedUUID()))
+	if p.config.StagingDirectory != "" {
+		p.stagingDir = normalizeStagingDirectory(p.config.StagingDirectory)
+	} else {
+		p.stagingDir = filepath.ToSlash(filepath.Join(DefaultStagingDir, uuid.TimeOrderedUUID()))
+	}
 	p.galaxyRolesPath = filepath.ToSlash(filepath.Join(p.stagingDir, "galaxy_roles"))
 	p.galaxyCollectionsPath = filepath.ToSlash(filepath.Join(p.stagingDir, "galaxy_collections"))
 
@@ -447,6 +860,16 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		p.config.GalaxyCommand = "ansible-galaxy"
 	}
 
+	// Galaxy server tokens must never appear in logs
+	for _, server := range p.config.GalaxyServers {
+		if server.Token != "" {
+			packersdk.LogSecretFilter.Set(server.Token)
+		}
+	}
+	if p.config.GalaxyInstallLocation == "" {
+		p.config.GalaxyInstallLocation = GalaxyInstallLocationTarget
+	}
+
 	// Set default install directories if not specified
 	if p.config.CollectionsPath == "" {
 		usr, err := os.UserHomeDir()
@@ -467,27 +890,115 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		return err
 	}
 
+	// Validate the ansible-navigator.yml the configuration generates
+	if p.config.NavigatorConfig != nil {
+		var errs *packersdk.MultiError
+		for _, err := range validateNavigatorConfigDocument(p.config.NavigatorConfig) {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+		if errs != nil {
+			return errs
+		}
+	}
+
+	// Write ansible-navigator's log and artifacts where download_artifacts finds them
+	if p.config.DownloadArtifacts != nil {
+		p.applyArtifactDefaults()
+	}
+
 	return nil
 }
 
-func (p *Provisioner) Provision(ctx context.Context, ui packersdk.Ui, comm packersdk.Communicator, generatedData map[string]interface{}) error {
+func (p *Provisioner) Provision(ctx context.Context, ui packersdk.Ui, comm packersdk.Communicator, generatedData map[string]interface{}) (retErr error) {
 	ui.Say("Provisioning with Ansible...")
 	if p.config.SkipVersionCheck && p.config.versionCheckTimeoutWasSet {
 		ui.Message("Warning: version_check_timeout is ignored when skip_version_check=true")
 	}
 	p.generatedData = generatedData
 
+	// In build_host mode everything below is staged and run on the build host.
+	if p.config.runsOnBuildHost() {
+		ui.Message(fmt.Sprintf("Running ansible-navigator on the build host against %s (%s)",
+			p.config.TargetHost, p.config.TargetConnection))
+		comm = &localCommunicator{}
+	}
+
+	// WinRM targets run PowerShell unless target_shell says otherwise
+	if p.config.detectTargetShell(generatedData) {
+		ui.Message("WinRM communicator detected; running commands on the target with PowerShell")
+		var errs *packersdk.MultiError
+		for _, err := range validateTargetShell(&p.config) {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+		if errs != nil {
+			return errs
+		}
+	}
+
 	ui.Message("Creating Ansible staging directory...")
-	if err := p.createDir(ui, comm, p.stagingDir); err != nil {
+	if err := p.createStagingDir(ui, comm); err != nil {
 		return fmt.Errorf("Error creating staging directory: %s", err)
 	}
+	if p.config.StagingDirectory != "" {
+		// Upload only what changed since the last run into this staging directory
+		p.loadStagingManifest(ui, comm)
+		if p.config.CleanStagingDirectory == "" {
+			ui.Message(fmt.Sprintf("[Warning] staging_directory %s, its files and %s are kept on the target "+
+				"and end up in the image. Set clean_staging_directory = %q on the last provisioner that uses it, "+
+				"or %q to keep it and silence this warning.",
+				p.stagingDir, stagingManifestName, CleanStagingAlways, CleanStagingNever))
+		}
+	}
 	defer func() {
-		ui.Message("Removing staging directory...")
-		_ = p.removeDir(ui, comm, p.stagingDir)
+		switch policy := p.config.stagingCleanupPolicy(); {
+		case policy == CleanStagingAlways, policy == CleanStagingOnSuccess && retErr == nil:
+			ui.Message("Removing staging directory...")
+			_ = p.removeStagingDir(ui, comm)
+		case policy == CleanStagingOnSuccess:
+			ui.Message(fmt.Sprintf("Keeping staging directory after failure: %s", p.stagingDir))
+			fallthrough
+		default:
+			if p.stagingManifest != nil {
+				if err := p.saveStagingManifest(ui, comm); err != nil {
+					ui.Error(fmt.Sprintf("Error saving staging manifest: %s", err))
+				}
+			}
+		}
 	}()
 
-	// Upload requirements_file (if provided) into staging directory so GalaxyManager can install it
-	if p.config.RequirementsFile != "" {
+	// Download artifacts before the staging directory is cleaned up, also on failure
+	if p.config.DownloadArtifacts != nil {
+		if err := p.resetArtifactsDir(ui, comm); err != nil {
+			return fmt.Errorf("Error creating artifacts directory: %s", err)
+		}
+		defer p.downloadArtifacts(ui, comm)
+	}
+
+	// Install ansible-navigator and ansible-core before anything needs them
+	if p.config.Bootstrap != nil {
+		if p.config.Bootstrap.Path != "" && !p.config.Bootstrap.Keep {
+			defer func() {
+				ui.Message("Removing bootstrap installation...")
+				_ = p.removeDir(ui, comm, p.config.Bootstrap.Path)
+			}()
+		}
+		if err := p.bootstrapAnsible(ui, comm); err != nil {
+			return err
+		}
+	}
+
+	// Check ansible-navigator, ansible and the container engine on the target
+	if !p.config.SkipVersionCheck {
+		capabilities, err := p.probeTarget(ui, comm)
+		if err != nil {
+			return err
+		}
+		p.capabilities = capabilities
+	}
+
+	// Upload requirements_file (if provided) into staging directory so GalaxyManager can install it.
+	// Host installs read the requirements file from the build host instead.
+	if p.config.RequirementsFile != "" && p.config.GalaxyInstallLocation != GalaxyInstallLocationHost {
 		ui.Message("Uploading requirements file...")
 		remoteReq := filepath.ToSlash(filepath.Join(p.stagingDir, filepath.Base(p.config.RequirementsFile)))
 		if err := p.uploadFile(ui, comm, remoteReq, p.config.RequirementsFile); err != nil {
@@ -495,6 +1006,15 @@ func (p *Provisioner) Provision(ctx context.Context, ui packersdk.Ui, comm packe
 		}
 	}
 
+	// Upload galaxy_keyring next to the requirements file for on-target signature verification.
+	if p.config.RequirementsFile != "" && p.config.GalaxyKeyring != "" && p.config.GalaxyInstallLocation != GalaxyInstallLocationHost {
+		ui.Message("Uploading Galaxy keyring...")
+		remoteKeyring := filepath.ToSlash(filepath.Join(p.stagingDir, filepath.Base(p.config.GalaxyKeyring)))
+		if err := p.uploadFile(ui, comm, remoteKeyring, p.config.GalaxyKeyring); err != nil {
+			return fmt.Errorf("Error uploading galaxy_keyring: %s", err)
+		}
+	}
+
 	// Upload any vars_files referenced by plays (ansible uses -e @file; on-target execution needs remote files)
 	for i := range p.config.Plays {
 		for j, varsFile := range p.config.Plays[i].VarsFiles {
@@ -507,25 +1027,19 @@ func (p *Provisioner) Provision(ctx context.Context, ui packersdk.Ui, comm packe
 		}
 	}
 
-	// Create minimal inventory file (local connection) and upload
-	tf, err := tmp.File("packer-provisioner-ansible-navigator-local-inventory")
+	// Upload inventory_file, or generate the inventory (the target, inventory_host hosts and groups)
+	inventoryRemote, err := p.uploadInventory(ui, comm)
 	if err != nil {
-		return fmt.Errorf("Error preparing inventory file: %s", err)
-	}
-	defer os.Remove(tf.Name())
-	if _, err := tf.Write([]byte("127.0.0.1")); err != nil {
-		tf.Close()
-		return fmt.Errorf("Error preparing inventory file: %s", err)
-	}
-	if err := tf.Close(); err != nil {
-		return fmt.Errorf("Error preparing inventory file: %s", err)
-	}
-	inventoryRemote := filepath.ToSlash(filepath.Join(p.stagingDir, "inventory.ini"))
-	ui.Message("Uploading inventory file...")
-	if err := p.uploadFile(ui, comm, inventoryRemote, tf.Name()); err != nil {
 		return fmt.Errorf("Error uploading inventory file: %s", err)
 	}
 
+	// Check the container engine, image and mounts on the target before anything runs
+	if isExecutionEnvironmentEnabled(p.config.NavigatorConfig) && eePreflightMode(p.config.NavigatorConfig.ExecutionEnvironment) != EEPreflightOff {
+		if err := p.runEEPreflight(ui, comm); err != nil {
+			return err
+		}
+	}
+
 	// Generate and upload ansible-navigator.yml if configured
 	var navigatorConfigRemotePath string
 	if p.config.NavigatorConfig != nil {
@@ -536,27 +1050,29 @@ func (p *Provisioner) Provision(ctx context.Context, ui packersdk.Ui, comm packe
 		// We need to do this before generating ansible.cfg or checking for unmapped settings
 		applyAutomaticEEDefaults(p.config.NavigatorConfig, collectionsPath)
 
-		// If ansible_config.defaults / ansible_config.ssh_connection are provided
+		// Run the execution environment image by its image_digest pin, if any.
+		pinImageDigest(p.config.NavigatorConfig.ExecutionEnvironment)
+
+		// If ansible_config.defaults / ansible_config.ssh_connection / ansible_config.section are provided
 		// (explicitly or via EE defaults), generate an ansible.cfg file, upload it
 		// to the staging directory, and reference it from the navigator config YAML
-		// via ansible.config.path.
+		// via ansible.config.path. An ansible_config.config file is merged into it.
 		if p.config.NavigatorConfig.AnsibleConfig != nil && needsGeneratedAnsibleCfg(p.config.NavigatorConfig.AnsibleConfig) {
-			if p.config.NavigatorConfig.AnsibleConfig.Config != "" {
-				return fmt.Errorf("invalid navigator_config.ansible_config: config is mutually exclusive with defaults/ssh_connection")
-			}
-
-			cfgContent, err := generateAnsibleCfgContent(p.config.NavigatorConfig.AnsibleConfig)
+			cfgContent, err := buildAnsibleCfgContent(ui, p.config.NavigatorConfig.AnsibleConfig)
 			if err != nil {
 				return fmt.Errorf("Error generating ansible.cfg content: %s", err)
 			}
 			if cfgContent != "" {
-				cfgTmpPath, err := createTempAnsibleCfgFile(cfgContent)
+				cfgTmpPath, err := createTempAnsibleCfgFile("", cfgContent)
 				if err != nil {
 					return fmt.Errorf("Error creating temporary ansible.cfg: %s", err)
 				}
 				defer os.Remove(cfgTmpPath)
 
-				cfgRemotePath := filepath.ToSlash(filepath.Join(p.stagingDir, "ansible.cfg"))
+				cfgRemotePath, err := p.ansibleCfgRemotePath(ui, comm, p.config.NavigatorConfig.AnsibleConfig.Config)
+				if err != nil {
+					return fmt.Errorf("Error uploading ansible.cfg: %s", err)
+				}
 				ui.Message("Uploading generated ansible.cfg...")
 				if err := p.uploadFile(ui, comm, cfgRemotePath, cfgTmpPath); err != nil {
 					return fmt.Errorf("Error uploading ansible.cfg: %s", err)
@@ -591,9 +1107,22 @@ func (p *Provisioner) Provision(ctx context.Context, ui packersdk.Ui, comm packe
 		}
 	}
 
+	// Verify the execution environment image signature on the target before anything runs in it
+	if isExecutionEnvironmentEnabled(p.config.NavigatorConfig) && p.config.NavigatorConfig.ExecutionEnvironment.Verify != nil {
+		if err := p.verifyExecutionEnvironmentImage(ui, comm); err != nil {
+			return err
+		}
+	}
+
 	// Install dependencies using GalaxyManager
 	galaxyManager := NewGalaxyManager(&p.config, ui, comm, p.stagingDir, p.galaxyRolesPath, p.galaxyCollectionsPath)
-	if err := galaxyManager.InstallRequirements(); err != nil {
+	err = galaxyManager.InstallRequirements()
+	p.galaxyVerification = galaxyManager.verification
+	if err != nil {
+		// Record failed verifications even though no play runs.
+		if p.galaxyVerification != nil {
+			p.writeEarlySummary(ui)
+		}
 		return fmt.Errorf("Error installing requirements: %s", err)
 	}
 
@@ -634,8 +1163,28 @@ func (p *Provisioner) executePlays(ui packersdk.Ui, comm packersdk.Communicator,
 		var playbookPath string
 		var cleanupFunc func()
 
+		// With playbook_dir, the project tree is uploaded and the play runs from it
+		workDir := p.stagingDir
+		playbookDir := p.config.playbookDirFor(play)
+		if playbookDir != "" {
+			remoteDir, err := p.stagePlaybookDir(ui, comm, playbookDir)
+			if err != nil {
+				return fmt.Errorf("Play '%s': failed to upload playbook_dir: %s", playName, err)
+			}
+			debugf(ui, debugEnabled, "Remote playbook_dir=%s", remoteDir)
+			workDir = remoteDir
+		}
+
 		// Determine if target is a playbook file or a role FQDN
-		if strings.HasSuffix(play.Target, ".yml") || strings.HasSuffix(play.Target, ".yaml") {
+		if isPlaybookTarget(play.Target) && playbookDir != "" {
+			// It's a playbook file inside the uploaded playbook_dir
+			rel, err := playbookPathInDir(playbookDir, play.Target)
+			if err != nil {
+				return fmt.Errorf("Play '%s': %s", playName, err)
+			}
+			playbookPath = workDir + "/" + rel
+			debugf(ui, debugEnabled, "Remote playbook path=%s", playbookPath)
+		} else if isPlaybookTarget(play.Target) {
 			// It's a playbook file - upload to remote
 			if absPath, err := filepath.Abs(play.Target); err == nil {
 				debugf(ui, debugEnabled, "Resolved playbook path: %s -> %s", play.Target, absPath)
@@ -657,8 +1206,8 @@ func (p *Provisioner) executePlays(ui packersdk.Ui, comm packersdk.Communicator,
 			}
 			debugf(ui, debugEnabled, "Generated temporary playbook path=%s", tmpPlaybook)
 
-			// Upload generated playbook to remote
-			remotePath := filepath.ToSlash(filepath.Join(p.stagingDir, filepath.Base(tmpPlaybook)))
+			// Upload generated playbook to remote, next to the roles/ of a playbook_dir
+			remotePath := filepath.ToSlash(filepath.Join(workDir, filepath.Base(tmpPlaybook)))
 			debugf(ui, debugEnabled, "Remote generated playbook path=%s", remotePath)
 			if err := p.uploadFile(ui, comm, remotePath, tmpPlaybook); err != nil {
 				os.Remove(tmpPlaybook)
@@ -671,7 +1220,7 @@ func (p *Provisioner) executePlays(ui packersdk.Ui, comm packersdk.Communicator,
 		}
 
 		// Execute the play
-		err := p.executeAnsiblePlaybook(ui, comm, playbookPath, play, inventory, navigatorConfigRemotePath)
+		err := p.executeAnsiblePlaybook(ui, comm, workDir, playbookPath, play, inventory, navigatorConfigRemotePath)
 
 		// Cleanup temporary playbook if it was generated
 		if cleanupFunc != nil {
@@ -816,7 +1365,14 @@ func (p *Provisioner) buildPluginArgsForPlay(ui packersdk.Ui, play Play, invento
 		args = append(args, fmt.Sprintf("-e=@%s", varsFile))
 	}
 
-	args = append(args, "-c=local", fmt.Sprintf("-i=%s", inventory))
+	if p.config.Limit != "" {
+		args = append(args, fmt.Sprintf("--limit=%s", p.config.Limit))
+	}
+
+	if p.config.globalConnection() {
+		args = append(args, "-c="+p.config.ansibleConnection())
+	}
+	args = append(args, fmt.Sprintf("-i=%s", inventory))
 
 	return args, extraVarsLocalPath, nil
 }
@@ -853,7 +1409,9 @@ func (p *Provisioner) createRolePlaybook(role string, play Play) (string, error)
 
 	// Build the playbook content
 	playbookContent := "---\n- hosts: all\n"
-	playbookContent += "  connection: local\n"
+	if p.config.globalConnection() {
+		playbookContent += fmt.Sprintf("  connection: %s\n", p.config.ansibleConnection())
+	}
 
 	if play.Become {
 		playbookContent += "  become: yes\n"
@@ -894,36 +1452,28 @@ func (p *Provisioner) createRolePlaybook(role string, play Play) (string, error)
 func (p *Provisioner) executeAnsiblePlaybook(
 	ui packersdk.Ui,
 	comm packersdk.Communicator,
+	workDir string,
 	playbookFile string,
 	play Play,
 	inventory string,
 	navigatorConfigRemotePath string,
 ) error {
 	ctx := context.TODO()
-	env_vars := ""
+	sh := p.config.remoteShell()
 
 	debugEnabled := isPluginDebugEnabled(p.config.NavigatorConfig)
 
 	// Build environment variables for collections and roles paths
 	galaxyManager := NewGalaxyManager(&p.config, ui, comm, p.stagingDir, p.galaxyRolesPath, p.galaxyCollectionsPath)
-	envPaths := galaxyManager.SetupEnvironmentPaths()
-	if len(envPaths) > 0 {
-		env_vars = strings.Join(envPaths, " ") + " "
-	}
+	env := galaxyManager.SetupEnvironmentPaths()
 
 	// Add standard Ansible environment variables
-	env_vars += "ANSIBLE_FORCE_COLOR=1 PYTHONUNBUFFERED=1"
+	env = append(env, "ANSIBLE_FORCE_COLOR=1", "PYTHONUNBUFFERED=1")
 
-	// Build PATH override if ansible_navigator_path is set
-	pathPrefixAssignment := ""
+	// ansible_navigator_path entries are prepended to PATH by the remote shell
 	if len(p.config.AnsibleNavigatorPath) > 0 {
-		pathPrefixAssignment = buildPathPrefixForRemoteShell(p.config.AnsibleNavigatorPath)
 		debugf(ui, debugEnabled, "Remote shell will prefix PATH with %v", p.config.AnsibleNavigatorPath)
 	}
-	pathPrefix := ""
-	if pathPrefixAssignment != "" {
-		pathPrefix = pathPrefixAssignment + " "
-	}
 
 	// Build plugin args and get local extra vars file path
 	pluginArgs, extraVarsLocalPath, err := p.buildPluginArgsForPlay(ui, play, inventory)
@@ -955,9 +1505,13 @@ func (p *Provisioner) executeAnsiblePlaybook(
 
 	// Add CLI flags from NavigatorConfig
 	if p.config.NavigatorConfig != nil {
-		cliFlags := buildNavigatorCLIFlags(p.config.NavigatorConfig)
+		cliFlags := buildNavigatorCLIFlags(p.config.NavigatorConfig, p.capabilities)
 		runArgs = append(runArgs, cliFlags...)
 	}
+	// Add flags for what the version check found on the target
+	if p.capabilities != nil {
+		runArgs = append(runArgs, p.capabilities.navigatorCLIFlags(p.config.NavigatorConfig)...)
+	}
 
 	// Add --settings flag if minimal YAML was generated
 	if navigatorConfigRemotePath != "" {
@@ -974,33 +1528,23 @@ func (p *Provisioner) executeAnsiblePlaybook(
 	runArgs = append(runArgs, pluginArgs...)
 	runArgs = append(runArgs, playbookFile)
 
-	command := ""
-	if debugEnabled && isExecutionEnvironmentEnabled(p.config.NavigatorConfig) {
-		preflight := buildEEDockerPreflightShell(pathPrefixAssignment)
-		command = fmt.Sprintf(
-			"cd %s && %s; %s%s %s %s",
-			shellEscapePOSIX(p.stagingDir),
-			preflight,
-			pathPrefix,
-			env_vars,
-			shellEscapePOSIX(p.config.Command),
-			strings.Join(shellEscapeAllPOSIX(runArgs), " "),
-		)
-	} else {
-		command = fmt.Sprintf(
-			"cd %s && %s%s %s %s",
-			shellEscapePOSIX(p.stagingDir),
-			pathPrefix,
-			env_vars,
-			shellEscapePOSIX(p.config.Command),
-			strings.Join(shellEscapeAllPOSIX(runArgs), " "),
-		)
-	}
-	ui.Message(fmt.Sprintf("Executing Ansible Navigator: %s", command))
+	script := sh.Run(workDir, p.config.AnsibleNavigatorPath, env, p.config.Command, runArgs)
+	// The EE preflight diagnostics are a POSIX shell script
+	if debugEnabled && isExecutionEnvironmentEnabled(p.config.NavigatorConfig) && p.config.TargetShell != TargetShellPowerShell {
+		preflight := buildEEPreflightShell(buildPathPrefixForRemoteShell(p.config.AnsibleNavigatorPath), p.config.NavigatorConfig.ExecutionEnvironment)
+		script = fmt.Sprintf("cd %s && %s; %s", sh.Quote(workDir), preflight, script)
+	}
+	ui.Message(fmt.Sprintf("Executing Ansible Navigator: %s", script))
 	cmd := &packersdk.RemoteCmd{
-		Command: command,
+		Command: sh.Command(script),
 	}
-	if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
+	if p.config.StructuredLogging {
+		summary := p.newSummary()
+		if err := runWithEvents(ctx, ui, comm, cmd, summary, p.config.VerboseTaskOutput); err != nil {
+			return err
+		}
+		p.reportSummary(ui, summary)
+	} else if err := cmd.RunWithUi(ctx, comm, ui); err != nil {
 		return err
 	}
 	if cmd.ExitStatus() != 0 {
@@ -1041,6 +1585,20 @@ func validateFileConfig(name string, config string, req bool) error {
 }
 
 func (p *Provisioner) uploadFile(ui packersdk.Ui, comm packersdk.Communicator, dst, src string) error {
+	// With a persistent staging directory, unchanged files are not uploaded again
+	rel, synced := p.stagedPath(dst)
+	var entry stagingManifestEntry
+	if synced {
+		var err error
+		if entry, err = fileManifestEntry(src); err != nil {
+			return fmt.Errorf("Error opening: %s", err)
+		}
+		if p.stagingManifest.Files[rel] == entry {
+			p.stagingManifest.skipped++
+			return nil
+		}
+	}
+
 	f, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("Error opening: %s", err)
@@ -1050,13 +1608,18 @@ func (p *Provisioner) uploadFile(ui packersdk.Ui, comm packersdk.Communicator, d
 	if err = comm.Upload(dst, f, nil); err != nil {
 		return fmt.Errorf("Error uploading %s: %s", src, err)
 	}
+	if synced {
+		p.stagingManifest.Files[rel] = entry
+		p.stagingManifest.uploaded++
+	}
 	return nil
 }
 
 func (p *Provisioner) createDir(ui packersdk.Ui, comm packersdk.Communicator, dir string) error {
 	ctx := context.TODO()
+	sh := p.config.remoteShell()
 	cmd := &packersdk.RemoteCmd{
-		Command: fmt.Sprintf("mkdir -p '%s'", dir),
+		Command: sh.Command(sh.Mkdir(dir)),
 	}
 
 	ui.Message(fmt.Sprintf("Creating directory: %s", dir))
@@ -1072,8 +1635,9 @@ func (p *Provisioner) createDir(ui packersdk.Ui, comm packersdk.Communicator, di
 
 func (p *Provisioner) removeDir(ui packersdk.Ui, comm packersdk.Communicator, dir string) error {
 	ctx := context.TODO()
+	sh := p.config.remoteShell()
 	cmd := &packersdk.RemoteCmd{
-		Command: fmt.Sprintf("rm -rf '%s'", dir),
+		Command: sh.Command(sh.Remove(dir)),
 	}
 
 	ui.Message(fmt.Sprintf("Removing directory: %s", dir))
@@ -13,6 +13,7 @@ type FlatAnsibleConfig struct {
 	Config        *string                      `mapstructure:"config" cty:"config" hcl:"config"`
 	Defaults      *FlatAnsibleConfigDefaults   `mapstructure:"defaults" cty:"defaults" hcl:"defaults"`
 	SSHConnection *FlatAnsibleConfigConnection `mapstructure:"ssh_connection" cty:"ssh_connection" hcl:"ssh_connection"`
+	Sections      []FlatAnsibleConfigSection   `mapstructure:"section" cty:"section" hcl:"section"`
 }
 
 // FlatMapstructure returns a new FlatAnsibleConfig.
@@ -30,6 +31,7 @@ func (*FlatAnsibleConfig) HCL2Spec() map[string]hcldec.Spec {
 		"config":         &hcldec.AttrSpec{Name: "config", Type: cty.String, Required: false},
 		"defaults":       &hcldec.BlockSpec{TypeName: "defaults", Nested: hcldec.ObjectSpec((*FlatAnsibleConfigDefaults)(nil).HCL2Spec())},
 		"ssh_connection": &hcldec.BlockSpec{TypeName: "ssh_connection", Nested: hcldec.ObjectSpec((*FlatAnsibleConfigConnection)(nil).HCL2Spec())},
+		"section":        &hcldec.BlockListSpec{TypeName: "section", Nested: hcldec.ObjectSpec((*FlatAnsibleConfigSection)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -86,6 +88,99 @@ func (*FlatAnsibleConfigDefaults) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatAnsibleConfigSection is an auto-generated flat version of AnsibleConfigSection.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatAnsibleConfigSection struct {
+	Name    *string           `mapstructure:"name" required:"true" cty:"name" hcl:"name"`
+	Options map[string]string `mapstructure:"options" cty:"options" hcl:"options"`
+}
+
+// FlatMapstructure returns a new FlatAnsibleConfigSection.
+// FlatAnsibleConfigSection is an auto-generated flat version of AnsibleConfigSection.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*AnsibleConfigSection) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatAnsibleConfigSection)
+}
+
+// HCL2Spec returns the hcl spec of a AnsibleConfigSection.
+// This spec is used by HCL to read the fields of AnsibleConfigSection.
+// The decoded values from this spec will then be applied to a FlatAnsibleConfigSection.
+func (*FlatAnsibleConfigSection) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":    &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"options": &hcldec.AttrSpec{Name: "options", Type: cty.Map(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatAnsibleRunnerConfig is an auto-generated flat version of AnsibleRunnerConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatAnsibleRunnerConfig struct {
+	ArtifactDir          *string `mapstructure:"artifact_dir" cty:"artifact_dir" hcl:"artifact_dir"`
+	RotateArtifactsCount *int    `mapstructure:"rotate_artifacts_count" cty:"rotate_artifacts_count" hcl:"rotate_artifacts_count"`
+	Timeout              *int    `mapstructure:"timeout" cty:"timeout" hcl:"timeout"`
+	JobEvents            *bool   `mapstructure:"job_events" cty:"job_events" hcl:"job_events"`
+}
+
+// FlatMapstructure returns a new FlatAnsibleRunnerConfig.
+// FlatAnsibleRunnerConfig is an auto-generated flat version of AnsibleRunnerConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*AnsibleRunnerConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatAnsibleRunnerConfig)
+}
+
+// HCL2Spec returns the hcl spec of a AnsibleRunnerConfig.
+// This spec is used by HCL to read the fields of AnsibleRunnerConfig.
+// The decoded values from this spec will then be applied to a FlatAnsibleRunnerConfig.
+func (*FlatAnsibleRunnerConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"artifact_dir":           &hcldec.AttrSpec{Name: "artifact_dir", Type: cty.String, Required: false},
+		"rotate_artifacts_count": &hcldec.AttrSpec{Name: "rotate_artifacts_count", Type: cty.Number, Required: false},
+		"timeout":                &hcldec.AttrSpec{Name: "timeout", Type: cty.Number, Required: false},
+		"job_events":             &hcldec.AttrSpec{Name: "job_events", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatBootstrapConfig is an auto-generated flat version of BootstrapConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatBootstrapConfig struct {
+	Method             *string  `mapstructure:"method" cty:"method" hcl:"method"`
+	NavigatorVersion   *string  `mapstructure:"navigator_version" cty:"navigator_version" hcl:"navigator_version"`
+	AnsibleCoreVersion *string  `mapstructure:"ansible_core_version" cty:"ansible_core_version" hcl:"ansible_core_version"`
+	ExtraPackages      []string `mapstructure:"extra_packages" cty:"extra_packages" hcl:"extra_packages"`
+	Packages           []string `mapstructure:"packages" cty:"packages" hcl:"packages"`
+	Python             *string  `mapstructure:"python" cty:"python" hcl:"python"`
+	Wheelhouse         *string  `mapstructure:"wheelhouse" cty:"wheelhouse" hcl:"wheelhouse"`
+	Path               *string  `mapstructure:"path" cty:"path" hcl:"path"`
+	Keep               *bool    `mapstructure:"keep" cty:"keep" hcl:"keep"`
+}
+
+// FlatMapstructure returns a new FlatBootstrapConfig.
+// FlatBootstrapConfig is an auto-generated flat version of BootstrapConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*BootstrapConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatBootstrapConfig)
+}
+
+// HCL2Spec returns the hcl spec of a BootstrapConfig.
+// This spec is used by HCL to read the fields of BootstrapConfig.
+// The decoded values from this spec will then be applied to a FlatBootstrapConfig.
+func (*FlatBootstrapConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"method":               &hcldec.AttrSpec{Name: "method", Type: cty.String, Required: false},
+		"navigator_version":    &hcldec.AttrSpec{Name: "navigator_version", Type: cty.String, Required: false},
+		"ansible_core_version": &hcldec.AttrSpec{Name: "ansible_core_version", Type: cty.String, Required: false},
+		"extra_packages":       &hcldec.AttrSpec{Name: "extra_packages", Type: cty.List(cty.String), Required: false},
+		"packages":             &hcldec.AttrSpec{Name: "packages", Type: cty.List(cty.String), Required: false},
+		"python":               &hcldec.AttrSpec{Name: "python", Type: cty.String, Required: false},
+		"wheelhouse":           &hcldec.AttrSpec{Name: "wheelhouse", Type: cty.String, Required: false},
+		"path":                 &hcldec.AttrSpec{Name: "path", Type: cty.String, Required: false},
+		"keep":                 &hcldec.AttrSpec{Name: "keep", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
 // FlatCollectionDocCache is an auto-generated flat version of CollectionDocCache.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatCollectionDocCache struct {
@@ -111,36 +206,85 @@ func (*FlatCollectionDocCache) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatColorConfig is an auto-generated flat version of ColorConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatColorConfig struct {
+	Enable *bool `mapstructure:"enable" cty:"enable" hcl:"enable"`
+	Osc4   *bool `mapstructure:"osc4" cty:"osc4" hcl:"osc4"`
+}
+
+// FlatMapstructure returns a new FlatColorConfig.
+// FlatColorConfig is an auto-generated flat version of ColorConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ColorConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatColorConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ColorConfig.
+// This spec is used by HCL to read the fields of ColorConfig.
+// The decoded values from this spec will then be applied to a FlatColorConfig.
+func (*FlatColorConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"enable": &hcldec.AttrSpec{Name: "enable", Type: cty.Bool, Required: false},
+		"osc4":   &hcldec.AttrSpec{Name: "osc4", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
 // FlatConfig is an auto-generated flat version of Config.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatConfig struct {
-	PackerBuildName      *string              `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
-	PackerBuilderType    *string              `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
-	PackerCoreVersion    *string              `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
-	PackerDebug          *bool                `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
-	PackerForce          *bool                `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
-	PackerOnError        *string              `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
-	PackerUserVars       map[string]string    `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
-	PackerSensitiveVars  []string             `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
-	Command              *string              `mapstructure:"command" cty:"command" hcl:"command"`
-	AnsibleNavigatorPath []string             `mapstructure:"ansible_navigator_path" cty:"ansible_navigator_path" hcl:"ansible_navigator_path"`
-	VersionCheckTimeout  *string              `mapstructure:"version_check_timeout" cty:"version_check_timeout" hcl:"version_check_timeout"`
-	SkipVersionCheck     *bool                `mapstructure:"skip_version_check" cty:"skip_version_check" hcl:"skip_version_check"`
-	KeepGoing            *bool                `mapstructure:"keep_going" cty:"keep_going" hcl:"keep_going"`
-	StructuredLogging    *bool                `mapstructure:"structured_logging" cty:"structured_logging" hcl:"structured_logging"`
-	LogOutputPath        *string              `mapstructure:"log_output_path" cty:"log_output_path" hcl:"log_output_path"`
-	VerboseTaskOutput    *bool                `mapstructure:"verbose_task_output" cty:"verbose_task_output" hcl:"verbose_task_output"`
-	Plays                []FlatPlay           `mapstructure:"play" cty:"play" hcl:"play"`
-	RequirementsFile     *string              `mapstructure:"requirements_file" cty:"requirements_file" hcl:"requirements_file"`
-	RolesPath            *string              `mapstructure:"roles_path" cty:"roles_path" hcl:"roles_path"`
-	CollectionsPath      *string              `mapstructure:"collections_path" cty:"collections_path" hcl:"collections_path"`
-	OfflineMode          *bool                `mapstructure:"offline_mode" cty:"offline_mode" hcl:"offline_mode"`
-	GalaxyCommand        *string              `mapstructure:"galaxy_command" cty:"galaxy_command" hcl:"galaxy_command"`
-	GalaxyArgs           []string             `mapstructure:"galaxy_args" cty:"galaxy_args" hcl:"galaxy_args"`
-	GalaxyForce          *bool                `mapstructure:"galaxy_force" cty:"galaxy_force" hcl:"galaxy_force"`
-	GalaxyForceWithDeps  *bool                `mapstructure:"galaxy_force_with_deps" cty:"galaxy_force_with_deps" hcl:"galaxy_force_with_deps"`
-	ShowExtraVars        *bool                `mapstructure:"show_extra_vars" cty:"show_extra_vars" hcl:"show_extra_vars"`
-	NavigatorConfig      *FlatNavigatorConfig `mapstructure:"navigator_config" cty:"navigator_config" hcl:"navigator_config"`
+	PackerBuildName                   *string                      `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType                 *string                      `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion                 *string                      `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug                       *bool                        `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce                       *bool                        `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError                     *string                      `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars                    map[string]string            `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars               []string                     `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	Command                           *string                      `mapstructure:"command" cty:"command" hcl:"command"`
+	AnsibleNavigatorPath              []string                     `mapstructure:"ansible_navigator_path" cty:"ansible_navigator_path" hcl:"ansible_navigator_path"`
+	Bootstrap                         *FlatBootstrapConfig         `mapstructure:"bootstrap" cty:"bootstrap" hcl:"bootstrap"`
+	TargetShell                       *string                      `mapstructure:"target_shell" cty:"target_shell" hcl:"target_shell"`
+	StagingDirectory                  *string                      `mapstructure:"staging_directory" cty:"staging_directory" hcl:"staging_directory"`
+	StagingDirectoryMode              *string                      `mapstructure:"staging_directory_mode" cty:"staging_directory_mode" hcl:"staging_directory_mode"`
+	StagingOwner                      *string                      `mapstructure:"staging_owner" cty:"staging_owner" hcl:"staging_owner"`
+	CleanStagingDirectory             *string                      `mapstructure:"clean_staging_directory" cty:"clean_staging_directory" hcl:"clean_staging_directory"`
+	ExecutionHost                     *string                      `mapstructure:"execution_host" cty:"execution_host" hcl:"execution_host"`
+	TargetConnection                  *string                      `mapstructure:"target_connection" cty:"target_connection" hcl:"target_connection"`
+	TargetHost                        *string                      `mapstructure:"target_host" cty:"target_host" hcl:"target_host"`
+	Groups                            []string                     `mapstructure:"groups" cty:"groups" hcl:"groups"`
+	InventoryGroups                   *string                      `mapstructure:"inventory_groups" cty:"inventory_groups" hcl:"inventory_groups"`
+	EmptyGroups                       []string                     `mapstructure:"empty_groups" cty:"empty_groups" hcl:"empty_groups"`
+	HostAlias                         *string                      `mapstructure:"host_alias" cty:"host_alias" hcl:"host_alias"`
+	InventoryHosts                    []FlatInventoryHost          `mapstructure:"inventory_host" cty:"inventory_host" hcl:"inventory_host"`
+	InventoryFile                     *string                      `mapstructure:"inventory_file" cty:"inventory_file" hcl:"inventory_file"`
+	Limit                             *string                      `mapstructure:"limit" cty:"limit" hcl:"limit"`
+	VersionCheckTimeout               *string                      `mapstructure:"version_check_timeout" cty:"version_check_timeout" hcl:"version_check_timeout"`
+	SkipVersionCheck                  *bool                        `mapstructure:"skip_version_check" cty:"skip_version_check" hcl:"skip_version_check"`
+	KeepGoing                         *bool                        `mapstructure:"keep_going" cty:"keep_going" hcl:"keep_going"`
+	StructuredLogging                 *bool                        `mapstructure:"structured_logging" cty:"structured_logging" hcl:"structured_logging"`
+	LogOutputPath                     *string                      `mapstructure:"log_output_path" cty:"log_output_path" hcl:"log_output_path"`
+	VerboseTaskOutput                 *bool                        `mapstructure:"verbose_task_output" cty:"verbose_task_output" hcl:"verbose_task_output"`
+	DownloadArtifacts                 *FlatDownloadArtifactsConfig `mapstructure:"download_artifacts" cty:"download_artifacts" hcl:"download_artifacts"`
+	Plays                             []FlatPlay                   `mapstructure:"play" cty:"play" hcl:"play"`
+	PlaybookDir                       *string                      `mapstructure:"playbook_dir" cty:"playbook_dir" hcl:"playbook_dir"`
+	PlaybookDirExclude                []string                     `mapstructure:"playbook_dir_exclude" cty:"playbook_dir_exclude" hcl:"playbook_dir_exclude"`
+	RequirementsFile                  *string                      `mapstructure:"requirements_file" cty:"requirements_file" hcl:"requirements_file"`
+	RolesPath                         *string                      `mapstructure:"roles_path" cty:"roles_path" hcl:"roles_path"`
+	CollectionsPath                   *string                      `mapstructure:"collections_path" cty:"collections_path" hcl:"collections_path"`
+	OfflineMode                       *bool                        `mapstructure:"offline_mode" cty:"offline_mode" hcl:"offline_mode"`
+	GalaxyCommand                     *string                      `mapstructure:"galaxy_command" cty:"galaxy_command" hcl:"galaxy_command"`
+	GalaxyArgs                        []string                     `mapstructure:"galaxy_args" cty:"galaxy_args" hcl:"galaxy_args"`
+	GalaxyForce                       *bool                        `mapstructure:"galaxy_force" cty:"galaxy_force" hcl:"galaxy_force"`
+	GalaxyForceWithDeps               *bool                        `mapstructure:"galaxy_force_with_deps" cty:"galaxy_force_with_deps" hcl:"galaxy_force_with_deps"`
+	GalaxyServers                     []FlatGalaxyServer           `mapstructure:"galaxy_server" cty:"galaxy_server" hcl:"galaxy_server"`
+	GalaxyInstallLocation             *string                      `mapstructure:"galaxy_install_location" cty:"galaxy_install_location" hcl:"galaxy_install_location"`
+	GalaxyKeyring                     *string                      `mapstructure:"galaxy_keyring" cty:"galaxy_keyring" hcl:"galaxy_keyring"`
+	GalaxyRequiredValidSignatureCount *string                      `mapstructure:"galaxy_required_valid_signature_count" cty:"galaxy_required_valid_signature_count" hcl:"galaxy_required_valid_signature_count"`
+	GalaxyIgnoreSignatureStatusCodes  []string                     `mapstructure:"galaxy_ignore_signature_status_codes" cty:"galaxy_ignore_signature_status_codes" hcl:"galaxy_ignore_signature_status_codes"`
+	ShowExtraVars                     *bool                        `mapstructure:"show_extra_vars" cty:"show_extra_vars" hcl:"show_extra_vars"`
+	NavigatorConfig                   *FlatNavigatorConfig         `mapstructure:"navigator_config" cty:"navigator_config" hcl:"navigator_config"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -155,33 +299,107 @@ func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
 // The decoded values from this spec will then be applied to a FlatConfig.
 func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"packer_build_name":          &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
-		"packer_builder_type":        &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
-		"packer_core_version":        &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
-		"packer_debug":               &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
-		"packer_force":               &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
-		"packer_on_error":            &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
-		"packer_user_variables":      &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
-		"packer_sensitive_variables": &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
-		"command":                    &hcldec.AttrSpec{Name: "command", Type: cty.String, Required: false},
-		"ansible_navigator_path":     &hcldec.AttrSpec{Name: "ansible_navigator_path", Type: cty.List(cty.String), Required: false},
-		"version_check_timeout":      &hcldec.AttrSpec{Name: "version_check_timeout", Type: cty.String, Required: false},
-		"skip_version_check":         &hcldec.AttrSpec{Name: "skip_version_check", Type: cty.Bool, Required: false},
-		"keep_going":                 &hcldec.AttrSpec{Name: "keep_going", Type: cty.Bool, Required: false},
-		"structured_logging":         &hcldec.AttrSpec{Name: "structured_logging", Type: cty.Bool, Required: false},
-		"log_output_path":            &hcldec.AttrSpec{Name: "log_output_path", Type: cty.String, Required: false},
-		"verbose_task_output":        &hcldec.AttrSpec{Name: "verbose_task_output", Type: cty.Bool, Required: false},
-		"play":                       &hcldec.BlockListSpec{TypeName: "play", Nested: hcldec.ObjectSpec((*FlatPlay)(nil).HCL2Spec())},
-		"requirements_file":          &hcldec.AttrSpec{Name: "requirements_file", Type: cty.String, Required: false},
-		"roles_path":                 &hcldec.AttrSpec{Name: "roles_path", Type: cty.String, Required: false},
-		"collections_path":           &hcldec.AttrSpec{Name: "collections_path", Type: cty.String, Required: false},
-		"offline_mode":               &hcldec.AttrSpec{Name: "offline_mode", Type: cty.Bool, Required: false},
-		"galaxy_command":             &hcldec.AttrSpec{Name: "galaxy_command", Type: cty.String, Required: false},
-		"galaxy_args":                &hcldec.AttrSpec{Name: "galaxy_args", Type: cty.List(cty.String), Required: false},
-		"galaxy_force":               &hcldec.AttrSpec{Name: "galaxy_force", Type: cty.Bool, Required: false},
-		"galaxy_force_with_deps":     &hcldec.AttrSpec{Name: "galaxy_force_with_deps", Type: cty.Bool, Required: false},
-		"show_extra_vars":            &hcldec.AttrSpec{Name: "show_extra_vars", Type: cty.Bool, Required: false},
-		"navigator_config":           &hcldec.BlockSpec{TypeName: "navigator_config", Nested: hcldec.ObjectSpec((*FlatNavigatorConfig)(nil).HCL2Spec())},
+		"packer_build_name":                     &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":                   &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":                   &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":                          &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":                          &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":                       &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":                 &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":            &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"command":                               &hcldec.AttrSpec{Name: "command", Type: cty.String, Required: false},
+		"ansible_navigator_path":                &hcldec.AttrSpec{Name: "ansible_navigator_path", Type: cty.List(cty.String), Required: false},
+		"bootstrap":                             &hcldec.BlockSpec{TypeName: "bootstrap", Nested: hcldec.ObjectSpec((*FlatBootstrapConfig)(nil).HCL2Spec())},
+		"target_shell":                          &hcldec.AttrSpec{Name: "target_shell", Type: cty.String, Required: false},
+		"staging_directory":                     &hcldec.AttrSpec{Name: "staging_directory", Type: cty.String, Required: false},
+		"staging_directory_mode":                &hcldec.AttrSpec{Name: "staging_directory_mode", Type: cty.String, Required: false},
+		"staging_owner":                         &hcldec.AttrSpec{Name: "staging_owner", Type: cty.String, Required: false},
+		"clean_staging_directory":               &hcldec.AttrSpec{Name: "clean_staging_directory", Type: cty.String, Required: false},
+		"execution_host":                        &hcldec.AttrSpec{Name: "execution_host", Type: cty.String, Required: false},
+		"target_connection":                     &hcldec.AttrSpec{Name: "target_connection", Type: cty.String, Required: false},
+		"target_host":                           &hcldec.AttrSpec{Name: "target_host", Type: cty.String, Required: false},
+		"groups":                                &hcldec.AttrSpec{Name: "groups", Type: cty.List(cty.String), Required: false},
+		"inventory_groups":                      &hcldec.AttrSpec{Name: "inventory_groups", Type: cty.String, Required: false},
+		"empty_groups":                          &hcldec.AttrSpec{Name: "empty_groups", Type: cty.List(cty.String), Required: false},
+		"host_alias":                            &hcldec.AttrSpec{Name: "host_alias", Type: cty.String, Required: false},
+		"inventory_host":                        &hcldec.BlockListSpec{TypeName: "inventory_host", Nested: hcldec.ObjectSpec((*FlatInventoryHost)(nil).HCL2Spec())},
+		"inventory_file":                        &hcldec.AttrSpec{Name: "inventory_file", Type: cty.String, Required: false},
+		"limit":                                 &hcldec.AttrSpec{Name: "limit", Type: cty.String, Required: false},
+		"version_check_timeout":                 &hcldec.AttrSpec{Name: "version_check_timeout", Type: cty.String, Required: false},
+		"skip_version_check":                    &hcldec.AttrSpec{Name: "skip_version_check", Type: cty.Bool, Required: false},
+		"keep_going":                            &hcldec.AttrSpec{Name: "keep_going", Type: cty.Bool, Required: false},
+		"structured_logging":                    &hcldec.AttrSpec{Name: "structured_logging", Type: cty.Bool, Required: false},
+		"log_output_path":                       &hcldec.AttrSpec{Name: "log_output_path", Type: cty.String, Required: false},
+		"verbose_task_output":                   &hcldec.AttrSpec{Name: "verbose_task_output", Type: cty.Bool, Required: false},
+		"download_artifacts":                    &hcldec.BlockSpec{TypeName: "download_artifacts", Nested: hcldec.ObjectSpec((*FlatDownloadArtifactsConfig)(nil).HCL2Spec())},
+		"play":                                  &hcldec.BlockListSpec{TypeName: "play", Nested: hcldec.ObjectSpec((*FlatPlay)(nil).HCL2Spec())},
+		"playbook_dir":                          &hcldec.AttrSpec{Name: "playbook_dir", Type: cty.String, Required: false},
+		"playbook_dir_exclude":                  &hcldec.AttrSpec{Name: "playbook_dir_exclude", Type: cty.List(cty.String), Required: false},
+		"requirements_file":                     &hcldec.AttrSpec{Name: "requirements_file", Type: cty.String, Required: false},
+		"roles_path":                            &hcldec.AttrSpec{Name: "roles_path", Type: cty.String, Required: false},
+		"collections_path":                      &hcldec.AttrSpec{Name: "collections_path", Type: cty.String, Required: false},
+		"offline_mode":                          &hcldec.AttrSpec{Name: "offline_mode", Type: cty.Bool, Required: false},
+		"galaxy_command":                        &hcldec.AttrSpec{Name: "galaxy_command", Type: cty.String, Required: false},
+		"galaxy_args":                           &hcldec.AttrSpec{Name: "galaxy_args", Type: cty.List(cty.String), Required: false},
+		"galaxy_force":                          &hcldec.AttrSpec{Name: "galaxy_force", Type: cty.Bool, Required: false},
+		"galaxy_force_with_deps":                &hcldec.AttrSpec{Name: "galaxy_force_with_deps", Type: cty.Bool, Required: false},
+		"galaxy_server":                         &hcldec.BlockListSpec{TypeName: "galaxy_server", Nested: hcldec.ObjectSpec((*FlatGalaxyServer)(nil).HCL2Spec())},
+		"galaxy_install_location":               &hcldec.AttrSpec{Name: "galaxy_install_location", Type: cty.String, Required: false},
+		"galaxy_keyring":                        &hcldec.AttrSpec{Name: "galaxy_keyring", Type: cty.String, Required: false},
+		"galaxy_required_valid_signature_count": &hcldec.AttrSpec{Name: "galaxy_required_valid_signature_count", Type: cty.String, Required: false},
+		"galaxy_ignore_signature_status_codes":  &hcldec.AttrSpec{Name: "galaxy_ignore_signature_status_codes", Type: cty.List(cty.String), Required: false},
+		"show_extra_vars":                       &hcldec.AttrSpec{Name: "show_extra_vars", Type: cty.Bool, Required: false},
+		"navigator_config":                      &hcldec.BlockSpec{TypeName: "navigator_config", Nested: hcldec.ObjectSpec((*FlatNavigatorConfig)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatDownloadArtifactsConfig is an auto-generated flat version of DownloadArtifactsConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatDownloadArtifactsConfig struct {
+	Destination *string  `mapstructure:"destination" cty:"destination" hcl:"destination"`
+	Paths       []string `mapstructure:"paths" cty:"paths" hcl:"paths"`
+}
+
+// FlatMapstructure returns a new FlatDownloadArtifactsConfig.
+// FlatDownloadArtifactsConfig is an auto-generated flat version of DownloadArtifactsConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*DownloadArtifactsConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatDownloadArtifactsConfig)
+}
+
+// HCL2Spec returns the hcl spec of a DownloadArtifactsConfig.
+// This spec is used by HCL to read the fields of DownloadArtifactsConfig.
+// The decoded values from this spec will then be applied to a FlatDownloadArtifactsConfig.
+func (*FlatDownloadArtifactsConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"destination": &hcldec.AttrSpec{Name: "destination", Type: cty.String, Required: false},
+		"paths":       &hcldec.AttrSpec{Name: "paths", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatEditorConfig is an auto-generated flat version of EditorConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatEditorConfig struct {
+	Command *string `mapstructure:"command" cty:"command" hcl:"command"`
+	Console *bool   `mapstructure:"console" cty:"console" hcl:"console"`
+}
+
+// FlatMapstructure returns a new FlatEditorConfig.
+// FlatEditorConfig is an auto-generated flat version of EditorConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*EditorConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatEditorConfig)
+}
+
+// HCL2Spec returns the hcl spec of a EditorConfig.
+// This spec is used by HCL to read the fields of EditorConfig.
+// The decoded values from this spec will then be applied to a FlatEditorConfig.
+func (*FlatEditorConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"command": &hcldec.AttrSpec{Name: "command", Type: cty.String, Required: false},
+		"console": &hcldec.AttrSpec{Name: "console", Type: cty.Bool, Required: false},
 	}
 	return s
 }
@@ -216,12 +434,15 @@ func (*FlatEnvironmentVariablesConfig) HCL2Spec() map[string]hcldec.Spec {
 type FlatExecutionEnvironment struct {
 	Enabled              *bool                           `mapstructure:"enabled" cty:"enabled" hcl:"enabled"`
 	Image                *string                         `mapstructure:"image" cty:"image" hcl:"image"`
+	ImageDigest          *string                         `mapstructure:"image_digest" cty:"image_digest" hcl:"image_digest"`
 	PullPolicy           *string                         `mapstructure:"pull_policy" cty:"pull_policy" hcl:"pull_policy"`
 	ContainerEngine      *string                         `mapstructure:"container_engine" cty:"container_engine" hcl:"container_engine"`
 	ContainerOptions     []string                        `mapstructure:"container_options" cty:"container_options" hcl:"container_options"`
 	PullArguments        []string                        `mapstructure:"pull_arguments" cty:"pull_arguments" hcl:"pull_arguments"`
 	EnvironmentVariables *FlatEnvironmentVariablesConfig `mapstructure:"environment_variables" cty:"environment_variables" hcl:"environment_variables"`
 	VolumeMounts         []FlatVolumeMount               `mapstructure:"volume_mounts" cty:"volume_mounts" hcl:"volume_mounts"`
+	Verify               *FlatImageVerifyConfig          `mapstructure:"verify" cty:"verify" hcl:"verify"`
+	Preflight            *string                         `mapstructure:"preflight" cty:"preflight" hcl:"preflight"`
 }
 
 // FlatMapstructure returns a new FlatExecutionEnvironment.
@@ -238,12 +459,129 @@ func (*FlatExecutionEnvironment) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
 		"enabled":               &hcldec.AttrSpec{Name: "enabled", Type: cty.Bool, Required: false},
 		"image":                 &hcldec.AttrSpec{Name: "image", Type: cty.String, Required: false},
+		"image_digest":          &hcldec.AttrSpec{Name: "image_digest", Type: cty.String, Required: false},
 		"pull_policy":           &hcldec.AttrSpec{Name: "pull_policy", Type: cty.String, Required: false},
 		"container_engine":      &hcldec.AttrSpec{Name: "container_engine", Type: cty.String, Required: false},
 		"container_options":     &hcldec.AttrSpec{Name: "container_options", Type: cty.List(cty.String), Required: false},
 		"pull_arguments":        &hcldec.AttrSpec{Name: "pull_arguments", Type: cty.List(cty.String), Required: false},
 		"environment_variables": &hcldec.BlockSpec{TypeName: "environment_variables", Nested: hcldec.ObjectSpec((*FlatEnvironmentVariablesConfig)(nil).HCL2Spec())},
 		"volume_mounts":         &hcldec.BlockListSpec{TypeName: "volume_mounts", Nested: hcldec.ObjectSpec((*FlatVolumeMount)(nil).HCL2Spec())},
+		"verify":                &hcldec.BlockSpec{TypeName: "verify", Nested: hcldec.ObjectSpec((*FlatImageVerifyConfig)(nil).HCL2Spec())},
+		"preflight":             &hcldec.AttrSpec{Name: "preflight", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatGalaxyServer is an auto-generated flat version of GalaxyServer.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatGalaxyServer struct {
+	Name          *string `mapstructure:"name" required:"true" cty:"name" hcl:"name"`
+	URL           *string `mapstructure:"url" required:"true" cty:"url" hcl:"url"`
+	AuthURL       *string `mapstructure:"auth_url" cty:"auth_url" hcl:"auth_url"`
+	Token         *string `mapstructure:"token" cty:"token" hcl:"token"`
+	ValidateCerts *bool   `mapstructure:"validate_certs" cty:"validate_certs" hcl:"validate_certs"`
+}
+
+// FlatMapstructure returns a new FlatGalaxyServer.
+// FlatGalaxyServer is an auto-generated flat version of GalaxyServer.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*GalaxyServer) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatGalaxyServer)
+}
+
+// HCL2Spec returns the hcl spec of a GalaxyServer.
+// This spec is used by HCL to read the fields of GalaxyServer.
+// The decoded values from this spec will then be applied to a FlatGalaxyServer.
+func (*FlatGalaxyServer) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":           &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"url":            &hcldec.AttrSpec{Name: "url", Type: cty.String, Required: false},
+		"auth_url":       &hcldec.AttrSpec{Name: "auth_url", Type: cty.String, Required: false},
+		"token":          &hcldec.AttrSpec{Name: "token", Type: cty.String, Required: false},
+		"validate_certs": &hcldec.AttrSpec{Name: "validate_certs", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatImageVerifyConfig is an auto-generated flat version of ImageVerifyConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImageVerifyConfig struct {
+	CosignKey *string `mapstructure:"cosign_key" cty:"cosign_key" hcl:"cosign_key"`
+	Policy    *string `mapstructure:"policy" cty:"policy" hcl:"policy"`
+}
+
+// FlatMapstructure returns a new FlatImageVerifyConfig.
+// FlatImageVerifyConfig is an auto-generated flat version of ImageVerifyConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImageVerifyConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImageVerifyConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ImageVerifyConfig.
+// This spec is used by HCL to read the fields of ImageVerifyConfig.
+// The decoded values from this spec will then be applied to a FlatImageVerifyConfig.
+func (*FlatImageVerifyConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"cosign_key": &hcldec.AttrSpec{Name: "cosign_key", Type: cty.String, Required: false},
+		"policy":     &hcldec.AttrSpec{Name: "policy", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatImagesConfig is an auto-generated flat version of ImagesConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImagesConfig struct {
+	Details []string `mapstructure:"details" cty:"details" hcl:"details"`
+}
+
+// FlatMapstructure returns a new FlatImagesConfig.
+// FlatImagesConfig is an auto-generated flat version of ImagesConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImagesConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImagesConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ImagesConfig.
+// This spec is used by HCL to read the fields of ImagesConfig.
+// The decoded values from this spec will then be applied to a FlatImagesConfig.
+func (*FlatImagesConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"details": &hcldec.AttrSpec{Name: "details", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatInventoryHost is an auto-generated flat version of InventoryHost.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatInventoryHost struct {
+	Name       *string           `mapstructure:"name" cty:"name" hcl:"name"`
+	Address    *string           `mapstructure:"address" cty:"address" hcl:"address"`
+	Connection *string           `mapstructure:"connection" cty:"connection" hcl:"connection"`
+	User       *string           `mapstructure:"user" cty:"user" hcl:"user"`
+	Port       *int              `mapstructure:"port" cty:"port" hcl:"port"`
+	Groups     []string          `mapstructure:"groups" cty:"groups" hcl:"groups"`
+	Vars       map[string]string `mapstructure:"vars" cty:"vars" hcl:"vars"`
+}
+
+// FlatMapstructure returns a new FlatInventoryHost.
+// FlatInventoryHost is an auto-generated flat version of InventoryHost.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*InventoryHost) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatInventoryHost)
+}
+
+// HCL2Spec returns the hcl spec of a InventoryHost.
+// This spec is used by HCL to read the fields of InventoryHost.
+// The decoded values from this spec will then be applied to a FlatInventoryHost.
+func (*FlatInventoryHost) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":       &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"address":    &hcldec.AttrSpec{Name: "address", Type: cty.String, Required: false},
+		"connection": &hcldec.AttrSpec{Name: "connection", Type: cty.String, Required: false},
+		"user":       &hcldec.AttrSpec{Name: "user", Type: cty.String, Required: false},
+		"port":       &hcldec.AttrSpec{Name: "port", Type: cty.Number, Required: false},
+		"groups":     &hcldec.AttrSpec{Name: "groups", Type: cty.List(cty.String), Required: false},
+		"vars":       &hcldec.AttrSpec{Name: "vars", Type: cty.Map(cty.String), Required: false},
 	}
 	return s
 }
@@ -284,6 +622,15 @@ type FlatNavigatorConfig struct {
 	Logging              *FlatLoggingConfig        `mapstructure:"logging" cty:"logging" hcl:"logging"`
 	PlaybookArtifact     *FlatPlaybookArtifact     `mapstructure:"playbook_artifact" cty:"playbook_artifact" hcl:"playbook_artifact"`
 	CollectionDocCache   *FlatCollectionDocCache   `mapstructure:"collection_doc_cache" cty:"collection_doc_cache" hcl:"collection_doc_cache"`
+	Color                *FlatColorConfig          `mapstructure:"color" cty:"color" hcl:"color"`
+	Editor               *FlatEditorConfig         `mapstructure:"editor" cty:"editor" hcl:"editor"`
+	Images               *FlatImagesConfig         `mapstructure:"images" cty:"images" hcl:"images"`
+	InventoryColumns     []string                  `mapstructure:"inventory_columns" cty:"inventory_columns" hcl:"inventory_columns"`
+	TimeZone             *string                   `mapstructure:"time_zone" cty:"time_zone" hcl:"time_zone"`
+	Format               *string                   `mapstructure:"format" cty:"format" hcl:"format"`
+	Settings             *FlatSettingsConfig       `mapstructure:"settings" cty:"settings" hcl:"settings"`
+	EnablePrompts        *bool                     `mapstructure:"enable_prompts" cty:"enable_prompts" hcl:"enable_prompts"`
+	AnsibleRunner        *FlatAnsibleRunnerConfig  `mapstructure:"ansible_runner" cty:"ansible_runner" hcl:"ansible_runner"`
 }
 
 // FlatMapstructure returns a new FlatNavigatorConfig.
@@ -304,6 +651,15 @@ func (*FlatNavigatorConfig) HCL2Spec() map[string]hcldec.Spec {
 		"logging":               &hcldec.BlockSpec{TypeName: "logging", Nested: hcldec.ObjectSpec((*FlatLoggingConfig)(nil).HCL2Spec())},
 		"playbook_artifact":     &hcldec.BlockSpec{TypeName: "playbook_artifact", Nested: hcldec.ObjectSpec((*FlatPlaybookArtifact)(nil).HCL2Spec())},
 		"collection_doc_cache":  &hcldec.BlockSpec{TypeName: "collection_doc_cache", Nested: hcldec.ObjectSpec((*FlatCollectionDocCache)(nil).HCL2Spec())},
+		"color":                 &hcldec.BlockSpec{TypeName: "color", Nested: hcldec.ObjectSpec((*FlatColorConfig)(nil).HCL2Spec())},
+		"editor":                &hcldec.BlockSpec{TypeName: "editor", Nested: hcldec.ObjectSpec((*FlatEditorConfig)(nil).HCL2Spec())},
+		"images":                &hcldec.BlockSpec{TypeName: "images", Nested: hcldec.ObjectSpec((*FlatImagesConfig)(nil).HCL2Spec())},
+		"inventory_columns":     &hcldec.AttrSpec{Name: "inventory_columns", Type: cty.List(cty.String), Required: false},
+		"time_zone":             &hcldec.AttrSpec{Name: "time_zone", Type: cty.String, Required: false},
+		"format":                &hcldec.AttrSpec{Name: "format", Type: cty.String, Required: false},
+		"settings":              &hcldec.BlockSpec{TypeName: "settings", Nested: hcldec.ObjectSpec((*FlatSettingsConfig)(nil).HCL2Spec())},
+		"enable_prompts":        &hcldec.AttrSpec{Name: "enable_prompts", Type: cty.Bool, Required: false},
+		"ansible_runner":        &hcldec.BlockSpec{TypeName: "ansible_runner", Nested: hcldec.ObjectSpec((*FlatAnsibleRunnerConfig)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -311,13 +667,14 @@ func (*FlatNavigatorConfig) HCL2Spec() map[string]hcldec.Spec {
 // FlatPlay is an auto-generated flat version of Play.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatPlay struct {
-	Name      *string           `mapstructure:"name" cty:"name" hcl:"name"`
-	Target    *string           `mapstructure:"target" cty:"target" hcl:"target"`
-	ExtraVars map[string]string `mapstructure:"extra_vars" cty:"extra_vars" hcl:"extra_vars"`
-	Tags      []string          `mapstructure:"tags" cty:"tags" hcl:"tags"`
-	VarsFiles []string          `mapstructure:"vars_files" cty:"vars_files" hcl:"vars_files"`
-	Become    *bool             `mapstructure:"become" cty:"become" hcl:"become"`
-	ExtraArgs []string          `mapstructure:"extra_args" cty:"extra_args" hcl:"extra_args"`
+	Name        *string           `mapstructure:"name" cty:"name" hcl:"name"`
+	Target      *string           `mapstructure:"target" cty:"target" hcl:"target"`
+	ExtraVars   map[string]string `mapstructure:"extra_vars" cty:"extra_vars" hcl:"extra_vars"`
+	Tags        []string          `mapstructure:"tags" cty:"tags" hcl:"tags"`
+	VarsFiles   []string          `mapstructure:"vars_files" cty:"vars_files" hcl:"vars_files"`
+	Become      *bool             `mapstructure:"become" cty:"become" hcl:"become"`
+	PlaybookDir *string           `mapstructure:"playbook_dir" cty:"playbook_dir" hcl:"playbook_dir"`
+	ExtraArgs   []string          `mapstructure:"extra_args" cty:"extra_args" hcl:"extra_args"`
 }
 
 // FlatMapstructure returns a new FlatPlay.
@@ -332,13 +689,14 @@ func (*Play) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
 // The decoded values from this spec will then be applied to a FlatPlay.
 func (*FlatPlay) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"name":       &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
-		"target":     &hcldec.AttrSpec{Name: "target", Type: cty.String, Required: false},
-		"extra_vars": &hcldec.AttrSpec{Name: "extra_vars", Type: cty.Map(cty.String), Required: false},
-		"tags":       &hcldec.AttrSpec{Name: "tags", Type: cty.List(cty.String), Required: false},
-		"vars_files": &hcldec.AttrSpec{Name: "vars_files", Type: cty.List(cty.String), Required: false},
-		"become":     &hcldec.AttrSpec{Name: "become", Type: cty.Bool, Required: false},
-		"extra_args": &hcldec.AttrSpec{Name: "extra_args", Type: cty.List(cty.String), Required: false},
+		"name":         &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"target":       &hcldec.AttrSpec{Name: "target", Type: cty.String, Required: false},
+		"extra_vars":   &hcldec.AttrSpec{Name: "extra_vars", Type: cty.Map(cty.String), Required: false},
+		"tags":         &hcldec.AttrSpec{Name: "tags", Type: cty.List(cty.String), Required: false},
+		"vars_files":   &hcldec.AttrSpec{Name: "vars_files", Type: cty.List(cty.String), Required: false},
+		"become":       &hcldec.AttrSpec{Name: "become", Type: cty.Bool, Required: false},
+		"playbook_dir": &hcldec.AttrSpec{Name: "playbook_dir", Type: cty.String, Required: false},
+		"extra_args":   &hcldec.AttrSpec{Name: "extra_args", Type: cty.List(cty.String), Required: false},
 	}
 	return s
 }
@@ -370,6 +728,35 @@ func (*FlatPlaybookArtifact) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatSettingsConfig is an auto-generated flat version of SettingsConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatSettingsConfig struct {
+	Effective *bool   `mapstructure:"effective" cty:"effective" hcl:"effective"`
+	Sample    *bool   `mapstructure:"sample" cty:"sample" hcl:"sample"`
+	Schema    *string `mapstructure:"schema" cty:"schema" hcl:"schema"`
+	Sources   *bool   `mapstructure:"sources" cty:"sources" hcl:"sources"`
+}
+
+// FlatMapstructure returns a new FlatSettingsConfig.
+// FlatSettingsConfig is an auto-generated flat version of SettingsConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*SettingsConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatSettingsConfig)
+}
+
+// HCL2Spec returns the hcl spec of a SettingsConfig.
+// This spec is used by HCL to read the fields of SettingsConfig.
+// The decoded values from this spec will then be applied to a FlatSettingsConfig.
+func (*FlatSettingsConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"effective": &hcldec.AttrSpec{Name: "effective", Type: cty.Bool, Required: false},
+		"sample":    &hcldec.AttrSpec{Name: "sample", Type: cty.Bool, Required: false},
+		"schema":    &hcldec.AttrSpec{Name: "schema", Type: cty.String, Required: false},
+		"sources":   &hcldec.AttrSpec{Name: "sources", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
 // FlatVolumeMount is an auto-generated flat version of VolumeMount.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatVolumeMount struct {
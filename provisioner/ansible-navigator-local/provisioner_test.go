@@ -338,3 +338,37 @@ func TestProvisionerProvision_ProvisionerExtraVars_JSONSinglePairAndTargetLast(t
 	require.Contains(t, cmd, "-i="+invRemote)
 	require.True(t, strings.HasSuffix(cmd, playRemote), "expected command to end with play target %q; got: %q", playRemote, cmd)
 }
+
+func TestProvisionerPrepare_GalaxyInstallLocation(t *testing.T) {
+	playbookFile, err := os.CreateTemp("", "playbook-*.yml")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(playbookFile.Name())
+
+	var p Provisioner
+	config := testConfig()
+	config["play"] = []map[string]interface{}{{"target": playbookFile.Name()}}
+	if err := p.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if p.config.GalaxyInstallLocation != GalaxyInstallLocationTarget {
+		t.Fatalf("expected default galaxy_install_location %q, got %q", GalaxyInstallLocationTarget, p.config.GalaxyInstallLocation)
+	}
+
+	var hostP Provisioner
+	config["galaxy_install_location"] = "host"
+	if err := hostP.Prepare(config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var badP Provisioner
+	config["galaxy_install_location"] = "controller"
+	err = badP.Prepare(config)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+	if !strings.Contains(err.Error(), "invalid galaxy_install_location") {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
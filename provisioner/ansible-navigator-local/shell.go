@@ -0,0 +1,250 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf16"
+)
+
+// Shells the commands on the target are written for
+const (
+	TargetShellPOSIX      = "posix"
+	TargetShellPowerShell = "powershell"
+)
+
+// remoteShell builds the commands the provisioner runs on the target. Methods
+// other than Command return script fragments; Command turns a script into the
+// command given to the communicator.
+type remoteShell interface {
+	// Quote returns s as a single word.
+	Quote(s string) string
+	// Run returns a script that runs command with args in dir, with pathPrefix
+	// prepended to PATH and env (KEY=value entries) set. Command and args are
+	// quoted by Run.
+	Run(dir string, pathPrefix []string, env []string, command string, args []string) string
+	// Mkdir returns a script that creates dir and its parents.
+	Mkdir(dir string) string
+	// Remove returns a script that removes p recursively, if it exists.
+	Remove(p string) string
+	// Unpack returns a script that unpacks the tar.gz archive into dir and
+	// removes the archive.
+	Unpack(archive, dir string) string
+	// RemoveFiles returns a script that removes files, relative to dir.
+	RemoveFiles(dir string, files []string) string
+	// FileSizes returns a script that prints, for every path in the file list
+	// (one per line, relative to dir), a line with its size in bytes, or "-"
+	// when it is not a regular file.
+	FileSizes(dir, list string) string
+	// EnvScript returns a script that sets env (KEY=value entries) when loaded
+	// with Source.
+	EnvScript(env []string) string
+	// ScriptName returns name with the extension the shell needs to load it as a script.
+	ScriptName(name string) string
+	// Source returns a script that loads the script file into the running shell.
+	Source(file string) string
+	// Join returns a script that runs scripts in order, stopping at the first failure.
+	Join(scripts ...string) string
+	// Command returns the command that runs script.
+	Command(script string) string
+}
+
+// remoteShell returns the shell of the target: posix unless target_shell is powershell.
+func (c *Config) remoteShell() remoteShell {
+	if c.TargetShell == TargetShellPowerShell {
+		return powerShell{}
+	}
+	return posixShell{}
+}
+
+// detectTargetShell sets target_shell from the communicator type when it is
+// not configured: WinRM targets run PowerShell. It returns whether it did.
+func (c *Config) detectTargetShell(generatedData map[string]interface{}) bool {
+	if c.TargetShell != "" || c.runsOnBuildHost() {
+		return false
+	}
+	if connType, _ := generatedData["ConnType"].(string); connType == "winrm" {
+		c.TargetShell = TargetShellPowerShell
+		return true
+	}
+	return false
+}
+
+// validateTargetShell validates target_shell and rejects options that only
+// work with a POSIX shell on the target.
+func validateTargetShell(c *Config) []error {
+	switch c.TargetShell {
+	case "", TargetShellPOSIX:
+		return nil
+	case TargetShellPowerShell:
+	default:
+		return []error{fmt.Errorf("invalid target_shell %q: must be %q or %q",
+			c.TargetShell, TargetShellPOSIX, TargetShellPowerShell)}
+	}
+
+	var errs []error
+	posixOnly := func(set bool, setting string) {
+		if set {
+			errs = append(errs, fmt.Errorf("%s requires a POSIX shell on the target and cannot be used with target_shell %q",
+				setting, TargetShellPowerShell))
+		}
+	}
+	posixOnly(c.runsOnBuildHost(), "execution_host "+ExecutionHostBuildHost)
+	posixOnly(c.StagingDirectoryMode != "", "staging_directory_mode")
+	posixOnly(c.StagingOwner != "", "staging_owner")
+	posixOnly(c.Bootstrap != nil, "bootstrap")
+	posixOnly(c.DownloadArtifacts != nil, "download_artifacts")
+	posixOnly(isExecutionEnvironmentEnabled(c.NavigatorConfig) && c.NavigatorConfig.ExecutionEnvironment.Verify != nil,
+		"navigator_config.execution_environment.verify")
+	return errs
+}
+
+// posixShell builds POSIX sh commands.
+type posixShell struct{}
+
+func (posixShell) Quote(s string) string { return shellEscapePOSIX(s) }
+
+// quoteAlways single-quotes s even when it needs no quoting.
+func (posixShell) quoteAlways(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+func (sh posixShell) Run(dir string, pathPrefix []string, env []string, command string, args []string) string {
+	var prefix string
+	if assignment := buildPathPrefixForRemoteShell(pathPrefix); assignment != "" {
+		prefix = assignment + " "
+	}
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		prefix += k + "=" + sh.Quote(v) + " "
+	}
+	words := append([]string{sh.Quote(command)}, shellEscapeAllPOSIX(args)...)
+	return fmt.Sprintf("cd %s && %s%s", sh.Quote(dir), prefix, strings.Join(words, " "))
+}
+
+func (sh posixShell) Mkdir(dir string) string { return "mkdir -p " + sh.quoteAlways(dir) }
+
+func (sh posixShell) Remove(p string) string { return "rm -rf " + sh.quoteAlways(p) }
+
+func (sh posixShell) Unpack(archive, dir string) string {
+	return fmt.Sprintf("%s && tar -xzf %s -C %s && rm -f %s",
+		sh.Mkdir(dir), sh.quoteAlways(archive), sh.quoteAlways(dir), sh.quoteAlways(archive))
+}
+
+func (sh posixShell) RemoveFiles(dir string, files []string) string {
+	return fmt.Sprintf("cd %s && rm -f -- %s", sh.Quote(dir), strings.Join(shellEscapeAllPOSIX(files), " "))
+}
+
+func (sh posixShell) FileSizes(dir, list string) string {
+	return fmt.Sprintf(`cd %s && while IFS= read -r f; do if [ -f "$f" ]; then wc -c < "$f"; else echo -; fi; done < %s`,
+		sh.Quote(dir), sh.quoteAlways(list))
+}
+
+func (sh posixShell) EnvScript(env []string) string {
+	var b strings.Builder
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", k, sh.quoteAlways(v))
+	}
+	return b.String()
+}
+
+func (posixShell) ScriptName(name string) string { return name }
+
+func (sh posixShell) Source(file string) string { return ". " + sh.quoteAlways(file) }
+
+func (posixShell) Join(scripts ...string) string { return strings.Join(scripts, " && ") }
+
+func (posixShell) Command(script string) string { return script }
+
+// powerShell builds Windows PowerShell commands. Scripts stop at the first
+// failing cmdlet; native commands are checked through $LASTEXITCODE.
+type powerShell struct{}
+
+func (powerShell) Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (sh powerShell) Run(dir string, pathPrefix []string, env []string, command string, args []string) string {
+	parts := []string{"Set-Location -LiteralPath " + sh.Quote(dir)}
+	if len(pathPrefix) > 0 {
+		parts = append(parts, "$env:PATH = "+sh.Quote(strings.Join(pathPrefix, ";")+";")+" + $env:PATH")
+	}
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		parts = append(parts, "$env:"+k+" = "+sh.Quote(v))
+	}
+	call := "& " + sh.Quote(command)
+	for _, arg := range args {
+		call += " " + sh.Quote(arg)
+	}
+	return strings.Join(append(parts, call, "exit $LASTEXITCODE"), "; ")
+}
+
+func (sh powerShell) Mkdir(dir string) string {
+	return "New-Item -ItemType Directory -Force -Path " + sh.Quote(dir) + " | Out-Null"
+}
+
+func (sh powerShell) Remove(p string) string {
+	quoted := sh.Quote(p)
+	return fmt.Sprintf("if (Test-Path -LiteralPath %[1]s) { Remove-Item -LiteralPath %[1]s -Recurse -Force }", quoted)
+}
+
+// Unpack relies on the tar.exe shipped with Windows 10 1803 and Windows Server 2019 and later.
+func (sh powerShell) Unpack(archive, dir string) string {
+	return sh.Join(
+		sh.Mkdir(dir),
+		fmt.Sprintf("tar -xzf %s -C %s", sh.Quote(archive), sh.Quote(dir)),
+		"if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }",
+		"Remove-Item -LiteralPath "+sh.Quote(archive)+" -Force",
+	)
+}
+
+func (sh powerShell) RemoveFiles(dir string, files []string) string {
+	quoted := make([]string, 0, len(files))
+	for _, f := range files {
+		quoted = append(quoted, sh.Quote(path.Join(dir, f)))
+	}
+	return "Remove-Item -LiteralPath " + strings.Join(quoted, ",") + " -Force -ErrorAction SilentlyContinue"
+}
+
+func (sh powerShell) FileSizes(dir, list string) string {
+	return fmt.Sprintf("Set-Location -LiteralPath %s; Get-Content -LiteralPath %s -Encoding UTF8 | ForEach-Object { "+
+		"if (Test-Path -LiteralPath $_ -PathType Leaf) { (Get-Item -LiteralPath $_).Length } else { '-' } }",
+		sh.Quote(dir), sh.Quote(list))
+}
+
+func (sh powerShell) EnvScript(env []string) string {
+	var b strings.Builder
+	for _, kv := range env {
+		k, v, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "$env:%s = %s\r\n", k, sh.Quote(v))
+	}
+	return b.String()
+}
+
+func (powerShell) ScriptName(name string) string { return name + ".ps1" }
+
+func (sh powerShell) Source(file string) string { return ". " + sh.Quote(file) }
+
+func (powerShell) Join(scripts ...string) string { return strings.Join(scripts, "; ") }
+
+// Command runs script through powershell.exe with -EncodedCommand, so it
+// needs no further quoting whether the communicator starts it from cmd.exe
+// or from PowerShell.
+func (powerShell) Command(script string) string {
+	script = "$ErrorActionPreference = 'Stop'; $ProgressPreference = 'SilentlyContinue'; " + script
+	encoded := utf16.Encode([]rune(script))
+	buf := make([]byte, 0, len(encoded)*2)
+	for _, u := range encoded {
+		buf = append(buf, byte(u), byte(u>>8))
+	}
+	return "powershell.exe -NoProfile -NonInteractive -ExecutionPolicy Bypass -EncodedCommand " +
+		base64.StdEncoding.EncodeToString(buf)
+}
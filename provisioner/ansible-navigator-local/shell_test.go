@@ -0,0 +1,129 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOSIXShell(t *testing.T) {
+	sh := posixShell{}
+	require.Equal(t, `cd /stage && PATH="/opt/bin:$PATH" TOKEN='a b' ansible-galaxy install '-r=/stage/req s.yml'`,
+		sh.Run("/stage", []string{"/opt/bin"}, []string{"TOKEN=a b"}, "ansible-galaxy", []string{"install", "-r=/stage/req s.yml"}))
+	require.Equal(t, "mkdir -p '/stage/it'\"'\"'s'", sh.Mkdir("/stage/it's"))
+	require.Equal(t, "rm -rf '/stage'", sh.Command(sh.Remove("/stage")))
+	require.Equal(t, "mkdir -p '/stage/dir' && tar -xzf '/stage/a.tar.gz' -C '/stage/dir' && rm -f '/stage/a.tar.gz' && cd /stage/dir && rm -f -- old.yml",
+		sh.Join(sh.Unpack("/stage/a.tar.gz", "/stage/dir"), sh.RemoveFiles("/stage/dir", []string{"old.yml"})))
+	require.Equal(t, `cd /stage && while IFS= read -r f; do if [ -f "$f" ]; then wc -c < "$f"; else echo -; fi; done < '/stage/list'`,
+		sh.FileSizes("/stage", "/stage/list"))
+}
+
+func TestPowerShell(t *testing.T) {
+	sh := powerShell{}
+	require.Equal(t, "'it''s'", sh.Quote("it's"))
+	require.Equal(t,
+		`Set-Location -LiteralPath 'C:/stage'; $env:PATH = 'C:/Python/Scripts;C:/tools;' + $env:PATH; `+
+			`$env:ANSIBLE_ROLES_PATH = 'C:/stage/galaxy_roles'; & 'ansible-navigator' 'run' '--extra-vars=@C:/stage/vars.json' 'site.yml'; exit $LASTEXITCODE`,
+		sh.Run("C:/stage", []string{"C:/Python/Scripts", "C:/tools"}, []string{"ANSIBLE_ROLES_PATH=C:/stage/galaxy_roles"},
+			"ansible-navigator", []string{"run", "--extra-vars=@C:/stage/vars.json", "site.yml"}))
+	require.Equal(t, "New-Item -ItemType Directory -Force -Path 'C:/stage' | Out-Null", sh.Mkdir("C:/stage"))
+	require.Equal(t, "if (Test-Path -LiteralPath 'C:/stage') { Remove-Item -LiteralPath 'C:/stage' -Recurse -Force }", sh.Remove("C:/stage"))
+	require.Equal(t, "Remove-Item -LiteralPath 'C:/stage/dir/a.yml','C:/stage/dir/b/c.yml' -Force -ErrorAction SilentlyContinue",
+		sh.RemoveFiles("C:/stage/dir", []string{"a.yml", "b/c.yml"}))
+	require.Equal(t, "Set-Location -LiteralPath 'C:/stage'; Get-Content -LiteralPath 'C:/stage/list' -Encoding UTF8 | ForEach-Object { "+
+		"if (Test-Path -LiteralPath $_ -PathType Leaf) { (Get-Item -LiteralPath $_).Length } else { '-' } }",
+		sh.FileSizes("C:/stage", "C:/stage/list"))
+
+	command := sh.Command(sh.Mkdir("C:/stage"))
+	require.True(t, strings.HasPrefix(command, "powershell.exe -NoProfile -NonInteractive -ExecutionPolicy Bypass -EncodedCommand "))
+	require.Equal(t, "$ErrorActionPreference = 'Stop'; $ProgressPreference = 'SilentlyContinue'; "+sh.Mkdir("C:/stage"),
+		decodePowerShellCommand(t, command))
+}
+
+func TestValidateTargetShell(t *testing.T) {
+	require.Empty(t, validateTargetShell(&Config{}))
+	require.Empty(t, validateTargetShell(&Config{TargetShell: TargetShellPOSIX, StagingOwner: "ansible"}))
+	require.Empty(t, validateTargetShell(&Config{TargetShell: TargetShellPowerShell, StagingDirectory: "C:/packer"}))
+
+	errs := validateTargetShell(&Config{TargetShell: "cmd"})
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), `invalid target_shell "cmd"`)
+
+	errs = validateTargetShell(&Config{
+		TargetShell:       TargetShellPowerShell,
+		StagingOwner:      "ansible",
+		Bootstrap:         &BootstrapConfig{},
+		DownloadArtifacts: &DownloadArtifactsConfig{Destination: "out"},
+	})
+	require.Len(t, errs, 3)
+	require.Contains(t, errs[0].Error(), "staging_owner requires a POSIX shell on the target")
+}
+
+func TestProvisioner_WinRMRunsPowerShell(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+
+	var p Provisioner
+	config := testConfig()
+	config["staging_directory"] = `C:\packer\staging`
+	config["ansible_navigator_path"] = []string{"C:/Python/Scripts"}
+	config["play"] = []map[string]interface{}{{"target": dir + "/site.yml"}}
+	require.NoError(t, p.Prepare(config))
+
+	comm := &communicatorMock{}
+	ui := newMockUi()
+	require.NoError(t, p.Provision(context.Background(), ui, comm, map[string]interface{}{"ConnType": "winrm"}))
+	require.Contains(t, ui.(*mockUi).messageMessages, "WinRM communicator detected; running commands on the target with PowerShell")
+
+	var scripts []string
+	for _, cmd := range comm.startCommand {
+		scripts = append(scripts, decodePowerShellCommand(t, cmd))
+	}
+	require.Contains(t, scripts[0], "New-Item -ItemType Directory -Force -Path 'C:/packer/staging'")
+
+	var run string
+	for _, script := range scripts {
+		if strings.Contains(script, "'ansible-navigator' 'run'") {
+			run = script
+		}
+	}
+	require.Contains(t, run, "Set-Location -LiteralPath 'C:/packer/staging'; $env:PATH = 'C:/Python/Scripts;' + $env:PATH")
+	require.Contains(t, run, "'-i=C:/packer/staging/inventory.ini' 'C:/packer/staging/site.yml'; exit $LASTEXITCODE")
+}
+
+func TestProvisioner_WinRMRejectsPOSIXOnlyOptions(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+
+	var p Provisioner
+	config := testConfig()
+	config["staging_owner"] = "ansible"
+	config["play"] = []map[string]interface{}{{"target": dir + "/site.yml"}}
+	require.NoError(t, p.Prepare(config))
+
+	comm := &communicatorMock{}
+	err := p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"ConnType": "winrm"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "staging_owner requires a POSIX shell on the target")
+	require.Empty(t, comm.startCommand)
+}
+
+// decodePowerShellCommand returns the script of a powerShell.Command command.
+func decodePowerShellCommand(t *testing.T, command string) string {
+	t.Helper()
+	encoded := command[strings.LastIndex(command, " ")+1:]
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,148 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// clean_staging_directory policies
+const (
+	CleanStagingAlways    = "always"
+	CleanStagingOnSuccess = "on_success"
+	CleanStagingNever     = "never"
+)
+
+// sudoIfNotRootShell sets $SUDO to "sudo -n" unless the remote shell runs as root.
+const sudoIfNotRootShell = `if [ "$(id -u)" -eq 0 ]; then SUDO=; else SUDO='sudo -n'; fi; `
+
+var (
+	windowsAbsPathPattern = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+	stagingOwnerPattern   = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*(:[A-Za-z0-9_][A-Za-z0-9_.-]*)?$`)
+)
+
+// normalizeStagingDirectory converts a Windows-style staging_directory
+// (C:\Temp\packer) to forward slashes (C:/Temp/packer) and cleans it.
+func normalizeStagingDirectory(dir string) string {
+	if windowsAbsPathPattern.MatchString(dir) {
+		dir = strings.ReplaceAll(dir, `\`, "/")
+	}
+	return path.Clean(dir)
+}
+
+// validateStagingDirectory validates staging_directory: an absolute POSIX
+// path, or a Windows drive path such as C:\Temp\packer.
+func validateStagingDirectory(dir string) []error {
+	if dir == "" {
+		return nil
+	}
+	if windowsAbsPathPattern.MatchString(dir) {
+		if len(normalizeStagingDirectory(dir)) > len("C:/") {
+			return nil
+		}
+	} else if path.IsAbs(dir) && path.Clean(dir) != "/" {
+		return nil
+	}
+	return []error{fmt.Errorf("staging_directory must be an absolute path on the target other than the root directory (got %q)", dir)}
+}
+
+// validateStaging validates the staging directory options.
+func validateStaging(c *Config) []error {
+	errs := validateStagingDirectory(c.StagingDirectory)
+	if c.StagingDirectoryMode != "" {
+		if mode, err := strconv.ParseUint(c.StagingDirectoryMode, 8, 32); err != nil || mode > 0o7777 {
+			errs = append(errs, fmt.Errorf(
+				"invalid staging_directory_mode: %q (must be an octal mode such as \"0700\")", c.StagingDirectoryMode))
+		}
+	}
+	if c.StagingOwner != "" && !stagingOwnerPattern.MatchString(c.StagingOwner) {
+		errs = append(errs, fmt.Errorf(
+			"invalid staging_owner: %q (must be user or user:group)", c.StagingOwner))
+	}
+	switch c.CleanStagingDirectory {
+	case "", CleanStagingAlways, CleanStagingOnSuccess, CleanStagingNever:
+	default:
+		errs = append(errs, fmt.Errorf("invalid clean_staging_directory: %q (must be %q, %q or %q)",
+			c.CleanStagingDirectory, CleanStagingAlways, CleanStagingOnSuccess, CleanStagingNever))
+	}
+	return errs
+}
+
+// stagingCleanupPolicy returns the clean_staging_directory policy. A
+// staging_directory is kept by default, for later runs in the same build.
+func (c *Config) stagingCleanupPolicy() string {
+	switch {
+	case c.CleanStagingDirectory != "":
+		return c.CleanStagingDirectory
+	case c.StagingDirectory != "":
+		return CleanStagingNever
+	default:
+		return CleanStagingAlways
+	}
+}
+
+// buildCreateStagingDirShell returns the command that creates the staging
+// directory with mode and owner. With an owner it is created with sudo and
+// handed to the owner, so it can live where the connecting user cannot create
+// directories.
+func buildCreateStagingDirShell(dir, mode, owner string) string {
+	quoted := shellEscapePOSIX(dir)
+	sudo := ""
+	command := ""
+	if owner != "" {
+		sudo = "$SUDO "
+		command = sudoIfNotRootShell
+	}
+	command += sudo + "mkdir -p " + quoted
+	if owner != "" {
+		command += " && $SUDO chown " + owner + " " + quoted
+	}
+	if mode != "" {
+		command += " && " + sudo + "chmod " + mode + " " + quoted
+	}
+	return command
+}
+
+func (p *Provisioner) createStagingDir(ui packersdk.Ui, comm packersdk.Communicator) error {
+	if p.config.StagingDirectoryMode == "" && p.config.StagingOwner == "" {
+		return p.createDir(ui, comm, p.stagingDir)
+	}
+	cmd := &packersdk.RemoteCmd{
+		Command: buildCreateStagingDirShell(p.stagingDir, p.config.StagingDirectoryMode, p.config.StagingOwner),
+	}
+	ui.Message(fmt.Sprintf("Creating directory: %s", p.stagingDir))
+	if err := cmd.RunWithUi(context.TODO(), comm, ui); err != nil {
+		return err
+	}
+	if cmd.ExitStatus() != 0 {
+		return fmt.Errorf("Non-zero exit status. See output above for more information.")
+	}
+	return nil
+}
+
+func (p *Provisioner) removeStagingDir(ui packersdk.Ui, comm packersdk.Communicator) error {
+	if p.config.StagingOwner == "" {
+		return p.removeDir(ui, comm, p.stagingDir)
+	}
+	cmd := &packersdk.RemoteCmd{
+		Command: sudoIfNotRootShell + "$SUDO rm -rf " + shellEscapePOSIX(p.stagingDir),
+	}
+	ui.Message(fmt.Sprintf("Removing directory: %s", p.stagingDir))
+	if err := cmd.RunWithUi(context.TODO(), comm, ui); err != nil {
+		return err
+	}
+	if cmd.ExitStatus() != 0 {
+		return fmt.Errorf("Non-zero exit status. See output above for more information.")
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/hashicorp/packer-plugin-sdk/tmp"
+)
+
+// stagingManifestName is the manifest kept in a persistent staging directory.
+const stagingManifestName = ".packer-staging-manifest.json"
+
+// stagingCheckListName is the file list uploaded to check the manifest against the target.
+const stagingCheckListName = ".packer-staging-check"
+
+// stagingManifestEntry identifies the content of a staged file.
+type stagingManifestEntry struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// stagingManifest records the files staged in a persistent staging directory,
+// by slash-separated path relative to it.
+type stagingManifest struct {
+	Files map[string]stagingManifestEntry `json:"files"`
+
+	uploaded int
+	skipped  int
+}
+
+func fileManifestEntry(file string) (stagingManifestEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return stagingManifestEntry{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return stagingManifestEntry{}, err
+	}
+	return stagingManifestEntry{Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// treeManifest returns the manifest entries of the regular files under dir
+// that are not excluded, by slash-separated path relative to dir.
+func treeManifest(dir string, exclude []string) (map[string]stagingManifestEntry, error) {
+	entries := make(map[string]stagingManifestEntry)
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if isExcludedPath(rel, exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		entry, err := fileManifestEntry(file)
+		if err != nil {
+			return err
+		}
+		entries[rel] = entry
+		return nil
+	})
+	return entries, err
+}
+
+// stagedPath returns dst relative to the staging directory when uploads to it
+// are incremental (staging_directory is set and dst is inside it).
+func (p *Provisioner) stagedPath(dst string) (string, bool) {
+	if p.stagingManifest == nil {
+		return "", false
+	}
+	rel := strings.TrimPrefix(dst, p.stagingDir+"/")
+	if rel == dst {
+		return "", false
+	}
+	return rel, true
+}
+
+// loadStagingManifest fetches the manifest of the persistent staging directory.
+// A missing or unreadable manifest starts an empty one, so everything is uploaded.
+func (p *Provisioner) loadStagingManifest(ui packersdk.Ui, comm packersdk.Communicator) {
+	p.stagingManifest = &stagingManifest{Files: map[string]stagingManifestEntry{}}
+
+	var buf bytes.Buffer
+	if err := comm.Download(path.Join(p.stagingDir, stagingManifestName), &buf); err != nil {
+		ui.Message("No staging manifest found; uploading all files")
+		return
+	}
+	var m stagingManifest
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil || m.Files == nil {
+		ui.Message("Ignoring unreadable staging manifest; uploading all files")
+		return
+	}
+	p.stagingManifest.Files = m.Files
+	ui.Message(fmt.Sprintf("Found staging manifest with %d files", len(m.Files)))
+	p.checkStagingManifest(ui, comm)
+}
+
+// checkStagingManifest compares the manifest with the files on the target, since
+// it only records what was uploaded. Entries whose file is missing or has another
+// size are dropped, so those files are uploaded again. When the check cannot run,
+// the whole manifest is dropped.
+func (p *Provisioner) checkStagingManifest(ui packersdk.Ui, comm packersdk.Communicator) {
+	m := p.stagingManifest
+	if len(m.Files) == 0 {
+		return
+	}
+	files := make([]string, 0, len(m.Files))
+	for rel := range m.Files {
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+
+	sizes, err := p.remoteFileSizes(comm, files)
+	if err != nil {
+		ui.Message(fmt.Sprintf("Could not check the staged files on the target (%s); uploading all files", err))
+		m.Files = map[string]stagingManifestEntry{}
+		return
+	}
+	var stale int
+	for i, rel := range files {
+		if sizes[i] != fmt.Sprint(m.Files[rel].Size) {
+			delete(m.Files, rel)
+			stale++
+		}
+	}
+	if stale > 0 {
+		ui.Message(fmt.Sprintf("%d staged files are missing or changed on the target; uploading them again", stale))
+	}
+}
+
+// remoteFileSizes returns the size of each file, relative to the staging
+// directory, on the target, or "-" for files that do not exist.
+func (p *Provisioner) remoteFileSizes(comm packersdk.Communicator, files []string) ([]string, error) {
+	list := path.Join(p.stagingDir, stagingCheckListName)
+	if err := comm.Upload(list, strings.NewReader(strings.Join(files, "\n")+"\n"), nil); err != nil {
+		return nil, err
+	}
+
+	sh := p.config.remoteShell()
+	var stdout bytes.Buffer
+	cmd := &packersdk.RemoteCmd{
+		Command: sh.Command(sh.Join(sh.FileSizes(p.stagingDir, list), sh.RemoveFiles(p.stagingDir, []string{stagingCheckListName}))),
+		Stdout:  &stdout,
+		Stderr:  io.Discard,
+	}
+	if err := comm.Start(context.TODO(), cmd); err != nil {
+		return nil, err
+	}
+	cmd.Wait()
+	if cmd.ExitStatus() != 0 {
+		return nil, fmt.Errorf("exit code %d", cmd.ExitStatus())
+	}
+
+	var sizes []string
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			sizes = append(sizes, line)
+		}
+	}
+	if len(sizes) != len(files) {
+		return nil, fmt.Errorf("expected %d sizes, got %d", len(files), len(sizes))
+	}
+	return sizes, nil
+}
+
+// saveStagingManifest uploads the manifest of the persistent staging directory.
+func (p *Provisioner) saveStagingManifest(ui packersdk.Ui, comm packersdk.Communicator) error {
+	m := p.stagingManifest
+	ui.Message(fmt.Sprintf("Staging sync: uploaded %d files, skipped %d unchanged", m.uploaded, m.skipped))
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return comm.Upload(path.Join(p.stagingDir, stagingManifestName), bytes.NewReader(data), nil)
+}
+
+// treeSync is an upload of a directory tree to the staging directory. When
+// uploads are incremental, only new and changed files are uploaded and files
+// that no longer exist locally are removed.
+type treeSync struct {
+	exclude []string
+	// prefix is the remote directory relative to the staging directory, or
+	// empty when the upload is not incremental.
+	prefix  string
+	local   map[string]stagingManifestEntry
+	upload  map[string]bool
+	removed []string
+}
+
+// newTreeSync compares the tree at localDir with the manifest entries under remoteDir.
+func (p *Provisioner) newTreeSync(localDir, remoteDir string, exclude []string) (*treeSync, error) {
+	s := &treeSync{exclude: exclude}
+	prefix, synced := p.stagedPath(remoteDir)
+	if !synced {
+		return s, nil
+	}
+
+	local, err := treeManifest(localDir, exclude)
+	if err != nil {
+		return nil, err
+	}
+	s.prefix, s.local, s.upload = prefix, local, make(map[string]bool)
+	for rel, entry := range local {
+		if p.stagingManifest.Files[prefix+"/"+rel] != entry {
+			s.upload[rel] = true
+		}
+	}
+	for key := range p.stagingManifest.Files {
+		if rel, ok := strings.CutPrefix(key, prefix+"/"); ok {
+			if _, exists := local[rel]; !exists {
+				s.removed = append(s.removed, rel)
+			}
+		}
+	}
+	sort.Strings(s.removed)
+	return s, nil
+}
+
+// skip is the writeTarGzFunc skip function for the upload.
+func (s *treeSync) skip(rel string, d fs.DirEntry) bool {
+	if isExcludedPath(rel, s.exclude) {
+		return true
+	}
+	return s.prefix != "" && d.Type().IsRegular() && !s.upload[rel]
+}
+
+// unchanged reports whether there is nothing to upload or remove.
+func (s *treeSync) unchanged() bool {
+	return s.prefix != "" && len(s.upload) == 0 && len(s.removed) == 0
+}
+
+// commitTreeSync records a completed upload in the staging manifest.
+func (p *Provisioner) commitTreeSync(s *treeSync) {
+	if s.prefix == "" {
+		return
+	}
+	m := p.stagingManifest
+	for rel := range s.upload {
+		m.Files[s.prefix+"/"+rel] = s.local[rel]
+	}
+	for _, rel := range s.removed {
+		delete(m.Files, s.prefix+"/"+rel)
+	}
+	m.uploaded += len(s.upload)
+	m.skipped += len(s.local) - len(s.upload)
+}
+
+// uploadTree packs localDir, minus excluded paths, into a tarball, uploads it
+// to the staging directory and unpacks it into remoteDir. Unlike
+// Communicator.UploadDir this honours the exclude patterns with every
+// communicator and needs a single upload. With a persistent staging directory
+// only new and changed files are uploaded.
+func (p *Provisioner) uploadTree(ui packersdk.Ui, comm packersdk.Communicator, localDir, remoteDir string, exclude []string) error {
+	sync, err := p.newTreeSync(localDir, remoteDir, exclude)
+	if err != nil {
+		return fmt.Errorf("failed to compare %s with the staging directory: %w", localDir, err)
+	}
+	if sync.unchanged() {
+		ui.Message(fmt.Sprintf("Unchanged, skipping upload: %s", localDir))
+		p.commitTreeSync(sync)
+		return nil
+	}
+
+	tf, err := tmp.File("packer-ansible-upload")
+	if err != nil {
+		return fmt.Errorf("failed to create archive of %s: %w", localDir, err)
+	}
+	defer os.Remove(tf.Name())
+	defer tf.Close()
+
+	if err := writeTarGzFunc(tf, localDir, sync.skip); err != nil {
+		return fmt.Errorf("failed to create archive of %s: %w", localDir, err)
+	}
+	if _, err := tf.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to create archive of %s: %w", localDir, err)
+	}
+
+	remoteArchive := filepath.ToSlash(filepath.Join(p.stagingDir, ".upload-"+path.Base(remoteDir)+".tar.gz"))
+	if err := comm.Upload(remoteArchive, tf, nil); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", localDir, err)
+	}
+
+	sh := p.config.remoteShell()
+	script := sh.Unpack(remoteArchive, remoteDir)
+	if len(sync.removed) > 0 {
+		script = sh.Join(script, sh.RemoveFiles(remoteDir, sync.removed))
+	}
+	cmd := &packersdk.RemoteCmd{Command: sh.Command(script)}
+	if err := cmd.RunWithUi(context.TODO(), comm, ui); err != nil {
+		return err
+	}
+	if cmd.ExitStatus() != 0 {
+		return fmt.Errorf("failed to unpack %s on target: exit code %d", localDir, cmd.ExitStatus())
+	}
+	p.commitTreeSync(sync)
+	return nil
+}
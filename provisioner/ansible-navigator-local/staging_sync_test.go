@@ -0,0 +1,138 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLocalCommunicator is a localCommunicator that records uploads by
+// base name, so provisioning can run against a real staging directory.
+type recordingLocalCommunicator struct {
+	localCommunicator
+	uploads []string
+}
+
+func (c *recordingLocalCommunicator) Upload(dst string, r io.Reader, fi *os.FileInfo) error {
+	c.uploads = append(c.uploads, filepath.Base(dst))
+	return c.localCommunicator.Upload(dst, r, fi)
+}
+
+func TestValidateStagingDirectory(t *testing.T) {
+	require.Empty(t, validateStagingDirectory(""))
+	require.Empty(t, validateStagingDirectory("/var/tmp/packer-ansible"))
+	require.Len(t, validateStagingDirectory("var/tmp"), 1)
+	require.Len(t, validateStagingDirectory("~/staging"), 1)
+	require.Len(t, validateStagingDirectory("/"), 1)
+}
+
+func TestProvisioner_StagingDirectoryIncrementalSync(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml", "roles/web/tasks/main.yml", "group_vars/all.yml")
+	staging := filepath.Join(t.TempDir(), "staging")
+	navigator := filepath.Join(t.TempDir(), "ansible-navigator")
+	require.NoError(t, os.WriteFile(navigator, []byte("#!/bin/sh\nexit 0\n"), 0o755))
+
+	provision := func() []string {
+		t.Helper()
+		var p Provisioner
+		config := testConfig()
+		config["command"] = navigator
+		config["staging_directory"] = staging
+		config["playbook_dir"] = dir
+		config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+		require.NoError(t, p.Prepare(config))
+		require.Equal(t, staging, p.stagingDir)
+
+		comm := &recordingLocalCommunicator{}
+		require.NoError(t, p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+		return comm.uploads
+	}
+
+	uploads := provision()
+	require.Contains(t, uploads, "inventory.ini")
+	require.Contains(t, uploads, ".upload-project.tar.gz")
+	require.Contains(t, uploads, stagingManifestName)
+	require.FileExists(t, filepath.Join(staging, "playbooks", "project", "roles", "web", "tasks", "main.yml"))
+
+	// Nothing changed: the staging directory was kept and nothing is uploaded again.
+	uploads = provision()
+	require.NotContains(t, uploads, "inventory.ini")
+	require.NotContains(t, uploads, ".upload-project.tar.gz")
+
+	// Changed and deleted files are synced.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "roles", "web", "tasks", "main.yml"), []byte("- debug: msg=changed\n"), 0o644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "group_vars", "all.yml")))
+	uploads = provision()
+	require.NotContains(t, uploads, "inventory.ini")
+	require.Contains(t, uploads, ".upload-project.tar.gz")
+
+	data, err := os.ReadFile(filepath.Join(staging, "playbooks", "project", "roles", "web", "tasks", "main.yml"))
+	require.NoError(t, err)
+	require.Equal(t, "- debug: msg=changed\n", string(data))
+	require.NoFileExists(t, filepath.Join(staging, "playbooks", "project", "group_vars", "all.yml"))
+	require.FileExists(t, filepath.Join(staging, "playbooks", "project", "site.yml"))
+}
+
+func TestLoadStagingManifest_Unreadable(t *testing.T) {
+	staging := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(staging, stagingManifestName), []byte("not json"), 0o644))
+
+	p := &Provisioner{stagingDir: staging}
+	ui := newMockUi()
+	p.loadStagingManifest(ui, &localCommunicator{})
+	require.NotNil(t, p.stagingManifest)
+	require.Empty(t, p.stagingManifest.Files)
+	require.Contains(t, ui.(*mockUi).messageMessages, "Ignoring unreadable staging manifest; uploading all files")
+}
+
+func TestProvisioner_StagingManifestCheckedAgainstTarget(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml", "roles/web/tasks/main.yml")
+	staging := filepath.Join(t.TempDir(), "staging")
+	navigator := filepath.Join(t.TempDir(), "ansible-navigator")
+	require.NoError(t, os.WriteFile(navigator, []byte("#!/bin/sh\nexit 0\n"), 0o755))
+
+	provision := func() ([]string, []string) {
+		t.Helper()
+		var p Provisioner
+		config := testConfig()
+		config["command"] = navigator
+		config["staging_directory"] = staging
+		config["playbook_dir"] = dir
+		config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+		require.NoError(t, p.Prepare(config))
+
+		ui := newMockUi()
+		comm := &recordingLocalCommunicator{}
+		require.NoError(t, p.Provision(context.Background(), ui, comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+		return comm.uploads, ui.(*mockUi).messageMessages
+	}
+
+	_, messages := provision()
+	require.Contains(t, strings.Join(messages, "\n"), `[Warning] staging_directory `+staging+`, its files and `+stagingManifestName+
+		` are kept on the target and end up in the image. Set clean_staging_directory = "always"`)
+
+	// Files removed or changed on the target behind the manifest's back are uploaded again.
+	task := filepath.Join(staging, "playbooks", "project", "roles", "web", "tasks", "main.yml")
+	require.NoError(t, os.Remove(task))
+	require.NoError(t, os.WriteFile(filepath.Join(staging, "inventory.ini"), nil, 0o644))
+
+	uploads, messages := provision()
+	require.Contains(t, messages, "2 staged files are missing or changed on the target; uploading them again")
+	require.Contains(t, uploads, "inventory.ini")
+	require.Contains(t, uploads, ".upload-project.tar.gz")
+	require.FileExists(t, task)
+	require.NoFileExists(t, filepath.Join(staging, stagingCheckListName))
+
+	_, messages = provision()
+	require.NotContains(t, strings.Join(messages, "\n"), "missing or changed on the target")
+}
@@ -0,0 +1,126 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStaging(t *testing.T) {
+	require.Empty(t, validateStaging(&Config{}))
+	require.Empty(t, validateStaging(&Config{
+		StagingDirectory:      `C:\Temp\packer`,
+		StagingDirectoryMode:  "0750",
+		StagingOwner:          "ansible:wheel",
+		CleanStagingDirectory: CleanStagingOnSuccess,
+	}))
+
+	tests := []struct {
+		name   string
+		config *Config
+		errMsg string
+	}{
+		{"windows drive root", &Config{StagingDirectory: `C:\`}, "staging_directory must be an absolute path"},
+		{"windows relative", &Config{StagingDirectory: `C:Temp`}, "staging_directory must be an absolute path"},
+		{"mode not octal", &Config{StagingDirectoryMode: "0789"}, "invalid staging_directory_mode"},
+		{"mode too large", &Config{StagingDirectoryMode: "17777"}, "invalid staging_directory_mode"},
+		{"owner with shell characters", &Config{StagingOwner: "root; id"}, "invalid staging_owner"},
+		{"unknown clean policy", &Config{CleanStagingDirectory: "sometimes"}, "invalid clean_staging_directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateStaging(tt.config)
+			require.Len(t, errs, 1)
+			require.Contains(t, errs[0].Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestStagingCleanupPolicy(t *testing.T) {
+	require.Equal(t, CleanStagingAlways, (&Config{}).stagingCleanupPolicy())
+	require.Equal(t, CleanStagingNever, (&Config{StagingDirectory: "/var/tmp/packer"}).stagingCleanupPolicy())
+	require.Equal(t, CleanStagingOnSuccess, (&Config{
+		StagingDirectory: "/var/tmp/packer", CleanStagingDirectory: CleanStagingOnSuccess,
+	}).stagingCleanupPolicy())
+}
+
+func TestBuildCreateStagingDirShell(t *testing.T) {
+	require.Equal(t, "mkdir -p /var/tmp/packer && chmod 0700 /var/tmp/packer",
+		buildCreateStagingDirShell("/var/tmp/packer", "0700", ""))
+	require.Equal(t, sudoIfNotRootShell+"$SUDO mkdir -p /opt/packer && $SUDO chown ansible:ansible /opt/packer && $SUDO chmod 0750 /opt/packer",
+		buildCreateStagingDirShell("/opt/packer", "0750", "ansible:ansible"))
+}
+
+func TestProvisioner_StagingDirectoryWindowsPath(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["staging_directory"] = `C:\Temp\packer\`
+	config["play"] = []map[string]interface{}{{"target": "web"}}
+	require.NoError(t, p.Prepare(config))
+	require.Equal(t, "C:/Temp/packer", p.stagingDir)
+	require.Equal(t, "C:/Temp/packer/galaxy_roles", p.galaxyRolesPath)
+}
+
+func TestProvisioner_StagingOwnerAndMode(t *testing.T) {
+	var p Provisioner
+	config := testConfig()
+	config["staging_directory_mode"] = "0700"
+	config["staging_owner"] = "ansible"
+	config["play"] = []map[string]interface{}{{"target": "web"}}
+	require.NoError(t, p.Prepare(config))
+
+	comm := &communicatorMock{}
+	require.NoError(t, p.Provision(context.Background(), newMockUi(), comm, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+	require.Equal(t, buildCreateStagingDirShell(p.stagingDir, "0700", "ansible"), comm.startCommand[0])
+	require.Equal(t, sudoIfNotRootShell+"$SUDO rm -rf "+p.stagingDir, comm.startCommand[len(comm.startCommand)-1])
+}
+
+func TestProvisioner_CleanStagingDirectory(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+
+	provision := func(policy string, fail bool) string {
+		t.Helper()
+		staging := filepath.Join(t.TempDir(), "staging")
+		navigator := filepath.Join(t.TempDir(), "ansible-navigator")
+		script := "#!/bin/sh\nexit 0\n"
+		if fail {
+			script = "#!/bin/sh\ncase \"$1\" in run) exit 1 ;; esac\nexit 0\n"
+		}
+		require.NoError(t, os.WriteFile(navigator, []byte(script), 0o755))
+
+		var p Provisioner
+		config := testConfig()
+		config["command"] = navigator
+		config["staging_directory"] = staging
+		config["clean_staging_directory"] = policy
+		config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+		require.NoError(t, p.Prepare(config))
+
+		err := p.Provision(context.Background(), newMockUi(), &localCommunicator{}, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"})
+		if fail {
+			require.Error(t, err)
+		} else {
+			require.NoError(t, err)
+		}
+		return staging
+	}
+
+	require.NoDirExists(t, provision(CleanStagingAlways, false))
+	require.NoDirExists(t, provision(CleanStagingAlways, true))
+	require.NoDirExists(t, provision(CleanStagingOnSuccess, false))
+
+	// Kept after a failure, with the manifest for the next run.
+	staging := provision(CleanStagingOnSuccess, true)
+	require.DirExists(t, staging)
+	require.FileExists(t, filepath.Join(staging, stagingManifestName))
+
+	require.DirExists(t, provision(CleanStagingNever, false))
+}
@@ -0,0 +1,303 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+var (
+	navigatorVersionPattern = regexp.MustCompile(`ansible-navigator\s+v?(\d+(?:\.\d+)+)`)
+	ansibleVersionPattern   = regexp.MustCompile(`ansible (?:\[core )?(\d+(?:\.\d+)+)`)
+)
+
+const (
+	// minNavigatorVersion is the oldest ansible-navigator release the
+	// provisioner runs, with the ansible-navigator settings file format it writes.
+	minNavigatorVersion = "1.0.0"
+	// minAnsibleVersion is the oldest ansible-core that runs plays without an
+	// execution environment.
+	minAnsibleVersion = "2.11.0"
+	// navigatorLongEEFlagsVersion is the first ansible-navigator release whose
+	// execution environment flags are documented with their long names. Older
+	// releases get the short names, which every release accepts.
+	navigatorLongEEFlagsVersion = "2.0.0"
+)
+
+// navigatorShortFlags maps execution environment flags to their short names.
+var navigatorShortFlags = map[string]string{
+	"--execution-environment":                  "--ee",
+	"--execution-environment-image":            "--eei",
+	"--execution-environment-container-engine": "--ce",
+}
+
+// navigatorFlagVersions lists navigator_config flags added after
+// minNavigatorVersion, with the release that added them.
+var navigatorFlagVersions = []struct {
+	flag, setting, version string
+}{
+	{"--enable-prompts", "navigator_config.enable_prompts", "2.0.0"},
+	{"--time-zone", "navigator_config.time_zone", "2.0.0"},
+	{"--ansible-runner-timeout", "navigator_config.ansible_runner.timeout", "2.0.0"},
+	{"--ansible-runner-write-job-events", "navigator_config.ansible_runner.job_events", "2.2.0"},
+}
+
+// targetCapabilities is what the version probe found on the target.
+type targetCapabilities struct {
+	// NavigatorVersion is empty when the version could not be parsed.
+	NavigatorVersion string
+	// AnsibleVersion is the ansible-core version, empty when ansible is not
+	// available or its version could not be parsed.
+	AnsibleVersion string
+	// ContainerEngine is "podman" or "docker", empty when neither is available.
+	ContainerEngine string
+}
+
+// navigatorCLIFlags returns the flags that make ansible-navigator run on the
+// target as found. ansible-navigator enables execution environments by default,
+// so without a container engine they are disabled unless configured.
+func (t *targetCapabilities) navigatorCLIFlags(config *NavigatorConfig) []string {
+	if t.ContainerEngine == "" && (config == nil || config.ExecutionEnvironment == nil) {
+		return []string{t.flagName("--execution-environment") + "=false"}
+	}
+	return nil
+}
+
+// flagName returns the name of an ansible-navigator flag that the version on
+// the target accepts. With t nil (skip_version_check) or an unknown version,
+// the name is returned unchanged.
+func (t *targetCapabilities) flagName(name string) string {
+	if t == nil || t.NavigatorVersion == "" || versionAtLeast(t.NavigatorVersion, navigatorLongEEFlagsVersion) {
+		return name
+	}
+	if short, ok := navigatorShortFlags[name]; ok {
+		return short
+	}
+	return name
+}
+
+// check returns an actionable error when the versions found cannot run the
+// configuration. Versions that could not be parsed are not checked.
+func (t *targetCapabilities) check(config *NavigatorConfig) error {
+	const bypass = "Use 'skip_version_check = true' to bypass this check."
+	if t.NavigatorVersion != "" {
+		if !versionAtLeast(t.NavigatorVersion, minNavigatorVersion) {
+			return fmt.Errorf(
+				"ansible-navigator %s on the target is older than %s, the oldest release this provisioner supports.\n\n"+
+					"Upgrade ansible-navigator on the target (for example with 'bootstrap') or set 'command' to a newer installation. %s",
+				t.NavigatorVersion, minNavigatorVersion, bypass)
+		}
+		var unsupported []string
+		for _, flag := range buildNavigatorCLIFlags(config, nil) {
+			for _, f := range navigatorFlagVersions {
+				if strings.HasPrefix(flag, f.flag+"=") && !versionAtLeast(t.NavigatorVersion, f.version) {
+					unsupported = append(unsupported, fmt.Sprintf("%s (%s, needs %s)", f.setting, f.flag, f.version))
+				}
+			}
+		}
+		if len(unsupported) > 0 {
+			return fmt.Errorf(
+				"ansible-navigator %s on the target does not support these settings:\n  %s\n\n"+
+					"Upgrade ansible-navigator on the target or remove the settings. %s",
+				t.NavigatorVersion, strings.Join(unsupported, "\n  "), bypass)
+		}
+	}
+
+	eeConfigured := config != nil && config.ExecutionEnvironment != nil
+	runsInEE := isExecutionEnvironmentEnabled(config) || (!eeConfigured && t.ContainerEngine != "")
+	if !runsInEE && t.AnsibleVersion != "" && !versionAtLeast(t.AnsibleVersion, minAnsibleVersion) {
+		return fmt.Errorf(
+			"ansible-core %s on the target is older than %s, which ansible-navigator needs to run plays without an execution environment.\n\n"+
+				"Upgrade ansible-core on the target, or enable navigator_config.execution_environment to run the plays in a container. %s",
+			t.AnsibleVersion, minAnsibleVersion, bypass)
+	}
+	return nil
+}
+
+// versionAtLeast reports whether the dotted numeric version is minimum or newer.
+// Missing components count as 0.
+func versionAtLeast(version, minimum string) bool {
+	v, m := strings.Split(version, "."), strings.Split(minimum, ".")
+	for i := 0; i < len(v) || i < len(m); i++ {
+		var a, b int
+		if i < len(v) {
+			a, _ = strconv.Atoi(v[i])
+		}
+		if i < len(m) {
+			b, _ = strconv.Atoi(m[i])
+		}
+		if a != b {
+			return a > b
+		}
+	}
+	return true
+}
+
+// parseNavigatorVersion returns the version in `ansible-navigator --version` output.
+func parseNavigatorVersion(output string) string {
+	if m := navigatorVersionPattern.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// parseAnsibleVersion returns the ansible-core version in `ansible --version`
+// output: "ansible [core 2.16.3]", or "ansible 2.9.27" for Ansible 2.9.
+func parseAnsibleVersion(output string) string {
+	if m := ansibleVersionPattern.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// probeCommand runs command with args on the target in the staging directory,
+// with ansible_navigator_path prepended to PATH, and returns its output and
+// exit status.
+func (p *Provisioner) probeCommand(ctx context.Context, comm packersdk.Communicator, command string, args ...string) (string, int, error) {
+	sh := p.config.remoteShell()
+	var stdout, stderr bytes.Buffer
+	cmd := &packersdk.RemoteCmd{
+		Command: sh.Command(sh.Run(p.stagingDir, p.config.AnsibleNavigatorPath, nil, command, args)),
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	}
+	if err := comm.Start(ctx, cmd); err != nil {
+		return "", 0, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		if ctx.Err() != nil {
+			return "", 0, ctx.Err()
+		}
+		return strings.TrimSpace(stdout.String() + "\n" + stderr.String()), cmd.ExitStatus(), nil
+	case <-ctx.Done():
+		return "", 0, ctx.Err()
+	}
+}
+
+// containerEngineCandidates returns the container engines to look for: the
+// configured one, or podman and docker in ansible-navigator's "auto" order.
+func containerEngineCandidates(config *NavigatorConfig) []string {
+	if isExecutionEnvironmentEnabled(config) {
+		if engine := config.ExecutionEnvironment.ContainerEngine; engine != "" && engine != "auto" {
+			return []string{engine}
+		}
+	}
+	return []string{"podman", "docker"}
+}
+
+// probeTarget runs `ansible-navigator --version` and `ansible --version` on the
+// target and looks for a container engine, within version_check_timeout.
+// Only a missing or hanging ansible-navigator is an error.
+func (p *Provisioner) probeTarget(ui packersdk.Ui, comm packersdk.Communicator) (*targetCapabilities, error) {
+	ui.Message("Checking ansible-navigator on the target...")
+
+	// Safe: defaulted in Prepare and validated
+	timeout, _ := time.ParseDuration(*p.config.VersionCheckTimeout)
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	timedOut := func(err error) error {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		return fmt.Errorf(
+			"ansible-navigator version check on the target timed out after %s.\n"+
+				"This may indicate ansible-navigator is not properly installed or configured on the target.\n\n"+
+				"Solutions:\n"+
+				"  1. Use 'command' with the full path to ansible-navigator on the target\n"+
+				"  2. Use 'ansible_navigator_path' to add directories to PATH\n"+
+				"  3. Increase 'version_check_timeout' (current: %s)\n"+
+				"  4. Use 'skip_version_check = true' to bypass the check",
+			*p.config.VersionCheckTimeout, *p.config.VersionCheckTimeout)
+	}
+
+	caps := &targetCapabilities{}
+	out, status, err := p.probeCommand(ctx, comm, p.config.Command, "--version")
+	if err != nil {
+		return nil, timedOut(err)
+	}
+	if status != 0 {
+		return nil, fmt.Errorf(
+			"%s not found on the target or failed to run (exit code %d):\n%s\n\n"+
+				"Install ansible-navigator on the target (for example with 'bootstrap'), set 'command' to its full path "+
+				"or add its directory with 'ansible_navigator_path'. Use 'skip_version_check = true' to bypass this check.",
+			p.config.Command, status, out)
+	}
+	if caps.NavigatorVersion = parseNavigatorVersion(out); caps.NavigatorVersion == "" {
+		ui.Message(fmt.Sprintf("[Warning] Could not find the ansible-navigator version in %s --version output", p.config.Command))
+	}
+
+	out, status, err = p.probeCommand(ctx, comm, "ansible", "--version")
+	if err != nil {
+		return nil, timedOut(err)
+	}
+	if status == 0 {
+		caps.AnsibleVersion = parseAnsibleVersion(out)
+	}
+
+	for _, engine := range containerEngineCandidates(p.config.NavigatorConfig) {
+		_, status, err := p.probeCommand(ctx, comm, engine, "--version")
+		if err != nil {
+			return nil, timedOut(err)
+		}
+		if status == 0 {
+			caps.ContainerEngine = engine
+			break
+		}
+	}
+
+	ui.Message(fmt.Sprintf("Found on the target: ansible-navigator %s, ansible-core %s, container engine %s",
+		valueOr(caps.NavigatorVersion, "(unknown version)"), valueOr(caps.AnsibleVersion, "not found"),
+		valueOr(caps.ContainerEngine, "not found")))
+
+	if err := caps.check(p.config.NavigatorConfig); err != nil {
+		return nil, err
+	}
+
+	flags := caps.navigatorCLIFlags(p.config.NavigatorConfig)
+	eeEnabled := isExecutionEnvironmentEnabled(p.config.NavigatorConfig)
+	eeConfigured := p.config.NavigatorConfig != nil && p.config.NavigatorConfig.ExecutionEnvironment != nil
+	switch {
+	case eeEnabled && caps.ContainerEngine == "":
+		ui.Message(fmt.Sprintf("[Warning] execution_environment is enabled but %s was not found on the target; "+
+			"install a container engine or set execution_environment.enabled = false",
+			strings.Join(containerEngineCandidates(p.config.NavigatorConfig), " or ")))
+	case (eeConfigured || len(flags) > 0) && !eeEnabled && caps.AnsibleVersion == "":
+		ui.Message("[Warning] ansible was not found on the target; without an execution environment " +
+			"ansible-navigator runs the ansible on the target's PATH")
+	}
+	if len(flags) > 0 {
+		ui.Message(fmt.Sprintf("No container engine on the target; running ansible-navigator with %s",
+			strings.Join(flags, " ")))
+	}
+	return caps, nil
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
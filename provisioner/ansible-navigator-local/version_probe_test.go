@@ -0,0 +1,183 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigatorlocal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersions(t *testing.T) {
+	require.Equal(t, "24.2.0", parseNavigatorVersion("ansible-navigator 24.2.0\n"))
+	require.Equal(t, "2.3.0", parseNavigatorVersion("WARNING: something\nansible-navigator 2.3.0"))
+	require.Empty(t, parseNavigatorVersion("command not found"))
+
+	require.Equal(t, "2.16.3", parseAnsibleVersion("ansible [core 2.16.3]\n  config file = None\n"))
+	require.Equal(t, "2.9.27", parseAnsibleVersion("ansible 2.9.27\n"))
+	require.Empty(t, parseAnsibleVersion(""))
+}
+
+func TestTargetCapabilities_NavigatorCLIFlags(t *testing.T) {
+	none := &targetCapabilities{}
+	require.Equal(t, []string{"--execution-environment=false"}, none.navigatorCLIFlags(nil))
+	require.Equal(t, []string{"--execution-environment=false"}, none.navigatorCLIFlags(&NavigatorConfig{Mode: "stdout"}))
+	// A configured execution environment is left to buildNavigatorCLIFlags.
+	require.Empty(t, none.navigatorCLIFlags(&NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: true}}))
+	require.Empty(t, (&targetCapabilities{ContainerEngine: "podman"}).navigatorCLIFlags(nil))
+	// Releases before 2.0 get the short names.
+	require.Equal(t, []string{"--ee=false"}, (&targetCapabilities{NavigatorVersion: "1.1.0"}).navigatorCLIFlags(nil))
+}
+
+func TestBuildNavigatorCLIFlags_TargetVersion(t *testing.T) {
+	config := &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{
+		Enabled: true, Image: "quay.io/ansible/creator-ee:latest", ContainerEngine: "podman",
+	}}
+	long := []string{"--execution-environment-image=quay.io/ansible/creator-ee:latest", "--execution-environment-container-engine=podman"}
+	require.Equal(t, long, buildNavigatorCLIFlags(config, nil))
+	require.Equal(t, long, buildNavigatorCLIFlags(config, &targetCapabilities{NavigatorVersion: "24.2.0"}))
+	require.Equal(t, long, buildNavigatorCLIFlags(config, &targetCapabilities{}))
+	require.Equal(t, []string{"--eei=quay.io/ansible/creator-ee:latest", "--ce=podman"},
+		buildNavigatorCLIFlags(config, &targetCapabilities{NavigatorVersion: "1.1.0"}))
+
+	disabled := &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: false}}
+	require.Equal(t, []string{"--ee=false"}, buildNavigatorCLIFlags(disabled, &targetCapabilities{NavigatorVersion: "1.1.0"}))
+}
+
+func TestTargetCapabilities_Check(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name   string
+		caps   targetCapabilities
+		config *NavigatorConfig
+		errMsg string
+	}{
+		{name: "current", caps: targetCapabilities{NavigatorVersion: "24.2.0", AnsibleVersion: "2.16.3"},
+			config: &NavigatorConfig{TimeZone: "UTC", EnablePrompts: &enabled}},
+		{name: "unknown versions", caps: targetCapabilities{}, config: &NavigatorConfig{TimeZone: "UTC"}},
+		{name: "navigator too old", caps: targetCapabilities{NavigatorVersion: "0.9.0"},
+			errMsg: "ansible-navigator 0.9.0 on the target is older than 1.0.0"},
+		{name: "setting too new", caps: targetCapabilities{NavigatorVersion: "2.1.0", AnsibleVersion: "2.14.0"},
+			config: &NavigatorConfig{TimeZone: "UTC", AnsibleRunner: &AnsibleRunnerConfig{JobEvents: &enabled}},
+			errMsg: "navigator_config.ansible_runner.job_events (--ansible-runner-write-job-events, needs 2.2.0)"},
+		{name: "settings too new", caps: targetCapabilities{NavigatorVersion: "1.1.0"},
+			config: &NavigatorConfig{TimeZone: "UTC"},
+			errMsg: "navigator_config.time_zone (--time-zone, needs 2.0.0)"},
+		{name: "ansible too old without EE", caps: targetCapabilities{NavigatorVersion: "24.2.0", AnsibleVersion: "2.9.27"},
+			errMsg: "ansible-core 2.9.27 on the target is older than 2.11.0"},
+		{name: "ansible too old with EE", caps: targetCapabilities{NavigatorVersion: "24.2.0", AnsibleVersion: "2.9.27", ContainerEngine: "podman"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.caps.check(tt.config)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.errMsg)
+			require.Contains(t, err.Error(), "skip_version_check = true")
+		})
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	require.True(t, versionAtLeast("2.0.0", "2.0.0"))
+	require.True(t, versionAtLeast("2.10", "2.9.0"))
+	require.True(t, versionAtLeast("24.2.0", "2.2.0"))
+	require.False(t, versionAtLeast("1.1.0", "2.0.0"))
+	require.False(t, versionAtLeast("2.9.27", "2.11.0"))
+}
+
+// writeTargetTools writes fake commands into a directory used as ansible_navigator_path.
+func writeTargetTools(t *testing.T, tools map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, script := range tools {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	}
+	return dir
+}
+
+func TestProvisioner_ProbeTarget(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+	argsFile := filepath.Join(t.TempDir(), "args")
+	tools := writeTargetTools(t, map[string]string{
+		"ansible-navigator": `[ "$1" = --version ] && { echo "ansible-navigator 24.2.0"; exit 0; }
+echo "$@" > ` + argsFile,
+		"ansible": `echo "ansible [core 2.16.3]"`,
+		"podman":  "exit 127",
+		"docker":  "exit 127",
+	})
+
+	var p Provisioner
+	config := testConfig()
+	config["ansible_navigator_path"] = []string{tools}
+	config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+	require.NoError(t, p.Prepare(config))
+
+	ui := newMockUi()
+	require.NoError(t, p.Provision(context.Background(), ui, &localCommunicator{}, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"}))
+	require.Equal(t, &targetCapabilities{NavigatorVersion: "24.2.0", AnsibleVersion: "2.16.3"}, p.capabilities)
+	require.Contains(t, ui.(*mockUi).messageMessages,
+		"Found on the target: ansible-navigator 24.2.0, ansible-core 2.16.3, container engine not found")
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	require.Contains(t, string(args), "run --execution-environment=false")
+}
+
+func TestProvisioner_ProbeTarget_Errors(t *testing.T) {
+	dir := writeProject(t, "project", "site.yml")
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		errMsg string
+	}{
+		{
+			name:   "navigator missing",
+			config: map[string]interface{}{"command": "/nonexistent/ansible-navigator"},
+			errMsg: "/nonexistent/ansible-navigator not found on the target or failed to run (exit code 127)",
+		},
+		{
+			name: "navigator hangs",
+			config: map[string]interface{}{
+				"ansible_navigator_path": []string{writeTargetTools(t, map[string]string{"ansible-navigator": "exec sleep 5"})},
+				"version_check_timeout":  "200ms",
+			},
+			errMsg: "version check on the target timed out after 200ms",
+		},
+		{
+			name: "navigator too old",
+			config: map[string]interface{}{
+				"ansible_navigator_path": []string{writeTargetTools(t, map[string]string{
+					"ansible-navigator": `echo "ansible-navigator 0.9.0"`,
+				})},
+			},
+			errMsg: "ansible-navigator 0.9.0 on the target is older than 1.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Provisioner
+			config := testConfig()
+			for k, v := range tt.config {
+				config[k] = v
+			}
+			config["play"] = []map[string]interface{}{{"target": filepath.Join(dir, "site.yml")}}
+			require.NoError(t, p.Prepare(config))
+
+			err := p.Provision(context.Background(), newMockUi(), &localCommunicator{}, map[string]interface{}{"PackerHTTPAddr": "127.0.0.1"})
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.errMsg)
+			require.Contains(t, err.Error(), "skip_version_check = true")
+		})
+	}
+}
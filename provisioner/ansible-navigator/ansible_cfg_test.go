@@ -0,0 +1,292 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAnsibleCfgContent_Sections(t *testing.T) {
+	content, err := generateAnsibleCfgContent(&AnsibleConfig{
+		Defaults: &AnsibleConfigDefaults{RemoteTmp: "/tmp/.ansible/tmp"},
+		Sections: []AnsibleConfigSection{
+			{Name: "privilege_escalation", Options: map[string]string{"become": "True"}},
+			{Name: "defaults", Options: map[string]string{
+				"interpreter_python": "auto_silent",
+				"forks":              "20",
+				"log_path":           "/tmp/ansible-%Y.log",
+			}},
+			{Name: "colors", Options: map[string]string{"ok": "bright green"}},
+			{Name: "defaults", Options: map[string]string{"callbacks_enabled": "ansible.posix.profile_tasks"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("generateAnsibleCfgContent failed: %v", err)
+	}
+
+	expected := `[defaults]
+remote_tmp = /tmp/.ansible/tmp
+host_key_checking = False
+callbacks_enabled = ansible.posix.profile_tasks
+forks = 20
+interpreter_python = auto_silent
+log_path = /tmp/ansible-%%Y.log
+
+[colors]
+ok = bright green
+
+[privilege_escalation]
+become = True
+
+`
+	if content != expected {
+		t.Fatalf("unexpected ansible.cfg content:\n%s\nexpected:\n%s", content, expected)
+	}
+}
+
+func TestGenerateAnsibleCfgContent_SectionsOnly(t *testing.T) {
+	ac := &AnsibleConfig{Sections: []AnsibleConfigSection{
+		{Name: "persistent_connection", Options: map[string]string{"command_timeout": "60"}},
+	}}
+	if !needsGeneratedAnsibleCfg(ac) {
+		t.Fatalf("expected section blocks to require a generated ansible.cfg")
+	}
+	content, err := generateAnsibleCfgContent(ac)
+	if err != nil {
+		t.Fatalf("generateAnsibleCfgContent failed: %v", err)
+	}
+	if content != "[persistent_connection]\ncommand_timeout = 60\n\n" {
+		t.Fatalf("unexpected ansible.cfg content: %q", content)
+	}
+}
+
+func TestGenerateAnsibleCfgContent_SectionOverridesUnsetTypedBool(t *testing.T) {
+	content, err := generateAnsibleCfgContent(&AnsibleConfig{
+		Defaults:      &AnsibleConfigDefaults{},
+		SSHConnection: &AnsibleConfigConnection{},
+		Sections: []AnsibleConfigSection{
+			{Name: "defaults", Options: map[string]string{"Host_Key_Checking": "True"}},
+			{Name: "ssh_connection", Options: map[string]string{"pipelining": "True"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("generateAnsibleCfgContent failed: %v", err)
+	}
+	if strings.Contains(content, "host_key_checking = False") || strings.Contains(content, "pipelining = False") {
+		t.Fatalf("expected section options to replace unset typed booleans, got: %q", content)
+	}
+	if !strings.Contains(content, "Host_Key_Checking = True") || !strings.Contains(content, "pipelining = True") {
+		t.Fatalf("expected section options in generated ansible.cfg, got: %q", content)
+	}
+}
+
+func TestValidateAnsibleCfgSections(t *testing.T) {
+	cases := map[string]struct {
+		config *AnsibleConfig
+		want   string
+	}{
+		"typed conflict": {
+			config: &AnsibleConfig{
+				Defaults: &AnsibleConfigDefaults{RemoteTmp: "/tmp/a"},
+				Sections: []AnsibleConfigSection{{Name: "defaults", Options: map[string]string{"REMOTE_TMP": "/tmp/b"}}},
+			},
+			want: "ansible.cfg option defaults.REMOTE_TMP conflicts with navigator_config.ansible_config.defaults.remote_tmp",
+		},
+		"typed true bool conflict": {
+			config: &AnsibleConfig{
+				SSHConnection: &AnsibleConfigConnection{Pipelining: true},
+				Sections:      []AnsibleConfigSection{{Name: "ssh_connection", Options: map[string]string{"pipelining": "False"}}},
+			},
+			want: "conflicts with navigator_config.ansible_config.ssh_connection.pipelining",
+		},
+		"duplicate across blocks": {
+			config: &AnsibleConfig{Sections: []AnsibleConfigSection{
+				{Name: "defaults", Options: map[string]string{"forks": "5"}},
+				{Name: "defaults", Options: map[string]string{"Forks": "10"}},
+			}},
+			want: "navigator_config.ansible_config.section[1].options: ansible.cfg option defaults.Forks is set more than once",
+		},
+		"empty name": {
+			config: &AnsibleConfig{Sections: []AnsibleConfigSection{{Options: map[string]string{"forks": "5"}}}},
+			want:   "navigator_config.ansible_config.section[0].name must not be empty",
+		},
+		"bracketed name": {
+			config: &AnsibleConfig{Sections: []AnsibleConfigSection{{Name: "[defaults]"}}},
+			want:   "must not contain brackets",
+		},
+		"invalid option name": {
+			config: &AnsibleConfig{Sections: []AnsibleConfigSection{{Name: "defaults", Options: map[string]string{"forks = 5": ""}}}},
+			want:   `invalid ansible.cfg option name "forks = 5"`,
+		},
+		"multi-line value": {
+			config: &AnsibleConfig{Sections: []AnsibleConfigSection{{Name: "defaults", Options: map[string]string{"forks": "5\n[evil]"}}}},
+			want:   "value of defaults.forks must not contain line breaks",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			errs := validateAnsibleCfgSections(tc.config)
+			if len(errs) != 1 || !strings.Contains(errs[0].Error(), tc.want) {
+				t.Fatalf("expected a single error containing %q, got: %v", tc.want, errs)
+			}
+			if _, err := generateAnsibleCfgContent(tc.config); err == nil {
+				t.Fatalf("expected generateAnsibleCfgContent to fail")
+			}
+		})
+	}
+}
+
+func TestApplyAutomaticEEDefaults_KeepsSectionTempDirs(t *testing.T) {
+	config := &NavigatorConfig{
+		ExecutionEnvironment: &ExecutionEnvironment{Enabled: boolPtr(true)},
+		AnsibleConfig: &AnsibleConfig{Sections: []AnsibleConfigSection{
+			{Name: "defaults", Options: map[string]string{"remote_tmp": "/var/tmp/ansible"}},
+		}},
+	}
+	applyAutomaticEEDefaults(config, "", "")
+
+	if config.AnsibleConfig.Defaults.RemoteTmp != "" {
+		t.Fatalf("expected remote_tmp from the section block to be kept, got typed %q", config.AnsibleConfig.Defaults.RemoteTmp)
+	}
+	if config.AnsibleConfig.Defaults.LocalTmp != "/tmp/.ansible-local" {
+		t.Fatalf("expected local_tmp default, got %q", config.AnsibleConfig.Defaults.LocalTmp)
+	}
+	if errs := validateAnsibleCfgSections(config.AnsibleConfig); len(errs) > 0 {
+		t.Fatalf("expected no conflicts after EE defaults, got: %v", errs)
+	}
+}
+
+func TestParseAnsibleCfg(t *testing.T) {
+	sections, err := parseAnsibleCfg(`# site defaults
+[defaults]
+inventory = hosts
+callbacks_enabled = timer,
+    profile_tasks
+; pick the remote user
+remote_user: deploy
+inventory = inventory.ini
+
+[galaxy]
+server_list = release
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := renderAnsibleCfg(sections)
+	want := "[defaults]\ninventory = inventory.ini\ncallbacks_enabled = timer,\n    profile_tasks\nremote_user = deploy\n\n[galaxy]\nserver_list = release\n\n"
+	if got != want {
+		t.Fatalf("unexpected parse result:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestParseAnsibleCfg_Errors(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		want    string
+	}{
+		"option before section": {"forks = 5\n", `line 1: option "forks = 5" is not in a section`},
+		"unterminated header":   {"[defaults\n", `line 1: invalid section header "[defaults"`},
+		"missing separator":     {"[defaults]\nforks\n", `line 2: expected "key = value", got "forks"`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseAnsibleCfg(tc.content)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got: %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestMergeAnsibleCfgContent(t *testing.T) {
+	base := `[defaults]
+inventory = hosts
+remote_tmp = /var/tmp/ansible
+host_key_checking = True
+
+[privilege_escalation]
+become = False
+`
+	content, overridden, err := mergeAnsibleCfgContent(base, &AnsibleConfig{
+		Defaults:      &AnsibleConfigDefaults{RemoteTmp: "/tmp/.ansible/tmp", LocalTmp: "/tmp/.ansible-local"},
+		SSHConnection: &AnsibleConfigConnection{Pipelining: true},
+		Sections: []AnsibleConfigSection{
+			{Name: "privilege_escalation", Options: map[string]string{"become": "True"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The user's order is kept, generated options replace user values, the unset
+	// host_key_checking keeps the user's value and new sections are appended.
+	want := `[defaults]
+inventory = hosts
+remote_tmp = /tmp/.ansible/tmp
+host_key_checking = True
+local_tmp = /tmp/.ansible-local
+
+[privilege_escalation]
+become = True
+
+[ssh_connection]
+pipelining = True
+
+`
+	if content != want {
+		t.Fatalf("unexpected merged content:\n%s\nwant:\n%s", content, want)
+	}
+	if strings.Join(overridden, ",") != "defaults.remote_tmp,privilege_escalation.become" {
+		t.Fatalf("unexpected overridden options: %v", overridden)
+	}
+}
+
+func TestBuildAnsibleCfgContent_MergesUserFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ansible.cfg")
+	if err := os.WriteFile(path, []byte("[defaults]\nforks = 5\nremote_tmp = /var/tmp\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := newMockUi()
+	content, err := buildAnsibleCfgContent(ui, &AnsibleConfig{
+		Config:   path,
+		Defaults: &AnsibleConfigDefaults{RemoteTmp: "/tmp/.ansible/tmp"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if content != "[defaults]\nforks = 5\nremote_tmp = /tmp/.ansible/tmp\nhost_key_checking = False\n\n" {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+	messages := strings.Join(ui.(*mockUi).messageMessages, "\n")
+	if !strings.Contains(messages, "ansible.cfg settings overridden by the plugin: defaults.remote_tmp") {
+		t.Fatalf("expected overridden options to be reported, got: %s", messages)
+	}
+
+	if _, err := buildAnsibleCfgContent(ui, &AnsibleConfig{Config: filepath.Join(t.TempDir(), "missing.cfg")}); err == nil {
+		t.Fatalf("expected an error for a missing ansible.cfg")
+	}
+}
+
+func TestCreateTempAnsibleCfgFile_Dir(t *testing.T) {
+	dir := t.TempDir()
+	path, err := createTempAnsibleCfgFile(dir, "[defaults]\nroles_path = roles\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Remove(path)
+	if filepath.Dir(path) != dir {
+		t.Fatalf("expected the file in %s, got %s", dir, path)
+	}
+
+	if _, err := createTempAnsibleCfgFile(dir, ""); err == nil {
+		t.Fatalf("expected an error for empty content")
+	}
+}
@@ -0,0 +1,354 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// containerEngineProbeTimeout bounds each container engine CLI call made while
+// detecting engine features, so an unresponsive daemon cannot stall the build.
+const containerEngineProbeTimeout = 5 * time.Second
+
+// containerEngine is the container engine (docker or podman) that runs execution
+// environment containers on the build host.
+type containerEngine interface {
+	// Name returns the engine executable: "docker" or "podman".
+	Name() string
+	// Rootless reports whether the engine runs containers without root privileges.
+	Rootless() bool
+	// HostEnv returns the environment variable that selects the engine endpoint
+	// and the value the plugin must export for it. The value is empty when the
+	// variable is already set or the engine's default endpoint is used.
+	HostEnv() (key, value string)
+	// Socket returns the path of the engine's local API socket, or "" when the
+	// engine is reached over the network.
+	Socket() string
+	// ImagePresent reports whether image is available in the local image store.
+	ImagePresent(image string) (bool, error)
+	// PullImage pulls image, passing args (execution_environment.pull_arguments)
+	// to the engine's pull command.
+	PullImage(image string, args []string) error
+	// ImageDigest returns the registry digest (sha256:...) of the local copy of
+	// image, or "" when the image was never pulled from or pushed to a registry.
+	ImageDigest(image string) (string, error)
+	// NeedsSELinuxRelabel reports whether bind mounts need the ":z" option.
+	NeedsSELinuxRelabel() bool
+	// HostGateway returns the hostname that resolves to the build host from
+	// inside containers started by the engine.
+	HostGateway() string
+}
+
+// containerEngineDeps are the system interactions used to detect container
+// engine features. They are replaced in tests.
+type containerEngineDeps struct {
+	stat          func(string) (os.FileInfo, error)
+	lookPath      func(string) (string, error)
+	commandOutput func(ctx context.Context, name string, args ...string) ([]byte, error)
+	lookupEnv     func(string) (string, bool)
+	getuid        func() int
+}
+
+func defaultContainerEngineDeps() containerEngineDeps {
+	return containerEngineDeps{
+		stat:     os.Stat,
+		lookPath: exec.LookPath,
+		commandOutput: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			cmd := exec.CommandContext(ctx, name, args...)
+			return cmd.Output()
+		},
+		lookupEnv: os.LookupEnv,
+		getuid:    os.Getuid,
+	}
+}
+
+// output runs an engine CLI command with containerEngineProbeTimeout.
+func (d containerEngineDeps) output(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), containerEngineProbeTimeout)
+	defer cancel()
+	out, err := d.commandOutput(ctx, name, args...)
+	return strings.TrimSpace(string(out)), err
+}
+
+// env returns the value of key when it is set to a non-blank value.
+func (d containerEngineDeps) env(key string) (string, bool) {
+	v, ok := d.lookupEnv(key)
+	if !ok || strings.TrimSpace(v) == "" {
+		return "", false
+	}
+	return v, true
+}
+
+// detectContainerEngine resolves the execution_environment.container_engine
+// setting and detects the features of the selected engine. Feature detection
+// fails open: when the engine CLI cannot be queried, conservative defaults are
+// used.
+func detectContainerEngine(setting string, deps containerEngineDeps) (containerEngine, error) {
+	name, err := resolveContainerEngine(setting, deps.lookPath)
+	if err != nil {
+		return nil, err
+	}
+	if name == "podman" {
+		return detectPodmanEngine(deps), nil
+	}
+	return detectDockerEngine(deps), nil
+}
+
+// engineFeatures holds the detected features shared by both engines.
+type engineFeatures struct {
+	deps containerEngineDeps
+
+	// host is the engine endpoint (e.g. unix:///run/user/1000/docker.sock).
+	host string
+	// exportHost is set when host was detected rather than taken from the environment.
+	exportHost bool
+	socket     string
+	rootless   bool
+	selinux    bool
+}
+
+func (f *engineFeatures) Rootless() bool            { return f.rootless }
+func (f *engineFeatures) Socket() string            { return f.socket }
+func (f *engineFeatures) NeedsSELinuxRelabel() bool { return f.selinux }
+
+// selinuxEnforcing reports whether the build host enforces SELinux.
+func selinuxEnforcing(deps containerEngineDeps) bool {
+	if _, err := deps.stat("/sys/fs/selinux/enforce"); err != nil {
+		return false
+	}
+	out, err := deps.output("getenforce")
+	return err == nil && out == "Enforcing"
+}
+
+// unixSocketPath returns the socket path of a unix:// endpoint, or "".
+func unixSocketPath(host string) string {
+	if strings.HasPrefix(host, "unix://") {
+		return strings.TrimPrefix(host, "unix://")
+	}
+	return ""
+}
+
+// imagePresent runs an image presence check. A non-zero exit status means the
+// image is not present; failing to run the command is an error.
+func imagePresent(deps containerEngineDeps, name string, args ...string) (bool, error) {
+	_, err := deps.output(name, args...)
+	if err == nil {
+		return true, nil
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return false, fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+}
+
+// pullImage runs `<engine> pull [args] image` without a timeout; pulls of large
+// images legitimately take minutes.
+func pullImage(deps containerEngineDeps, name, image string, args []string) error {
+	return runCommand(deps, name, append(append([]string{"pull"}, args...), image)...)
+}
+
+// runCommand runs a command without a timeout. The error includes the command's
+// stderr when it exits non-zero.
+func runCommand(deps containerEngineDeps, name string, args ...string) error {
+	cmd := strings.Join(append([]string{name}, args...), " ")
+	if _, err := deps.commandOutput(context.Background(), name, args...); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return fmt.Errorf("%s: %w: %s", cmd, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("%s: %w", cmd, err)
+	}
+	return nil
+}
+
+// imageRepoDigest returns the digest of the RepoDigests entry of image that
+// belongs to image's repository (docker and podman share the inspect format).
+func imageRepoDigest(deps containerEngineDeps, name, image string) (string, error) {
+	out, err := deps.output(name, "image", "inspect", "--format", "{{json .RepoDigests}}", image)
+	if err != nil {
+		return "", fmt.Errorf("%s image inspect %s: %w", name, image, err)
+	}
+	var repoDigests []string
+	if err := json.Unmarshal([]byte(out), &repoDigests); err != nil {
+		return "", fmt.Errorf("%s image inspect %s: unexpected RepoDigests %q", name, image, out)
+	}
+	want := normalizeImageRepository(imageRepository(image))
+	for _, rd := range repoDigests {
+		repo, digest, ok := strings.Cut(rd, "@")
+		if ok && normalizeImageRepository(repo) == want {
+			return digest, nil
+		}
+	}
+	return "", nil
+}
+
+// dockerEngine is Docker Engine or Docker Desktop, rootful or rootless.
+type dockerEngine struct {
+	engineFeatures
+}
+
+const dockerDefaultSocket = "/var/run/docker.sock"
+
+func detectDockerEngine(deps containerEngineDeps) *dockerEngine {
+	e := &dockerEngine{engineFeatures{deps: deps}}
+
+	// DOCKER_HOST wins over the active context, as in the docker CLI.
+	if host, ok := deps.env("DOCKER_HOST"); ok {
+		e.host = host
+	} else if host, err := deps.output("docker", "context", "inspect", "--format", "{{.Endpoints.docker.Host}}"); err == nil && host != "" {
+		// Rootless Docker and non-default contexts use a different socket.
+		e.host = host
+		e.exportHost = true
+	}
+
+	if e.host == "" {
+		e.socket = dockerDefaultSocket
+	} else {
+		e.socket = unixSocketPath(e.host)
+	}
+
+	// The daemon reports "name=rootless" and "name=selinux" security options.
+	if out, err := deps.output("docker", "info", "--format", "{{json .SecurityOptions}}"); err == nil {
+		e.rootless = strings.Contains(out, "name=rootless")
+		e.selinux = strings.Contains(out, "name=selinux")
+	} else {
+		e.rootless = strings.HasPrefix(e.socket, "/run/user/")
+		e.selinux = selinuxEnforcing(deps)
+	}
+	return e
+}
+
+func (e *dockerEngine) Name() string { return "docker" }
+
+func (e *dockerEngine) HostEnv() (string, string) {
+	if !e.exportHost {
+		return "DOCKER_HOST", ""
+	}
+	return "DOCKER_HOST", e.host
+}
+
+func (e *dockerEngine) ImagePresent(image string) (bool, error) {
+	return imagePresent(e.deps, "docker", "image", "inspect", "--format", "{{.Id}}", image)
+}
+
+func (e *dockerEngine) PullImage(image string, args []string) error {
+	return pullImage(e.deps, "docker", image, args)
+}
+
+func (e *dockerEngine) ImageDigest(image string) (string, error) {
+	return imageRepoDigest(e.deps, "docker", image)
+}
+
+// HostGateway is resolved automatically by Docker Desktop only; Docker Engine
+// on Linux needs an --add-host mapping to host-gateway.
+func (e *dockerEngine) HostGateway() string { return "host.docker.internal" }
+
+// podmanEngine is Podman, rootful or rootless.
+type podmanEngine struct {
+	engineFeatures
+}
+
+func detectPodmanEngine(deps containerEngineDeps) *podmanEngine {
+	e := &podmanEngine{engineFeatures{deps: deps}}
+
+	// Podman talks to its local storage directly; exporting CONTAINER_HOST would
+	// switch it to remote mode, so an endpoint is only ever taken from the environment.
+	if host, ok := deps.env("CONTAINER_HOST"); ok {
+		e.host = host
+		e.socket = unixSocketPath(host)
+	}
+
+	out, err := deps.output("podman", "info", "--format",
+		"{{.Host.Security.Rootless}} {{.Host.Security.SELinuxEnabled}} {{.Host.RemoteSocket.Path}}")
+	if fields := strings.Fields(out); err == nil && len(fields) >= 2 {
+		e.rootless = fields[0] == "true"
+		e.selinux = fields[1] == "true"
+		if e.socket == "" && e.host == "" && len(fields) >= 3 {
+			e.socket = unixSocketPath(fields[2])
+			if e.socket == "" {
+				e.socket = fields[2]
+			}
+		}
+		return e
+	}
+
+	e.rootless = deps.getuid() != 0
+	e.selinux = selinuxEnforcing(deps)
+	if e.host == "" {
+		if e.rootless {
+			runtimeDir, ok := deps.env("XDG_RUNTIME_DIR")
+			if !ok {
+				runtimeDir = fmt.Sprintf("/run/user/%d", deps.getuid())
+			}
+			e.socket = runtimeDir + "/podman/podman.sock"
+		} else {
+			e.socket = "/run/podman/podman.sock"
+		}
+	}
+	return e
+}
+
+func (e *podmanEngine) Name() string { return "podman" }
+
+func (e *podmanEngine) HostEnv() (string, string) { return "CONTAINER_HOST", "" }
+
+func (e *podmanEngine) ImagePresent(image string) (bool, error) {
+	return imagePresent(e.deps, "podman", "image", "exists", image)
+}
+
+func (e *podmanEngine) PullImage(image string, args []string) error {
+	return pullImage(e.deps, "podman", image, args)
+}
+
+func (e *podmanEngine) ImageDigest(image string) (string, error) {
+	return imageRepoDigest(e.deps, "podman", image)
+}
+
+// HostGateway is added to /etc/hosts of every podman container.
+func (e *podmanEngine) HostGateway() string { return "host.containers.internal" }
+
+// hostGatewayAddHostOption returns the container option that maps hostname to
+// the build host, or "" when the engine resolves hostname on its own (or it is
+// not a gateway name at all).
+func hostGatewayAddHostOption(hostname string, engine containerEngine) string {
+	switch {
+	case hostname == "gateway.docker.internal":
+		// Not resolved automatically by any engine.
+	case engine != nil && engine.Name() == "docker" && hostname == engine.HostGateway():
+		// Docker Desktop resolves it, Docker Engine on Linux does not; the
+		// mapping is harmless where it is redundant.
+	default:
+		return ""
+	}
+	return "--add-host=" + hostname + ":host-gateway"
+}
+
+// withSELinuxRelabel adds the "z" bind mount option when engine needs it. The
+// plugin only mounts content that is shared with the host and other containers
+// (Galaxy caches, the requirements directory, the keyring), so it uses the shared
+// label rather than the private "Z", which would lock them to one container.
+func withSELinuxRelabel(options string, engine containerEngine) string {
+	if engine == nil || !engine.NeedsSELinuxRelabel() {
+		return options
+	}
+	for _, opt := range strings.Split(options, ",") {
+		if opt == "z" || opt == "Z" {
+			return options
+		}
+	}
+	if options == "" {
+		return "z"
+	}
+	return options + ",z"
+}
@@ -0,0 +1,199 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngineDeps returns deps where only the given commands ("name arg...")
+// succeed with the given output, and only the given environment is set.
+func fakeEngineDeps(outputs map[string]string, env map[string]string, uid int) containerEngineDeps {
+	return containerEngineDeps{
+		stat: func(string) (os.FileInfo, error) { return nil, os.ErrNotExist },
+		lookPath: func(name string) (string, error) {
+			for cmd := range outputs {
+				if strings.HasPrefix(cmd, name+" ") {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", exec.ErrNotFound
+		},
+		commandOutput: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			out, ok := outputs[name+" "+strings.Join(args, " ")]
+			if !ok {
+				return nil, exec.ErrNotFound
+			}
+			return []byte(out), nil
+		},
+		lookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		getuid: func() int { return uid },
+	}
+}
+
+func TestDetectContainerEngine_DockerRootlessContext(t *testing.T) {
+	deps := fakeEngineDeps(map[string]string{
+		"docker context inspect --format {{.Endpoints.docker.Host}}": "unix:///run/user/1000/docker.sock\n",
+		"docker info --format {{json .SecurityOptions}}":             `["name=seccomp,profile=builtin","name=rootless","name=cgroupns"]`,
+	}, nil, 1000)
+
+	engine, err := detectContainerEngine("docker", deps)
+	require.NoError(t, err)
+	require.Equal(t, "docker", engine.Name())
+	require.True(t, engine.Rootless())
+	require.False(t, engine.NeedsSELinuxRelabel())
+	require.Equal(t, "/run/user/1000/docker.sock", engine.Socket())
+	require.Equal(t, "host.docker.internal", engine.HostGateway())
+
+	key, value := engine.HostEnv()
+	require.Equal(t, "DOCKER_HOST", key)
+	require.Equal(t, "unix:///run/user/1000/docker.sock", value)
+}
+
+func TestDetectContainerEngine_DockerHostFromEnvironment(t *testing.T) {
+	deps := fakeEngineDeps(map[string]string{
+		"docker info --format {{json .SecurityOptions}}": `["name=selinux"]`,
+	}, map[string]string{"DOCKER_HOST": "tcp://10.0.0.5:2376"}, 0)
+
+	engine, err := detectContainerEngine("docker", deps)
+	require.NoError(t, err)
+	require.True(t, engine.NeedsSELinuxRelabel())
+	require.Empty(t, engine.Socket(), "remote endpoints have no local socket")
+
+	_, value := engine.HostEnv()
+	require.Empty(t, value, "an explicit DOCKER_HOST is not re-exported")
+}
+
+func TestDetectContainerEngine_PodmanInfo(t *testing.T) {
+	deps := fakeEngineDeps(map[string]string{
+		"podman info --format {{.Host.Security.Rootless}} {{.Host.Security.SELinuxEnabled}} {{.Host.RemoteSocket.Path}}": "true true /run/user/1000/podman/podman.sock\n",
+	}, nil, 1000)
+
+	engine, err := detectContainerEngine("auto", deps)
+	require.NoError(t, err)
+	require.Equal(t, "podman", engine.Name())
+	require.True(t, engine.Rootless())
+	require.True(t, engine.NeedsSELinuxRelabel())
+	require.Equal(t, "/run/user/1000/podman/podman.sock", engine.Socket())
+	require.Equal(t, "host.containers.internal", engine.HostGateway())
+
+	key, value := engine.HostEnv()
+	require.Equal(t, "CONTAINER_HOST", key)
+	require.Empty(t, value)
+}
+
+func TestDetectContainerEngine_PodmanFallbacks(t *testing.T) {
+	// podman is installed but `podman info` fails: fall back to uid and XDG_RUNTIME_DIR.
+	deps := fakeEngineDeps(map[string]string{"podman version": ""}, map[string]string{"XDG_RUNTIME_DIR": "/run/user/1001"}, 1001)
+	engine, err := detectContainerEngine("podman", deps)
+	require.NoError(t, err)
+	require.True(t, engine.Rootless())
+	require.False(t, engine.NeedsSELinuxRelabel())
+	require.Equal(t, "/run/user/1001/podman/podman.sock", engine.Socket())
+
+	engine, err = detectContainerEngine("podman", fakeEngineDeps(nil, nil, 0))
+	require.NoError(t, err)
+	require.False(t, engine.Rootless())
+	require.Equal(t, "/run/podman/podman.sock", engine.Socket())
+
+	_, err = detectContainerEngine("auto", fakeEngineDeps(nil, nil, 0))
+	require.ErrorContains(t, err, "no container engine found in PATH")
+}
+
+func TestContainerEngine_ImagePresent(t *testing.T) {
+	deps := fakeEngineDeps(map[string]string{
+		"podman image exists quay.io/ansible/creator-ee:v1": "",
+	}, nil, 0)
+	engine := detectPodmanEngine(deps)
+
+	present, err := engine.ImagePresent("quay.io/ansible/creator-ee:v1")
+	require.NoError(t, err)
+	require.True(t, present)
+
+	// Failing to run the engine CLI is an error, not "absent".
+	_, err = engine.ImagePresent("quay.io/ansible/creator-ee:v2")
+	require.ErrorContains(t, err, "podman image exists quay.io/ansible/creator-ee:v2")
+}
+
+func TestHostGatewayAddHostOption(t *testing.T) {
+	docker := detectDockerEngine(fakeEngineDeps(nil, nil, 0))
+	podman := detectPodmanEngine(fakeEngineDeps(nil, nil, 0))
+
+	require.Equal(t, "--add-host=gateway.docker.internal:host-gateway", hostGatewayAddHostOption("gateway.docker.internal", nil))
+	require.Equal(t, "--add-host=gateway.docker.internal:host-gateway", hostGatewayAddHostOption("gateway.docker.internal", podman))
+	require.Equal(t, "--add-host=host.docker.internal:host-gateway", hostGatewayAddHostOption("host.docker.internal", docker))
+	require.Empty(t, hostGatewayAddHostOption("host.docker.internal", nil))
+	require.Empty(t, hostGatewayAddHostOption("host.containers.internal", podman))
+	require.Empty(t, hostGatewayAddHostOption("127.0.0.1", docker))
+}
+
+func TestApplyAutomaticEEDefaultsWithEngine(t *testing.T) {
+	engine := detectPodmanEngine(fakeEngineDeps(map[string]string{
+		"podman info --format {{.Host.Security.Rootless}} {{.Host.Security.SELinuxEnabled}} {{.Host.RemoteSocket.Path}}": "false true /run/podman/podman.sock",
+	}, nil, 0))
+	config := &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: boolPtr(true)}}
+
+	applyAutomaticEEDefaultsWithEngine(config, "/var/cache/collections", "host.containers.internal", engine)
+	require.Empty(t, config.ExecutionEnvironment.ContainerOptions)
+	require.Len(t, config.ExecutionEnvironment.VolumeMounts, 1)
+	require.Equal(t, "ro,z", config.ExecutionEnvironment.VolumeMounts[0].Options)
+
+	docker := detectDockerEngine(fakeEngineDeps(nil, nil, 0))
+	config = &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: boolPtr(true)}}
+	applyAutomaticEEDefaultsWithEngine(config, "/var/cache/collections", "host.docker.internal", docker)
+	require.Equal(t, []string{"--add-host=host.docker.internal:host-gateway"}, config.ExecutionEnvironment.ContainerOptions)
+	require.Equal(t, "ro", config.ExecutionEnvironment.VolumeMounts[0].Options)
+}
+
+func TestWithSELinuxRelabel(t *testing.T) {
+	relabel := detectPodmanEngine(fakeEngineDeps(map[string]string{
+		"podman info --format {{.Host.Security.Rootless}} {{.Host.Security.SELinuxEnabled}} {{.Host.RemoteSocket.Path}}": "true true",
+	}, nil, 1000))
+
+	require.Equal(t, "z", withSELinuxRelabel("", relabel))
+	require.Equal(t, "ro,z", withSELinuxRelabel("ro", relabel))
+	require.Equal(t, "ro,Z", withSELinuxRelabel("ro,Z", relabel))
+	require.Equal(t, "ro", withSELinuxRelabel("ro", nil))
+}
+
+func TestEEPreflight_Podman(t *testing.T) {
+	uiOut := new(bytes.Buffer)
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: uiOut}
+
+	deps := fakeEngineDeps(map[string]string{
+		"podman info --format {{.Host.Security.Rootless}} {{.Host.Security.SELinuxEnabled}} {{.Host.RemoteSocket.Path}}": "true false /run/user/1000/podman/podman.sock",
+	}, nil, 1000)
+	info := deps.commandOutput
+	deps.commandOutput = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if name == "podman" && args[0] == "image" {
+			// `podman image exists` exits 1 when the image is missing.
+			return nil, exec.Command("false").Run()
+		}
+		return info(ctx, name, args...)
+	}
+
+	ee := &ExecutionEnvironment{Enabled: boolPtr(true), Image: "quay.io/ansible/creator-ee:v1", PullPolicy: "never"}
+	emitEEPreflightWithDeps(ui, true, nil, detectPodmanEngine(deps), ee, nil, deps)
+	out := uiOut.String()
+
+	require.Contains(t, out, "[EE preflight] container engine: podman (rootless), SELinux relabel: no")
+	require.Contains(t, out, "[EE preflight] CONTAINER_HOST: unset")
+	require.Contains(t, out, "[EE preflight] /run/user/1000/podman/podman.sock: missing")
+	require.Contains(t, out, "[EE preflight] podman client: missing in PATH")
+	require.Contains(t, out, `[DEBUG][WARN] [EE preflight] image quay.io/ansible/creator-ee:v1: not present locally and pull_policy is "never"`)
+	require.NotContains(t, out, "dockerd")
+}
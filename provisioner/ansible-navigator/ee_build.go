@@ -0,0 +1,204 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"gopkg.in/yaml.v3"
+)
+
+// Defaults for execution_environment.build
+const (
+	DefaultEEBuildRepository = "packer-ansible-navigator-ee"
+	DefaultEEBuildCommand    = "ansible-builder"
+)
+
+// eeBuildTagLength is the number of content hash hex digits in the built image tag.
+const eeBuildTagLength = 16
+
+// eeBuildDefinition is the part of an ansible-builder execution-environment.yml
+// that references other files.
+type eeBuildDefinition struct {
+	Dependencies         map[string]interface{} `yaml:"dependencies"`
+	AdditionalBuildFiles []struct {
+		Src string `yaml:"src"`
+	} `yaml:"additional_build_files"`
+}
+
+// validateImageBuild validates execution_environment.build.
+func validateImageBuild(ee *ExecutionEnvironment) []error {
+	b := ee.Build
+	if b == nil {
+		return nil
+	}
+	var errs []error
+	if err := validateFileConfig(b.File, "navigator_config.execution_environment.build.file", true); err != nil {
+		errs = append(errs, err)
+	}
+	if ee.Image != "" {
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.image and build are mutually exclusive (the built image is used)"))
+	}
+	if ee.ImageDigest != "" || ee.Verify != nil {
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.image_digest and verify cannot be used with build (built images are local and unsigned)"))
+	}
+	if strings.Contains(b.Repository, "@") || strings.LastIndex(b.Repository, ":") > strings.LastIndex(b.Repository, "/") {
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.build.repository must not include a tag or digest (got %q)", b.Repository))
+	}
+	return errs
+}
+
+// eeBuildInputs returns the files and directories the execution-environment.yml
+// at file references: dependency files and additional_build_files sources.
+// Inline dependencies are part of the file itself.
+func eeBuildInputs(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var def eeBuildDefinition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	dir := filepath.Dir(file)
+	var inputs []string
+	for _, dep := range def.Dependencies {
+		if name, ok := dep.(string); ok {
+			if path := filepath.Join(dir, name); fileExists(path) {
+				inputs = append(inputs, path)
+			}
+		}
+	}
+	for _, f := range def.AdditionalBuildFiles {
+		// src may be a glob
+		matches, err := filepath.Glob(filepath.Join(dir, f.Src))
+		if err != nil {
+			return nil, fmt.Errorf("%s: additional_build_files src %q: %w", file, f.Src, err)
+		}
+		inputs = append(inputs, matches...)
+	}
+	sort.Strings(inputs)
+	return inputs, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// eeBuildHash returns a content hash of everything that goes into the image:
+// the execution-environment.yml, the files it references and the builder arguments.
+func eeBuildHash(b *ImageBuildConfig) (string, error) {
+	h := sha256.New()
+	addFile := func(path string) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(filepath.Dir(b.File), path)
+		if err != nil {
+			rel = path
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+		return nil
+	}
+
+	if err := addFile(b.File); err != nil {
+		return "", err
+	}
+	inputs, err := eeBuildInputs(b.File)
+	if err != nil {
+		return "", err
+	}
+	for _, input := range inputs {
+		err := filepath.WalkDir(input, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			return addFile(path)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to hash execution environment build input %s: %w", input, err)
+		}
+	}
+	fmt.Fprintf(h, "args\x00%s", strings.Join(b.ExtraArgs, "\x00"))
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// buildExecutionEnvironmentImage builds the execution environment image with
+// ansible-builder, unless an image with the same content hash already exists,
+// and points ee at it. The build output is streamed to the UI.
+func buildExecutionEnvironmentImage(ui packersdk.Ui, engine containerEngine, ee *ExecutionEnvironment, run func(*exec.Cmd) error) error {
+	b := ee.Build
+	if engine == nil {
+		return fmt.Errorf("execution_environment.build requires a container engine (docker or podman)")
+	}
+
+	hash, err := eeBuildHash(b)
+	if err != nil {
+		return fmt.Errorf("failed to hash execution environment build inputs: %w", err)
+	}
+	repository := b.Repository
+	if repository == "" {
+		repository = DefaultEEBuildRepository
+	}
+	image := repository + ":" + hash[:eeBuildTagLength]
+
+	present, err := engine.ImagePresent(image)
+	if err != nil {
+		return fmt.Errorf("failed to check execution environment image %s: %w", image, err)
+	}
+	if present {
+		ui.Message(fmt.Sprintf("Reusing execution environment image %s (build inputs unchanged)", image))
+	} else {
+		contextDir, err := os.MkdirTemp("", "packer-ee-build-")
+		if err != nil {
+			return fmt.Errorf("failed to create execution environment build context: %w", err)
+		}
+		defer os.RemoveAll(contextDir)
+
+		command := b.Command
+		if command == "" {
+			command = DefaultEEBuildCommand
+		}
+		args := []string{"build",
+			"--file", b.File,
+			"--context", contextDir,
+			"--tag", image,
+			"--container-runtime", engine.Name(),
+		}
+		args = append(args, b.ExtraArgs...)
+
+		ui.Say(fmt.Sprintf("Building execution environment image %s from %s...", image, b.File))
+		cmd := exec.Command(command, args...)
+		cmd.Env = os.Environ()
+		if key, value := engine.HostEnv(); value != "" {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+		if err := run(cmd); err != nil {
+			return fmt.Errorf("failed to build execution environment image with %s: %w", command, err)
+		}
+	}
+
+	// The image exists only in the local store.
+	ee.Image = image
+	ee.PullPolicy = "never"
+	return nil
+}
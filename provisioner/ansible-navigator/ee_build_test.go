@@ -0,0 +1,154 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testEEDefinition = `version: 3
+images:
+  base_image:
+    name: quay.io/ansible/creator-base:latest
+dependencies:
+  galaxy: requirements.yml
+  python: requirements.txt
+  system:
+    - git [platform:rpm]
+additional_build_files:
+  - src: files/*.cfg
+    dest: configs
+`
+
+// writeEEBuildDir writes an ansible-builder definition with its dependency files.
+func writeEEBuildDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"execution-environment.yml": testEEDefinition,
+		"requirements.yml":          "collections:\n  - community.general\n",
+		"requirements.txt":          "jmespath\n",
+		"files/ansible.cfg":         "[defaults]\n",
+		"README.md":                 "not a build input\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestEEBuildInputs(t *testing.T) {
+	dir := writeEEBuildDir(t)
+	inputs, err := eeBuildInputs(filepath.Join(dir, "execution-environment.yml"))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "files", "ansible.cfg"),
+		filepath.Join(dir, "requirements.txt"),
+		filepath.Join(dir, "requirements.yml"),
+	}, inputs)
+}
+
+func TestEEBuildHash(t *testing.T) {
+	dir := writeEEBuildDir(t)
+	build := &ImageBuildConfig{File: filepath.Join(dir, "execution-environment.yml")}
+	hash, err := eeBuildHash(build)
+	require.NoError(t, err)
+
+	// Files that are not build inputs do not change the hash.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0o644))
+	same, err := eeBuildHash(build)
+	require.NoError(t, err)
+	require.Equal(t, hash, same)
+
+	// Referenced files and builder arguments do.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("jmespath==1.0.1\n"), 0o644))
+	changed, err := eeBuildHash(build)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changed)
+
+	build.ExtraArgs = []string{"--build-arg=PKGMGR_OPTS=--nodocs"}
+	withArgs, err := eeBuildHash(build)
+	require.NoError(t, err)
+	require.NotEqual(t, changed, withArgs)
+}
+
+func TestBuildExecutionEnvironmentImage(t *testing.T) {
+	dir := writeEEBuildDir(t)
+	file := filepath.Join(dir, "execution-environment.yml")
+	hash, err := eeBuildHash(&ImageBuildConfig{File: file})
+	require.NoError(t, err)
+	image := "registry.example.com/ee:" + hash[:eeBuildTagLength]
+
+	// Missing image: ansible-builder runs with the detected engine.
+	var calls []string
+	engine := recordingPodman(map[string]string{"podman version": ""}, &calls)
+	var built []string
+	run := func(cmd *exec.Cmd) error {
+		built = cmd.Args
+		return nil
+	}
+	ee := &ExecutionEnvironment{Enabled: boolPtr(true), Build: &ImageBuildConfig{File: file, Repository: "registry.example.com/ee"}}
+	require.NoError(t, buildExecutionEnvironmentImage(newMockUi(), engine, ee, run))
+	require.Equal(t, image, ee.Image)
+	require.Equal(t, "never", ee.PullPolicy)
+	require.Equal(t, "ansible-builder", built[0])
+	joined := strings.Join(built, " ")
+	require.Contains(t, joined, "build --file "+file+" --context ")
+	require.Contains(t, joined, "--tag "+image+" --container-runtime podman")
+
+	// Present image: reused without building.
+	engine = recordingPodman(map[string]string{"podman image exists " + image: ""}, &calls)
+	ee = &ExecutionEnvironment{Enabled: boolPtr(true), Build: &ImageBuildConfig{File: file, Repository: "registry.example.com/ee"}}
+	ui := newMockUi().(*mockUi)
+	require.NoError(t, buildExecutionEnvironmentImage(ui, engine, ee, func(*exec.Cmd) error {
+		t.Fatal("the image must not be rebuilt")
+		return nil
+	}))
+	require.Equal(t, image, ee.Image)
+	require.Contains(t, strings.Join(ui.messageMessages, "\n"), "Reusing execution environment image "+image)
+
+	// Build failures and a missing engine are errors.
+	ee = &ExecutionEnvironment{Enabled: boolPtr(true), Build: &ImageBuildConfig{File: file, Command: "/opt/bin/ansible-builder"}}
+	err = buildExecutionEnvironmentImage(newMockUi(), recordingPodman(map[string]string{"podman version": ""}, &calls), ee,
+		func(*exec.Cmd) error { return errors.New("exit status 1") })
+	require.ErrorContains(t, err, "failed to build execution environment image with /opt/bin/ansible-builder: exit status 1")
+
+	err = buildExecutionEnvironmentImage(newMockUi(), nil, ee, run)
+	require.ErrorContains(t, err, "requires a container engine")
+}
+
+func TestValidateImageBuild(t *testing.T) {
+	file := filepath.Join(writeEEBuildDir(t), "execution-environment.yml")
+	require.Empty(t, validateImageBuild(&ExecutionEnvironment{Build: &ImageBuildConfig{File: file, Repository: "localhost:5000/ee"}}))
+
+	errs := validateImageBuild(&ExecutionEnvironment{
+		Image:  "quay.io/ansible/creator-ee:v1",
+		Verify: &ImageVerifyConfig{CosignKey: "k8s://ns/key"},
+		Build:  &ImageBuildConfig{File: file + ".missing", Repository: "ee:v1"},
+	})
+	require.Len(t, errs, 4)
+	require.Contains(t, errs[0].Error(), "build.file")
+	require.Contains(t, errs[1].Error(), "image and build are mutually exclusive")
+	require.Contains(t, errs[2].Error(), "cannot be used with build")
+	require.Contains(t, errs[3].Error(), "build.repository must not include a tag")
+}
+
+func TestStreamCommand(t *testing.T) {
+	ui := newMockUi().(*mockUi)
+	require.NoError(t, streamCommand(ui, exec.Command("sh", "-c", "echo step 1/3; echo warning >&2")))
+	require.ElementsMatch(t, []string{"step 1/3", "warning"}, ui.messageMessages)
+
+	require.Error(t, streamCommand(newMockUi(), exec.Command("sh", "-c", "exit 2")))
+}
@@ -0,0 +1,191 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// imageDigestPattern matches the image_digest setting.
+var imageDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// ExecutionEnvironmentImage records the execution environment image a build
+// ran with. It is reported in the structured summary.
+type ExecutionEnvironmentImage struct {
+	// Image is the configured image reference.
+	Image string `json:"image"`
+	// Digest is the registry digest (sha256:...) the image resolved to.
+	Digest string `json:"digest,omitempty"`
+	// Reference is the image reference passed to ansible-navigator.
+	Reference string `json:"reference"`
+	// Engine is the container engine that pulled the image.
+	Engine string `json:"engine,omitempty"`
+	// Pulled is set when the image was pulled for this build.
+	Pulled bool `json:"pulled"`
+	// Verification is the signature verification result, when verify is configured.
+	Verification *ImageVerification `json:"verification,omitempty"`
+}
+
+type eeImageCacheKey struct {
+	engine string
+	image  string
+}
+
+// eeImageCache holds the images prepared by this plugin process, so builds that
+// share the process pull and resolve each image once. pull_policy "always"
+// bypasses it.
+var eeImageCache sync.Map // eeImageCacheKey -> ExecutionEnvironmentImage
+
+// imageRepository returns image without its tag or digest.
+func imageRepository(image string) string {
+	repo, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	return repo
+}
+
+// normalizeImageRepository expands Docker Hub short names, so that "ubuntu",
+// "library/ubuntu" and "docker.io/library/ubuntu" compare equal.
+func normalizeImageRepository(repo string) string {
+	first, rest, found := strings.Cut(repo, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return repo
+	}
+	if !found {
+		return "docker.io/library/" + repo
+	}
+	return "docker.io/" + first + "/" + rest
+}
+
+// shouldPullImage applies an ansible-navigator pull policy to the local image store.
+func shouldPullImage(policy, image string, present bool) bool {
+	switch policy {
+	case "always":
+		return true
+	case "never":
+		return false
+	case "missing":
+		return !present
+	default: // "tag", navigator's default
+		return !present || imageTag(image) == "latest"
+	}
+}
+
+// prepareExecutionEnvironmentImage pulls the execution environment image once,
+// resolves it to its registry digest and pins ee.Image to that digest, so every
+// play (and Galaxy installs inside the EE) runs the same image without further
+// pull checks. The digest is verified against ee.ImageDigest when set.
+//
+// Without a detected container engine only an image_digest pin is applied, and
+// the registry verifies it when ansible-navigator pulls the image.
+func prepareExecutionEnvironmentImage(ui packersdk.Ui, engine containerEngine, ee *ExecutionEnvironment) (*ExecutionEnvironmentImage, error) {
+	if ee == nil || !derefBool(ee.Enabled) || ee.Image == "" {
+		return nil, nil
+	}
+
+	if engine == nil {
+		if ee.ImageDigest == "" {
+			return nil, nil
+		}
+		pinned := &ExecutionEnvironmentImage{
+			Image:     ee.Image,
+			Digest:    ee.ImageDigest,
+			Reference: imageRepository(ee.Image) + "@" + ee.ImageDigest,
+		}
+		ee.Image = pinned.Reference
+		return pinned, nil
+	}
+
+	key := eeImageCacheKey{engine: engine.Name(), image: ee.Image}
+	if cached, ok := eeImageCache.Load(key); ok && ee.PullPolicy != "always" {
+		prepared := cached.(ExecutionEnvironmentImage)
+		prepared.Pulled = false
+		if err := checkImageDigest(&prepared, ee.ImageDigest); err != nil {
+			return nil, err
+		}
+		ui.Message(fmt.Sprintf("Using execution environment image %s (already prepared by this plugin process)", prepared.Reference))
+		pinExecutionEnvironmentImage(ee, &prepared)
+		return &prepared, nil
+	}
+
+	present, err := engine.ImagePresent(ee.Image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check execution environment image %s: %w", ee.Image, err)
+	}
+
+	prepared := ExecutionEnvironmentImage{Image: ee.Image, Reference: ee.Image, Engine: engine.Name()}
+	if shouldPullImage(ee.PullPolicy, ee.Image, present) {
+		ui.Message(fmt.Sprintf("Pulling execution environment image %s with %s...", ee.Image, engine.Name()))
+		if err := engine.PullImage(ee.Image, ee.PullArguments); err != nil {
+			return nil, fmt.Errorf("failed to pull execution environment image: %w", err)
+		}
+		prepared.Pulled = true
+	} else if !present {
+		return nil, fmt.Errorf("execution environment image %s is not present locally and pull_policy is %q", ee.Image, ee.PullPolicy)
+	}
+
+	if prepared.Digest, err = engine.ImageDigest(ee.Image); err != nil {
+		return nil, fmt.Errorf("failed to resolve the digest of execution environment image %s: %w", ee.Image, err)
+	}
+	if err := checkImageDigest(&prepared, ee.ImageDigest); err != nil {
+		return nil, err
+	}
+	if prepared.Digest == "" {
+		// Locally built images have no registry digest to pin.
+		ui.Message(fmt.Sprintf("Execution environment image %s has no registry digest; using it by tag", ee.Image))
+	} else {
+		prepared.Reference = imageRepository(ee.Image) + "@" + prepared.Digest
+		ui.Message(fmt.Sprintf("Pinned execution environment image %s to %s", ee.Image, prepared.Reference))
+	}
+
+	eeImageCache.Store(key, prepared)
+	pinExecutionEnvironmentImage(ee, &prepared)
+	return &prepared, nil
+}
+
+// checkImageDigest verifies the resolved digest against the image_digest pin.
+func checkImageDigest(prepared *ExecutionEnvironmentImage, want string) error {
+	if want == "" || prepared.Digest == want {
+		return nil
+	}
+	if prepared.Digest == "" {
+		return fmt.Errorf("execution environment image %s has no registry digest to verify against image_digest %s", prepared.Image, want)
+	}
+	return fmt.Errorf("execution environment image %s resolved to digest %s, expected image_digest %s", prepared.Image, prepared.Digest, want)
+}
+
+// pinExecutionEnvironmentImage points ee at the prepared image. The image is in
+// the local store, so ansible-navigator must not pull (or re-check) it.
+func pinExecutionEnvironmentImage(ee *ExecutionEnvironment, prepared *ExecutionEnvironmentImage) {
+	ee.Image = prepared.Reference
+	ee.PullPolicy = "never"
+}
+
+// validateImageDigest validates execution_environment.image_digest.
+func validateImageDigest(ee *ExecutionEnvironment) []error {
+	if ee.ImageDigest == "" {
+		return nil
+	}
+	if !imageDigestPattern.MatchString(ee.ImageDigest) {
+		return []error{fmt.Errorf(
+			"navigator_config.execution_environment.image_digest must be a sha256 digest (sha256:<64 hex characters>), got %q", ee.ImageDigest)}
+	}
+	if ee.Image == "" {
+		return []error{fmt.Errorf(
+			"navigator_config.execution_environment.image_digest requires navigator_config.execution_environment.image")}
+	}
+	if _, digest, ok := strings.Cut(ee.Image, "@"); ok && digest != ee.ImageDigest {
+		return []error{fmt.Errorf(
+			"navigator_config.execution_environment.image is pinned to %s, which does not match image_digest %s", digest, ee.ImageDigest)}
+	}
+	return nil
+}
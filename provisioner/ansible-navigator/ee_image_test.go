@@ -0,0 +1,163 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDigestA = "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	testDigestB = "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+// recordingPodman returns a podman engine whose CLI answers from outputs, treats
+// unknown `image exists` checks as a missing image, and records every call.
+func recordingPodman(outputs map[string]string, calls *[]string) containerEngine {
+	deps := fakeEngineDeps(outputs, nil, 1000)
+	run := deps.commandOutput
+	deps.commandOutput = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		cmd := name + " " + strings.Join(args, " ")
+		*calls = append(*calls, cmd)
+		if _, ok := outputs[cmd]; !ok && strings.HasPrefix(cmd, "podman image exists ") {
+			return nil, exec.Command("false").Run()
+		}
+		return run(ctx, name, args...)
+	}
+	return detectPodmanEngine(deps)
+}
+
+func TestPrepareExecutionEnvironmentImage_PullsAndPins(t *testing.T) {
+	image := "quay.io/ansible/prepare-pulls:v1"
+	var calls []string
+	engine := recordingPodman(map[string]string{
+		"podman pull --tls-verify=false " + image:                      "",
+		"podman image inspect --format {{json .RepoDigests}} " + image: `["quay.io/ansible/other@` + testDigestB + `","quay.io/ansible/prepare-pulls@` + testDigestA + `"]`,
+	}, &calls)
+	ee := &ExecutionEnvironment{Enabled: boolPtr(true), Image: image, PullPolicy: "missing", PullArguments: []string{"--tls-verify=false"}}
+
+	prepared, err := prepareExecutionEnvironmentImage(newMockUi(), engine, ee)
+	require.NoError(t, err)
+	require.Equal(t, &ExecutionEnvironmentImage{
+		Image:     image,
+		Digest:    testDigestA,
+		Reference: "quay.io/ansible/prepare-pulls@" + testDigestA,
+		Engine:    "podman",
+		Pulled:    true,
+	}, prepared)
+	require.Equal(t, prepared.Reference, ee.Image)
+	require.Equal(t, "never", ee.PullPolicy, "navigator must not pull the pinned image again")
+	require.Contains(t, calls, "podman pull --tls-verify=false "+image)
+
+	// A second build in the same process reuses the prepared image.
+	calls = nil
+	ee = &ExecutionEnvironment{Enabled: boolPtr(true), Image: image, ImageDigest: testDigestA}
+	prepared, err = prepareExecutionEnvironmentImage(newMockUi(), engine, ee)
+	require.NoError(t, err)
+	require.False(t, prepared.Pulled)
+	require.Equal(t, "quay.io/ansible/prepare-pulls@"+testDigestA, ee.Image)
+	require.Empty(t, calls)
+
+	// pull_policy "always" pulls again.
+	ee = &ExecutionEnvironment{Enabled: boolPtr(true), Image: image, PullPolicy: "always", PullArguments: []string{"--tls-verify=false"}}
+	prepared, err = prepareExecutionEnvironmentImage(newMockUi(), engine, ee)
+	require.NoError(t, err)
+	require.True(t, prepared.Pulled)
+	require.Contains(t, calls, "podman pull --tls-verify=false "+image)
+}
+
+func TestPrepareExecutionEnvironmentImage_DigestMismatch(t *testing.T) {
+	image := "quay.io/ansible/prepare-mismatch:v1"
+	var calls []string
+	engine := recordingPodman(map[string]string{
+		"podman image exists " + image:                                 "",
+		"podman image inspect --format {{json .RepoDigests}} " + image: `["quay.io/ansible/prepare-mismatch@` + testDigestB + `"]`,
+	}, &calls)
+	ee := &ExecutionEnvironment{Enabled: boolPtr(true), Image: image, PullPolicy: "missing", ImageDigest: testDigestA}
+
+	_, err := prepareExecutionEnvironmentImage(newMockUi(), engine, ee)
+	require.ErrorContains(t, err, "resolved to digest "+testDigestB+", expected image_digest "+testDigestA)
+	require.Equal(t, image, ee.Image)
+	require.NotContains(t, strings.Join(calls, "\n"), "podman pull", "a present image is not pulled with pull_policy missing")
+}
+
+func TestPrepareExecutionEnvironmentImage_NeverPolicyMissingImage(t *testing.T) {
+	var calls []string
+	engine := recordingPodman(map[string]string{"podman version": ""}, &calls)
+	ee := &ExecutionEnvironment{Enabled: boolPtr(true), Image: "quay.io/ansible/prepare-never:v1", PullPolicy: "never"}
+
+	_, err := prepareExecutionEnvironmentImage(newMockUi(), engine, ee)
+	require.ErrorContains(t, err, `is not present locally and pull_policy is "never"`)
+}
+
+func TestPrepareExecutionEnvironmentImage_LocalImageWithoutDigest(t *testing.T) {
+	image := "localhost/my-ee:dev"
+	var calls []string
+	engine := recordingPodman(map[string]string{
+		"podman image exists " + image:                                 "",
+		"podman image inspect --format {{json .RepoDigests}} " + image: `[]`,
+	}, &calls)
+	ee := &ExecutionEnvironment{Enabled: boolPtr(true), Image: image, PullPolicy: "tag"}
+
+	prepared, err := prepareExecutionEnvironmentImage(newMockUi(), engine, ee)
+	require.NoError(t, err)
+	require.Empty(t, prepared.Digest)
+	require.Equal(t, image, ee.Image)
+}
+
+func TestPrepareExecutionEnvironmentImage_NoEngine(t *testing.T) {
+	ee := &ExecutionEnvironment{Enabled: boolPtr(true), Image: "quay.io/ansible/creator-ee:v1"}
+	prepared, err := prepareExecutionEnvironmentImage(newMockUi(), nil, ee)
+	require.NoError(t, err)
+	require.Nil(t, prepared)
+	require.Equal(t, "quay.io/ansible/creator-ee:v1", ee.Image)
+
+	ee.ImageDigest = testDigestA
+	prepared, err = prepareExecutionEnvironmentImage(newMockUi(), nil, ee)
+	require.NoError(t, err)
+	require.Equal(t, "quay.io/ansible/creator-ee@"+testDigestA, prepared.Reference)
+	require.Equal(t, prepared.Reference, ee.Image)
+}
+
+func TestImageRepository(t *testing.T) {
+	require.Equal(t, "quay.io/ansible/creator-ee", imageRepository("quay.io/ansible/creator-ee:v1"))
+	require.Equal(t, "localhost:5000/ee", imageRepository("localhost:5000/ee"))
+	require.Equal(t, "localhost:5000/ee", imageRepository("localhost:5000/ee:v1@"+testDigestA))
+	require.Equal(t, "docker.io/library/ubuntu", normalizeImageRepository("ubuntu"))
+	require.Equal(t, "docker.io/ansible/ee", normalizeImageRepository("ansible/ee"))
+	require.Equal(t, "localhost/ee", normalizeImageRepository("localhost/ee"))
+}
+
+func TestShouldPullImage(t *testing.T) {
+	require.True(t, shouldPullImage("always", "ee:v1", true))
+	require.False(t, shouldPullImage("never", "ee:v1", false))
+	require.False(t, shouldPullImage("missing", "ee:v1", true))
+	require.True(t, shouldPullImage("missing", "ee:v1", false))
+	require.True(t, shouldPullImage("", "ee:latest", true))
+	require.False(t, shouldPullImage("tag", "ee:v1", true))
+}
+
+func TestValidateImageDigest(t *testing.T) {
+	require.Empty(t, validateImageDigest(&ExecutionEnvironment{Image: "ee:v1", ImageDigest: testDigestA}))
+	require.Empty(t, validateImageDigest(&ExecutionEnvironment{Image: "ee@" + testDigestA, ImageDigest: testDigestA}))
+
+	errs := validateImageDigest(&ExecutionEnvironment{Image: "ee:v1", ImageDigest: "sha256:abc"})
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "must be a sha256 digest")
+
+	errs = validateImageDigest(&ExecutionEnvironment{ImageDigest: testDigestA})
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "requires navigator_config.execution_environment.image")
+
+	errs = validateImageDigest(&ExecutionEnvironment{Image: "ee@" + testDigestB, ImageDigest: testDigestA})
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), "does not match image_digest")
+}
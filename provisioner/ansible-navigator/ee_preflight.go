@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,46 +19,27 @@ func debugWarnf(ui packersdk.Ui, enabled bool, format string, args ...interface{
 }
 
 func isExecutionEnvironmentEnabled(nc *NavigatorConfig) bool {
-	return nc != nil && nc.ExecutionEnvironment != nil && nc.ExecutionEnvironment.Enabled
+	return nc != nil && nc.ExecutionEnvironment != nil && derefBool(nc.ExecutionEnvironment.Enabled)
 }
 
-type eeDockerPreflightDeps struct {
-	stat               func(string) (os.FileInfo, error)
-	lookPath           func(string) (string, error)
-	commandOutput      func(ctx context.Context, name string, args ...string) ([]byte, error)
-	dockerHostProvider func() (string, bool) // value, present
-}
-
-func defaultEEDockerPreflightDeps() eeDockerPreflightDeps {
-	return eeDockerPreflightDeps{
-		stat:     os.Stat,
-		lookPath: exec.LookPath,
-		commandOutput: func(ctx context.Context, name string, args ...string) ([]byte, error) {
-			cmd := exec.CommandContext(ctx, name, args...)
-			return cmd.Output()
-		},
-		dockerHostProvider: func() (string, bool) {
-			v, ok := os.LookupEnv("DOCKER_HOST")
-			return v, ok
-		},
-	}
-}
-
-type eeDockerPreflightResult struct {
-	dockerHostPresent bool
-	dockerHostValue   string
-
-	dockerSockExists      bool
-	dockerSockIsSocket    bool
-	dockerSockStatErrored bool
-
-	dockerClientFound bool
+type eePreflightResult struct {
+	hostEnvKey     string
+	hostEnvPresent bool
 
+	socket               string
+	sockExists           bool
+	sockIsSocket         bool
+	sockStatErrored      bool
+	clientFound          bool
 	dockerdDetected      bool
 	dockerdCheckPossible bool
+
+	imageChecked bool
+	imagePresent bool
+	imageErr     error
 }
 
-func detectDockerdFast(deps eeDockerPreflightDeps) (detected bool, possible bool) {
+func detectDockerdFast(deps containerEngineDeps) (detected bool, possible bool) {
 	// Guard: if ps isn't present, skip silently.
 	if _, err := deps.lookPath("ps"); err != nil {
 		return false, false
@@ -98,17 +79,20 @@ func findExecutableInPath(stat func(string) (os.FileInfo, error), pathValue, exe
 	return false
 }
 
-func collectEEDockerPreflight(ansibleNavigatorPath []string, deps eeDockerPreflightDeps) eeDockerPreflightResult {
-	var res eeDockerPreflightResult
+func collectEEPreflight(engine containerEngine, image string, ansibleNavigatorPath []string, deps containerEngineDeps) eePreflightResult {
+	var res eePreflightResult
 
-	res.dockerHostValue, res.dockerHostPresent = deps.dockerHostProvider()
+	res.hostEnvKey, _ = engine.HostEnv()
+	_, res.hostEnvPresent = deps.env(res.hostEnvKey)
 
-	info, err := deps.stat("/var/run/docker.sock")
-	if err == nil {
-		res.dockerSockExists = true
-		res.dockerSockIsSocket = info.Mode()&os.ModeSocket != 0
-	} else if !os.IsNotExist(err) {
-		res.dockerSockStatErrored = true
+	if res.socket = engine.Socket(); res.socket != "" {
+		info, err := deps.stat(res.socket)
+		if err == nil {
+			res.sockExists = true
+			res.sockIsSocket = info.Mode()&os.ModeSocket != 0
+		} else if !os.IsNotExist(err) {
+			res.sockStatErrored = true
+		}
 	}
 
 	lookupPath := os.Getenv("PATH")
@@ -118,83 +102,243 @@ func collectEEDockerPreflight(ansibleNavigatorPath []string, deps eeDockerPrefli
 			lookupPath = prefix + string(os.PathListSeparator) + lookupPath
 		}
 	}
-	res.dockerClientFound = findExecutableInPath(deps.stat, lookupPath, "docker")
+	res.clientFound = findExecutableInPath(deps.stat, lookupPath, engine.Name())
+
+	if engine.Name() == "docker" {
+		res.dockerdDetected, res.dockerdCheckPossible = detectDockerdFast(deps)
+	}
 
-	res.dockerdDetected, res.dockerdCheckPossible = detectDockerdFast(deps)
+	if image != "" {
+		res.imageChecked = true
+		res.imagePresent, res.imageErr = engine.ImagePresent(image)
+	}
 
 	return res
 }
 
-func emitEEDockerPreflight(ui packersdk.Ui, debugEnabled bool, ansibleNavigatorPath []string) {
+// emitEEPreflight prints debug-only diagnostics for the container engine that
+// runs the execution environment, and for the prepared image (if any). It never
+// changes behavior.
+func emitEEPreflight(ui packersdk.Ui, debugEnabled bool, ansibleNavigatorPath []string, engine containerEngine, ee *ExecutionEnvironment, prepared *ExecutionEnvironmentImage) {
+	emitEEPreflightWithDeps(ui, debugEnabled, ansibleNavigatorPath, engine, ee, prepared, defaultContainerEngineDeps())
+}
+
+// emitEEPreflightWithDeps is a test seam.
+func emitEEPreflightWithDeps(ui packersdk.Ui, debugEnabled bool, ansibleNavigatorPath []string, engine containerEngine, ee *ExecutionEnvironment, prepared *ExecutionEnvironmentImage, deps containerEngineDeps) {
 	if !debugEnabled {
 		return
 	}
+	if prepared != nil && prepared.Verification != nil && prepared.Verification.Passed {
+		// Image signature verification runs even without a detected engine.
+		defer emitImageVerificationPreflight(ui, prepared.Verification)
+	}
+	if engine == nil {
+		return
+	}
 
-	deps := defaultEEDockerPreflightDeps()
-	res := collectEEDockerPreflight(ansibleNavigatorPath, deps)
+	var image, pullPolicy string
+	if ee != nil {
+		image, pullPolicy = ee.Image, ee.PullPolicy
+	}
+	res := collectEEPreflight(engine, image, ansibleNavigatorPath, deps)
 
-	// DOCKER_HOST
-	if !res.dockerHostPresent || strings.TrimSpace(res.dockerHostValue) == "" {
-		debugf(ui, true, "[EE preflight] DOCKER_HOST: unset")
-	} else {
-		// Avoid leaking secrets (user:pass@, tokens, etc.).
-		debugf(ui, true, "[EE preflight] DOCKER_HOST: set (redacted)")
+	mode := "rootful"
+	if engine.Rootless() {
+		mode = "rootless"
 	}
+	relabel := "no"
+	if engine.NeedsSELinuxRelabel() {
+		relabel = "yes"
+	}
+	debugf(ui, true, "[EE preflight] container engine: %s (%s), SELinux relabel: %s", engine.Name(), mode, relabel)
 
-	// docker.sock
-	if res.dockerSockStatErrored {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: stat error")
-	} else if !res.dockerSockExists {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: missing")
-	} else if res.dockerSockIsSocket {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: present (socket)")
+	// Endpoint variable (DOCKER_HOST / CONTAINER_HOST); avoid leaking secrets (user:pass@, tokens, etc.).
+	if !res.hostEnvPresent {
+		debugf(ui, true, "[EE preflight] %s: unset", res.hostEnvKey)
 	} else {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: present (not a socket)")
+		debugf(ui, true, "[EE preflight] %s: set (redacted)", res.hostEnvKey)
+	}
+
+	// Engine socket
+	switch {
+	case res.socket == "":
+		debugf(ui, true, "[EE preflight] %s socket: remote endpoint", engine.Name())
+	case res.sockStatErrored:
+		debugf(ui, true, "[EE preflight] %s: stat error", res.socket)
+	case !res.sockExists:
+		debugf(ui, true, "[EE preflight] %s: missing", res.socket)
+	case res.sockIsSocket:
+		debugf(ui, true, "[EE preflight] %s: present (socket)", res.socket)
+	default:
+		debugf(ui, true, "[EE preflight] %s: present (not a socket)", res.socket)
 	}
 
-	// docker client
-	if res.dockerClientFound {
-		debugf(ui, true, "[EE preflight] docker client: found in PATH")
+	// Engine client
+	if res.clientFound {
+		debugf(ui, true, "[EE preflight] %s client: found in PATH", engine.Name())
 	} else {
-		debugf(ui, true, "[EE preflight] docker client: missing in PATH")
+		debugf(ui, true, "[EE preflight] %s client: missing in PATH", engine.Name())
 	}
 
 	// dockerd heuristic (warning-only)
 	if res.dockerdDetected {
 		debugWarnf(ui, true, "[EE preflight] detected dockerd process (possible DinD); ensure DOCKER_HOST or /var/run/docker.sock is correctly wired")
 	}
+
+	// Execution environment image
+	if res.imageChecked {
+		switch {
+		case res.imageErr != nil:
+			debugWarnf(ui, true, "[EE preflight] image %s: presence check failed: %v", image, res.imageErr)
+		case res.imagePresent:
+			debugf(ui, true, "[EE preflight] image %s: present locally", image)
+		case pullPolicy == "never":
+			debugWarnf(ui, true, "[EE preflight] image %s: not present locally and pull_policy is \"never\"", image)
+		default:
+			debugf(ui, true, "[EE preflight] image %s: not present locally (will be pulled)", image)
+		}
+	}
 }
 
-// emitEEDockerPreflightWithDeps is a test seam.
-func emitEEDockerPreflightWithDeps(ui packersdk.Ui, debugEnabled bool, ansibleNavigatorPath []string, deps eeDockerPreflightDeps) {
-	if !debugEnabled {
-		return
+// emitImageVerificationPreflight reports the signature verification that ran
+// before the first play. A failed verification aborts the build before this point.
+func emitImageVerificationPreflight(ui packersdk.Ui, v *ImageVerification) {
+	source := v.Key
+	if v.Method == ImageVerificationPolicy {
+		source = v.Policy
 	}
-	res := collectEEDockerPreflight(ansibleNavigatorPath, deps)
+	debugf(ui, true, "[EE preflight] image %s: signature verified (%s: %s)", v.Reference, v.Method, source)
+}
 
-	if !res.dockerHostPresent || strings.TrimSpace(res.dockerHostValue) == "" {
-		debugf(ui, true, "[EE preflight] DOCKER_HOST: unset")
-	} else {
-		debugf(ui, true, "[EE preflight] DOCKER_HOST: set (redacted)")
+// execution_environment.preflight modes
+const (
+	EEPreflightWarn = "warn"
+	EEPreflightFail = "fail"
+	EEPreflightOff  = "off"
+)
+
+// eePreflightMode returns the effective execution_environment.preflight mode.
+func eePreflightMode(ee *ExecutionEnvironment) string {
+	if ee == nil || ee.Preflight == "" {
+		return EEPreflightWarn
 	}
+	return ee.Preflight
+}
 
-	if res.dockerSockStatErrored {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: stat error")
-	} else if !res.dockerSockExists {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: missing")
-	} else if res.dockerSockIsSocket {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: present (socket)")
-	} else {
-		debugf(ui, true, "[EE preflight] /var/run/docker.sock: present (not a socket)")
+// eeProxyTarget is the proxy adapter address the execution environment must reach.
+type eeProxyTarget struct {
+	host string
+	port int
+}
+
+// eeProxyCheckScript connects to argv[1]:argv[2]; every EE image has python3.
+const eeProxyCheckScript = "import socket,sys; socket.create_connection((sys.argv[1], int(sys.argv[2])), 5)"
+
+// checkExecutionEnvironment runs the execution environment preflight checks and
+// returns one actionable message per problem found. engine is nil when detection
+// failed with detectErr; proxy is nil when the proxy adapter is not used.
+func checkExecutionEnvironment(engine containerEngine, detectErr error, ee *ExecutionEnvironment, proxy *eeProxyTarget, deps containerEngineDeps) []string {
+	var problems []string
+	if engine == nil {
+		return append(problems, fmt.Sprintf(
+			"no usable container engine: %v. Install docker or podman, or set execution_environment.container_engine", detectErr))
 	}
+	name := engine.Name()
 
-	if res.dockerClientFound {
-		debugf(ui, true, "[EE preflight] docker client: found in PATH")
-	} else {
-		debugf(ui, true, "[EE preflight] docker client: missing in PATH")
+	// Engine client
+	if _, err := deps.output(name, "--version"); err != nil {
+		return append(problems, fmt.Sprintf("the %s client does not respond (`%s --version`): %v", name, name, err))
 	}
 
-	if res.dockerdDetected {
-		debugWarnf(ui, true, "[EE preflight] detected dockerd process (possible DinD); ensure DOCKER_HOST or /var/run/docker.sock is correctly wired")
+	// Engine endpoint
+	if _, err := deps.output(name, "info"); err != nil {
+		endpoint := engine.Socket()
+		if endpoint == "" {
+			key, _ := engine.HostEnv()
+			endpoint, _ = deps.env(key)
+		}
+		hint := "check CONTAINER_HOST or `podman system connection`"
+		if name == "docker" {
+			hint = "start the Docker daemon, or point DOCKER_HOST or `docker context` at a running one"
+			if socket := engine.Socket(); socket != "" {
+				if _, statErr := deps.stat(socket); statErr != nil {
+					hint = fmt.Sprintf("the socket %s does not exist; start the Docker daemon, or point DOCKER_HOST or `docker context` at a running one", socket)
+				}
+			}
+		}
+		return append(problems, fmt.Sprintf("cannot reach the %s endpoint %s (`%s info`): %v; %s", name, endpoint, name, err, hint))
+	}
+
+	// Image
+	imageAvailable := false
+	if ee.Image != "" {
+		present, err := engine.ImagePresent(ee.Image)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("cannot check image %s: %v", ee.Image, err))
+		case present:
+			imageAvailable = true
+		case ee.PullPolicy == "never":
+			problems = append(problems, fmt.Sprintf(
+				"image %s is not present locally and pull_policy is \"never\"; pull it first or change pull_policy", ee.Image))
+		default:
+			if err := runCommand(deps, name, "manifest", "inspect", ee.Image); err != nil {
+				problems = append(problems, fmt.Sprintf(
+					"image %s is not present locally and cannot be pulled: %v; check the image name and registry credentials (`%s login`)", ee.Image, err, name))
+			} else {
+				imageAvailable = true
+			}
+		}
+	}
+
+	// Volume mount sources
+	for i, mount := range ee.VolumeMounts {
+		if _, err := deps.stat(mount.Src); err != nil {
+			problems = append(problems, fmt.Sprintf(
+				"execution_environment.volume_mounts[%d].src %s does not exist on the build host", i, mount.Src))
+		}
+	}
+
+	// Proxy adapter, from inside a throwaway container with the EE's network settings
+	if proxy != nil && imageAvailable {
+		args := []string{"run", "--rm", "--pull=missing"}
+		args = append(args, ee.ContainerOptions...)
+		if opt := hostGatewayAddHostOption(proxy.host, engine); opt != "" {
+			args = append(args, opt)
+		}
+		args = append(args, "--entrypoint", "python3", ee.Image, "-c", eeProxyCheckScript, proxy.host, strconv.Itoa(proxy.port))
+		if err := runCommand(deps, name, args...); err != nil {
+			problems = append(problems, fmt.Sprintf(
+				"the proxy adapter at %s:%d is not reachable from inside the execution environment: %v; "+
+					"set ansible_proxy_host to %s with ansible_proxy_bind_address = \"0.0.0.0\", or add \"--network=host\" to container_options",
+				proxy.host, proxy.port, err, engine.HostGateway()))
+		}
+	}
+
+	return problems
+}
+
+// runEEPreflight runs the execution environment preflight checks before the
+// first play. Problems are warnings in "warn" mode and fail the build in "fail" mode.
+func runEEPreflight(ui packersdk.Ui, engine containerEngine, detectErr error, ee *ExecutionEnvironment, proxy *eeProxyTarget, deps containerEngineDeps) error {
+	mode := eePreflightMode(ee)
+	if mode == EEPreflightOff {
+		return nil
+	}
+
+	ui.Message("Running execution environment preflight checks...")
+	problems := checkExecutionEnvironment(engine, detectErr, ee, proxy, deps)
+	if len(problems) == 0 {
+		ui.Message("Execution environment preflight checks passed")
+		return nil
+	}
+
+	if mode == EEPreflightFail {
+		return fmt.Errorf("execution environment preflight failed:\n  - %s\n(set execution_environment.preflight = \"warn\" to continue anyway)",
+			strings.Join(problems, "\n  - "))
+	}
+	for _, problem := range problems {
+		ui.Message(fmt.Sprintf("Warning: [EE preflight] %s", problem))
 	}
+	return nil
 }
@@ -3,7 +3,9 @@ package ansiblenavigator
 import (
 	"bytes"
 	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -15,7 +17,7 @@ func TestEEDockerPreflight_Gating(t *testing.T) {
 	uiOut := new(bytes.Buffer)
 	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: uiOut}
 
-	deps := eeDockerPreflightDeps{
+	deps := containerEngineDeps{
 		stat: func(string) (os.FileInfo, error) { return nil, os.ErrNotExist },
 		lookPath: func(string) (string, error) {
 			return "", os.ErrNotExist
@@ -23,11 +25,11 @@ func TestEEDockerPreflight_Gating(t *testing.T) {
 		commandOutput: func(ctx context.Context, name string, args ...string) ([]byte, error) {
 			return nil, os.ErrNotExist
 		},
-		dockerHostProvider: func() (string, bool) { return "", false },
+		lookupEnv: func(string) (string, bool) { return "", false },
 	}
 
 	// Disabled
-	emitEEDockerPreflightWithDeps(ui, false, nil, deps)
+	emitEEPreflightWithDeps(ui, false, nil, detectDockerEngine(deps), nil, nil, deps)
 	require.Empty(t, strings.TrimSpace(uiOut.String()))
 }
 
@@ -35,7 +37,7 @@ func TestEEDockerPreflight_DockerMissing_AndDockerdWarning(t *testing.T) {
 	uiOut := new(bytes.Buffer)
 	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: uiOut}
 
-	deps := eeDockerPreflightDeps{
+	deps := containerEngineDeps{
 		stat: func(string) (os.FileInfo, error) { return nil, os.ErrNotExist },
 		lookPath: func(name string) (string, error) {
 			if name == "ps" {
@@ -50,10 +52,10 @@ func TestEEDockerPreflight_DockerMissing_AndDockerdWarning(t *testing.T) {
 			}
 			return nil, os.ErrNotExist
 		},
-		dockerHostProvider: func() (string, bool) { return "", false },
+		lookupEnv: func(string) (string, bool) { return "", false },
 	}
 
-	emitEEDockerPreflightWithDeps(ui, true, []string{"/nonexistent"}, deps)
+	emitEEPreflightWithDeps(ui, true, []string{"/nonexistent"}, detectDockerEngine(deps), nil, nil, deps)
 	out := uiOut.String()
 
 	require.Contains(t, out, "[DEBUG] [EE preflight] DOCKER_HOST: unset")
@@ -61,3 +63,102 @@ func TestEEDockerPreflight_DockerMissing_AndDockerdWarning(t *testing.T) {
 	require.Contains(t, out, "[DEBUG] [EE preflight] docker client: missing in PATH")
 	require.Contains(t, out, "[DEBUG][WARN] [EE preflight] detected dockerd process")
 }
+
+// healthyDockerOutputs are the docker CLI answers of a working engine that has image.
+func healthyDockerOutputs(image string) map[string]string {
+	return map[string]string{
+		"docker --version": "Docker version 27.0.3",
+		"docker info":      "Server Version: 27.0.3",
+		"docker image inspect --format {{.Id}} " + image: "sha256:0123",
+	}
+}
+
+func TestCheckExecutionEnvironment_Healthy(t *testing.T) {
+	image := "quay.io/ansible/creator-ee:v1"
+	outputs := healthyDockerOutputs(image)
+	proxyRun := "docker run --rm --pull=missing --add-host=host.docker.internal:host-gateway --entrypoint python3 " + image +
+		" -c " + eeProxyCheckScript + " host.docker.internal 2222"
+	outputs[proxyRun] = ""
+	deps := fakeEngineDeps(outputs, nil, 0)
+	deps.stat = func(path string) (os.FileInfo, error) {
+		if path == "/srv/data" {
+			return nil, nil
+		}
+		return nil, os.ErrNotExist
+	}
+	ee := &ExecutionEnvironment{Image: image, VolumeMounts: []VolumeMount{{Src: "/srv/data", Dest: "/data"}}}
+
+	problems := checkExecutionEnvironment(detectDockerEngine(deps), nil, ee, &eeProxyTarget{host: "host.docker.internal", port: 2222}, deps)
+	require.Empty(t, problems)
+}
+
+func TestCheckExecutionEnvironment_Problems(t *testing.T) {
+	image := "quay.io/ansible/creator-ee:v1"
+
+	problems := checkExecutionEnvironment(nil, errors.New("no container engine found in PATH"), &ExecutionEnvironment{}, nil, fakeEngineDeps(nil, nil, 0))
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0], "no usable container engine: no container engine found in PATH")
+
+	// Daemon down: the socket from the docker context is missing.
+	deps := fakeEngineDeps(map[string]string{"docker --version": "Docker version 27.0.3"}, nil, 0)
+	problems = checkExecutionEnvironment(detectDockerEngine(deps), nil, &ExecutionEnvironment{Image: image}, nil, deps)
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0], "cannot reach the docker endpoint /var/run/docker.sock")
+	require.Contains(t, problems[0], "the socket /var/run/docker.sock does not exist")
+
+	// Missing image with pull_policy never, missing mount source, unreachable proxy.
+	outputs := healthyDockerOutputs("other:v1")
+	deps = fakeEngineDeps(outputs, nil, 0)
+	run := deps.commandOutput
+	deps.commandOutput = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if args[0] == "image" {
+			return nil, exec.Command("false").Run()
+		}
+		return run(ctx, name, args...)
+	}
+	ee := &ExecutionEnvironment{Image: image, PullPolicy: "never", VolumeMounts: []VolumeMount{{Src: "/missing", Dest: "/data"}}}
+	problems = checkExecutionEnvironment(detectDockerEngine(deps), nil, ee, &eeProxyTarget{host: "127.0.0.1", port: 2222}, deps)
+	require.Len(t, problems, 2, "the proxy check needs the image")
+	require.Contains(t, problems[0], `image quay.io/ansible/creator-ee:v1 is not present locally and pull_policy is "never"`)
+	require.Contains(t, problems[1], "execution_environment.volume_mounts[0].src /missing does not exist on the build host")
+
+	// Missing image that cannot be pulled.
+	ee = &ExecutionEnvironment{Image: image}
+	problems = checkExecutionEnvironment(detectDockerEngine(deps), nil, ee, nil, deps)
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0], "is not present locally and cannot be pulled")
+	require.Contains(t, problems[0], "`docker login`")
+
+	// Proxy unreachable from inside the container (127.0.0.1 is the container itself).
+	deps = fakeEngineDeps(healthyDockerOutputs(image), nil, 0)
+	problems = checkExecutionEnvironment(detectDockerEngine(deps), nil, ee, &eeProxyTarget{host: "127.0.0.1", port: 2222}, deps)
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0], "the proxy adapter at 127.0.0.1:2222 is not reachable from inside the execution environment")
+	require.Contains(t, problems[0], "set ansible_proxy_host to host.docker.internal")
+}
+
+func TestRunEEPreflight_Modes(t *testing.T) {
+	deps := fakeEngineDeps(nil, nil, 0)
+	detectErr := errors.New("no container engine found in PATH")
+
+	ui := newMockUi().(*mockUi)
+	require.NoError(t, runEEPreflight(ui, nil, detectErr, &ExecutionEnvironment{}, nil, deps))
+	require.Contains(t, strings.Join(ui.messageMessages, "\n"), "Warning: [EE preflight] no usable container engine")
+
+	err := runEEPreflight(newMockUi(), nil, detectErr, &ExecutionEnvironment{Preflight: EEPreflightFail}, nil, deps)
+	require.ErrorContains(t, err, "execution environment preflight failed:\n  - no usable container engine")
+
+	ui = newMockUi().(*mockUi)
+	require.NoError(t, runEEPreflight(ui, nil, detectErr, &ExecutionEnvironment{Preflight: EEPreflightOff}, nil, deps))
+	require.Empty(t, ui.messageMessages)
+}
+
+func TestValidateNavigatorSettings_Preflight(t *testing.T) {
+	config := &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: boolPtr(true), Preflight: "strict"}}
+	errs := validateNavigatorSettings(config)
+	require.Len(t, errs, 1)
+	require.Contains(t, errs[0].Error(), `navigator_config.execution_environment.preflight must be "warn", "fail" or "off" (got "strict")`)
+
+	config.ExecutionEnvironment.Preflight = EEPreflightFail
+	require.Empty(t, validateNavigatorSettings(config))
+}
@@ -0,0 +1,111 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"fmt"
+	"strings"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// Image verification methods
+const (
+	ImageVerificationCosign = "cosign"
+	ImageVerificationPolicy = "policy"
+)
+
+// ImageVerification is the result of execution_environment.verify.
+type ImageVerification struct {
+	// Method is "cosign" or "policy".
+	Method string `json:"method"`
+	// Key is the cosign public key (method "cosign").
+	Key string `json:"key,omitempty"`
+	// Policy is the containers-policy.json path (method "policy").
+	Policy string `json:"policy,omitempty"`
+	// Reference is the image reference that was verified.
+	Reference string `json:"reference"`
+	Passed    bool   `json:"passed"`
+	Message   string `json:"message,omitempty"`
+}
+
+// isCosignKMSKey reports whether a cosign key is a KMS or provider URI rather
+// than a file (awskms://, gcpkms://, hashivault://, k8s://, ...).
+func isCosignKMSKey(key string) bool {
+	return strings.Contains(key, "://")
+}
+
+// validateImageVerify validates execution_environment.verify.
+func validateImageVerify(ee *ExecutionEnvironment) []error {
+	v := ee.Verify
+	if v == nil {
+		return nil
+	}
+	var errs []error
+	switch {
+	case v.CosignKey == "" && v.Policy == "":
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.verify requires cosign_key or policy"))
+	case v.CosignKey != "" && v.Policy != "":
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.verify: cosign_key and policy are mutually exclusive"))
+	case v.CosignKey != "" && !isCosignKMSKey(v.CosignKey):
+		if err := validateFileConfig(v.CosignKey, "navigator_config.execution_environment.verify.cosign_key", true); err != nil {
+			errs = append(errs, err)
+		}
+	case v.Policy != "":
+		if err := validateFileConfig(v.Policy, "navigator_config.execution_environment.verify.policy", true); err != nil {
+			errs = append(errs, err)
+		}
+		if ee.ContainerEngine == "docker" {
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.execution_environment.verify.policy requires the podman container engine"))
+		}
+	}
+	if ee.Image == "" {
+		errs = append(errs, fmt.Errorf(
+			"navigator_config.execution_environment.verify requires navigator_config.execution_environment.image"))
+	}
+	return errs
+}
+
+// verifyExecutionEnvironmentImage verifies the signature of reference, the
+// prepared execution environment image, as configured by ee.Verify. The result
+// is returned even when verification fails, so that it can be reported.
+func verifyExecutionEnvironmentImage(ui packersdk.Ui, engine containerEngine, ee *ExecutionEnvironment, reference string, deps containerEngineDeps) (*ImageVerification, error) {
+	v := ee.Verify
+	result := &ImageVerification{Reference: reference}
+
+	var err error
+	if v.CosignKey != "" {
+		result.Method, result.Key = ImageVerificationCosign, v.CosignKey
+		ui.Message(fmt.Sprintf("Verifying execution environment image %s with cosign...", reference))
+		if _, lookErr := deps.lookPath("cosign"); lookErr != nil {
+			err = fmt.Errorf("cosign not found in PATH")
+		} else {
+			err = runCommand(deps, "cosign", "verify", "--key", v.CosignKey, reference)
+		}
+	} else {
+		result.Method, result.Policy = ImageVerificationPolicy, v.Policy
+		ui.Message(fmt.Sprintf("Verifying execution environment image %s against %s...", reference, v.Policy))
+		if engine == nil || engine.Name() != "podman" {
+			err = fmt.Errorf("verify.policy requires the podman container engine")
+		} else {
+			// podman evaluates the signature policy on every pull, including
+			// pulls of images that are already in the local store.
+			args := append(append([]string{}, ee.PullArguments...), "--signature-policy="+v.Policy)
+			err = engine.PullImage(reference, args)
+		}
+	}
+
+	if err != nil {
+		result.Message = err.Error()
+		return result, fmt.Errorf("execution environment image %s failed signature verification (%s): %w", reference, result.Method, err)
+	}
+	result.Passed = true
+	ui.Message(fmt.Sprintf("Execution environment image %s signature verified (%s)", reference, result.Method))
+	return result, nil
+}
@@ -0,0 +1,145 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"github.com/stretchr/testify/require"
+)
+
+const testVerifyReference = "quay.io/ansible/creator-ee@" + testDigestA
+
+func TestVerifyExecutionEnvironmentImage_Cosign(t *testing.T) {
+	deps := fakeEngineDeps(map[string]string{
+		"cosign verify --key /keys/cosign.pub " + testVerifyReference: `[{"critical":{}}]`,
+	}, nil, 0)
+	ee := &ExecutionEnvironment{Verify: &ImageVerifyConfig{CosignKey: "/keys/cosign.pub"}}
+
+	result, err := verifyExecutionEnvironmentImage(newMockUi(), nil, ee, testVerifyReference, deps)
+	require.NoError(t, err)
+	require.Equal(t, &ImageVerification{
+		Method:    ImageVerificationCosign,
+		Key:       "/keys/cosign.pub",
+		Reference: testVerifyReference,
+		Passed:    true,
+	}, result)
+}
+
+func TestVerifyExecutionEnvironmentImage_CosignFailure(t *testing.T) {
+	deps := fakeEngineDeps(map[string]string{"cosign version": ""}, nil, 0)
+	deps.commandOutput = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return exec.Command("sh", "-c", "echo 'Error: no matching signatures' >&2; exit 1").Output()
+	}
+	ee := &ExecutionEnvironment{Verify: &ImageVerifyConfig{CosignKey: "/keys/cosign.pub"}}
+
+	result, err := verifyExecutionEnvironmentImage(newMockUi(), nil, ee, testVerifyReference, deps)
+	require.ErrorContains(t, err, "failed signature verification (cosign)")
+	require.ErrorContains(t, err, "no matching signatures")
+	require.False(t, result.Passed)
+	require.Contains(t, result.Message, "no matching signatures")
+
+	_, err = verifyExecutionEnvironmentImage(newMockUi(), nil, ee, testVerifyReference, fakeEngineDeps(nil, nil, 0))
+	require.ErrorContains(t, err, "cosign not found in PATH")
+}
+
+func TestVerifyExecutionEnvironmentImage_Policy(t *testing.T) {
+	var calls []string
+	engine := recordingPodman(map[string]string{
+		"podman pull --tls-verify=false --signature-policy=/etc/packer/policy.json " + testVerifyReference: "",
+	}, &calls)
+	calls = nil // ignore detection
+	ee := &ExecutionEnvironment{
+		PullArguments: []string{"--tls-verify=false"},
+		Verify:        &ImageVerifyConfig{Policy: "/etc/packer/policy.json"},
+	}
+
+	result, err := verifyExecutionEnvironmentImage(newMockUi(), engine, ee, testVerifyReference, fakeEngineDeps(nil, nil, 0))
+	require.NoError(t, err)
+	require.True(t, result.Passed)
+	require.Equal(t, ImageVerificationPolicy, result.Method)
+	require.Equal(t, []string{"podman pull --tls-verify=false --signature-policy=/etc/packer/policy.json " + testVerifyReference}, calls)
+	require.Equal(t, []string{"--tls-verify=false"}, ee.PullArguments)
+
+	docker := detectDockerEngine(fakeEngineDeps(nil, nil, 0))
+	_, err = verifyExecutionEnvironmentImage(newMockUi(), docker, ee, testVerifyReference, fakeEngineDeps(nil, nil, 0))
+	require.ErrorContains(t, err, "verify.policy requires the podman container engine")
+}
+
+func TestValidateImageVerify(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "cosign.pub")
+	require.NoError(t, os.WriteFile(key, []byte("-----BEGIN PUBLIC KEY-----\n"), 0o600))
+
+	ee := &ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{CosignKey: key}}
+	require.Empty(t, validateImageVerify(ee))
+	ee.Verify.CosignKey = "awskms:///alias/ee-signing"
+	require.Empty(t, validateImageVerify(ee))
+
+	tests := []struct {
+		name   string
+		ee     *ExecutionEnvironment
+		errMsg string
+	}{
+		{"empty", &ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{}}, "requires cosign_key or policy"},
+		{"both", &ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{CosignKey: key, Policy: key}}, "mutually exclusive"},
+		{"missing key", &ExecutionEnvironment{Image: "ee:v1", Verify: &ImageVerifyConfig{CosignKey: key + ".missing"}}, "verify.cosign_key"},
+		{"policy with docker", &ExecutionEnvironment{Image: "ee:v1", ContainerEngine: "docker", Verify: &ImageVerifyConfig{Policy: key}}, "requires the podman container engine"},
+		{"no image", &ExecutionEnvironment{Verify: &ImageVerifyConfig{CosignKey: key}}, "verify requires navigator_config.execution_environment.image"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateImageVerify(tt.ee)
+			require.Len(t, errs, 1)
+			require.Contains(t, errs[0].Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestEEPreflight_ReportsImageVerification(t *testing.T) {
+	uiOut := new(bytes.Buffer)
+	ui := &packersdk.BasicUi{Reader: new(bytes.Buffer), Writer: uiOut}
+	prepared := &ExecutionEnvironmentImage{
+		Reference: testVerifyReference,
+		Verification: &ImageVerification{
+			Method: ImageVerificationCosign, Key: "/keys/cosign.pub", Reference: testVerifyReference, Passed: true,
+		},
+	}
+
+	// Reported even when no container engine was detected.
+	emitEEPreflightWithDeps(ui, true, nil, nil, &ExecutionEnvironment{}, prepared, fakeEngineDeps(nil, nil, 0))
+	require.Contains(t, uiOut.String(), "[EE preflight] image "+testVerifyReference+": signature verified (cosign: /keys/cosign.pub)")
+}
+
+func TestWriteEarlySummary_RecordsFailedImageVerification(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "summary.json")
+	p := &Provisioner{}
+	p.config.StructuredLogging = true
+	p.config.LogOutputPath = logPath
+	p.eeImage = &ExecutionEnvironmentImage{
+		Image:     "quay.io/ansible/creator-ee:v1",
+		Reference: testVerifyReference,
+		Verification: &ImageVerification{
+			Method: ImageVerificationCosign, Key: "/keys/cosign.pub", Reference: testVerifyReference, Message: "no matching signatures",
+		},
+	}
+
+	p.writeEarlySummary(newMockUi())
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	var summary Summary
+	require.NoError(t, json.Unmarshal(data, &summary))
+	require.NotNil(t, summary.ExecutionEnvironmentImage)
+	require.False(t, summary.ExecutionEnvironmentImage.Verification.Passed)
+	require.Contains(t, string(data), `"verification"`)
+}
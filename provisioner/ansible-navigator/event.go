@@ -33,6 +33,31 @@ type Summary struct {
 	TasksTotal  int              `json:"tasks_total"`
 	TasksFailed int              `json:"tasks_failed"`
 	FailedTasks []NavigatorEvent `json:"failed_tasks"`
+	// GalaxyVerification is set when galaxy_keyring enabled collection verification.
+	GalaxyVerification *GalaxyVerification `json:"galaxy_verification,omitempty"`
+	// ExecutionEnvironmentImage is the execution environment image, pinned by digest when known.
+	ExecutionEnvironmentImage *ExecutionEnvironmentImage `json:"execution_environment_image,omitempty"`
+}
+
+// Collection verification statuses
+const (
+	CollectionVerificationVerified = "verified"
+	CollectionVerificationFailed   = "failed"
+)
+
+// GalaxyVerification contains the result of `ansible-galaxy collection verify`
+type GalaxyVerification struct {
+	Keyring     string                   `json:"keyring"`
+	Passed      bool                     `json:"passed"`
+	Collections []CollectionVerification `json:"collections"`
+}
+
+// CollectionVerification is the verification result for a single collection
+type CollectionVerification struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
 }
 
 // handleNavigatorEvent processes individual ansible-navigator JSON events
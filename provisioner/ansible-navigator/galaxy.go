@@ -11,6 +11,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -24,10 +25,19 @@ import (
 type GalaxyManager struct {
 	config *Config
 	ui     packersdk.Ui
+	// engine is the detected container engine used for installs inside the
+	// execution environment. When nil, the engine is resolved from the config.
+	engine containerEngine
+	// verification holds the collection verification result when galaxy_keyring is set.
+	verification *GalaxyVerification
 }
 
 // NewGalaxyManager creates a new GalaxyManager instance
 func NewGalaxyManager(config *Config, ui packersdk.Ui) *GalaxyManager {
+	// Galaxy server tokens are redacted from everything the manager prints.
+	if secrets := galaxyServerSecrets(config.GalaxyServers); len(secrets) > 0 {
+		ui = &redactingUi{Ui: ui, secrets: secrets}
+	}
 	return &GalaxyManager{
 		config: config,
 		ui:     ui,
@@ -66,22 +76,42 @@ func (gm *GalaxyManager) installFromFile(filePath string) error {
 	hasRoles := regexp.MustCompile(`(?m)^roles:`).Match(content)
 	hasCollections := regexp.MustCompile(`(?m)^collections:`).Match(content)
 
-	// Install roles if present (or if it's v1 format without collections)
-	if hasRoles || !hasCollections {
-		if err := gm.installRolesFromFile(filePath); err != nil {
-			return err
-		}
+	// Installs inside the execution environment read the requirements file
+	// from its mount point in the container.
+	reqFile := filePath
+	if gm.installInExecutionEnvironment() {
+		reqFile = path.Join(eeRequirementsMountPath, filepath.Base(filePath))
 	}
 
-	// Install collections if present
-	if hasCollections {
-		if err := gm.installCollectionsFromFile(filePath); err != nil {
+	if gm.config.GalaxyParallelism > 1 {
+		if err := gm.installFromFileParallel(content); err != nil {
 			return err
 		}
+	} else {
+		// Install roles if present (or if it's v1 format without collections)
+		if hasRoles || !hasCollections {
+			if err := gm.installRolesFromFile(reqFile); err != nil {
+				return err
+			}
+		}
+
+		// Install collections if present
+		if hasCollections {
+			if err := gm.installCollectionsFromFile(reqFile); err != nil {
+				return err
+			}
+		}
+
+		if !hasRoles && !hasCollections {
+			gm.ui.Message("Warning: requirements file does not contain 'roles:' or 'collections:' sections")
+		}
 	}
 
-	if !hasRoles && !hasCollections {
-		gm.ui.Message("Warning: requirements file does not contain 'roles:' or 'collections:' sections")
+	// Verify installed collections against their signatures
+	if hasCollections && gm.verifySignatures() {
+		if err := gm.verifyCollections(reqFile); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -90,40 +120,53 @@ func (gm *GalaxyManager) installFromFile(filePath string) error {
 // installRolesFromFile installs roles from a requirements file
 func (gm *GalaxyManager) installRolesFromFile(filePath string) error {
 	gm.ui.Message("Installing roles from requirements file...")
-	args := []string{"install", fmt.Sprintf("-r=%s", filepath.ToSlash(filePath))}
 
 	// Add roles path if specified
-	if gm.config.RolesPath != "" {
-		args = append(args, fmt.Sprintf("-p=%s", gm.config.RolesPath))
+	rolesPath := gm.config.RolesPath
+	if gm.installInExecutionEnvironment() {
+		rolesPath = eeRolesMountPath
 	}
 
-	// Add offline option
-	if gm.config.OfflineMode {
-		args = append(args, "--offline")
-	}
-
-	// Add force options
-	if gm.config.GalaxyForceWithDeps {
-		args = append(args, "--force-with-deps")
-	} else if gm.config.GalaxyForce {
-		args = append(args, "--force")
-	}
-
-	// Append user-provided args last
-	args = append(args, gm.config.GalaxyArgs...)
-
-	return gm.executeGalaxyCommand(args, "roles")
+	return gm.executeGalaxyCommand(gm.rolesInstallArgs(filePath, rolesPath), "roles")
 }
 
 // installCollectionsFromFile installs collections from a requirements file
 func (gm *GalaxyManager) installCollectionsFromFile(filePath string) error {
 	gm.ui.Message("Installing collections from requirements file...")
-	args := []string{"collection", "install", fmt.Sprintf("-r=%s", filepath.ToSlash(filePath))}
 
 	// Add collections path if specified
-	if gm.config.CollectionsPath != "" {
-		args = append(args, fmt.Sprintf("-p=%s", gm.config.CollectionsPath))
+	collectionsPath := gm.config.CollectionsPath
+	if gm.installInExecutionEnvironment() {
+		collectionsPath = eeCollectionsMountPath
+	}
+
+	return gm.executeGalaxyCommand(gm.collectionsInstallArgs(filePath, collectionsPath), "collections")
+}
+
+// rolesInstallArgs builds `ansible-galaxy install` arguments for a roles requirements file.
+func (gm *GalaxyManager) rolesInstallArgs(filePath string, rolesPath string) []string {
+	args := []string{"install", fmt.Sprintf("-r=%s", filepath.ToSlash(filePath))}
+	if rolesPath != "" {
+		args = append(args, fmt.Sprintf("-p=%s", rolesPath))
 	}
+	return append(args, gm.commonInstallArgs()...)
+}
+
+// collectionsInstallArgs builds `ansible-galaxy collection install` arguments for a
+// collections requirements file.
+func (gm *GalaxyManager) collectionsInstallArgs(filePath string, collectionsPath string) []string {
+	args := []string{"collection", "install", fmt.Sprintf("-r=%s", filepath.ToSlash(filePath))}
+	if collectionsPath != "" {
+		args = append(args, fmt.Sprintf("-p=%s", collectionsPath))
+	}
+	args = append(args, gm.signatureArgs()...)
+	return append(args, gm.commonInstallArgs()...)
+}
+
+// commonInstallArgs returns the offline/force/user arguments shared by role and
+// collection installs.
+func (gm *GalaxyManager) commonInstallArgs() []string {
+	var args []string
 
 	// Add offline option
 	if gm.config.OfflineMode {
@@ -138,18 +181,33 @@ func (gm *GalaxyManager) installCollectionsFromFile(filePath string) error {
 	}
 
 	// Append user-provided args last
-	args = append(args, gm.config.GalaxyArgs...)
-
-	return gm.executeGalaxyCommand(args, "collections")
+	return append(args, gm.config.GalaxyArgs...)
 }
 
 // NOTE: legacy inline collections and legacy galaxy_file paths are intentionally removed.
 
 // executeGalaxyCommand executes an ansible-galaxy command with streaming output
 func (gm *GalaxyManager) executeGalaxyCommand(args []string, target string) error {
+	if gm.installInExecutionEnvironment() {
+		return gm.executeGalaxyCommandInExecutionEnvironment(args, target)
+	}
+
 	cmd := exec.Command(gm.config.GalaxyCommand, args...)
-	cmd.Env = os.Environ()
+	cmd.Env = append(os.Environ(), galaxyServerEnv(gm.config.GalaxyServers)...)
+	return gm.runGalaxyProcess(cmd, target)
+}
 
+// runGalaxyProcess runs an ansible-galaxy process, streaming its output to the UI.
+func (gm *GalaxyManager) runGalaxyProcess(cmd *exec.Cmd, target string) error {
+	if err := streamCommand(gm.ui, cmd); err != nil {
+		return fmt.Errorf("ansible-galaxy failed for %s: %w", target, err)
+	}
+	return nil
+}
+
+// streamCommand runs cmd on the build host, streaming its stdout and stderr to
+// the UI line by line.
+func streamCommand(ui packersdk.Ui, cmd *exec.Cmd) error {
 	// Setup pipes
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -170,11 +228,11 @@ func (gm *GalaxyManager) executeGalaxyCommand(args []string, target string) erro
 			line, err := reader.ReadString('\n')
 			if line != "" {
 				line = strings.TrimRightFunc(line, unicode.IsSpace)
-				gm.ui.Message(line)
+				ui.Message(line)
 			}
 			if err != nil {
 				if err != io.EOF {
-					gm.ui.Error(err.Error())
+					ui.Error(err.Error())
 				}
 				break
 			}
@@ -187,16 +245,12 @@ func (gm *GalaxyManager) executeGalaxyCommand(args []string, target string) erro
 
 	// Execute command
 	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start ansible-galaxy: %w", err)
+		return fmt.Errorf("failed to start %s: %w", filepath.Base(cmd.Path), err)
 	}
 
 	wg.Wait()
 
-	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("ansible-galaxy failed for %s: %w", target, err)
-	}
-
-	return nil
+	return cmd.Wait()
 }
 
 // SetupEnvironmentPaths configures ANSIBLE_COLLECTIONS_PATH and ANSIBLE_ROLES_PATH
@@ -0,0 +1,229 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	// GalaxyRuntimeHost runs ansible-galaxy on the build host.
+	GalaxyRuntimeHost = "host"
+	// GalaxyRuntimeExecutionEnvironment runs ansible-galaxy inside the
+	// configured execution environment image.
+	GalaxyRuntimeExecutionEnvironment = "execution_environment"
+
+	// Container paths used for the collections/roles/requirements mounts.
+	eeCollectionsMountPath  = "/tmp/.packer_ansible/collections"
+	eeRolesMountPath        = "/tmp/.packer_ansible/roles"
+	eeRequirementsMountPath = "/tmp/.packer_ansible/requirements"
+)
+
+// installInExecutionEnvironment reports whether ansible-galaxy runs inside the EE image.
+func (gm *GalaxyManager) installInExecutionEnvironment() bool {
+	return gm.config.GalaxyRuntime == GalaxyRuntimeExecutionEnvironment
+}
+
+// executeGalaxyCommandInExecutionEnvironment runs ansible-galaxy in a throwaway
+// container of the execution environment image, using the same container engine,
+// pull policy, environment and volume mounts that the navigator config uses.
+func (gm *GalaxyManager) executeGalaxyCommandInExecutionEnvironment(args []string, target string) error {
+	ee := gm.config.NavigatorConfig.ExecutionEnvironment
+
+	var engine string
+	if gm.engine != nil {
+		engine = gm.engine.Name()
+	} else {
+		resolved, err := resolveContainerEngine(ee.ContainerEngine, exec.LookPath)
+		if err != nil {
+			return err
+		}
+		engine = resolved
+	}
+
+	runArgs, err := gm.buildExecutionEnvironmentGalaxyArgs(engine, args)
+	if err != nil {
+		return err
+	}
+
+	gm.ui.Message(fmt.Sprintf("Executing Ansible Galaxy in execution environment: %s %s", engine, strings.Join(runArgs, " ")))
+
+	cmd := exec.Command(engine, runArgs...)
+	// Galaxy server settings are passed through by name (-e KEY) so tokens never
+	// appear on the container engine command line.
+	cmd.Env = append(os.Environ(), galaxyServerEnv(gm.config.GalaxyServers)...)
+	if gm.engine != nil {
+		if key, value := gm.engine.HostEnv(); value != "" {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+	return gm.runGalaxyProcess(cmd, target)
+}
+
+// buildExecutionEnvironmentGalaxyArgs builds the container engine arguments that
+// run ansible-galaxy with galaxyArgs inside the execution environment image.
+func (gm *GalaxyManager) buildExecutionEnvironmentGalaxyArgs(engine string, galaxyArgs []string) ([]string, error) {
+	ee := gm.config.NavigatorConfig.ExecutionEnvironment
+	if ee.Image == "" {
+		return nil, fmt.Errorf("navigator_config.execution_environment.image must be set when galaxy_runtime is %q",
+			GalaxyRuntimeExecutionEnvironment)
+	}
+
+	collectionsPath, err := absHostPath(gm.config.CollectionsPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collections_path: %w", err)
+	}
+	rolesPath, err := absHostPath(gm.config.RolesPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid roles_path: %w", err)
+	}
+	requirementsDir, err := absHostPath(filepath.Dir(gm.config.RequirementsFile))
+	if err != nil {
+		return nil, fmt.Errorf("invalid requirements_file: %w", err)
+	}
+	// Mount sources must exist before the container starts.
+	for _, dir := range []string{collectionsPath, rolesPath} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	runArgs := []string{"run", "--rm"}
+	if pull := containerPullArg(ee.PullPolicy, ee.Image); pull != "" {
+		runArgs = append(runArgs, pull)
+	}
+
+	// Docker runs containers as root by default; keep installed content owned by the build user.
+	if engine == "docker" && runtime.GOOS != "windows" && !hasContainerUserOption(ee.ContainerOptions) {
+		runArgs = append(runArgs, fmt.Sprintf("--user=%d:%d", os.Getuid(), os.Getgid()))
+	}
+
+	if env := ee.EnvironmentVariables; env != nil {
+		keys := make([]string, 0, len(env.Set))
+		for k := range env.Set {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			runArgs = append(runArgs, "-e", fmt.Sprintf("%s=%s", k, env.Set[k]))
+		}
+		for _, k := range env.Pass {
+			runArgs = append(runArgs, "-e", k)
+		}
+	}
+	for _, kv := range galaxyServerEnv(gm.config.GalaxyServers) {
+		k, _, _ := strings.Cut(kv, "=")
+		runArgs = append(runArgs, "-e", k)
+	}
+
+	for _, mount := range ee.VolumeMounts {
+		// The collections mount is re-added read-write below.
+		if mount.Dest == eeCollectionsMountPath {
+			continue
+		}
+		spec := mount.Src + ":" + mount.Dest
+		if mount.Options != "" {
+			spec += ":" + mount.Options
+		}
+		runArgs = append(runArgs, "-v", spec)
+	}
+	// The plugin-managed mounts are relabeled when the engine enforces SELinux.
+	relabel := func(options string) string {
+		if options = withSELinuxRelabel(options, gm.engine); options != "" {
+			return ":" + options
+		}
+		return ""
+	}
+	runArgs = append(runArgs,
+		"-v", collectionsPath+":"+eeCollectionsMountPath+relabel(""),
+		"-v", rolesPath+":"+eeRolesMountPath+relabel(""),
+		"-v", requirementsDir+":"+eeRequirementsMountPath+relabel("ro"),
+	)
+	if gm.config.GalaxyKeyring != "" {
+		// Only the keyring file is mounted, so nothing else in its directory is
+		// exposed to the container or relabeled.
+		keyring, err := absHostPath(gm.config.GalaxyKeyring)
+		if err != nil {
+			return nil, fmt.Errorf("invalid galaxy_keyring: %w", err)
+		}
+		runArgs = append(runArgs, "-v", keyring+":"+path.Join(eeKeyringMountPath, filepath.Base(keyring))+relabel("ro"))
+	}
+
+	runArgs = append(runArgs, ee.ContainerOptions...)
+
+	// galaxy_command may be a host path; inside the image only the executable name is meaningful.
+	runArgs = append(runArgs, ee.Image, filepath.Base(gm.config.GalaxyCommand))
+	return append(runArgs, galaxyArgs...), nil
+}
+
+// resolveContainerEngine maps the execution_environment.container_engine setting
+// to an executable. "auto" (or empty) prefers podman, then docker, matching
+// ansible-navigator's own detection order.
+func resolveContainerEngine(engine string, lookPath func(string) (string, error)) (string, error) {
+	switch engine {
+	case "podman", "docker":
+		return engine, nil
+	case "", "auto":
+		for _, candidate := range []string{"podman", "docker"} {
+			if _, err := lookPath(candidate); err == nil {
+				return candidate, nil
+			}
+		}
+		return "", fmt.Errorf("no container engine found in PATH (tried podman, docker)")
+	default:
+		return "", fmt.Errorf("unsupported container engine %q (must be auto, podman or docker)", engine)
+	}
+}
+
+// containerPullArg maps an ansible-navigator pull policy to a `run --pull=` flag.
+// The "tag" policy (navigator's default) pulls only when the image uses the
+// "latest" tag or no tag at all.
+func containerPullArg(policy string, image string) string {
+	switch policy {
+	case "always", "missing", "never":
+		return "--pull=" + policy
+	case "", "tag":
+		if imageTag(image) == "latest" {
+			return "--pull=always"
+		}
+		return "--pull=missing"
+	default:
+		return ""
+	}
+}
+
+// imageTag returns the tag of an image reference ("latest" when untagged,
+// empty when pinned by digest).
+func imageTag(image string) string {
+	if strings.Contains(image, "@") {
+		return ""
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return "latest"
+}
+
+func hasContainerUserOption(options []string) bool {
+	for _, opt := range options {
+		if opt == "--user" || opt == "-u" || strings.HasPrefix(opt, "--user=") {
+			return true
+		}
+	}
+	return false
+}
+
+func absHostPath(p string) (string, error) {
+	return filepath.Abs(expandUserPath(p))
+}
@@ -0,0 +1,214 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerPullArg(t *testing.T) {
+	tests := []struct {
+		policy string
+		image  string
+		want   string
+	}{
+		{"always", "quay.io/ansible/creator-ee:v1", "--pull=always"},
+		{"missing", "quay.io/ansible/creator-ee:v1", "--pull=missing"},
+		{"never", "quay.io/ansible/creator-ee", "--pull=never"},
+		{"tag", "quay.io/ansible/creator-ee:latest", "--pull=always"},
+		{"", "quay.io/ansible/creator-ee", "--pull=always"},
+		{"tag", "localhost:5000/ee:v2", "--pull=missing"},
+		{"tag", "quay.io/ansible/creator-ee@sha256:abc", "--pull=missing"},
+		{"bogus", "ee", ""},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s", tt.policy, tt.image), func(t *testing.T) {
+			require.Equal(t, tt.want, containerPullArg(tt.policy, tt.image))
+		})
+	}
+}
+
+func TestResolveContainerEngine(t *testing.T) {
+	only := func(names ...string) func(string) (string, error) {
+		return func(file string) (string, error) {
+			for _, n := range names {
+				if n == file {
+					return "/usr/bin/" + file, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+
+	got, err := resolveContainerEngine("auto", only("docker", "podman"))
+	require.NoError(t, err)
+	require.Equal(t, "podman", got)
+
+	got, err = resolveContainerEngine("", only("docker"))
+	require.NoError(t, err)
+	require.Equal(t, "docker", got)
+
+	got, err = resolveContainerEngine("docker", only())
+	require.NoError(t, err)
+	require.Equal(t, "docker", got)
+
+	_, err = resolveContainerEngine("auto", only())
+	require.ErrorContains(t, err, "no container engine found")
+
+	_, err = resolveContainerEngine("lxc", only())
+	require.ErrorContains(t, err, "unsupported container engine")
+}
+
+func eeGalaxyConfig(t *testing.T, engine string) *Config {
+	t.Helper()
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+
+	return &Config{
+		RequirementsFile: reqFile,
+		GalaxyCommand:    "/usr/local/bin/ansible-galaxy",
+		GalaxyRuntime:    GalaxyRuntimeExecutionEnvironment,
+		CollectionsPath:  filepath.Join(tmpDir, "collections"),
+		RolesPath:        filepath.Join(tmpDir, "roles"),
+		NavigatorConfig: &NavigatorConfig{
+			ExecutionEnvironment: &ExecutionEnvironment{
+				Enabled:          boolPtr(true),
+				Image:            "quay.io/ansible/creator-ee:v24.2.0",
+				PullPolicy:       "missing",
+				ContainerEngine:  engine,
+				ContainerOptions: []string{"--net=host"},
+				EnvironmentVariables: &EnvironmentVariablesConfig{
+					Set:  map[string]string{"HOME": "/tmp", "ANSIBLE_REMOTE_TMP": "/tmp/.ansible/tmp"},
+					Pass: []string{"HTTPS_PROXY"},
+				},
+				VolumeMounts: []VolumeMount{
+					{Src: "/etc/pki", Dest: "/etc/pki", Options: "ro"},
+					{Src: "/old/collections", Dest: eeCollectionsMountPath, Options: "ro"},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildExecutionEnvironmentGalaxyArgs_MirrorsNavigatorSettings(t *testing.T) {
+	cfg := eeGalaxyConfig(t, "podman")
+	cfg.GalaxyServers = []GalaxyServer{{Name: "hub", URL: "https://hub.example.com", Token: "s3cr3t"}}
+	gm := NewGalaxyManager(cfg, newMockUi())
+
+	args, err := gm.buildExecutionEnvironmentGalaxyArgs("podman", []string{"collection", "install", "-r=/tmp/.packer_ansible/requirements/requirements.yml"})
+	require.NoError(t, err)
+
+	reqDir := filepath.Dir(cfg.RequirementsFile)
+	require.Equal(t, []string{
+		"run", "--rm", "--pull=missing",
+		"-e", "ANSIBLE_REMOTE_TMP=/tmp/.ansible/tmp",
+		"-e", "HOME=/tmp",
+		"-e", "HTTPS_PROXY",
+		"-e", "ANSIBLE_GALAXY_SERVER_LIST",
+		"-e", "ANSIBLE_GALAXY_SERVER_HUB_URL",
+		"-e", "ANSIBLE_GALAXY_SERVER_HUB_TOKEN",
+		"-v", "/etc/pki:/etc/pki:ro",
+		"-v", cfg.CollectionsPath + ":" + eeCollectionsMountPath,
+		"-v", cfg.RolesPath + ":" + eeRolesMountPath,
+		"-v", reqDir + ":" + eeRequirementsMountPath + ":ro",
+		"--net=host",
+		"quay.io/ansible/creator-ee:v24.2.0", "ansible-galaxy",
+		"collection", "install", "-r=/tmp/.packer_ansible/requirements/requirements.yml",
+	}, args)
+	require.NotContains(t, strings.Join(args, " "), "s3cr3t")
+
+	// Mount sources are created up front.
+	require.DirExists(t, cfg.CollectionsPath)
+	require.DirExists(t, cfg.RolesPath)
+}
+
+func TestBuildExecutionEnvironmentGalaxyArgs_DockerRunsAsBuildUser(t *testing.T) {
+	cfg := eeGalaxyConfig(t, "docker")
+	gm := NewGalaxyManager(cfg, newMockUi())
+
+	args, err := gm.buildExecutionEnvironmentGalaxyArgs("docker", nil)
+	require.NoError(t, err)
+	require.Contains(t, args, fmt.Sprintf("--user=%d:%d", os.Getuid(), os.Getgid()))
+
+	// An explicit user in container_options wins.
+	cfg.NavigatorConfig.ExecutionEnvironment.ContainerOptions = []string{"--user=1234:1234"}
+	args, err = gm.buildExecutionEnvironmentGalaxyArgs("docker", nil)
+	require.NoError(t, err)
+	var userArgs []string
+	for _, a := range args {
+		if strings.HasPrefix(a, "--user") {
+			userArgs = append(userArgs, a)
+		}
+	}
+	require.Equal(t, []string{"--user=1234:1234"}, userArgs)
+}
+
+func TestGalaxyManager_InstallRequirements_InExecutionEnvironment(t *testing.T) {
+	binDir := t.TempDir()
+	outputFile := filepath.Join(binDir, "engine_calls.txt")
+	stub := `#!/usr/bin/env bash
+echo "$@" >> "` + outputFile + `"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "podman"), []byte(stub), 0o755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cfg := eeGalaxyConfig(t, "auto")
+	gm := NewGalaxyManager(cfg, newMockUi())
+	require.NoError(t, gm.InstallRequirements())
+
+	data, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	got := string(data)
+	require.Contains(t, got, "run --rm --pull=missing")
+	require.Contains(t, got, "quay.io/ansible/creator-ee:v24.2.0 ansible-galaxy collection install -r=/tmp/.packer_ansible/requirements/requirements.yml -p=/tmp/.packer_ansible/collections")
+}
+
+func TestConfigValidate_GalaxyRuntime(t *testing.T) {
+	cfg := &Config{Plays: []Play{{Target: "ns.coll.role"}}}
+
+	cfg.GalaxyRuntime = "execution_environment"
+	err := cfg.Validate()
+	require.ErrorContains(t, err, "requires navigator_config.execution_environment.enabled = true")
+
+	cfg.NavigatorConfig = &NavigatorConfig{ExecutionEnvironment: &ExecutionEnvironment{Enabled: boolPtr(true)}}
+	err = cfg.Validate()
+	require.ErrorContains(t, err, "requires navigator_config.execution_environment.image")
+
+	cfg.NavigatorConfig.ExecutionEnvironment.Image = "quay.io/ansible/creator-ee:latest"
+	require.NoError(t, cfg.Validate())
+
+	cfg.GalaxyRuntime = "target"
+	require.ErrorContains(t, cfg.Validate(), "invalid galaxy_runtime")
+}
+
+func TestBuildExecutionEnvironmentGalaxyArgs_SELinuxSharedLabels(t *testing.T) {
+	cfg := eeGalaxyConfig(t, "podman")
+	cfg.GalaxyKeyring = filepath.Join(t.TempDir(), "pubring.kbx")
+	gm := NewGalaxyManager(cfg, newMockUi())
+	gm.engine = detectPodmanEngine(fakeEngineDeps(map[string]string{
+		"podman info --format {{.Host.Security.Rootless}} {{.Host.Security.SELinuxEnabled}} {{.Host.RemoteSocket.Path}}": "false true /run/podman/podman.sock",
+	}, nil, 0))
+
+	args, err := gm.buildExecutionEnvironmentGalaxyArgs("podman", nil)
+	require.NoError(t, err)
+	joined := strings.Join(args, " ")
+	require.Contains(t, joined, "-v "+cfg.CollectionsPath+":"+eeCollectionsMountPath+":z ")
+	require.Contains(t, joined, "-v "+cfg.RolesPath+":"+eeRolesMountPath+":z ")
+	require.Contains(t, joined, "-v "+filepath.Dir(cfg.RequirementsFile)+":"+eeRequirementsMountPath+":ro,z ")
+	require.Contains(t, joined, "-v "+cfg.GalaxyKeyring+":"+eeKeyringMountPath+"/pubring.kbx:ro,z ")
+	require.NotContains(t, joined, ":Z")
+	require.NotContains(t, joined, ",Z")
+	// User volume mounts are passed through unchanged.
+	require.Contains(t, joined, "-v /etc/pki:/etc/pki:ro ")
+}
@@ -0,0 +1,529 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+	"gopkg.in/yaml.v3"
+)
+
+// galaxyUnitsDir is the directory (relative to roles_path/collections_path) where
+// each parallel install unit gets a private working directory.
+const galaxyUnitsDir = ".packer-galaxy-units"
+
+// galaxyUnit is a single role or collection requirement installed independently.
+type galaxyUnit struct {
+	// Kind is "role" or "collection".
+	Kind string
+	// Name identifies the requirement in output and errors.
+	Name string
+	// InstalledName is the directory name the requirement installs to, when it can be
+	// derived from the requirement ("ns.name" for collections, role name for roles).
+	InstalledName string
+	// Version is the requirement's version or version range, if any.
+	Version string
+	entry   *yaml.Node
+}
+
+func (u galaxyUnit) label() string {
+	return u.Kind + " " + u.Name
+}
+
+// splitGalaxyRequirements splits requirements file content into independent units.
+// Both the v2 format (roles:/collections: keys) and the v1 format (a top-level
+// list of roles) are supported.
+func splitGalaxyRequirements(content []byte) ([]galaxyUnit, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse requirements file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+
+	var units []galaxyUnit
+	switch root.Kind {
+	case yaml.SequenceNode:
+		for _, entry := range root.Content {
+			units = append(units, newGalaxyUnit("role", entry))
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			kind := ""
+			switch root.Content[i].Value {
+			case "roles":
+				kind = "role"
+			case "collections":
+				kind = "collection"
+			default:
+				continue
+			}
+			for _, entry := range root.Content[i+1].Content {
+				units = append(units, newGalaxyUnit(kind, entry))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("failed to parse requirements file: unexpected top-level YAML type")
+	}
+	return units, nil
+}
+
+func newGalaxyUnit(kind string, entry *yaml.Node) galaxyUnit {
+	unit := galaxyUnit{Kind: kind, entry: entry}
+
+	fields := map[string]string{}
+	if entry.Kind == yaml.ScalarNode {
+		fields["name"] = entry.Value
+	} else if entry.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(entry.Content); i += 2 {
+			fields[entry.Content[i].Value] = entry.Content[i+1].Value
+		}
+	}
+	unit.Version = fields["version"]
+
+	switch kind {
+	case "collection":
+		unit.Name = fields["name"]
+		// Only plain Galaxy "namespace.collection" names map to an installed directory;
+		// URLs, paths and SCM sources are always installed.
+		if t := fields["type"]; (t == "" || t == "galaxy") && isCollectionFQCN(unit.Name) {
+			unit.InstalledName = unit.Name
+		}
+	case "role":
+		unit.Name = fields["name"]
+		if unit.Name == "" {
+			unit.Name = fields["src"]
+		}
+		if fields["name"] != "" {
+			unit.InstalledName = fields["name"]
+		} else if src := fields["src"]; src != "" && !strings.ContainsAny(src, "/:,") {
+			unit.InstalledName = src
+		}
+	}
+	if unit.Name == "" {
+		unit.Name = fmt.Sprintf("(line %d)", entry.Line)
+	}
+	return unit
+}
+
+func isCollectionFQCN(name string) bool {
+	parts := strings.Split(name, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return false
+	}
+	return !strings.ContainsAny(name, "/:\\")
+}
+
+// installFromFileParallel installs every requirement as its own unit with at most
+// galaxy_parallelism concurrent ansible-galaxy processes.
+//
+// Each unit installs into a private directory. Once every unit has finished, the
+// unit directories are merged into roles_path / collections_path in requirements
+// file order, so the result does not depend on which unit finished first. A
+// dependency installed by several units is kept once; the install fails when
+// they installed different versions of it. Requirements already installed in a
+// version matching their pin are skipped unless galaxy_force or
+// galaxy_force_with_deps is set.
+func (gm *GalaxyManager) installFromFileParallel(content []byte) error {
+	units, err := splitGalaxyRequirements(content)
+	if err != nil {
+		return err
+	}
+	if len(units) == 0 {
+		gm.ui.Message("Warning: requirements file does not contain 'roles:' or 'collections:' sections")
+		return nil
+	}
+
+	force := gm.config.GalaxyForce || gm.config.GalaxyForceWithDeps
+	gm.ui.Message(fmt.Sprintf("Installing %d requirement(s) with galaxy_parallelism=%d...", len(units), gm.config.GalaxyParallelism))
+
+	var (
+		wg       sync.WaitGroup
+		uiMu     sync.Mutex
+		failures []string
+		sem      = make(chan struct{}, gm.config.GalaxyParallelism)
+		results  = make([]*unitInstall, len(units))
+		errs     = make([]error, len(units))
+		merge    = &galaxyMerge{force: force, merged: map[string]mergedEntry{}}
+	)
+
+	for i, unit := range units {
+		if !force {
+			if dir, ok := gm.unitInstalled(unit); ok {
+				gm.ui.Message(fmt.Sprintf("[%s] already installed, skipping", unit.label()))
+				// Units merged later must not replace what this requirement uses.
+				merge.merged[dir] = mergedEntry{version: installedVersion(dir), unit: unit.label()}
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, unit galaxyUnit) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			unitGM := *gm
+			unitGM.ui = &prefixedUi{Ui: gm.ui, prefix: "[" + unit.label() + "] ", mu: &uiMu}
+
+			results[i], errs[i] = unitGM.installUnit(i, unit)
+		}(i, unit)
+	}
+	wg.Wait()
+
+	// Failures are listed in requirements order, whatever order the installs finished in.
+	for i, result := range results {
+		if errs[i] != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", units[i].label(), errs[i]))
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		if err := merge.mergeTree(filepath.Join(result.dir, "content"), result.root, "", result.depth, units[i].label()); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", units[i].label(), err))
+		}
+		_ = os.RemoveAll(result.dir)
+	}
+
+	for _, root := range []string{gm.config.RolesPath, gm.config.CollectionsPath} {
+		if root != "" {
+			_ = os.Remove(filepath.Join(root, galaxyUnitsDir))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to install %d requirement(s):\n  - %s", len(failures), strings.Join(failures, "\n  - "))
+	}
+	return nil
+}
+
+// unitInstalled returns the directory of a unit's content when it is already
+// present in its destination in a version that satisfies the requirement.
+func (gm *GalaxyManager) unitInstalled(unit galaxyUnit) (string, bool) {
+	if unit.InstalledName == "" {
+		return "", false
+	}
+	var root, rel string
+	switch unit.Kind {
+	case "collection":
+		ns, name, _ := strings.Cut(unit.InstalledName, ".")
+		root, rel = gm.config.CollectionsPath, filepath.Join("ansible_collections", ns, name)
+	case "role":
+		root, rel = gm.config.RolesPath, unit.InstalledName
+	}
+	if root == "" {
+		return "", false
+	}
+	absRoot, err := absHostPath(root)
+	if err != nil {
+		return "", false
+	}
+	dir := filepath.Join(absRoot, rel)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return dir, versionSatisfies(installedVersion(dir), unit.Version)
+}
+
+// unitInstall is a unit installed into its private directory.
+type unitInstall struct {
+	// dir is the unit directory; the installed content is in its "content" subdirectory.
+	dir string
+	// root is the absolute roles_path or collections_path the content is merged into.
+	root string
+	// depth is the level below root of the installed roles or collections.
+	depth int
+}
+
+// installUnit installs a single unit into its private directory. The caller
+// merges the result and removes the directory.
+func (gm *GalaxyManager) installUnit(index int, unit galaxyUnit) (*unitInstall, error) {
+	root, mount, option := gm.config.CollectionsPath, eeCollectionsMountPath, "collections_path"
+	if unit.Kind == "role" {
+		root, mount, option = gm.config.RolesPath, eeRolesMountPath, "roles_path"
+	}
+	if root == "" {
+		return nil, fmt.Errorf("%s must be set when galaxy_parallelism > 1", option)
+	}
+	absRoot, err := absHostPath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	unitName := fmt.Sprintf("%s-%d", unit.Kind, index)
+	unitDir := filepath.Join(absRoot, galaxyUnitsDir, unitName)
+	if err := os.RemoveAll(unitDir); err != nil {
+		return nil, fmt.Errorf("failed to reset unit directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(unitDir, "content"), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create unit directory: %w", err)
+	}
+
+	key := "collections"
+	if unit.Kind == "role" {
+		key = "roles"
+	}
+	reqData, err := yaml.Marshal(map[string][]*yaml.Node{key: {unit.entry}})
+	if err != nil {
+		os.RemoveAll(unitDir)
+		return nil, fmt.Errorf("failed to render requirement: %w", err)
+	}
+	reqFile := filepath.Join(unitDir, "requirements.yml")
+	if err := os.WriteFile(reqFile, reqData, 0o600); err != nil {
+		os.RemoveAll(unitDir)
+		return nil, fmt.Errorf("failed to write requirement: %w", err)
+	}
+
+	// Inside the execution environment the unit directory is reached through the
+	// roles/collections mount.
+	reqArg, destArg := reqFile, filepath.Join(unitDir, "content")
+	if gm.installInExecutionEnvironment() {
+		unitMount := path.Join(mount, galaxyUnitsDir, unitName)
+		reqArg, destArg = path.Join(unitMount, "requirements.yml"), path.Join(unitMount, "content")
+	}
+
+	var args []string
+	if unit.Kind == "role" {
+		args = gm.rolesInstallArgs(reqArg, destArg)
+	} else {
+		args = gm.collectionsInstallArgs(reqArg, destArg)
+	}
+	if err := gm.executeGalaxyCommand(args, unit.label()); err != nil {
+		os.RemoveAll(unitDir)
+		return nil, err
+	}
+
+	if unit.Kind == "role" {
+		// <roles_path>/<role>
+		return &unitInstall{dir: unitDir, root: absRoot, depth: 1}, nil
+	}
+	// <collections_path>/ansible_collections/<namespace>/<collection>
+	return &unitInstall{dir: unitDir, root: absRoot, depth: 3}, nil
+}
+
+// galaxyMerge merges unit directories into the destination and remembers which
+// unit provided each role or collection.
+type galaxyMerge struct {
+	force  bool
+	merged map[string]mergedEntry
+}
+
+type mergedEntry struct {
+	version string
+	unit    string
+}
+
+// mergeTree moves installed content from src into dst. Directories are descended
+// into until depth reaches 1; entries at that level (and any non-directory or
+// "*.info" metadata entries above it) are moved as a whole. rel is the path of
+// src below the destination root.
+//
+// A role or collection already merged from another unit is kept when it has the
+// same version and is an error otherwise. One that was there before the install
+// is replaced when force is set or its version differs.
+func (m *galaxyMerge) mergeTree(src, dst, rel string, depth int, unit string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		from := filepath.Join(src, entry.Name())
+		to := filepath.Join(dst, entry.Name())
+		entryRel := path.Join(rel, entry.Name())
+
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), ".info") {
+			if err := m.move(from, to, m.force); err != nil {
+				return err
+			}
+			continue
+		}
+		if depth > 1 {
+			if err := m.mergeTree(from, to, entryRel, depth-1, unit); err != nil {
+				return err
+			}
+			continue
+		}
+
+		version := installedVersion(from)
+		if prev, ok := m.merged[to]; ok {
+			if prev.version != version {
+				return fmt.Errorf("%s %s conflicts with %s installed by %s",
+					installedContentName(entryRel), versionLabel(version), versionLabel(prev.version), prev.unit)
+			}
+			continue
+		}
+		replace := m.force
+		if _, err := os.Lstat(to); err == nil && installedVersion(to) != version {
+			replace = true
+		}
+		if err := m.move(from, to, replace); err != nil {
+			return err
+		}
+		m.merged[to] = mergedEntry{version: version, unit: unit}
+	}
+	return nil
+}
+
+// move renames from to to. An existing to is kept unless replace is set.
+func (m *galaxyMerge) move(from, to string, replace bool) error {
+	if _, err := os.Lstat(to); err == nil {
+		if !replace {
+			return nil
+		}
+		if err := os.RemoveAll(to); err != nil {
+			return err
+		}
+	}
+	return os.Rename(from, to)
+}
+
+// installedContentName returns "namespace.collection" or the role name for a
+// path below roles_path or collections_path.
+func installedContentName(rel string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(rel, "ansible_collections/"), "/", ".")
+}
+
+// installedVersion returns the version of an installed collection (from its
+// MANIFEST.json) or role (from meta/.galaxy_install_info), or "" when unknown.
+func installedVersion(dir string) string {
+	if data, err := os.ReadFile(filepath.Join(dir, "MANIFEST.json")); err == nil {
+		var manifest struct {
+			CollectionInfo struct {
+				Version string `json:"version"`
+			} `json:"collection_info"`
+		}
+		if json.Unmarshal(data, &manifest) == nil {
+			return manifest.CollectionInfo.Version
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "meta", ".galaxy_install_info")); err == nil {
+		var info struct {
+			Version string `yaml:"version"`
+		}
+		if yaml.Unmarshal(data, &info) == nil {
+			return info.Version
+		}
+	}
+	return ""
+}
+
+func versionLabel(version string) string {
+	if version == "" {
+		return "(unknown version)"
+	}
+	return version
+}
+
+// versionSatisfies reports whether version satisfies a requirement's version:
+// an exact version or role ref, or ansible-galaxy's comma-separated ranges such
+// as ">=1.2.0,<2.0.0". An empty or "*" requirement accepts any version.
+func versionSatisfies(version, requirement string) bool {
+	requirement = strings.TrimSpace(requirement)
+	if requirement == "" || requirement == "*" {
+		return true
+	}
+	if version == "" {
+		return false
+	}
+	for _, clause := range strings.Split(requirement, ",") {
+		clause = strings.TrimSpace(clause)
+		op := "=="
+		for _, candidate := range []string{"==", "!=", ">=", "<=", ">", "<"} {
+			if strings.HasPrefix(clause, candidate) {
+				op, clause = candidate, strings.TrimSpace(clause[len(candidate):])
+				break
+			}
+		}
+		c := compareVersions(version, clause)
+		ok := map[string]bool{"==": c == 0, "!=": c != 0, ">=": c >= 0, "<=": c <= 0, ">": c > 0, "<": c < 0}[op]
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compareVersions compares dotted versions part by part, numerically where both
+// parts are numbers. A leading "v" is ignored.
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		x, y := "0", "0"
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		nx, errX := strconv.Atoi(x)
+		ny, errY := strconv.Atoi(y)
+		switch {
+		case errX == nil && errY == nil && nx != ny:
+			if nx < ny {
+				return -1
+			}
+			return 1
+		case errX != nil || errY != nil:
+			if c := strings.Compare(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// prefixedUi prefixes every line of output with a unit label and serializes
+// output from concurrent units.
+type prefixedUi struct {
+	packersdk.Ui
+	prefix string
+	mu     *sync.Mutex
+}
+
+func (u *prefixedUi) Say(s string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.Ui.Say(u.prefix + s)
+}
+
+func (u *prefixedUi) Message(s string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.Ui.Message(u.prefix + s)
+}
+
+func (u *prefixedUi) Error(s string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.Ui.Error(u.prefix + s)
+}
+
+func (u *prefixedUi) Sayf(format string, args ...any) {
+	u.Say(fmt.Sprintf(format, args...))
+}
+
+func (u *prefixedUi) Errorf(format string, args ...any) {
+	u.Error(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,300 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitGalaxyRequirements_V2Format(t *testing.T) {
+	content := []byte(`---
+roles:
+  - name: geerlingguy.docker
+    version: "6.1.0"
+  - src: https://github.com/org/role.git
+collections:
+  - community.general
+  - name: ansible.posix
+    version: ">=1.5.0"
+  - name: https://github.com/org/coll.git
+    type: git
+`)
+	units, err := splitGalaxyRequirements(content)
+	require.NoError(t, err)
+	require.Len(t, units, 5)
+
+	require.Equal(t, "role", units[0].Kind)
+	require.Equal(t, "geerlingguy.docker", units[0].Name)
+	require.Equal(t, "geerlingguy.docker", units[0].InstalledName)
+
+	require.Equal(t, "https://github.com/org/role.git", units[1].Name)
+	require.Empty(t, units[1].InstalledName)
+
+	require.Equal(t, "collection", units[2].Kind)
+	require.Equal(t, "community.general", units[2].InstalledName)
+	require.Equal(t, "ansible.posix", units[3].InstalledName)
+	require.Empty(t, units[4].InstalledName)
+}
+
+func TestSplitGalaxyRequirements_V1RoleList(t *testing.T) {
+	units, err := splitGalaxyRequirements([]byte("- src: geerlingguy.java\n- name: foo\n  src: https://example.com/foo.tar.gz\n"))
+	require.NoError(t, err)
+	require.Len(t, units, 2)
+	require.Equal(t, "role", units[0].Kind)
+	require.Equal(t, "geerlingguy.java", units[0].InstalledName)
+	require.Equal(t, "foo", units[1].InstalledName)
+}
+
+func TestVersionSatisfies(t *testing.T) {
+	require.True(t, versionSatisfies("1.2.0", ""))
+	require.True(t, versionSatisfies("", "*"))
+	require.True(t, versionSatisfies("6.1.0", "6.1.0"))
+	require.True(t, versionSatisfies("6.1.0", "==6.1"))
+	require.True(t, versionSatisfies("1.10.0", ">=1.9.0,<2.0.0"))
+	require.True(t, versionSatisfies("v2.0", "v2.0"))
+	require.False(t, versionSatisfies("2.0.0", ">=1.9.0,<2.0.0"))
+	require.False(t, versionSatisfies("1.0.0", "!=1.0.0"))
+	require.False(t, versionSatisfies("", "1.0.0"))
+	require.False(t, versionSatisfies("main", "v2.0"))
+}
+
+func TestInstalledVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.Empty(t, installedVersion(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "MANIFEST.json"), []byte(`{"collection_info": {"version": "3.1.0"}}`), 0o644))
+	require.Equal(t, "3.1.0", installedVersion(dir))
+
+	role := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(role, "meta"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(role, "meta", ".galaxy_install_info"), []byte("install_date: today\nversion: 6.1.0\n"), 0o644))
+	require.Equal(t, "6.1.0", installedVersion(role))
+}
+
+func TestGalaxyMerge_MergeTree(t *testing.T) {
+	write := func(root, rel, content string) {
+		p := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o644))
+	}
+	manifest := func(version string) string {
+		return `{"collection_info": {"version": "` + version + `"}}`
+	}
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	dst := t.TempDir()
+	write(dst, "ansible_collections/ansible/utils/MANIFEST.json", manifest("2.0.0"))
+	write(dst, "ansible_collections/ansible/netcommon/MANIFEST.json", manifest("5.0.0"))
+
+	src := t.TempDir()
+	write(src, "ansible_collections/ansible/utils/MANIFEST.json", manifest("2.0.0"))
+	write(src, "ansible_collections/ansible/utils/plugins/new", "")
+	write(src, "ansible_collections/ansible/netcommon/MANIFEST.json", manifest("6.0.0"))
+	write(src, "ansible_collections/community/general/MANIFEST.json", manifest("8.0.0"))
+	write(src, "ansible_collections/community.general-8.0.0.info/GALAXY.yml", "info")
+
+	m := &galaxyMerge{merged: map[string]mergedEntry{}}
+	require.NoError(t, m.mergeTree(src, dst, "", 3, "collection community.general"))
+
+	// Same version: the existing copy is kept. Other version: it is replaced.
+	require.NoFileExists(t, filepath.Join(dst, "ansible_collections/ansible/utils/plugins/new"))
+	require.Equal(t, "6.0.0", installedVersion(filepath.Join(dst, "ansible_collections/ansible/netcommon")))
+	require.FileExists(t, filepath.Join(dst, "ansible_collections/community/general/MANIFEST.json"))
+	require.FileExists(t, filepath.Join(dst, "ansible_collections/community.general-8.0.0.info/GALAXY.yml"))
+
+	// A later unit with the same version of a merged collection is fine.
+	same := t.TempDir()
+	write(same, "ansible_collections/ansible/utils/MANIFEST.json", manifest("2.0.0"))
+	require.NoError(t, m.mergeTree(same, dst, "", 3, "collection cisco.ios"))
+
+	// A later unit with another version of a merged collection is a conflict.
+	other := t.TempDir()
+	write(other, "ansible_collections/ansible/netcommon/MANIFEST.json", manifest("5.0.0"))
+	err := m.mergeTree(other, dst, "", 3, "collection cisco.nxos")
+	require.EqualError(t, err, "ansible.netcommon 5.0.0 conflicts with 6.0.0 installed by collection community.general")
+	require.Equal(t, "6.0.0", installedVersion(filepath.Join(dst, "ansible_collections/ansible/netcommon")))
+
+	// force replaces existing content of the same version.
+	forced := t.TempDir()
+	write(forced, "ansible_collections/ansible/utils/MANIFEST.json", manifest("2.0.0"))
+	write(forced, "ansible_collections/ansible/utils/plugins/new", "")
+	require.NoError(t, (&galaxyMerge{force: true, merged: map[string]mergedEntry{}}).mergeTree(forced, dst, "", 3, "collection ansible.utils"))
+	require.Equal(t, "", read(filepath.Join(dst, "ansible_collections/ansible/utils/plugins/new")))
+}
+
+// writeParallelGalaxyStub installs the requirement named in the -r file into the -p
+// destination, in its pinned version or 1.0.0, together with a shared ansible.utils
+// dependency: 1.0.0 for requirements whose name contains "old", 2.0.0 otherwise.
+// Requirements whose name contains "broken" fail.
+func writeParallelGalaxyStub(t *testing.T, dir string) string {
+	t.Helper()
+	stub := `#!/usr/bin/env bash
+for a in "$@"; do
+  case "$a" in
+    -r=*) req="${a#-r=}" ;;
+    -p=*) dest="${a#-p=}" ;;
+  esac
+done
+name=$(sed -n 's/^.*name: *//p' "$req" | head -1)
+[ -z "$name" ] && name=$(sed -n 's/^ *- *//p' "$req" | head -1)
+version=$(sed -n 's/^ *version: *//p' "$req" | tr -d "\"'" | head -1)
+case "$name" in *slow*) sleep 0.3 ;; esac
+case "$name" in *broken*) echo "ERROR! cannot install $name"; exit 1 ;; esac
+utils=2.0.0
+case "$name" in *old*) utils=1.0.0 ;; esac
+manifest() { mkdir -p "$1"; echo "{\"collection_info\": {\"version\": \"$2\"}}" > "$1/MANIFEST.json"; }
+if [ "$1" = "collection" ]; then
+  manifest "$dest/ansible_collections/${name%%.*}/${name#*.}" "${version:-1.0.0}"
+  manifest "$dest/ansible_collections/ansible/utils" "$utils"
+else
+  mkdir -p "$dest/$name"
+fi
+echo "installed $name"
+`
+	p := filepath.Join(dir, "ansible-galaxy")
+	require.NoError(t, os.WriteFile(p, []byte(stub), 0o755))
+	return p
+}
+
+func TestGalaxyManager_InstallRequirements_ParallelInstallsEveryUnit(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte(`roles:
+  - name: geerlingguy.docker
+collections:
+  - community.general
+  - name: ansible.posix
+  - name: community.docker
+`), 0o644))
+
+	cfg := &Config{
+		RequirementsFile:  reqFile,
+		GalaxyCommand:     writeParallelGalaxyStub(t, tmpDir),
+		GalaxyParallelism: 3,
+		RolesPath:         filepath.Join(tmpDir, "roles"),
+		CollectionsPath:   filepath.Join(tmpDir, "collections"),
+	}
+	ui := newMockUi()
+	gm := NewGalaxyManager(cfg, ui)
+	require.NoError(t, gm.InstallRequirements())
+
+	require.DirExists(t, filepath.Join(cfg.RolesPath, "geerlingguy.docker"))
+	for _, c := range []string{"community/general", "ansible/posix", "community/docker", "ansible/utils"} {
+		require.DirExists(t, filepath.Join(cfg.CollectionsPath, "ansible_collections", c))
+	}
+	require.NoDirExists(t, filepath.Join(cfg.CollectionsPath, galaxyUnitsDir))
+	require.NoDirExists(t, filepath.Join(cfg.RolesPath, galaxyUnitsDir))
+
+	msgs := strings.Join(ui.(*mockUi).messageMessages, "\n")
+	require.Contains(t, msgs, "[collection ansible.posix] installed ansible.posix")
+	require.Contains(t, msgs, "[role geerlingguy.docker] installed geerlingguy.docker")
+
+	// A second run skips everything that is already installed.
+	ui2 := newMockUi()
+	require.NoError(t, NewGalaxyManager(cfg, ui2).InstallRequirements())
+	msgs2 := strings.Join(ui2.(*mockUi).messageMessages, "\n")
+	require.Contains(t, msgs2, "[collection community.general] already installed, skipping")
+	require.NotContains(t, msgs2, "installed community.general")
+}
+
+func TestGalaxyManager_InstallRequirements_ParallelAggregatesFailures(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte(`collections:
+  - name: broken.slow
+  - name: community.general
+  - name: broken.two
+`), 0o644))
+
+	cfg := &Config{
+		RequirementsFile:  reqFile,
+		GalaxyCommand:     writeParallelGalaxyStub(t, tmpDir),
+		GalaxyParallelism: 2,
+		RolesPath:         filepath.Join(tmpDir, "roles"),
+		CollectionsPath:   filepath.Join(tmpDir, "collections"),
+	}
+	err := NewGalaxyManager(cfg, newMockUi()).InstallRequirements()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to install 2 requirement(s)")
+	// Failures are listed in requirements order even though broken.slow fails last.
+	slow := strings.Index(err.Error(), "collection broken.slow: ansible-galaxy failed")
+	two := strings.Index(err.Error(), "collection broken.two: ansible-galaxy failed")
+	require.NotEqual(t, -1, slow)
+	require.NotEqual(t, -1, two)
+	require.Less(t, slow, two)
+
+	// Successful units are still installed.
+	require.DirExists(t, filepath.Join(cfg.CollectionsPath, "ansible_collections", "community", "general"))
+}
+
+func TestGalaxyManager_InstallRequirements_ParallelHonoursVersionPins(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	cfg := &Config{
+		RequirementsFile:  reqFile,
+		GalaxyCommand:     writeParallelGalaxyStub(t, tmpDir),
+		GalaxyParallelism: 2,
+		RolesPath:         filepath.Join(tmpDir, "roles"),
+		CollectionsPath:   filepath.Join(tmpDir, "collections"),
+	}
+	install := func(requirements string) (string, error) {
+		require.NoError(t, os.WriteFile(reqFile, []byte(requirements), 0o644))
+		ui := newMockUi()
+		err := NewGalaxyManager(cfg, ui).InstallRequirements()
+		return strings.Join(ui.(*mockUi).messageMessages, "\n"), err
+	}
+	general := filepath.Join(cfg.CollectionsPath, "ansible_collections", "community", "general")
+
+	_, err := install("collections:\n  - name: community.general\n    version: \"7.0.0\"\n")
+	require.NoError(t, err)
+	require.Equal(t, "7.0.0", installedVersion(general))
+
+	// A matching pin is skipped, a different one is installed over the old version.
+	msgs, err := install("collections:\n  - name: community.general\n    version: \">=7.0.0,<8.0.0\"\n")
+	require.NoError(t, err)
+	require.Contains(t, msgs, "[collection community.general] already installed, skipping")
+
+	msgs, err = install("collections:\n  - name: community.general\n    version: \"8.0.0\"\n")
+	require.NoError(t, err)
+	require.Contains(t, msgs, "[collection community.general] installed community.general")
+	require.Equal(t, "8.0.0", installedVersion(general))
+}
+
+func TestGalaxyManager_InstallRequirements_ParallelDependencyConflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	reqFile := filepath.Join(tmpDir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte(`collections:
+  - name: cisco.ios
+  - name: cisco.old
+  - name: cisco.nxos
+`), 0o644))
+
+	cfg := &Config{
+		RequirementsFile:  reqFile,
+		GalaxyCommand:     writeParallelGalaxyStub(t, tmpDir),
+		GalaxyParallelism: 3,
+		RolesPath:         filepath.Join(tmpDir, "roles"),
+		CollectionsPath:   filepath.Join(tmpDir, "collections"),
+	}
+	err := NewGalaxyManager(cfg, newMockUi()).InstallRequirements()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to install 1 requirement(s)")
+	require.Contains(t, err.Error(), "collection cisco.old: ansible.utils 1.0.0 conflicts with 2.0.0 installed by collection cisco.ios")
+
+	// The first unit in requirements order provides the shared dependency.
+	require.Equal(t, "2.0.0", installedVersion(filepath.Join(cfg.CollectionsPath, "ansible_collections", "ansible", "utils")))
+	require.DirExists(t, filepath.Join(cfg.CollectionsPath, "ansible_collections", "cisco", "nxos"))
+	require.NoDirExists(t, filepath.Join(cfg.CollectionsPath, galaxyUnitsDir))
+}
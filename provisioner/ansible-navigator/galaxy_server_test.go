@@ -0,0 +1,116 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestGalaxyServerEnv_RendersServerListInOrder(t *testing.T) {
+	env := galaxyServerEnv([]GalaxyServer{
+		{
+			Name:          "automation_hub",
+			URL:           "https://console.redhat.com/api/automation-hub/",
+			AuthURL:       "https://sso.redhat.com/token",
+			Token:         "s3cr3t",
+			ValidateCerts: boolPtr(false),
+		},
+		{Name: "galaxy", URL: "https://galaxy.ansible.com/"},
+	})
+
+	require.Equal(t, []string{
+		"ANSIBLE_GALAXY_SERVER_LIST=automation_hub,galaxy",
+		"ANSIBLE_GALAXY_SERVER_AUTOMATION_HUB_URL=https://console.redhat.com/api/automation-hub/",
+		"ANSIBLE_GALAXY_SERVER_AUTOMATION_HUB_AUTH_URL=https://sso.redhat.com/token",
+		"ANSIBLE_GALAXY_SERVER_AUTOMATION_HUB_TOKEN=s3cr3t",
+		"ANSIBLE_GALAXY_SERVER_AUTOMATION_HUB_VALIDATE_CERTS=false",
+		"ANSIBLE_GALAXY_SERVER_GALAXY_URL=https://galaxy.ansible.com/",
+	}, env)
+
+	require.Nil(t, galaxyServerEnv(nil))
+}
+
+func TestValidateGalaxyServers(t *testing.T) {
+	errs := validateGalaxyServers([]GalaxyServer{
+		{Name: "hub", URL: "https://hub.example.com"},
+		{Name: "HUB", URL: "https://other.example.com"},
+		{Name: "bad-name", URL: "https://x.example.com"},
+		{URL: "https://y.example.com"},
+		{Name: "nourl"},
+	})
+
+	var msgs []string
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	joined := strings.Join(msgs, "\n")
+	require.Len(t, errs, 4)
+	require.Contains(t, joined, `galaxy_server 1: duplicate name "HUB"`)
+	require.Contains(t, joined, `galaxy_server 2: invalid name "bad-name"`)
+	require.Contains(t, joined, "galaxy_server 3: name must be specified")
+	require.Contains(t, joined, "galaxy_server 4: url must be specified")
+}
+
+func TestGalaxyManager_GalaxyServers_PassedAsEnvAndTokenRedacted(t *testing.T) {
+	tmpDir := t.TempDir()
+	stubPath := filepath.Join(tmpDir, "ansible-galaxy-stub.sh")
+	requirementsFile := filepath.Join(tmpDir, "requirements.yml")
+
+	// The stub echoes the token so the test can verify output redaction.
+	stub := `#!/usr/bin/env bash
+echo "server_list=${ANSIBLE_GALAXY_SERVER_LIST} token=${ANSIBLE_GALAXY_SERVER_HUB_TOKEN}"
+exit 0
+`
+	require.NoError(t, os.WriteFile(stubPath, []byte(stub), 0o755))
+	require.NoError(t, os.WriteFile(requirementsFile, []byte("collections:\n  - name: community.general\n"), 0o644))
+
+	ui := newMockUi()
+	cfg := &Config{
+		RequirementsFile: requirementsFile,
+		GalaxyCommand:    stubPath,
+		GalaxyServers: []GalaxyServer{
+			{Name: "hub", URL: "https://hub.example.com/api/galaxy/", Token: "s3cr3t-token"},
+		},
+	}
+	gm := NewGalaxyManager(cfg, ui)
+	require.NoError(t, gm.InstallRequirements())
+
+	msgs := strings.Join(ui.(*mockUi).messageMessages, "\n")
+	require.Contains(t, msgs, "server_list=hub token=*****")
+	require.NotContains(t, msgs, "s3cr3t-token")
+}
+
+func TestProvisionerPrepare_GalaxyServerValidation(t *testing.T) {
+	var p Provisioner
+	config := testConfig(t)
+	defer os.Remove(config["command"].(string))
+
+	playbookFile, err := os.CreateTemp("", "playbook")
+	require.NoError(t, err)
+	defer os.Remove(playbookFile.Name())
+
+	config["play"] = []map[string]interface{}{{"target": playbookFile.Name()}}
+	config["galaxy_server"] = []map[string]interface{}{
+		{"name": "hub", "url": "https://hub.example.com", "token": "abc", "validate_certs": false},
+	}
+	require.NoError(t, p.Prepare(config))
+	require.Len(t, p.config.GalaxyServers, 1)
+	require.NotNil(t, p.config.GalaxyServers[0].ValidateCerts)
+	require.False(t, *p.config.GalaxyServers[0].ValidateCerts)
+
+	var bad Provisioner
+	config["galaxy_server"] = []map[string]interface{}{{"name": "hub"}}
+	err = bad.Prepare(config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "galaxy_server 0: url must be specified")
+}
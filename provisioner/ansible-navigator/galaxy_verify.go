@@ -0,0 +1,210 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	packersdk "github.com/hashicorp/packer-plugin-sdk/packer"
+)
+
+// eeKeyringMountPath is the container directory the galaxy_keyring file is mounted in.
+const eeKeyringMountPath = "/tmp/.packer_ansible/keyring"
+
+var (
+	signatureCountPattern      = regexp.MustCompile(`^\+?(all|[1-9][0-9]*)$`)
+	signatureStatusCodePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+	// ansible-galaxy collection verify output, e.g.
+	//   Successfully verified that checksums for 'ns.coll:1.0.0' match the remote collection.
+	//   Collection ns.coll contains modified content in the following files:
+	//   Signature verification failed for 'ns.coll' (return code 1):
+	verifyOKPattern        = regexp.MustCompile(`Successfully verified that checksums for '([^']+)'`)
+	verifyModifiedPattern  = regexp.MustCompile(`Collection (\S+) contains modified content`)
+	verifySignaturePattern = regexp.MustCompile(`Signature verification failed for '([^']+)'`)
+)
+
+// validateGalaxySignatureOptions validates galaxy_keyring and the options that tune it.
+func validateGalaxySignatureOptions(c *Config) []error {
+	var errs []error
+	if c.GalaxyKeyring != "" {
+		if err := validateFileConfig(c.GalaxyKeyring, "galaxy_keyring", true); err != nil {
+			errs = append(errs, err)
+		}
+	} else if c.GalaxyRequiredValidSignatureCount != "" || len(c.GalaxyIgnoreSignatureStatusCodes) > 0 {
+		errs = append(errs, fmt.Errorf(
+			"galaxy_required_valid_signature_count and galaxy_ignore_signature_status_codes require galaxy_keyring"))
+	}
+
+	if c.GalaxyRequiredValidSignatureCount != "" && !signatureCountPattern.MatchString(c.GalaxyRequiredValidSignatureCount) {
+		errs = append(errs, fmt.Errorf(
+			"invalid galaxy_required_valid_signature_count: %q (must be a positive number or \"all\", optionally prefixed with \"+\")",
+			c.GalaxyRequiredValidSignatureCount))
+	}
+	for _, code := range c.GalaxyIgnoreSignatureStatusCodes {
+		if !signatureStatusCodePattern.MatchString(code) {
+			errs = append(errs, fmt.Errorf(
+				"invalid galaxy_ignore_signature_status_codes entry: %q (must be a GnuPG status code such as NO_PUBKEY)", code))
+		}
+	}
+	return errs
+}
+
+// verifySignatures reports whether collection signatures are verified.
+func (gm *GalaxyManager) verifySignatures() bool {
+	return gm.config.GalaxyKeyring != ""
+}
+
+// signatureArgs returns the keyring arguments shared by `collection install` and
+// `collection verify`.
+func (gm *GalaxyManager) signatureArgs() []string {
+	if !gm.verifySignatures() {
+		return nil
+	}
+	keyring := gm.config.GalaxyKeyring
+	if gm.installInExecutionEnvironment() {
+		keyring = path.Join(eeKeyringMountPath, filepath.Base(keyring))
+	}
+	args := []string{fmt.Sprintf("--keyring=%s", filepath.ToSlash(keyring))}
+	if gm.config.GalaxyRequiredValidSignatureCount != "" {
+		args = append(args, fmt.Sprintf("--required-valid-signature-count=%s", gm.config.GalaxyRequiredValidSignatureCount))
+	}
+	for _, code := range gm.config.GalaxyIgnoreSignatureStatusCodes {
+		args = append(args, fmt.Sprintf("--ignore-signature-status-code=%s", code))
+	}
+	return args
+}
+
+// verifyCollections runs `ansible-galaxy collection verify` against the installed
+// collections and records the per-collection results in gm.verification. Any
+// mismatch or signature failure is returned as an error.
+func (gm *GalaxyManager) verifyCollections(reqFile string) error {
+	collectionsPath := gm.config.CollectionsPath
+	if gm.installInExecutionEnvironment() {
+		collectionsPath = eeCollectionsMountPath
+	}
+
+	args := []string{"collection", "verify", fmt.Sprintf("-r=%s", filepath.ToSlash(reqFile))}
+	if collectionsPath != "" {
+		args = append(args, fmt.Sprintf("-p=%s", collectionsPath))
+	}
+	if gm.config.OfflineMode {
+		args = append(args, "--offline")
+	}
+	args = append(args, gm.signatureArgs()...)
+
+	gm.ui.Message("Verifying installed collections...")
+	capture := &capturingUi{Ui: gm.ui}
+	verifyGM := *gm
+	verifyGM.ui = capture
+	runErr := verifyGM.executeGalaxyCommand(args, "collection verification")
+
+	gm.verification = parseCollectionVerifyOutput(capture.lines)
+	gm.verification.Keyring = gm.config.GalaxyKeyring
+	if runErr != nil {
+		gm.verification.Passed = false
+	}
+
+	if !gm.verification.Passed {
+		var failed []string
+		for _, c := range gm.verification.Collections {
+			if c.Status == CollectionVerificationFailed {
+				failed = append(failed, c.Name)
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("collection verification failed for: %s", strings.Join(failed, ", "))
+		}
+		if runErr != nil {
+			return fmt.Errorf("collection verification failed: %w", runErr)
+		}
+		return fmt.Errorf("collection verification failed")
+	}
+
+	gm.ui.Message(fmt.Sprintf("Verified %d collection(s)", len(gm.verification.Collections)))
+	return nil
+}
+
+// parseCollectionVerifyOutput builds a GalaxyVerification from the lines printed
+// by `ansible-galaxy collection verify`.
+func parseCollectionVerifyOutput(lines []string) *GalaxyVerification {
+	result := &GalaxyVerification{Passed: true, Collections: []CollectionVerification{}}
+	index := map[string]int{}
+
+	record := func(name, status, message string) {
+		// "ns.coll:1.0.0" -> name "ns.coll", version "1.0.0"
+		name, version, _ := strings.Cut(name, ":")
+		if i, ok := index[name]; ok {
+			// A failure always wins over an earlier success for the same collection.
+			if status == CollectionVerificationFailed {
+				result.Collections[i].Status = status
+				result.Collections[i].Message = message
+			}
+			if version != "" {
+				result.Collections[i].Version = version
+			}
+			return
+		}
+		index[name] = len(result.Collections)
+		result.Collections = append(result.Collections, CollectionVerification{
+			Name:    name,
+			Version: version,
+			Status:  status,
+			Message: message,
+		})
+	}
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if m := verifyOKPattern.FindStringSubmatch(line); m != nil {
+			record(m[1], CollectionVerificationVerified, "")
+		} else if m := verifyModifiedPattern.FindStringSubmatch(line); m != nil {
+			record(m[1], CollectionVerificationFailed, "contains modified content")
+		} else if m := verifySignaturePattern.FindStringSubmatch(line); m != nil {
+			record(m[1], CollectionVerificationFailed, "signature verification failed")
+		}
+	}
+
+	for _, c := range result.Collections {
+		if c.Status == CollectionVerificationFailed {
+			result.Passed = false
+		}
+	}
+	return result
+}
+
+// capturingUi records every line of output while passing it through.
+type capturingUi struct {
+	packersdk.Ui
+	mu    sync.Mutex
+	lines []string
+}
+
+func (u *capturingUi) record(s string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.lines = append(u.lines, strings.Split(s, "\n")...)
+}
+
+func (u *capturingUi) Say(s string) {
+	u.record(s)
+	u.Ui.Say(s)
+}
+
+func (u *capturingUi) Message(s string) {
+	u.record(s)
+	u.Ui.Message(s)
+}
+
+func (u *capturingUi) Error(s string) {
+	u.record(s)
+	u.Ui.Error(s)
+}
@@ -0,0 +1,176 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeVerifyStub writes an ansible-galaxy stub that records its arguments and
+// prints verifyOutput (exiting with verifyExit) for `collection verify`.
+func writeVerifyStub(t *testing.T, dir string, verifyOutput string, verifyExit int) (stubPath, callsFile string) {
+	t.Helper()
+	callsFile = filepath.Join(dir, "galaxy_calls.txt")
+	stubPath = filepath.Join(dir, "ansible-galaxy-stub.sh")
+	stub := `#!/usr/bin/env bash
+echo "$@" >> "` + callsFile + `"
+if [ "$1" = "collection" ] && [ "$2" = "verify" ]; then
+  cat <<'OUT'
+` + verifyOutput + `
+OUT
+  exit ` + strconv.Itoa(verifyExit) + `
+fi
+exit 0
+`
+	require.NoError(t, os.WriteFile(stubPath, []byte(stub), 0o755))
+	return stubPath, callsFile
+}
+
+func signatureTestConfig(t *testing.T, stubPath string) *Config {
+	t.Helper()
+	dir := t.TempDir()
+	reqFile := filepath.Join(dir, "requirements.yml")
+	require.NoError(t, os.WriteFile(reqFile, []byte("collections:\n  - name: community.general\n  - name: ansible.posix\n"), 0o644))
+	keyring := filepath.Join(dir, "pubring.kbx")
+	require.NoError(t, os.WriteFile(keyring, []byte("keyring"), 0o600))
+
+	return &Config{
+		RequirementsFile:                  reqFile,
+		GalaxyCommand:                     stubPath,
+		CollectionsPath:                   filepath.Join(dir, "collections"),
+		GalaxyKeyring:                     keyring,
+		GalaxyRequiredValidSignatureCount: "+1",
+		GalaxyIgnoreSignatureStatusCodes:  []string{"NO_PUBKEY", "EXPKEYSIG"},
+	}
+}
+
+func TestGalaxyManager_InstallRequirements_VerifiesCollectionSignatures(t *testing.T) {
+	stubPath, callsFile := writeVerifyStub(t, t.TempDir(),
+		"Successfully verified that checksums for 'community.general:8.0.0' match the remote collection.\n"+
+			"Successfully verified that checksums for 'ansible.posix:1.5.4' match the remote collection.", 0)
+	cfg := signatureTestConfig(t, stubPath)
+
+	gm := NewGalaxyManager(cfg, newMockUi())
+	require.NoError(t, gm.InstallRequirements())
+
+	data, err := os.ReadFile(callsFile)
+	require.NoError(t, err)
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, calls, 2)
+
+	signatureArgs := "--keyring=" + filepath.ToSlash(cfg.GalaxyKeyring) +
+		" --required-valid-signature-count=+1" +
+		" --ignore-signature-status-code=NO_PUBKEY --ignore-signature-status-code=EXPKEYSIG"
+	require.True(t, strings.HasPrefix(calls[0], "collection install -r="))
+	require.Contains(t, calls[0], signatureArgs)
+	require.Equal(t, "collection verify -r="+filepath.ToSlash(cfg.RequirementsFile)+" -p="+cfg.CollectionsPath+" "+signatureArgs, calls[1])
+
+	require.NotNil(t, gm.verification)
+	require.True(t, gm.verification.Passed)
+	require.Equal(t, cfg.GalaxyKeyring, gm.verification.Keyring)
+	require.Equal(t, []CollectionVerification{
+		{Name: "community.general", Version: "8.0.0", Status: CollectionVerificationVerified},
+		{Name: "ansible.posix", Version: "1.5.4", Status: CollectionVerificationVerified},
+	}, gm.verification.Collections)
+}
+
+func TestGalaxyManager_InstallRequirements_FailsOnVerificationMismatch(t *testing.T) {
+	stubPath, _ := writeVerifyStub(t, t.TempDir(),
+		"Successfully verified that checksums for 'community.general:8.0.0' match the remote collection.\n"+
+			"Collection ansible.posix contains modified content in the following files:\n"+
+			"    plugins/modules/mount.py", 1)
+	cfg := signatureTestConfig(t, stubPath)
+
+	gm := NewGalaxyManager(cfg, newMockUi())
+	err := gm.InstallRequirements()
+	require.ErrorContains(t, err, "collection verification failed for: ansible.posix")
+
+	require.NotNil(t, gm.verification)
+	require.False(t, gm.verification.Passed)
+	require.Equal(t, CollectionVerificationFailed, gm.verification.Collections[1].Status)
+}
+
+func TestGalaxyManager_InstallRequirements_NoVerificationWithoutKeyring(t *testing.T) {
+	stubPath, callsFile := writeVerifyStub(t, t.TempDir(), "", 0)
+	cfg := signatureTestConfig(t, stubPath)
+	cfg.GalaxyKeyring = ""
+	cfg.GalaxyRequiredValidSignatureCount = ""
+	cfg.GalaxyIgnoreSignatureStatusCodes = nil
+
+	gm := NewGalaxyManager(cfg, newMockUi())
+	require.NoError(t, gm.InstallRequirements())
+
+	data, err := os.ReadFile(callsFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "verify")
+	require.NotContains(t, string(data), "--keyring")
+	require.Nil(t, gm.verification)
+}
+
+func TestParseCollectionVerifyOutput(t *testing.T) {
+	result := parseCollectionVerifyOutput([]string{
+		"Executing Ansible Galaxy: ansible-galaxy collection verify -r=requirements.yml",
+		"Successfully verified that checksums for 'community.general:8.0.0' are internally consistent with its manifest.",
+		"Successfully verified that checksums for 'ansible.posix:1.5.4' match the remote collection.",
+		"Signature verification failed for 'ansible.posix' (return code 1):",
+		"    * NO_PUBKEY",
+	})
+	require.False(t, result.Passed)
+	require.Equal(t, []CollectionVerification{
+		{Name: "community.general", Version: "8.0.0", Status: CollectionVerificationVerified},
+		{Name: "ansible.posix", Version: "1.5.4", Status: CollectionVerificationFailed, Message: "signature verification failed"},
+	}, result.Collections)
+
+	// The summary encodes an empty list rather than null when nothing was parsed.
+	data, err := json.Marshal(parseCollectionVerifyOutput(nil))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"keyring":"","passed":true,"collections":[]}`, string(data))
+}
+
+func TestBuildExecutionEnvironmentGalaxyArgs_MountsKeyring(t *testing.T) {
+	cfg := eeGalaxyConfig(t, "podman")
+	cfg.GalaxyKeyring = filepath.Join(t.TempDir(), "pubring.kbx")
+	gm := NewGalaxyManager(cfg, newMockUi())
+
+	args, err := gm.buildExecutionEnvironmentGalaxyArgs("podman", nil)
+	require.NoError(t, err)
+	require.Contains(t, strings.Join(args, " "), "-v "+cfg.GalaxyKeyring+":"+eeKeyringMountPath+"/pubring.kbx:ro")
+	require.Equal(t, []string{"--keyring=" + eeKeyringMountPath + "/pubring.kbx"}, gm.signatureArgs())
+}
+
+func TestConfigValidate_GalaxySignatureOptions(t *testing.T) {
+	keyring := filepath.Join(t.TempDir(), "pubring.kbx")
+	require.NoError(t, os.WriteFile(keyring, []byte("keyring"), 0o600))
+
+	cfg := &Config{Plays: []Play{{Target: "ns.coll.role"}}}
+	cfg.GalaxyRequiredValidSignatureCount = "1"
+	require.ErrorContains(t, cfg.Validate(), "require galaxy_keyring")
+
+	cfg.GalaxyKeyring = keyring
+	for _, valid := range []string{"1", "+2", "all", "+all"} {
+		cfg.GalaxyRequiredValidSignatureCount = valid
+		require.NoError(t, cfg.Validate(), valid)
+	}
+	for _, invalid := range []string{"0", "-1", "some", "+"} {
+		cfg.GalaxyRequiredValidSignatureCount = invalid
+		require.ErrorContains(t, cfg.Validate(), "invalid galaxy_required_valid_signature_count", invalid)
+	}
+	cfg.GalaxyRequiredValidSignatureCount = ""
+
+	cfg.GalaxyIgnoreSignatureStatusCodes = []string{"NO_PUBKEY", "bad code"}
+	require.ErrorContains(t, cfg.Validate(), `invalid galaxy_ignore_signature_status_codes entry: "bad code"`)
+	cfg.GalaxyIgnoreSignatureStatusCodes = nil
+
+	cfg.GalaxyKeyring = filepath.Join(t.TempDir(), "missing.kbx")
+	require.ErrorContains(t, cfg.Validate(), "galaxy_keyring")
+}
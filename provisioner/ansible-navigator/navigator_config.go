@@ -42,18 +42,25 @@ func envVarIsSetOrPassed(env *EnvironmentVariablesConfig, key string) bool {
 // ansible-galaxy installs collections to <collectionsPath>/ansible_collections/<namespace>/<collection>,
 // and this function mounts the entire collectionsPath directory into the container.
 func applyAutomaticEEDefaults(config *NavigatorConfig, collectionsPath string, ansibleProxyHost string) {
+	applyAutomaticEEDefaultsWithEngine(config, collectionsPath, ansibleProxyHost, nil)
+}
+
+// applyAutomaticEEDefaultsWithEngine is applyAutomaticEEDefaults for a detected
+// container engine: the proxy host is mapped to the build host when the engine
+// does not resolve it itself, and the collections mount is relabeled for SELinux
+// when needed. A nil engine applies the engine-independent defaults only.
+func applyAutomaticEEDefaultsWithEngine(config *NavigatorConfig, collectionsPath string, ansibleProxyHost string, engine containerEngine) {
 	if config == nil {
 		return
 	}
 
-	if config.ExecutionEnvironment == nil || !config.ExecutionEnvironment.Enabled {
+	if config.ExecutionEnvironment == nil || !derefBool(config.ExecutionEnvironment.Enabled) {
 		return
 	}
 
-	// Auto-configure Docker host mapping for gateway.docker.internal
-	// This is required for Linux Docker to resolve the host gateway, whereas Docker Desktop does it automatically.
-	if ansibleProxyHost == "gateway.docker.internal" {
-		addHostFlag := "--add-host=gateway.docker.internal:host-gateway"
+	// Map gateway hostnames (gateway.docker.internal, and host.docker.internal on
+	// Docker Engine) to the build host so the container can reach the SSH proxy.
+	if addHostFlag := hostGatewayAddHostOption(ansibleProxyHost, engine); addHostFlag != "" {
 		hasFlag := false
 		for _, opt := range config.ExecutionEnvironment.ContainerOptions {
 			if opt == addHostFlag {
@@ -74,11 +81,12 @@ func applyAutomaticEEDefaults(config *NavigatorConfig, collectionsPath string, a
 		config.AnsibleConfig.Defaults = &AnsibleConfigDefaults{}
 	}
 
-	// Set temp directory defaults to prevent permission errors
-	if config.AnsibleConfig.Defaults.RemoteTmp == "" {
+	// Set temp directory defaults to prevent permission errors, unless a generic
+	// [defaults] section block already sets them.
+	if config.AnsibleConfig.Defaults.RemoteTmp == "" && !ansibleCfgSectionOption(config.AnsibleConfig, "defaults", "remote_tmp") {
 		config.AnsibleConfig.Defaults.RemoteTmp = "/tmp/.ansible/tmp"
 	}
-	if config.AnsibleConfig.Defaults.LocalTmp == "" {
+	if config.AnsibleConfig.Defaults.LocalTmp == "" && !ansibleCfgSectionOption(config.AnsibleConfig, "defaults", "local_tmp") {
 		config.AnsibleConfig.Defaults.LocalTmp = "/tmp/.ansible-local"
 	}
 
@@ -125,7 +133,7 @@ func applyAutomaticEEDefaults(config *NavigatorConfig, collectionsPath string, a
 		}
 
 		// Add ANSIBLE_COLLECTIONS_PATH environment variable pointing to container path
-		containerCollectionsPath := "/tmp/.packer_ansible/collections"
+		containerCollectionsPath := eeCollectionsMountPath
 		if !envVarIsSetOrPassed(env, "ANSIBLE_COLLECTIONS_PATH") {
 			env.Set["ANSIBLE_COLLECTIONS_PATH"] = containerCollectionsPath
 		}
@@ -144,7 +152,7 @@ func applyAutomaticEEDefaults(config *NavigatorConfig, collectionsPath string, a
 				VolumeMount{
 					Src:     absCollectionsPath,
 					Dest:    containerCollectionsPath,
-					Options: "ro",
+					Options: withSELinuxRelabel("ro", engine),
 				})
 		}
 	}
@@ -157,6 +165,13 @@ func applyAutomaticEEDefaults(config *NavigatorConfig, collectionsPath string, a
 // all configuration under the "ansible-navigator:" top-level key. This format is
 // required by ansible-navigator 25.x+ to avoid version migration prompts.
 func GenerateNavigatorConfigYAML(config *NavigatorConfig, collectionsPath string, ansibleProxyHost string) (string, error) {
+	return GenerateNavigatorConfigYAMLWithBase(config, nil, collectionsPath, ansibleProxyHost)
+}
+
+// GenerateNavigatorConfigYAMLWithBase is like GenerateNavigatorConfigYAML, but
+// overlays the generated settings on base, the content of a navigator_config_file.
+// Settings in base that NavigatorConfig does not model are kept as-is.
+func GenerateNavigatorConfigYAMLWithBase(config *NavigatorConfig, base map[string]interface{}, collectionsPath string, ansibleProxyHost string) (string, error) {
 	if config == nil {
 		return "", fmt.Errorf("navigator_config cannot be nil")
 	}
@@ -165,6 +180,9 @@ func GenerateNavigatorConfigYAML(config *NavigatorConfig, collectionsPath string
 
 	// Convert to YAML-friendly structure with proper field names
 	yamlConfig := convertToYAMLStructure(config)
+	if base != nil {
+		yamlConfig = overlayYAML(base, yamlConfig)
+	}
 
 	// Marshal to YAML
 	yamlData, err := yaml.Marshal(yamlConfig)
@@ -191,7 +209,7 @@ func convertToYAMLStructure(config *NavigatorConfig) map[string]interface{} {
 
 	if config.ExecutionEnvironment != nil {
 		eeMap := make(map[string]interface{})
-		eeMap["enabled"] = config.ExecutionEnvironment.Enabled
+		eeMap["enabled"] = derefBool(config.ExecutionEnvironment.Enabled)
 		if config.ExecutionEnvironment.Image != "" {
 			eeMap["image"] = config.ExecutionEnvironment.Image
 		}
@@ -268,7 +286,7 @@ func convertToYAMLStructure(config *NavigatorConfig) map[string]interface{} {
 		if config.Logging.File != "" {
 			loggingMap["file"] = config.Logging.File
 		}
-		loggingMap["append"] = config.Logging.Append
+		loggingMap["append"] = derefBool(config.Logging.Append)
 		if len(loggingMap) > 0 {
 			ansibleNavigator["logging"] = loggingMap
 		}
@@ -276,7 +294,7 @@ func convertToYAMLStructure(config *NavigatorConfig) map[string]interface{} {
 
 	if config.PlaybookArtifact != nil {
 		artifactMap := make(map[string]interface{})
-		artifactMap["enable"] = config.PlaybookArtifact.Enable
+		artifactMap["enable"] = derefBool(config.PlaybookArtifact.Enable)
 		if config.PlaybookArtifact.Replay != "" {
 			artifactMap["replay"] = config.PlaybookArtifact.Replay
 		}
@@ -301,12 +319,220 @@ func convertToYAMLStructure(config *NavigatorConfig) map[string]interface{} {
 		}
 	}
 
+	if colorMap := colorYAML(config.Color); len(colorMap) > 0 {
+		ansibleNavigator["color"] = colorMap
+	}
+	if editorMap := editorYAML(config.Editor); len(editorMap) > 0 {
+		ansibleNavigator["editor"] = editorMap
+	}
+	if imagesMap := imagesYAML(config.Images); len(imagesMap) > 0 {
+		ansibleNavigator["images"] = imagesMap
+	}
+	if len(config.InventoryColumns) > 0 {
+		ansibleNavigator["inventory-columns"] = config.InventoryColumns
+	}
+	if config.TimeZone != "" {
+		ansibleNavigator["time-zone"] = config.TimeZone
+	}
+	if config.Format != "" {
+		ansibleNavigator["format"] = config.Format
+	}
+	if settingsMap := settingsYAML(config.Settings); len(settingsMap) > 0 {
+		ansibleNavigator["settings"] = settingsMap
+	}
+	if config.EnablePrompts != nil {
+		ansibleNavigator["enable-prompts"] = *config.EnablePrompts
+	}
+	if runnerMap := ansibleRunnerYAML(config.AnsibleRunner); len(runnerMap) > 0 {
+		ansibleNavigator["ansible-runner"] = runnerMap
+	}
+
 	// Wrap everything under the ansible-navigator root key
 	return map[string]interface{}{
 		"ansible-navigator": ansibleNavigator,
 	}
 }
 
+// validateNavigatorSettings validates navigator_config settings that the
+// ansible-navigator schema constrains beyond their types.
+func validateNavigatorSettings(config *NavigatorConfig) []error {
+	var errs []error
+	if config == nil {
+		return nil
+	}
+
+	enumErrs := []error{
+		validateEnumSetting("navigator_config.mode", config.Mode, "mode"),
+		validateEnumSetting("navigator_config.format", config.Format, "format"),
+	}
+	if ee := config.ExecutionEnvironment; ee != nil {
+		enumErrs = append(enumErrs,
+			validateEnumSetting("navigator_config.execution_environment.pull_policy", ee.PullPolicy, "execution-environment", "pull", "policy"),
+			validateEnumSetting("navigator_config.execution_environment.container_engine", ee.ContainerEngine, "execution-environment", "container-engine"))
+	}
+	if config.Logging != nil {
+		enumErrs = append(enumErrs,
+			validateEnumSetting("navigator_config.logging.level", config.Logging.Level, "logging", "level"))
+	}
+	if config.Settings != nil {
+		enumErrs = append(enumErrs,
+			validateEnumSetting("navigator_config.settings.schema", config.Settings.Schema, "settings", "schema"))
+	}
+	if config.Images != nil {
+		for i, detail := range config.Images.Details {
+			enumErrs = append(enumErrs,
+				validateEnumSetting(fmt.Sprintf("navigator_config.images.details[%d]", i), detail, "images", "details"))
+		}
+	}
+	for _, err := range enumErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if ee := config.ExecutionEnvironment; ee != nil {
+		// Each pull argument is passed to the container engine as a single argv entry.
+		for i, arg := range ee.PullArguments {
+			if strings.TrimSpace(arg) == "" {
+				errs = append(errs, fmt.Errorf(
+					"navigator_config.execution_environment.pull_arguments[%d] must not be empty", i))
+			} else if strings.ContainsAny(arg, " \t\n\r") {
+				errs = append(errs, fmt.Errorf(
+					"navigator_config.execution_environment.pull_arguments[%d] must be a single argument without whitespace (use \"--flag=value\" or separate entries): %q", i, arg))
+			}
+		}
+		errs = append(errs, validateImageDigest(ee)...)
+		errs = append(errs, validateImageVerify(ee)...)
+		errs = append(errs, validateImageBuild(ee)...)
+		switch ee.Preflight {
+		case "", EEPreflightWarn, EEPreflightFail, EEPreflightOff:
+		default:
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.execution_environment.preflight must be %q, %q or %q (got %q)",
+				EEPreflightWarn, EEPreflightFail, EEPreflightOff, ee.Preflight))
+		}
+	}
+
+	if ar := config.AnsibleRunner; ar != nil {
+		if ar.RotateArtifactsCount < 0 {
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.ansible_runner.rotate_artifacts_count must not be negative (got %d)", ar.RotateArtifactsCount))
+		}
+		if ar.Timeout < 0 {
+			errs = append(errs, fmt.Errorf(
+				"navigator_config.ansible_runner.timeout must not be negative (got %d)", ar.Timeout))
+		}
+	}
+
+	return errs
+}
+
+// validateNavigatorConfigDocument validates the ansible-navigator.yml generated
+// from config (overlaid on base, the navigator_config_file content, when set)
+// against the embedded ansible-navigator settings schema.
+func validateNavigatorConfigDocument(config *NavigatorConfig, base map[string]interface{}) []error {
+	doc := convertToYAMLStructure(config)
+	if base != nil {
+		doc = overlayYAML(base, doc)
+	}
+
+	// Round-trip through YAML so values have the types ansible-navigator will read.
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return []error{fmt.Errorf("failed to marshal navigator_config to YAML: %w", err)}
+	}
+	var decoded interface{}
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		return []error{fmt.Errorf("failed to parse generated ansible-navigator.yml: %w", err)}
+	}
+
+	return validateSchema(navigatorSettingsSchema, decoded, nil, navigatorConfigHCLPath)
+}
+
+// colorYAML converts color settings to the ansible-navigator.yml "color" object.
+func colorYAML(color *ColorConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if color == nil {
+		return m
+	}
+	if color.Enable != nil {
+		m["enable"] = *color.Enable
+	}
+	if color.Osc4 != nil {
+		m["osc4"] = *color.Osc4
+	}
+	return m
+}
+
+// editorYAML converts editor settings to the ansible-navigator.yml "editor" object.
+func editorYAML(editor *EditorConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if editor == nil {
+		return m
+	}
+	if editor.Command != "" {
+		m["command"] = editor.Command
+	}
+	if editor.Console != nil {
+		m["console"] = *editor.Console
+	}
+	return m
+}
+
+// imagesYAML converts images settings to the ansible-navigator.yml "images" object.
+func imagesYAML(images *ImagesConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if images == nil {
+		return m
+	}
+	if len(images.Details) > 0 {
+		m["details"] = images.Details
+	}
+	return m
+}
+
+// settingsYAML converts settings subcommand options to the ansible-navigator.yml "settings" object.
+func settingsYAML(settings *SettingsConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if settings == nil {
+		return m
+	}
+	if settings.Effective != nil {
+		m["effective"] = *settings.Effective
+	}
+	if settings.Sample != nil {
+		m["sample"] = *settings.Sample
+	}
+	if settings.Schema != "" {
+		m["schema"] = settings.Schema
+	}
+	if settings.Sources != nil {
+		m["sources"] = *settings.Sources
+	}
+	return m
+}
+
+// ansibleRunnerYAML converts ansible-runner settings to the ansible-navigator.yml "ansible-runner" object.
+func ansibleRunnerYAML(runner *AnsibleRunnerConfig) map[string]interface{} {
+	m := make(map[string]interface{})
+	if runner == nil {
+		return m
+	}
+	if runner.ArtifactDir != "" {
+		m["artifact-dir"] = runner.ArtifactDir
+	}
+	if runner.RotateArtifactsCount > 0 {
+		m["rotate-artifacts-count"] = runner.RotateArtifactsCount
+	}
+	if runner.Timeout > 0 {
+		m["timeout"] = runner.Timeout
+	}
+	if runner.JobEvents != nil {
+		m["job-events"] = *runner.JobEvents
+	}
+	return m
+}
+
 // createNavigatorConfigFile creates a temporary ansible-navigator.yml file
 // Returns the absolute path to the file
 func createNavigatorConfigFile(content string) (string, error) {
@@ -0,0 +1,429 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// navigatorConfigV1Keys maps settings that ansible-navigator renamed in the
+// Version 2 settings format to their Version 2 location. Paths are relative to
+// the ansible-navigator root key.
+var navigatorConfigV1Keys = []struct {
+	from, to string
+}{
+	{"ansible.inventories", "ansible.inventory.entries"},
+	{"documentation.plugin", "ansible.doc.plugin"},
+	{"execution-environment.pull-policy", "execution-environment.pull.policy"},
+	{"help-builder", "ansible-builder.help"},
+	{"help-config", "ansible.config.help"},
+	{"help-doc", "ansible.doc.help"},
+	{"help-inventory", "ansible.inventory.help"},
+	{"help-playbook", "ansible.playbook.help"},
+}
+
+// navigatorConfigFile is a loaded navigator_config_file.
+type navigatorConfigFile struct {
+	// Raw holds the complete (migrated) file content so settings the typed
+	// NavigatorConfig does not model are preserved in the generated file.
+	Raw map[string]interface{}
+	// Config holds the settings the typed NavigatorConfig models.
+	Config *NavigatorConfig
+	// Migrations lists the Version 1 settings that were rewritten, as "old -> new".
+	Migrations []string
+}
+
+// loadNavigatorConfigFile reads an ansible-navigator.yml settings file. Version 1
+// files are migrated to the Version 2 layout in memory.
+func loadNavigatorConfigFile(path string) (*navigatorConfigFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	for key := range doc {
+		if key != navigatorConfigRootKey {
+			return nil, fmt.Errorf("%s: unexpected top-level key %q (settings must be nested under %q)", path, key, navigatorConfigRootKey)
+		}
+	}
+	root, ok := doc[navigatorConfigRootKey].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: %s must be a mapping", path, navigatorConfigRootKey)
+	}
+
+	migrations := migrateNavigatorConfigV1(root)
+
+	d := &navigatorConfigDecoder{}
+	config := d.decode(root)
+	if len(d.errs) > 0 {
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(d.errs, "; "))
+	}
+
+	return &navigatorConfigFile{Raw: doc, Config: config, Migrations: migrations}, nil
+}
+
+// migrateNavigatorConfigV1 rewrites Version 1 settings in root to their Version 2
+// location and returns the applied migrations.
+func migrateNavigatorConfigV1(root map[string]interface{}) []string {
+	var migrations []string
+
+	// Version 1 used plain strings for ansible.config and ansible.playbook.
+	if ansible, ok := root["ansible"].(map[string]interface{}); ok {
+		for _, key := range []string{"config", "playbook"} {
+			if s, ok := ansible[key].(string); ok {
+				ansible[key] = map[string]interface{}{"path": s}
+				migrations = append(migrations, fmt.Sprintf("ansible.%s -> ansible.%s.path", key, key))
+			}
+		}
+	}
+
+	for _, m := range navigatorConfigV1Keys {
+		value, ok := removeYAMLPath(root, m.from)
+		if !ok {
+			continue
+		}
+		setYAMLPath(root, m.to, value)
+		migrations = append(migrations, m.from+" -> "+m.to)
+	}
+	return migrations
+}
+
+// removeYAMLPath removes and returns the value at the dotted path, dropping
+// parent mappings left empty.
+func removeYAMLPath(m map[string]interface{}, path string) (interface{}, bool) {
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		value, ok := m[head]
+		delete(m, head)
+		return value, ok
+	}
+	child, ok := m[head].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := removeYAMLPath(child, rest)
+	if ok && len(child) == 0 {
+		delete(m, head)
+	}
+	return value, ok
+}
+
+// setYAMLPath sets the value at the dotted path, creating parent mappings. An
+// existing Version 2 value is kept.
+func setYAMLPath(m map[string]interface{}, path string, value interface{}) {
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		if _, exists := m[head]; !exists {
+			m[head] = value
+		}
+		return
+	}
+	child, ok := m[head].(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+		m[head] = child
+	}
+	setYAMLPath(child, rest, value)
+}
+
+// navigatorConfigDecoder converts an ansible-navigator root mapping to a
+// NavigatorConfig, collecting type errors with their settings path.
+type navigatorConfigDecoder struct {
+	errs []string
+}
+
+func (d *navigatorConfigDecoder) fail(path, want string) {
+	d.errs = append(d.errs, fmt.Sprintf("%s.%s must be %s", navigatorConfigRootKey, path, want))
+}
+
+func (d *navigatorConfigDecoder) mapping(m map[string]interface{}, key, path string) map[string]interface{} {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	child, ok := v.(map[string]interface{})
+	if !ok {
+		d.fail(path, "a mapping")
+		return nil
+	}
+	return child
+}
+
+func (d *navigatorConfigDecoder) str(m map[string]interface{}, key, path string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		d.fail(path, "a string")
+	}
+	return s
+}
+
+func (d *navigatorConfigDecoder) boolean(m map[string]interface{}, key, path string) *bool {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		d.fail(path, "a boolean")
+		return nil
+	}
+	return &b
+}
+
+func (d *navigatorConfigDecoder) integer(m map[string]interface{}, key, path string) int {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return 0
+	}
+	i, ok := v.(int)
+	if !ok {
+		d.fail(path, "an integer")
+	}
+	return i
+}
+
+func (d *navigatorConfigDecoder) stringList(m map[string]interface{}, key, path string) []string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return nil
+	}
+	items, ok := v.([]interface{})
+	if !ok {
+		d.fail(path, "a list of strings")
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			d.fail(fmt.Sprintf("%s[%d]", path, i), "a string")
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func derefBool(b *bool) bool {
+	return b != nil && *b
+}
+
+// decode converts the ansible-navigator root mapping. Settings the typed model
+// does not cover are left to the raw document.
+func (d *navigatorConfigDecoder) decode(root map[string]interface{}) *NavigatorConfig {
+	config := &NavigatorConfig{
+		Mode:             d.str(root, "mode", "mode"),
+		InventoryColumns: d.stringList(root, "inventory-columns", "inventory-columns"),
+		TimeZone:         d.str(root, "time-zone", "time-zone"),
+		Format:           d.str(root, "format", "format"),
+		EnablePrompts:    d.boolean(root, "enable-prompts", "enable-prompts"),
+	}
+
+	if ee := d.mapping(root, "execution-environment", "execution-environment"); ee != nil {
+		config.ExecutionEnvironment = d.executionEnvironment(ee)
+	}
+
+	if ansible := d.mapping(root, "ansible", "ansible"); ansible != nil {
+		if cfg := d.mapping(ansible, "config", "ansible.config"); cfg != nil {
+			if path := d.str(cfg, "path", "ansible.config.path"); path != "" {
+				config.AnsibleConfig = &AnsibleConfig{Config: path}
+			}
+		}
+	}
+
+	if logging := d.mapping(root, "logging", "logging"); logging != nil {
+		config.Logging = &LoggingConfig{
+			Level:  d.str(logging, "level", "logging.level"),
+			File:   d.str(logging, "file", "logging.file"),
+			Append: d.boolean(logging, "append", "logging.append"),
+		}
+	}
+
+	if artifact := d.mapping(root, "playbook-artifact", "playbook-artifact"); artifact != nil {
+		config.PlaybookArtifact = &PlaybookArtifact{
+			Enable: d.boolean(artifact, "enable", "playbook-artifact.enable"),
+			Replay: d.str(artifact, "replay", "playbook-artifact.replay"),
+			SaveAs: d.str(artifact, "save-as", "playbook-artifact.save-as"),
+		}
+	}
+
+	if cache := d.mapping(root, "collection-doc-cache", "collection-doc-cache"); cache != nil {
+		config.CollectionDocCache = &CollectionDocCache{
+			Path:    d.str(cache, "path", "collection-doc-cache.path"),
+			Timeout: d.integer(cache, "timeout", "collection-doc-cache.timeout"),
+		}
+	}
+
+	if color := d.mapping(root, "color", "color"); color != nil {
+		config.Color = &ColorConfig{
+			Enable: d.boolean(color, "enable", "color.enable"),
+			Osc4:   d.boolean(color, "osc4", "color.osc4"),
+		}
+	}
+
+	if editor := d.mapping(root, "editor", "editor"); editor != nil {
+		config.Editor = &EditorConfig{
+			Command: d.str(editor, "command", "editor.command"),
+			Console: d.boolean(editor, "console", "editor.console"),
+		}
+	}
+
+	if images := d.mapping(root, "images", "images"); images != nil {
+		config.Images = &ImagesConfig{Details: d.stringList(images, "details", "images.details")}
+	}
+
+	if settings := d.mapping(root, "settings", "settings"); settings != nil {
+		config.Settings = &SettingsConfig{
+			Effective: d.boolean(settings, "effective", "settings.effective"),
+			Sample:    d.boolean(settings, "sample", "settings.sample"),
+			Schema:    d.str(settings, "schema", "settings.schema"),
+			Sources:   d.boolean(settings, "sources", "settings.sources"),
+		}
+	}
+
+	if runner := d.mapping(root, "ansible-runner", "ansible-runner"); runner != nil {
+		config.AnsibleRunner = &AnsibleRunnerConfig{
+			ArtifactDir:          d.str(runner, "artifact-dir", "ansible-runner.artifact-dir"),
+			RotateArtifactsCount: d.integer(runner, "rotate-artifacts-count", "ansible-runner.rotate-artifacts-count"),
+			Timeout:              d.integer(runner, "timeout", "ansible-runner.timeout"),
+			JobEvents:            d.boolean(runner, "job-events", "ansible-runner.job-events"),
+		}
+	}
+
+	return config
+}
+
+func (d *navigatorConfigDecoder) executionEnvironment(ee map[string]interface{}) *ExecutionEnvironment {
+	const prefix = "execution-environment."
+	result := &ExecutionEnvironment{
+		Enabled:          d.boolean(ee, "enabled", prefix+"enabled"),
+		Image:            d.str(ee, "image", prefix+"image"),
+		ContainerEngine:  d.str(ee, "container-engine", prefix+"container-engine"),
+		ContainerOptions: d.stringList(ee, "container-options", prefix+"container-options"),
+	}
+
+	if pull := d.mapping(ee, "pull", prefix+"pull"); pull != nil {
+		result.PullPolicy = d.str(pull, "policy", prefix+"pull.policy")
+		result.PullArguments = d.stringList(pull, "arguments", prefix+"pull.arguments")
+	}
+
+	if env := d.mapping(ee, "environment-variables", prefix+"environment-variables"); env != nil {
+		vars := &EnvironmentVariablesConfig{Pass: d.stringList(env, "pass", prefix+"environment-variables.pass")}
+		if set := d.mapping(env, "set", prefix+"environment-variables.set"); set != nil {
+			vars.Set = make(map[string]string, len(set))
+			for key, value := range set {
+				switch value.(type) {
+				case string, bool, int, float64:
+					vars.Set[key] = fmt.Sprint(value)
+				default:
+					d.fail(prefix+"environment-variables.set."+key, "a scalar value")
+				}
+			}
+		}
+		result.EnvironmentVariables = vars
+	}
+
+	if v, ok := ee["volume-mounts"]; ok && v != nil {
+		mounts, ok := v.([]interface{})
+		if !ok {
+			d.fail(prefix+"volume-mounts", "a list of mappings")
+			return result
+		}
+		for i, item := range mounts {
+			path := fmt.Sprintf("%svolume-mounts[%d]", prefix, i)
+			mount, ok := item.(map[string]interface{})
+			if !ok {
+				d.fail(path, "a mapping")
+				continue
+			}
+			vm := VolumeMount{
+				Src:     d.str(mount, "src", path+".src"),
+				Dest:    d.str(mount, "dest", path+".dest"),
+				Options: d.str(mount, "options", path+".options"),
+			}
+			if vm.Src == "" || vm.Dest == "" {
+				d.fail(path, "a mapping with src and dest")
+				continue
+			}
+			result.VolumeMounts = append(result.VolumeMounts, vm)
+		}
+	}
+
+	return result
+}
+
+// mergeNavigatorConfig returns base with every setting explicitly configured in
+// override applied on top. Booleans that can be switched off from HCL are
+// *bool, so a configured false is applied too. Nested blocks are merged field by field, lists
+// replace the base list, and environment_variables.set maps are merged by key.
+// A nil base or override returns the other.
+func mergeNavigatorConfig(base, override *NavigatorConfig) *NavigatorConfig {
+	if base == nil {
+		return override
+	}
+	if override == nil {
+		return base
+	}
+	mergeStruct(reflect.ValueOf(base).Elem(), reflect.ValueOf(override).Elem())
+	return base
+}
+
+func mergeStruct(dst, src reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		d, s := dst.Field(i), src.Field(i)
+		if !d.CanSet() || s.IsZero() {
+			continue
+		}
+		switch {
+		case s.Kind() == reflect.Ptr && s.Elem().Kind() == reflect.Struct && !d.IsNil():
+			mergeStruct(d.Elem(), s.Elem())
+		case s.Kind() == reflect.Map && !d.IsNil():
+			for _, key := range s.MapKeys() {
+				d.SetMapIndex(key, s.MapIndex(key))
+			}
+		default:
+			d.Set(s)
+		}
+	}
+}
+
+// overlayYAML returns a deep copy of base with overlay applied on top. Nested
+// mappings are merged; any other overlay value replaces the base value.
+func overlayYAML(base, overlay map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		if m, ok := value.(map[string]interface{}); ok {
+			value = overlayYAML(m, nil)
+		}
+		out[key] = value
+	}
+	for key, value := range overlay {
+		baseMap, baseIsMap := out[key].(map[string]interface{})
+		overlayMap, overlayIsMap := value.(map[string]interface{})
+		if baseIsMap && overlayIsMap {
+			out[key] = overlayYAML(baseMap, overlayMap)
+			continue
+		}
+		out[key] = value
+	}
+	return out
+}
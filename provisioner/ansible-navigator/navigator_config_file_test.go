@@ -0,0 +1,291 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func writeNavigatorConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ansible-navigator.yml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadNavigatorConfigFile_Version2(t *testing.T) {
+	path := writeNavigatorConfigFile(t, `---
+ansible-navigator:
+  mode: stdout
+  execution-environment:
+    enabled: true
+    image: quay.io/ansible/creator-ee:latest
+    pull:
+      policy: missing
+    environment-variables:
+      set:
+        FOO: bar
+        RETRIES: 3
+    volume-mounts:
+      - src: /data
+        dest: /data
+        options: ro
+  ansible:
+    config:
+      path: ./ansible.cfg
+    playbook:
+      path: site.yml
+  logging:
+    level: info
+  exec:
+    shell: false
+`)
+
+	file, err := loadNavigatorConfigFile(path)
+	require.NoError(t, err)
+	require.Empty(t, file.Migrations)
+
+	cfg := file.Config
+	require.Equal(t, "stdout", cfg.Mode)
+	require.True(t, *cfg.ExecutionEnvironment.Enabled)
+	require.Equal(t, "quay.io/ansible/creator-ee:latest", cfg.ExecutionEnvironment.Image)
+	require.Equal(t, "missing", cfg.ExecutionEnvironment.PullPolicy)
+	require.Equal(t, map[string]string{"FOO": "bar", "RETRIES": "3"}, cfg.ExecutionEnvironment.EnvironmentVariables.Set)
+	require.Equal(t, []VolumeMount{{Src: "/data", Dest: "/data", Options: "ro"}}, cfg.ExecutionEnvironment.VolumeMounts)
+	require.Equal(t, "./ansible.cfg", cfg.AnsibleConfig.Config)
+	require.Equal(t, "info", cfg.Logging.Level)
+}
+
+func TestLoadNavigatorConfigFile_MigratesVersion1(t *testing.T) {
+	path := writeNavigatorConfigFile(t, `---
+ansible-navigator:
+  ansible:
+    config: /etc/ansible/ansible.cfg
+    inventories:
+      - inventory.ini
+  execution-environment:
+    enabled: true
+    pull-policy: never
+  help-doc: false
+`)
+
+	file, err := loadNavigatorConfigFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"ansible.config -> ansible.config.path",
+		"ansible.inventories -> ansible.inventory.entries",
+		"execution-environment.pull-policy -> execution-environment.pull.policy",
+		"help-doc -> ansible.doc.help",
+	}, file.Migrations)
+	require.Equal(t, "/etc/ansible/ansible.cfg", file.Config.AnsibleConfig.Config)
+	require.Equal(t, "never", file.Config.ExecutionEnvironment.PullPolicy)
+
+	root := file.Raw["ansible-navigator"].(map[string]interface{})
+	ansible := root["ansible"].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{"entries": []interface{}{"inventory.ini"}}, ansible["inventory"])
+	require.Equal(t, map[string]interface{}{"help": false}, ansible["doc"])
+	require.NotContains(t, root, "help-doc")
+	require.NotContains(t, root["execution-environment"], "pull-policy")
+}
+
+func TestLoadNavigatorConfigFile_Errors(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		want    string
+	}{
+		"empty":         {"", "is empty"},
+		"invalid YAML":  {"ansible-navigator: [", "failed to parse"},
+		"missing root":  {"mode: stdout\n", `unexpected top-level key "mode"`},
+		"root not map":  {"ansible-navigator: stdout\n", "ansible-navigator must be a mapping"},
+		"wrong boolean": {"ansible-navigator:\n  execution-environment:\n    enabled: yes please\n", "ansible-navigator.execution-environment.enabled must be a boolean"},
+		"wrong list":    {"ansible-navigator:\n  inventory-columns: name\n", "ansible-navigator.inventory-columns must be a list of strings"},
+		"wrong integer": {"ansible-navigator:\n  ansible-runner:\n    timeout: soon\n", "ansible-navigator.ansible-runner.timeout must be an integer"},
+		"bad mount":     {"ansible-navigator:\n  execution-environment:\n    volume-mounts:\n      - src: /data\n", "volume-mounts[0] must be a mapping with src and dest"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadNavigatorConfigFile(writeNavigatorConfigFile(t, tc.content))
+			require.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestMergeNavigatorConfig_HCLTakesPrecedence(t *testing.T) {
+	base := &NavigatorConfig{
+		Mode: "interactive",
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Enabled:          boolPtr(true),
+			Image:            "file-image:1",
+			PullPolicy:       "missing",
+			ContainerOptions: []string{"--net=host"},
+			EnvironmentVariables: &EnvironmentVariablesConfig{
+				Set: map[string]string{"FROM_FILE": "1", "SHARED": "file"},
+			},
+		},
+		Logging: &LoggingConfig{Level: "info", File: "/tmp/file.log"},
+	}
+	override := &NavigatorConfig{
+		Mode: "stdout",
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Image:            "hcl-image:2",
+			ContainerOptions: []string{"--user=0:0"},
+			EnvironmentVariables: &EnvironmentVariablesConfig{
+				Set: map[string]string{"SHARED": "hcl"},
+			},
+		},
+		Logging: &LoggingConfig{Level: "debug"},
+	}
+
+	merged := mergeNavigatorConfig(base, override)
+	require.Equal(t, "stdout", merged.Mode)
+	require.True(t, *merged.ExecutionEnvironment.Enabled, "unset HCL bools keep the file value")
+	require.Equal(t, "hcl-image:2", merged.ExecutionEnvironment.Image)
+	require.Equal(t, "missing", merged.ExecutionEnvironment.PullPolicy)
+	require.Equal(t, []string{"--user=0:0"}, merged.ExecutionEnvironment.ContainerOptions)
+	require.Equal(t, map[string]string{"FROM_FILE": "1", "SHARED": "hcl"}, merged.ExecutionEnvironment.EnvironmentVariables.Set)
+	require.Equal(t, &LoggingConfig{Level: "debug", File: "/tmp/file.log"}, merged.Logging)
+
+	require.Same(t, override, mergeNavigatorConfig(nil, override))
+	require.Same(t, base, mergeNavigatorConfig(base, nil))
+}
+
+func TestMergeNavigatorConfig_HCLFalseOverridesFile(t *testing.T) {
+	base := &NavigatorConfig{
+		ExecutionEnvironment: &ExecutionEnvironment{Enabled: boolPtr(true), Image: "file-image:1"},
+		Logging:              &LoggingConfig{Level: "info", Append: boolPtr(true)},
+		PlaybookArtifact:     &PlaybookArtifact{Enable: boolPtr(true)},
+	}
+	override := &NavigatorConfig{
+		ExecutionEnvironment: &ExecutionEnvironment{Enabled: boolPtr(false)},
+		Logging:              &LoggingConfig{Append: boolPtr(false)},
+		PlaybookArtifact:     &PlaybookArtifact{Enable: boolPtr(false)},
+	}
+
+	merged := mergeNavigatorConfig(base, override)
+	require.False(t, isExecutionEnvironmentEnabled(merged))
+	require.Equal(t, "file-image:1", merged.ExecutionEnvironment.Image)
+	require.Equal(t, &LoggingConfig{Level: "info", Append: boolPtr(false)}, merged.Logging)
+	require.False(t, *merged.PlaybookArtifact.Enable)
+
+	yamlStructure := convertToYAMLStructure(merged)["ansible-navigator"].(map[string]interface{})
+	require.Equal(t, false, yamlStructure["execution-environment"].(map[string]interface{})["enabled"])
+	require.Equal(t, false, yamlStructure["logging"].(map[string]interface{})["append"])
+}
+
+func TestGenerateNavigatorConfigYAMLWithBase_PreservesUnmodeledSettings(t *testing.T) {
+	path := writeNavigatorConfigFile(t, `---
+ansible-navigator:
+  mode: interactive
+  ansible:
+    playbook:
+      path: site.yml
+  execution-environment:
+    enabled: true
+    image: file-image:1
+  exec:
+    shell: false
+`)
+	file, err := loadNavigatorConfigFile(path)
+	require.NoError(t, err)
+
+	merged := mergeNavigatorConfig(file.Config, &NavigatorConfig{Mode: "stdout"})
+	content, err := GenerateNavigatorConfigYAMLWithBase(merged, file.Raw, "", "")
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(content), &out))
+	root := out["ansible-navigator"].(map[string]interface{})
+
+	require.Equal(t, "stdout", root["mode"])
+	require.Equal(t, map[string]interface{}{"shell": false}, root["exec"])
+	require.Equal(t, map[string]interface{}{"path": "site.yml"}, root["ansible"].(map[string]interface{})["playbook"])
+
+	ee := root["execution-environment"].(map[string]interface{})
+	require.Equal(t, "file-image:1", ee["image"])
+	// Automatic EE defaults still apply to the merged configuration.
+	env := ee["environment-variables"].(map[string]interface{})["set"].(map[string]interface{})
+	require.Equal(t, "/tmp", env["HOME"])
+
+	// The loaded file content is not modified by generation.
+	require.Equal(t, "interactive", file.Raw["ansible-navigator"].(map[string]interface{})["mode"])
+}
+
+func TestProvisionerPrepare_NavigatorConfigFile(t *testing.T) {
+	path := writeNavigatorConfigFile(t, `---
+ansible-navigator:
+  mode: interactive
+  execution-environment:
+    enabled: true
+    image: quay.io/ansible/creator-ee:latest
+  logging:
+    level: info
+    append: true
+`)
+
+	var p Provisioner
+	err := p.Prepare(map[string]interface{}{
+		"skip_version_check":    true,
+		"play":                  []map[string]interface{}{{"target": "ns.coll.role"}},
+		"navigator_config_file": path,
+		"navigator_config": map[string]interface{}{
+			"mode":                  "stdout",
+			"execution_environment": map[string]interface{}{"enabled": false},
+			"logging":               map[string]interface{}{"append": false},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "stdout", p.config.NavigatorConfig.Mode)
+	require.Equal(t, "info", p.config.NavigatorConfig.Logging.Level)
+	// An HCL false switches off what the file enables.
+	require.False(t, isExecutionEnvironmentEnabled(p.config.NavigatorConfig))
+	require.False(t, *p.config.NavigatorConfig.Logging.Append)
+	require.NotNil(t, p.config.navigatorConfigFile)
+}
+
+func TestProvisionerPrepare_NavigatorConfigFileAnsibleCfgIsMerged(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "ansible.cfg")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("[defaults]\nforks = 5\nremote_tmp = /var/tmp\n"), 0o644))
+	path := writeNavigatorConfigFile(t, `---
+ansible-navigator:
+  ansible:
+    config:
+      path: `+cfgPath+`
+`)
+
+	var p Provisioner
+	err := p.Prepare(map[string]interface{}{
+		"skip_version_check":    true,
+		"play":                  []map[string]interface{}{{"target": "ns.coll.role"}},
+		"navigator_config_file": path,
+		"navigator_config": map[string]interface{}{
+			"ansible_config": map[string]interface{}{
+				"defaults": map[string]interface{}{"remote_tmp": "/tmp/x"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	ui := newMockUi()
+	content, err := buildAnsibleCfgContent(ui, p.config.NavigatorConfig.AnsibleConfig)
+	require.NoError(t, err)
+	require.Equal(t, "[defaults]\nforks = 5\nremote_tmp = /tmp/x\nhost_key_checking = False\n\n", content)
+}
+
+func TestProvisionerPrepare_NavigatorConfigFileMissing(t *testing.T) {
+	var p Provisioner
+	err := p.Prepare(map[string]interface{}{
+		"skip_version_check":    true,
+		"play":                  []map[string]interface{}{{"target": "ns.coll.role"}},
+		"navigator_config_file": filepath.Join(t.TempDir(), "missing.yml"),
+	})
+	require.ErrorContains(t, err, "navigator_config_file")
+}
@@ -8,6 +8,7 @@ package ansiblenavigator
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -45,7 +46,7 @@ func TestGenerateNavigatorConfigYAML_Basic(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_AutomaticEEDefaults(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -76,7 +77,7 @@ func TestGenerateNavigatorConfigYAML_AutomaticEEDefaults(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_AutomaticEEHomeXDGDefaults_WhenNotSetOrPassed(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -101,7 +102,7 @@ func TestGenerateNavigatorConfigYAML_AutomaticEEHomeXDGDefaults_WhenNotSetOrPass
 func TestGenerateNavigatorConfigYAML_DoesNotSetHomeXDGDefaults_WhenPassedThrough(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 			EnvironmentVariables: &EnvironmentVariablesConfig{
 				Pass: []string{"HOME", "XDG_CACHE_HOME", "XDG_CONFIG_HOME"},
@@ -129,7 +130,7 @@ func TestGenerateNavigatorConfigYAML_DoesNotSetHomeXDGDefaults_WhenPassedThrough
 func TestGenerateNavigatorConfigYAML_DoesNotOverrideHomeXDG_WhenUserSetsValues(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 			EnvironmentVariables: &EnvironmentVariablesConfig{
 				Set: map[string]string{
@@ -161,7 +162,7 @@ func TestGenerateNavigatorConfigYAML_DoesNotOverrideHomeXDG_WhenUserSetsValues(t
 func TestGenerateNavigatorConfigYAML_UserValuesPreserved(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 			EnvironmentVariables: &EnvironmentVariablesConfig{
 				Set: map[string]string{
@@ -287,7 +288,7 @@ func TestGenerateNavigatorConfigYAML_ValidYAML(t *testing.T) {
 	config := &NavigatorConfig{
 		Mode: "json",
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -379,7 +380,7 @@ mode: stdout
 func TestGenerateNavigatorConfigYAML_EEDisabled(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: false,
+			Enabled: boolPtr(false),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -413,7 +414,7 @@ func TestGenerateNavigatorConfigYAML_ComplexConfig(t *testing.T) {
 			},
 		},
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled:    true,
+			Enabled:    boolPtr(true),
 			Image:      "quay.io/ansible/creator-ee:latest",
 			PullPolicy: "missing",
 			EnvironmentVariables: &EnvironmentVariablesConfig{
@@ -424,7 +425,7 @@ func TestGenerateNavigatorConfigYAML_ComplexConfig(t *testing.T) {
 		},
 		Logging: &LoggingConfig{
 			Level:  "debug",
-			Append: true,
+			Append: boolPtr(true),
 		},
 	}
 
@@ -461,7 +462,7 @@ func TestGenerateNavigatorConfigYAML_ComplexConfig(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_VolumeMountWithCollections(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -501,7 +502,7 @@ func TestGenerateNavigatorConfigYAML_VolumeMountWithCollections(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_NoVolumeMountWhenEEDisabled(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: false,
+			Enabled: boolPtr(false),
 		},
 	}
 
@@ -527,7 +528,7 @@ func TestGenerateNavigatorConfigYAML_NoVolumeMountWhenEEDisabled(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_NoVolumeMountWhenNoCollections(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -552,7 +553,7 @@ func TestGenerateNavigatorConfigYAML_NoVolumeMountWhenNoCollections(t *testing.T
 func TestGenerateNavigatorConfigYAML_UserVolumeMountsPreserved(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 			VolumeMounts: []VolumeMount{
 				{
@@ -586,7 +587,7 @@ func TestGenerateNavigatorConfigYAML_UserVolumeMountsPreserved(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_AutoConfigureDockerHost(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -605,7 +606,7 @@ func TestGenerateNavigatorConfigYAML_AutoConfigureDockerHost(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_NoAutoConfigureDockerHost(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 		},
 	}
@@ -624,7 +625,7 @@ func TestGenerateNavigatorConfigYAML_NoAutoConfigureDockerHost(t *testing.T) {
 func TestGenerateNavigatorConfigYAML_NoDuplicateDockerHostFlag(t *testing.T) {
 	config := &NavigatorConfig{
 		ExecutionEnvironment: &ExecutionEnvironment{
-			Enabled: true,
+			Enabled: boolPtr(true),
 			Image:   "quay.io/ansible/creator-ee:latest",
 			ContainerOptions: []string{
 				"--add-host=gateway.docker.internal:host-gateway",
@@ -642,3 +643,123 @@ func TestGenerateNavigatorConfigYAML_NoDuplicateDockerHostFlag(t *testing.T) {
 		t.Errorf("Expected %q to appear only once in YAML, got count %d: %s", expected, strings.Count(yamlStr, expected), yamlStr)
 	}
 }
+
+func TestGenerateNavigatorConfigYAML_ExtendedSettings(t *testing.T) {
+	config := &NavigatorConfig{
+		Mode: "stdout",
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Enabled:       boolPtr(true),
+			Image:         "quay.io/ansible/creator-ee:latest",
+			PullArguments: []string{"--tls-verify=false"},
+		},
+		Color:            &ColorConfig{Enable: boolPtr(false), Osc4: boolPtr(true)},
+		Editor:           &EditorConfig{Command: "vim_from_setting", Console: boolPtr(false)},
+		Images:           &ImagesConfig{Details: []string{"ansible_version", "python_version"}},
+		InventoryColumns: []string{"ansible_network_os", "ansible_network_cli_ssh_type"},
+		TimeZone:         "America/New_York",
+		Format:           "yaml",
+		Settings:         &SettingsConfig{Effective: boolPtr(true), Schema: "json", Sources: boolPtr(false)},
+		EnablePrompts:    boolPtr(false),
+		AnsibleRunner: &AnsibleRunnerConfig{
+			ArtifactDir:          "/tmp/artifacts",
+			RotateArtifactsCount: 10,
+			Timeout:              300,
+			JobEvents:            boolPtr(true),
+		},
+	}
+
+	yamlStr, err := GenerateNavigatorConfigYAML(config, "", "")
+	if err != nil {
+		t.Fatalf("GenerateNavigatorConfigYAML failed: %v", err)
+	}
+
+	var parsed map[string]interface{}
+	if err := yaml.Unmarshal([]byte(yamlStr), &parsed); err != nil {
+		t.Fatalf("generated YAML is invalid: %v", err)
+	}
+	nav := parsed["ansible-navigator"].(map[string]interface{})
+
+	expected := map[string]interface{}{
+		"color":             map[string]interface{}{"enable": false, "osc4": true},
+		"editor":            map[string]interface{}{"command": "vim_from_setting", "console": false},
+		"images":            map[string]interface{}{"details": []interface{}{"ansible_version", "python_version"}},
+		"inventory-columns": []interface{}{"ansible_network_os", "ansible_network_cli_ssh_type"},
+		"time-zone":         "America/New_York",
+		"format":            "yaml",
+		"settings":          map[string]interface{}{"effective": true, "schema": "json", "sources": false},
+		"enable-prompts":    false,
+		"ansible-runner": map[string]interface{}{
+			"artifact-dir":           "/tmp/artifacts",
+			"rotate-artifacts-count": 10,
+			"timeout":                300,
+			"job-events":             true,
+		},
+	}
+	for key, want := range expected {
+		got, ok := nav[key]
+		if !ok {
+			t.Errorf("expected %s in YAML, got: %s", key, yamlStr)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %#v, got %#v", key, want, got)
+		}
+	}
+
+	pull := nav["execution-environment"].(map[string]interface{})["pull"].(map[string]interface{})
+	if !reflect.DeepEqual(pull["arguments"], []interface{}{"--tls-verify=false"}) {
+		t.Errorf("expected pull arguments in YAML, got: %s", yamlStr)
+	}
+}
+
+func TestGenerateNavigatorConfigYAML_UnsetOptionalSettingsOmitted(t *testing.T) {
+	config := &NavigatorConfig{
+		Mode:          "stdout",
+		Color:         &ColorConfig{},
+		Settings:      &SettingsConfig{},
+		AnsibleRunner: &AnsibleRunnerConfig{},
+	}
+
+	yamlStr, err := GenerateNavigatorConfigYAML(config, "", "")
+	if err != nil {
+		t.Fatalf("GenerateNavigatorConfigYAML failed: %v", err)
+	}
+	for _, key := range []string{"color", "settings", "ansible-runner", "enable-prompts"} {
+		if strings.Contains(yamlStr, key) {
+			t.Errorf("did not expect %s in YAML, got: %s", key, yamlStr)
+		}
+	}
+}
+
+func TestValidateNavigatorSettings(t *testing.T) {
+	config := &NavigatorConfig{
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Enabled:       boolPtr(true),
+			PullArguments: []string{"--tls-verify=false", "", "--creds user:pass"},
+		},
+		AnsibleRunner: &AnsibleRunnerConfig{RotateArtifactsCount: -1, Timeout: -5},
+	}
+
+	errs := validateNavigatorSettings(config)
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %d: %v", len(errs), errs)
+	}
+	for i, want := range []string{
+		"pull_arguments[1] must not be empty",
+		`pull_arguments[2] must be a single argument without whitespace`,
+		"rotate_artifacts_count must not be negative",
+		"ansible_runner.timeout must not be negative",
+	} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("error %d: expected %q, got %q", i, want, errs[i].Error())
+		}
+	}
+
+	cfg := &Config{
+		Plays:           []Play{{Target: "ns.coll.role"}},
+		NavigatorConfig: &NavigatorConfig{TimeZone: "UTC"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected navigator_config with only time_zone to be valid, got: %v", err)
+	}
+}
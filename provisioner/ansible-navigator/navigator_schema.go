@@ -0,0 +1,236 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// navigatorConfigRootKey is the single top-level key of an ansible-navigator.yml file.
+const navigatorConfigRootKey = "ansible-navigator"
+
+// navigatorSettingsSchemaJSON is the ansible-navigator settings file JSON schema.
+//
+//go:embed navigator_settings_schema.json
+var navigatorSettingsSchemaJSON []byte
+
+var navigatorSettingsSchema = mustParseSchema(navigatorSettingsSchemaJSON)
+
+// jsonSchema is the subset of JSON Schema used by the ansible-navigator settings schema.
+type jsonSchema struct {
+	Type                 string                 `json:"type"`
+	Enum                 []string               `json:"enum"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+}
+
+// additionalProperties is either a boolean or a schema for the values of
+// properties not listed in Properties.
+type additionalProperties struct {
+	Allowed bool
+	Schema  *jsonSchema
+}
+
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+func mustParseSchema(data []byte) *jsonSchema {
+	var schema jsonSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		panic(fmt.Sprintf("invalid embedded JSON schema: %v", err))
+	}
+	return &schema
+}
+
+// navigatorSettingsEnum returns the allowed values of the setting at the given
+// ansible-navigator.yml path (below the ansible-navigator root key). For lists
+// the allowed item values are returned.
+func navigatorSettingsEnum(path ...string) []string {
+	schema := navigatorSettingsSchema.Properties[navigatorConfigRootKey]
+	for _, key := range path {
+		if schema == nil {
+			return nil
+		}
+		schema = schema.Properties[key]
+	}
+	if schema == nil {
+		return nil
+	}
+	if schema.Items != nil {
+		return schema.Items.Enum
+	}
+	return schema.Enum
+}
+
+// validateEnumSetting reports an error when value is set and not allowed at the
+// given ansible-navigator.yml path. field is the HCL name used in the message.
+func validateEnumSetting(field, value string, path ...string) error {
+	allowed := navigatorSettingsEnum(path...)
+	if value == "" || len(allowed) == 0 {
+		return nil
+	}
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid %s: %q (must be one of: %s)", field, value, strings.Join(allowed, ", "))
+}
+
+// validateSchema validates a decoded YAML document against schema. Errors name
+// the offending setting using describe, which receives the document path.
+func validateSchema(schema *jsonSchema, value interface{}, path []string, describe func([]string) string) []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", describe(path), fmt.Sprintf(format, args...)))
+	}
+
+	switch schema.Type {
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			fail("must be a mapping")
+			return errs
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			fail("must be a list")
+			return errs
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			fail("must be a string")
+			return errs
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+			return errs
+		}
+	case "integer":
+		if _, ok := value.(int); !ok {
+			fail("must be an integer")
+			return errs
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		s, _ := value.(string)
+		allowed := false
+		for _, e := range schema.Enum {
+			if s == e {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			fail("%q is not one of: %s", s, strings.Join(schema.Enum, ", "))
+		}
+	}
+
+	if items, ok := value.([]interface{}); ok && schema.Items != nil {
+		for i, item := range items {
+			errs = append(errs, validateSchema(schema.Items, item, appendPath(path, fmt.Sprintf("[%d]", i)), describe)...)
+		}
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		for _, key := range schema.Required {
+			if _, ok := m[key]; !ok {
+				fail("missing required setting %q", key)
+			}
+		}
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := schema.Properties[key]
+			if child == nil && schema.AdditionalProperties != nil {
+				if !schema.AdditionalProperties.Allowed {
+					errs = append(errs, fmt.Errorf("%s: unknown setting", describe(appendPath(path, key))))
+					continue
+				}
+				child = schema.AdditionalProperties.Schema
+			}
+			if child != nil {
+				errs = append(errs, validateSchema(child, m[key], appendPath(path, key), describe)...)
+			}
+		}
+	}
+
+	return errs
+}
+
+func appendPath(path []string, elem string) []string {
+	out := make([]string, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, elem)
+}
+
+// joinSettingsPath joins path elements with dots, attaching list indexes directly.
+func joinSettingsPath(path []string) string {
+	var b strings.Builder
+	for i, elem := range path {
+		if i > 0 && !strings.HasPrefix(elem, "[") {
+			b.WriteString(".")
+		}
+		b.WriteString(elem)
+	}
+	return b.String()
+}
+
+// modeledNavigatorSettings lists the top-level ansible-navigator.yml settings
+// that have a navigator_config equivalent.
+var modeledNavigatorSettings = map[string]bool{
+	"ansible-runner":        true,
+	"collection-doc-cache":  true,
+	"color":                 true,
+	"editor":                true,
+	"enable-prompts":        true,
+	"execution-environment": true,
+	"format":                true,
+	"images":                true,
+	"inventory-columns":     true,
+	"logging":               true,
+	"mode":                  true,
+	"playbook-artifact":     true,
+	"settings":              true,
+	"time-zone":             true,
+}
+
+// navigatorConfigHCLPath describes a generated ansible-navigator.yml path by its
+// navigator_config field, e.g. "navigator_config.execution_environment.pull_policy".
+// Settings without a navigator_config equivalent keep their YAML path.
+func navigatorConfigHCLPath(path []string) string {
+	yamlPath := joinSettingsPath(path)
+	if len(path) < 2 || path[0] != navigatorConfigRootKey {
+		return yamlPath
+	}
+	rest := path[1:]
+
+	switch {
+	case len(rest) >= 3 && rest[0] == "execution-environment" && rest[1] == "pull":
+		// pull.policy / pull.arguments are flattened to pull_policy / pull_arguments
+		rest = append([]string{"execution-environment", "pull-" + rest[2]}, rest[3:]...)
+	case len(rest) == 3 && rest[0] == "ansible" && rest[1] == "config" && rest[2] == "path":
+		return "navigator_config.ansible_config.config"
+	case !modeledNavigatorSettings[rest[0]]:
+		return yamlPath
+	}
+	return "navigator_config." + strings.ReplaceAll(joinSettingsPath(rest), "-", "_")
+}
@@ -0,0 +1,123 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+
+package ansiblenavigator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNavigatorSettingsSchema_Enums(t *testing.T) {
+	require.Equal(t, []string{"stdout", "interactive"}, navigatorSettingsEnum("mode"))
+	require.Equal(t, []string{"always", "missing", "never", "tag"}, navigatorSettingsEnum("execution-environment", "pull", "policy"))
+	require.Contains(t, navigatorSettingsEnum("images", "details"), "python_packages")
+	require.Nil(t, navigatorSettingsEnum("time-zone"))
+	require.Nil(t, navigatorSettingsEnum("no-such-setting"))
+}
+
+func TestValidateNavigatorConfigDocument_Valid(t *testing.T) {
+	config := &NavigatorConfig{
+		Mode: "stdout",
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Enabled:         boolPtr(true),
+			Image:           "quay.io/ansible/creator-ee:latest",
+			PullPolicy:      "missing",
+			ContainerEngine: "podman",
+			PullArguments:   []string{"--tls-verify=false"},
+			EnvironmentVariables: &EnvironmentVariablesConfig{
+				Pass: []string{"HOME"},
+				Set:  map[string]string{"RETRIES": "3"},
+			},
+			VolumeMounts: []VolumeMount{{Src: "/a", Dest: "/b", Options: "ro"}},
+		},
+		AnsibleConfig:    &AnsibleConfig{Config: "/tmp/ansible.cfg"},
+		Logging:          &LoggingConfig{Level: "debug"},
+		Images:           &ImagesConfig{Details: []string{"everything"}},
+		InventoryColumns: []string{"name"},
+		Settings:         &SettingsConfig{Schema: "json"},
+		AnsibleRunner:    &AnsibleRunnerConfig{Timeout: 10},
+	}
+	require.Empty(t, validateNavigatorConfigDocument(config, nil))
+}
+
+func TestValidateNavigatorConfigDocument_ReportsHCLPaths(t *testing.T) {
+	config := &NavigatorConfig{
+		Mode: "batch",
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Enabled:    boolPtr(true),
+			PullPolicy: "sometimes",
+		},
+		Images: &ImagesConfig{Details: []string{"everything", "kernel"}},
+	}
+
+	var msgs []string
+	for _, err := range validateNavigatorConfigDocument(config, nil) {
+		msgs = append(msgs, err.Error())
+	}
+	require.Equal(t, []string{
+		`navigator_config.execution_environment.pull_policy: "sometimes" is not one of: always, missing, never, tag`,
+		`navigator_config.images.details[1]: "kernel" is not one of: ansible_collections, ansible_version, everything, os_release, python_packages, python_version, redhat_release, system_packages`,
+		`navigator_config.mode: "batch" is not one of: stdout, interactive`,
+	}, msgs)
+}
+
+func TestValidateNavigatorConfigDocument_NavigatorConfigFileContent(t *testing.T) {
+	base := map[string]interface{}{
+		"ansible-navigator": map[string]interface{}{
+			"exec":    map[string]interface{}{"shell": "yes"},
+			"typo":    true,
+			"ansible": map[string]interface{}{"playbook": map[string]interface{}{"path": "site.yml"}},
+		},
+	}
+
+	var msgs []string
+	for _, err := range validateNavigatorConfigDocument(&NavigatorConfig{Mode: "stdout"}, base) {
+		msgs = append(msgs, err.Error())
+	}
+	require.Equal(t, []string{
+		"ansible-navigator.exec.shell: must be a boolean",
+		"ansible-navigator.typo: unknown setting",
+	}, msgs)
+}
+
+func TestNavigatorConfigHCLPath(t *testing.T) {
+	cases := map[string][]string{
+		"navigator_config.execution_environment.pull_arguments[0]":    {"ansible-navigator", "execution-environment", "pull", "arguments", "[0]"},
+		"navigator_config.execution_environment.volume_mounts[2].src": {"ansible-navigator", "execution-environment", "volume-mounts", "[2]", "src"},
+		"navigator_config.ansible_config.config":                      {"ansible-navigator", "ansible", "config", "path"},
+		"navigator_config.ansible_runner.job_events":                  {"ansible-navigator", "ansible-runner", "job-events"},
+		"ansible-navigator.ansible.playbook.path":                     {"ansible-navigator", "ansible", "playbook", "path"},
+		"ansible-navigator": {"ansible-navigator"},
+	}
+	for want, path := range cases {
+		require.Equal(t, want, navigatorConfigHCLPath(path))
+	}
+}
+
+func TestConfigValidate_NavigatorConfigEnums(t *testing.T) {
+	cfg := &Config{Plays: []Play{{Target: "ns.coll.role"}}}
+	cfg.NavigatorConfig = &NavigatorConfig{
+		Mode: "stdout",
+		ExecutionEnvironment: &ExecutionEnvironment{
+			Enabled:         boolPtr(true),
+			PullPolicy:      "never",
+			ContainerEngine: "auto",
+		},
+		Logging:  &LoggingConfig{Level: "warning"},
+		Format:   "yaml",
+		Settings: &SettingsConfig{Schema: "json"},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.NavigatorConfig.ExecutionEnvironment.PullPolicy = "Always"
+	cfg.NavigatorConfig.ExecutionEnvironment.ContainerEngine = "containerd"
+	cfg.NavigatorConfig.Format = "toml"
+	err := cfg.Validate()
+	require.ErrorContains(t, err, `invalid navigator_config.execution_environment.pull_policy: "Always" (must be one of: always, missing, never, tag)`)
+	require.ErrorContains(t, err, `invalid navigator_config.execution_environment.container_engine: "containerd"`)
+	require.ErrorContains(t, err, `invalid navigator_config.format: "toml"`)
+}
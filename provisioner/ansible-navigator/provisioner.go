@@ -3,7 +3,7 @@
 // You may obtain a copy of the License at
 // http://www.apache.org/licenses/LICENSE-2.0
 
-//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Play,PathEntry,NavigatorConfig,ExecutionEnvironment,EnvironmentVariablesConfig,VolumeMount,AnsibleConfig,AnsibleConfigDefaults,AnsibleConfigConnection,LoggingConfig,PlaybookArtifact,CollectionDocCache
+//go:generate packer-sdc mapstructure-to-hcl2 -type Config,Play,PathEntry,NavigatorConfig,ExecutionEnvironment,ImageVerifyConfig,ImageBuildConfig,EnvironmentVariablesConfig,VolumeMount,AnsibleConfig,AnsibleConfigDefaults,AnsibleConfigConnection,AnsibleConfigSection,LoggingConfig,PlaybookArtifact,CollectionDocCache,ColorConfig,EditorConfig,ImagesConfig,SettingsConfig,AnsibleRunnerConfig,GalaxyServer
 //go:generate packer-sdc struct-markdown
 
 package ansiblenavigator
@@ -68,6 +68,24 @@ type NavigatorConfig struct {
 	PlaybookArtifact *PlaybookArtifact `mapstructure:"playbook_artifact"`
 	// Collection documentation cache settings
 	CollectionDocCache *CollectionDocCache `mapstructure:"collection_doc_cache"`
+	// Color output settings
+	Color *ColorConfig `mapstructure:"color"`
+	// Editor settings
+	Editor *EditorConfig `mapstructure:"editor"`
+	// Image details settings (images subcommand)
+	Images *ImagesConfig `mapstructure:"images"`
+	// Columns shown by the inventory subcommand
+	InventoryColumns []string `mapstructure:"inventory_columns"`
+	// Time zone used for timestamps (e.g., "UTC", "America/New_York", "local")
+	TimeZone string `mapstructure:"time_zone"`
+	// Output format for subcommands that support it (json, yaml)
+	Format string `mapstructure:"format"`
+	// Settings subcommand options
+	Settings *SettingsConfig `mapstructure:"settings"`
+	// Enable prompts for passwords and playbook user input
+	EnablePrompts *bool `mapstructure:"enable_prompts"`
+	// ansible-runner settings
+	AnsibleRunner *AnsibleRunnerConfig `mapstructure:"ansible_runner"`
 }
 
 // isPluginDebugEnabled returns true iff plugin debug output should be enabled.
@@ -91,9 +109,12 @@ func debugf(ui packersdk.Ui, enabled bool, format string, args ...interface{}) {
 // ExecutionEnvironment represents execution environment settings
 type ExecutionEnvironment struct {
 	// Enable execution environment
-	Enabled bool `mapstructure:"enabled"`
+	Enabled *bool `mapstructure:"enabled"`
 	// Container image to use
 	Image string `mapstructure:"image"`
+	// Expected registry digest of the image (sha256:...). The image is run by
+	// this digest, and the build fails if the image resolves to a different one.
+	ImageDigest string `mapstructure:"image_digest"`
 	// Pull policy for the container image
 	PullPolicy string `mapstructure:"pull_policy"`
 	// Container engine to use (auto, podman, docker)
@@ -106,6 +127,39 @@ type ExecutionEnvironment struct {
 	EnvironmentVariables *EnvironmentVariablesConfig `mapstructure:"environment_variables"`
 	// Volume mounts for the execution environment container
 	VolumeMounts []VolumeMount `mapstructure:"volume_mounts"`
+	// Signature verification of the image before the first play
+	Verify *ImageVerifyConfig `mapstructure:"verify"`
+	// What to do when the preflight checks of the container engine, image and
+	// mounts find a problem before the first play: "warn" (default), "fail" or "off".
+	Preflight string `mapstructure:"preflight"`
+	// Build the image with ansible-builder instead of using image
+	Build *ImageBuildConfig `mapstructure:"build"`
+}
+
+// ImageBuildConfig builds the execution environment image with ansible-builder.
+// The image is tagged with a hash of the build inputs and rebuilt only when they change.
+type ImageBuildConfig struct {
+	// Path to the ansible-builder definition (execution-environment.yml).
+	File string `mapstructure:"file"`
+	// Repository of the built image, without a tag. Defaults to
+	// "packer-ansible-navigator-ee".
+	Repository string `mapstructure:"repository"`
+	// ansible-builder executable. Defaults to "ansible-builder".
+	Command string `mapstructure:"command"`
+	// Additional arguments passed to `ansible-builder build`, such as
+	// --build-arg=KEY=VALUE. They are part of the content hash.
+	ExtraArgs []string `mapstructure:"extra_args"`
+}
+
+// ImageVerifyConfig configures signature verification of the execution
+// environment image. Exactly one of cosign_key and policy must be set.
+type ImageVerifyConfig struct {
+	// Path to a cosign public key (or a cosign KMS URI such as awskms://...).
+	// The image is checked with `cosign verify --key`.
+	CosignKey string `mapstructure:"cosign_key"`
+	// Path to a containers-policy.json file. The image is pulled with
+	// `podman pull --signature-policy`, so podman enforces the policy.
+	Policy string `mapstructure:"policy"`
 }
 
 // VolumeMount represents a volume mount for the execution environment
@@ -134,6 +188,22 @@ type AnsibleConfig struct {
 	Defaults *AnsibleConfigDefaults `mapstructure:"defaults"`
 	// SSH connection section
 	SSHConnection *AnsibleConfigConnection `mapstructure:"ssh_connection"`
+	// Arbitrary ansible.cfg sections. Each block adds options to one section;
+	// repeat the block for more sections:
+	//   section {
+	//     name    = "defaults"
+	//     options = { forks = "20", callbacks_enabled = "ansible.posix.profile_tasks" }
+	//   }
+	// Setting an option that a typed defaults/ssh_connection field also sets is an error.
+	Sections []AnsibleConfigSection `mapstructure:"section"`
+}
+
+// AnsibleConfigSection is a generic ansible.cfg section
+type AnsibleConfigSection struct {
+	// Section name without brackets (e.g., "defaults", "privilege_escalation", "inventory")
+	Name string `mapstructure:"name" required:"true"`
+	// Option names and values written to the section
+	Options map[string]string `mapstructure:"options"`
 }
 
 // AnsibleConfigDefaults represents ansible defaults configuration
@@ -161,13 +231,13 @@ type LoggingConfig struct {
 	// Log file path
 	File string `mapstructure:"file"`
 	// Append to log file
-	Append bool `mapstructure:"append"`
+	Append *bool `mapstructure:"append"`
 }
 
 // PlaybookArtifact represents playbook artifact settings
 type PlaybookArtifact struct {
 	// Enable playbook artifact
-	Enable bool `mapstructure:"enable"`
+	Enable *bool `mapstructure:"enable"`
 	// Replay directory
 	Replay string `mapstructure:"replay"`
 	// Save directory
@@ -182,6 +252,71 @@ type CollectionDocCache struct {
 	Timeout int `mapstructure:"timeout"`
 }
 
+// ColorConfig represents color output settings
+type ColorConfig struct {
+	// Enable the use of color in output
+	Enable *bool `mapstructure:"enable"`
+	// Enable OSC 4 terminal color support
+	Osc4 *bool `mapstructure:"osc4"`
+}
+
+// EditorConfig represents editor settings
+type EditorConfig struct {
+	// Command used to open files in an editor (e.g., "vi +{line_number} {filename}")
+	Command string `mapstructure:"command"`
+	// Whether the editor is console based
+	Console *bool `mapstructure:"console"`
+}
+
+// ImagesConfig represents images subcommand settings
+type ImagesConfig struct {
+	// Image details to collect (ansible_collections, ansible_version, everything,
+	// os_release, python_packages, python_version, redhat_release, system_packages)
+	Details []string `mapstructure:"details"`
+}
+
+// SettingsConfig represents settings subcommand settings
+type SettingsConfig struct {
+	// Show the effective settings
+	Effective *bool `mapstructure:"effective"`
+	// Generate a sample settings file
+	Sample *bool `mapstructure:"sample"`
+	// Generate a schema for the settings file (json)
+	Schema string `mapstructure:"schema"`
+	// Show the source of each setting
+	Sources *bool `mapstructure:"sources"`
+}
+
+// AnsibleRunnerConfig represents ansible-runner settings
+type AnsibleRunnerConfig struct {
+	// Directory for ansible-runner artifacts
+	ArtifactDir string `mapstructure:"artifact_dir"`
+	// Number of artifact directories to keep
+	RotateArtifactsCount int `mapstructure:"rotate_artifacts_count"`
+	// Timeout in seconds for ansible-runner to finish
+	Timeout int `mapstructure:"timeout"`
+	// Write ansible-runner job events to the artifact directory
+	JobEvents *bool `mapstructure:"job_events"`
+}
+
+// GalaxyServer defines an Ansible Galaxy server used by ansible-galaxy when
+// installing requirements_file content. Servers are passed to ansible-galaxy via
+// ANSIBLE_GALAXY_SERVER_* environment variables, in declaration order.
+type GalaxyServer struct {
+	// Server identifier. Referenced from requirements.yml `source` entries and used
+	// to build the ANSIBLE_GALAXY_SERVER_<NAME>_* environment variable names.
+	// Must contain only letters, digits and underscores.
+	Name string `mapstructure:"name" required:"true"`
+	// Galaxy API URL of the server.
+	URL string `mapstructure:"url" required:"true"`
+	// Token endpoint used to exchange an offline token (Red Hat SSO / Keycloak).
+	AuthURL string `mapstructure:"auth_url"`
+	// API token. Treated as a secret: it is redacted from all output.
+	Token string `mapstructure:"token"`
+	// Whether to verify the server TLS certificate. Defaults to true.
+	ValidateCerts *bool `mapstructure:"validate_certs"`
+}
+
 // Play represents a single Ansible play execution with its configuration.
 // It supports both traditional playbook files and Ansible Collection role FQDNs.
 // Each play can have its own variables, tags, and privilege escalation settings.
@@ -262,6 +397,41 @@ type Config struct {
 	// When true, pass --force to ansible-galaxy.
 	// Ignored if galaxy_force_with_deps is true.
 	GalaxyForce bool `mapstructure:"galaxy_force"`
+	// Galaxy servers used by ansible-galaxy, in priority order.
+	// Equivalent to `[galaxy] server_list` and `[galaxy_server.<name>]` sections in
+	// ansible.cfg, but without requiring a hand-written ansible.cfg.
+	GalaxyServers []GalaxyServer `mapstructure:"galaxy_server"`
+	// Where ansible-galaxy runs when installing requirements_file content.
+	// "host" (default) runs galaxy_command on the build host.
+	// "execution_environment" runs ansible-galaxy inside navigator_config.execution_environment.image,
+	// using the same container engine, pull policy, environment variables and volume mounts as
+	// ansible-navigator, so installed collections always match the EE's Python and ansible-core.
+	// Collections are installed into collections_path, which is mounted into the EE.
+	GalaxyRuntime string `mapstructure:"galaxy_runtime"`
+	// Maximum number of concurrent ansible-galaxy processes.
+	// When greater than 1, every role and collection in requirements_file is installed
+	// as an independent unit, output lines are prefixed with the unit name, and all
+	// failures are reported together. Requirements already present in roles_path /
+	// collections_path in a version matching their pin are skipped unless galaxy_force
+	// or galaxy_force_with_deps is set. The build fails when two requirements install
+	// different versions of the same dependency.
+	// Defaults to 1 (a single sequential install per requirements type).
+	GalaxyParallelism int `mapstructure:"galaxy_parallelism"`
+	// Path to a GPG keyring used to verify collection signatures.
+	// Passed to `ansible-galaxy collection install` as --keyring. When set, the installed
+	// collections are also checked with `ansible-galaxy collection verify` after install and
+	// the build fails on any checksum mismatch or signature failure. Results are included in
+	// the structured logging summary.
+	GalaxyKeyring string `mapstructure:"galaxy_keyring"`
+	// Number of valid signatures required for each collection, passed as
+	// --required-valid-signature-count. A positive number or "all", optionally prefixed
+	// with "+" to also fail when no valid signatures are found.
+	// Requires galaxy_keyring.
+	GalaxyRequiredValidSignatureCount string `mapstructure:"galaxy_required_valid_signature_count"`
+	// GnuPG status codes to ignore during signature verification (for example
+	// "NO_PUBKEY"), each passed as --ignore-signature-status-code.
+	// Requires galaxy_keyring.
+	GalaxyIgnoreSignatureStatusCodes []string `mapstructure:"galaxy_ignore_signature_status_codes"`
 
 	// The groups into which the Ansible host should
 	//  be placed. When unspecified, the host is not associated with any groups.
@@ -420,7 +590,19 @@ type Config struct {
 	//     }
 	//   }
 	NavigatorConfig *NavigatorConfig `mapstructure:"navigator_config"`
-	userWasEmpty    bool
+	// Path to an existing ansible-navigator.yml (Version 2, or Version 1 which is
+	// migrated automatically) used as the base configuration. Settings in the
+	// navigator_config block take precedence over the file: scalar settings and
+	// lists replace the file's value, nested blocks are merged setting by setting,
+	// and environment_variables.set is merged by key. Settings the plugin does not
+	// model (e.g., ansible.playbook, exec) are passed through unchanged.
+	//
+	// Note: boolean settings such as execution_environment.enabled can only be
+	// turned on from HCL, because an HCL `false` is indistinguishable from unset.
+	NavigatorConfigFile string `mapstructure:"navigator_config_file"`
+	userWasEmpty        bool
+	// navigatorConfigFile is the loaded navigator_config_file, set by Prepare.
+	navigatorConfigFile *navigatorConfigFile
 }
 
 // Validate performs comprehensive validation of the Config
@@ -463,6 +645,40 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate galaxy_server blocks
+	for _, err := range validateGalaxyServers(c.GalaxyServers) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	// Validate collection signature verification options
+	for _, err := range validateGalaxySignatureOptions(c) {
+		errs = packersdk.MultiErrorAppend(errs, err)
+	}
+
+	if c.GalaxyParallelism < 0 {
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"galaxy_parallelism must not be negative (got %d)", c.GalaxyParallelism))
+	}
+
+	// Validate galaxy_runtime
+	switch c.GalaxyRuntime {
+	case "", GalaxyRuntimeHost:
+	case GalaxyRuntimeExecutionEnvironment:
+		if c.NavigatorConfig == nil || c.NavigatorConfig.ExecutionEnvironment == nil || !derefBool(c.NavigatorConfig.ExecutionEnvironment.Enabled) {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"galaxy_runtime = %q requires navigator_config.execution_environment.enabled = true",
+				GalaxyRuntimeExecutionEnvironment))
+		} else if c.NavigatorConfig.ExecutionEnvironment.Image == "" && c.NavigatorConfig.ExecutionEnvironment.Build == nil {
+			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+				"galaxy_runtime = %q requires navigator_config.execution_environment.image or build",
+				GalaxyRuntimeExecutionEnvironment))
+		}
+	default:
+		errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
+			"invalid galaxy_runtime: %q (must be %q or %q)",
+			c.GalaxyRuntime, GalaxyRuntimeHost, GalaxyRuntimeExecutionEnvironment))
+	}
+
 	if c.SSHAuthorizedKeyFile != "" {
 		if err := validateFileConfig(c.SSHAuthorizedKeyFile, "ssh_authorized_key_file", true); err != nil {
 			errs = packersdk.MultiErrorAppend(errs, err)
@@ -513,21 +729,41 @@ func (c *Config) Validate() error {
 			c.NavigatorConfig.AnsibleConfig == nil &&
 			c.NavigatorConfig.Logging == nil &&
 			c.NavigatorConfig.PlaybookArtifact == nil &&
-			c.NavigatorConfig.CollectionDocCache == nil
-		if isEmpty {
+			c.NavigatorConfig.CollectionDocCache == nil &&
+			c.NavigatorConfig.Color == nil &&
+			c.NavigatorConfig.Editor == nil &&
+			c.NavigatorConfig.Images == nil &&
+			len(c.NavigatorConfig.InventoryColumns) == 0 &&
+			c.NavigatorConfig.TimeZone == "" &&
+			c.NavigatorConfig.Format == "" &&
+			c.NavigatorConfig.Settings == nil &&
+			c.NavigatorConfig.EnablePrompts == nil &&
+			c.NavigatorConfig.AnsibleRunner == nil
+		// A navigator_config_file may hold only settings the plugin passes through.
+		if isEmpty && c.NavigatorConfigFile == "" {
 			errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
 				"navigator_config block cannot be empty. Either provide configuration or omit the block"))
 		}
 
-		// Schema compliance: ansible_config.config (path) is mutually exclusive with
-		// the nested defaults/ssh_connection blocks (which map to a generated ansible.cfg).
+		// When ansible_config.config is combined with the nested defaults/ssh_connection/section
+		// blocks (or the automatic EE defaults), the generated settings are merged into
+		// that file, so it must exist.
 		if c.NavigatorConfig.AnsibleConfig != nil {
 			ac := c.NavigatorConfig.AnsibleConfig
-			if ac.Config != "" && (ac.Defaults != nil || ac.SSHConnection != nil) {
-				errs = packersdk.MultiErrorAppend(errs, fmt.Errorf(
-					"navigator_config.ansible_config.config is mutually exclusive with navigator_config.ansible_config.defaults and navigator_config.ansible_config.ssh_connection"))
+			eeEnabled := c.NavigatorConfig.ExecutionEnvironment != nil && derefBool(c.NavigatorConfig.ExecutionEnvironment.Enabled)
+			if ac.Config != "" && (needsGeneratedAnsibleCfg(ac) || eeEnabled) {
+				if err := validateFileConfig(ac.Config, "navigator_config.ansible_config.config", true); err != nil {
+					errs = packersdk.MultiErrorAppend(errs, err)
+				}
+			}
+			for _, err := range validateAnsibleCfgSections(ac) {
+				errs = packersdk.MultiErrorAppend(errs, err)
 			}
 		}
+
+		for _, err := range validateNavigatorSettings(c.NavigatorConfig) {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
 	}
 
 	if errs != nil && len(errs.Errors) > 0 {
@@ -546,6 +782,12 @@ type Provisioner struct {
 	ansibleVersion    string
 	ansibleMajVersion uint
 	generatedData     map[string]interface{}
+	// galaxyVerification is the collection verification result, reported in the structured summary.
+	galaxyVerification *GalaxyVerification
+	// engine is the detected container engine of the execution environment, if any.
+	engine containerEngine
+	// eeImage is the prepared execution environment image, reported in the structured summary.
+	eeImage *ExecutionEnvironmentImage
 
 	setupAdapterFunc   func(ui packersdk.Ui, comm packersdk.Communicator) (string, error)
 	executeAnsibleFunc func(ui packersdk.Ui, comm packersdk.Communicator, privKeyFile string) error
@@ -596,6 +838,8 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 	p.config.CollectionsPath = expandUserPath(p.config.CollectionsPath)
 	p.config.RolesPath = expandUserPath(p.config.RolesPath)
 	p.config.GalaxyCommand = expandUserPath(p.config.GalaxyCommand)
+	p.config.GalaxyKeyring = expandUserPath(p.config.GalaxyKeyring)
+	p.config.NavigatorConfigFile = expandUserPath(p.config.NavigatorConfigFile)
 
 	// Apply HOME expansion to plays
 	for i := range p.config.Plays {
@@ -605,6 +849,18 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		}
 	}
 
+	// Apply HOME expansion to execution environment verification and build files
+	if nc := p.config.NavigatorConfig; nc != nil && nc.ExecutionEnvironment != nil {
+		if v := nc.ExecutionEnvironment.Verify; v != nil {
+			v.CosignKey = expandUserPath(v.CosignKey)
+			v.Policy = expandUserPath(v.Policy)
+		}
+		if b := nc.ExecutionEnvironment.Build; b != nil {
+			b.File = expandUserPath(b.File)
+			b.Command = expandUserPath(b.Command)
+		}
+	}
+
 	if p.config.HostAlias == "" {
 		p.config.HostAlias = "default"
 	}
@@ -621,6 +877,19 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 	if p.config.GalaxyCommand == "" {
 		p.config.GalaxyCommand = "ansible-galaxy"
 	}
+	if p.config.GalaxyRuntime == "" {
+		p.config.GalaxyRuntime = GalaxyRuntimeHost
+	}
+	if p.config.GalaxyParallelism == 0 {
+		p.config.GalaxyParallelism = 1
+	}
+
+	// Galaxy server tokens must never appear in logs
+	for _, server := range p.config.GalaxyServers {
+		if server.Token != "" {
+			packersdk.LogSecretFilter.Set(server.Token)
+		}
+	}
 
 	// Set default install directories if not specified
 	if p.config.CollectionsPath == "" {
@@ -679,11 +948,39 @@ func (p *Provisioner) Prepare(raws ...interface{}) error {
 		p.config.AnsibleProxyHost = "127.0.0.1"
 	}
 
+	// Load navigator_config_file and apply the navigator_config block on top
+	if p.config.NavigatorConfigFile != "" {
+		if err := validateFileConfig(p.config.NavigatorConfigFile, "navigator_config_file", true); err != nil {
+			return err
+		}
+		file, err := loadNavigatorConfigFile(p.config.NavigatorConfigFile)
+		if err != nil {
+			return fmt.Errorf("navigator_config_file: %w", err)
+		}
+		p.config.navigatorConfigFile = file
+		p.config.NavigatorConfig = mergeNavigatorConfig(file.Config, p.config.NavigatorConfig)
+	}
+
 	// Validate configuration
 	if err := p.config.Validate(); err != nil {
 		return err
 	}
 
+	// Validate the ansible-navigator.yml the configuration generates
+	if p.config.NavigatorConfig != nil {
+		var base map[string]interface{}
+		if p.config.navigatorConfigFile != nil {
+			base = p.config.navigatorConfigFile.Raw
+		}
+		var errs *packersdk.MultiError
+		for _, err := range validateNavigatorConfigDocument(p.config.NavigatorConfig, base) {
+			errs = packersdk.MultiErrorAppend(errs, err)
+		}
+		if errs != nil {
+			return errs
+		}
+	}
+
 	return nil
 }
 
@@ -1300,12 +1597,91 @@ func createRolePlaybook(role string, play Play) (string, error) {
 	return tmpFile.Name(), nil
 }
 
+// writeEarlySummary writes the structured summary when the build fails before
+// any play runs, so that verification results are still recorded.
+func (p *Provisioner) writeEarlySummary(ui packersdk.Ui) {
+	if !p.config.StructuredLogging || p.config.LogOutputPath == "" {
+		return
+	}
+	summary := &Summary{
+		FailedTasks:               make([]NavigatorEvent, 0),
+		GalaxyVerification:        p.galaxyVerification,
+		ExecutionEnvironmentImage: p.eeImage,
+	}
+	if err := writeSummaryJSON(summary, p.config.LogOutputPath); err != nil {
+		ui.Message(fmt.Sprintf("[Warning] Could not write structured log to %s: %v", p.config.LogOutputPath, err))
+	}
+}
+
 func (p *Provisioner) executeAnsible(ui packersdk.Ui, comm packersdk.Communicator, privKeyFile string) error {
 	httpAddr := p.generatedData["PackerHTTPAddr"].(string)
 
 	debugEnabled := isPluginDebugEnabled(p.config.NavigatorConfig)
 	debugf(ui, debugEnabled, "Plugin debug mode enabled (gated by navigator_config.logging.level=debug)")
 
+	// Detect the container engine that runs the execution environment (also used
+	// by Galaxy installs inside the EE). Detection fails open.
+	p.engine = nil
+	if isExecutionEnvironmentEnabled(p.config.NavigatorConfig) {
+		ee := p.config.NavigatorConfig.ExecutionEnvironment
+		deps := defaultContainerEngineDeps()
+		detected, err := detectContainerEngine(ee.ContainerEngine, deps)
+		if err != nil {
+			if eePreflightMode(ee) == EEPreflightOff {
+				ui.Message(fmt.Sprintf("Warning: failed to detect container engine: %v", err))
+			}
+		} else {
+			p.engine = detected
+			debugf(ui, debugEnabled, "Detected container engine: %s (rootless=%t, selinux_relabel=%t, host_gateway=%s)",
+				detected.Name(), detected.Rootless(), detected.NeedsSELinuxRelabel(), detected.HostGateway())
+		}
+
+		// Check the engine, image, mounts and proxy reachability before the first play.
+		var proxy *eeProxyTarget
+		if !p.config.UseProxy.False() {
+			proxy = &eeProxyTarget{host: p.config.AnsibleProxyHost, port: p.config.LocalPort}
+		}
+		if err := runEEPreflight(ui, p.engine, err, ee, proxy, deps); err != nil {
+			return err
+		}
+
+		// Build the image from execution-environment.yml when configured.
+		if ee.Build != nil {
+			if err := buildExecutionEnvironmentImage(ui, p.engine, ee, func(cmd *exec.Cmd) error { return streamCommand(ui, cmd) }); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Pull the execution environment image once and pin it by digest, so every
+	// play runs the same image.
+	p.eeImage = nil
+	if isExecutionEnvironmentEnabled(p.config.NavigatorConfig) {
+		prepared, err := prepareExecutionEnvironmentImage(ui, p.engine, p.config.NavigatorConfig.ExecutionEnvironment)
+		if err != nil {
+			return err
+		}
+		if prepared != nil && prepared.Digest != "" {
+			// Provisioners cannot add artifact metadata; -machine-readable output carries the digest.
+			ui.Machine("execution-environment-image", prepared.Reference, prepared.Digest)
+		}
+		p.eeImage = prepared
+
+		// Verify the image signature before anything runs in it.
+		ee := p.config.NavigatorConfig.ExecutionEnvironment
+		if ee.Verify != nil {
+			if p.eeImage == nil {
+				p.eeImage = &ExecutionEnvironmentImage{Image: ee.Image, Reference: ee.Image}
+			}
+			verification, err := verifyExecutionEnvironmentImage(ui, p.engine, ee, p.eeImage.Reference, defaultContainerEngineDeps())
+			p.eeImage.Verification = verification
+			if err != nil {
+				p.writeEarlySummary(ui)
+				return err
+			}
+		}
+	}
+
 	// Pure YAML configuration approach
 	var navigatorConfigPath string
 
@@ -1313,21 +1689,23 @@ func (p *Provisioner) executeAnsible(ui packersdk.Ui, comm packersdk.Communicato
 		// Pass collections path for EE automatic defaults
 		collectionsPath := p.config.CollectionsPath
 
-		// Apply EE automatic defaults (e.g., temp paths, collections mount)
-		applyAutomaticEEDefaults(p.config.NavigatorConfig, collectionsPath, p.config.AnsibleProxyHost)
+		// Apply EE automatic defaults (e.g., temp paths, collections mount, host gateway mapping)
+		applyAutomaticEEDefaultsWithEngine(p.config.NavigatorConfig, collectionsPath, p.config.AnsibleProxyHost, p.engine)
 
-		// Handle ansible.cfg generation if needed
+		// Handle ansible.cfg generation if needed, merging into ansible_config.config when set
 		if p.config.NavigatorConfig.AnsibleConfig != nil && needsGeneratedAnsibleCfg(p.config.NavigatorConfig.AnsibleConfig) {
-			if p.config.NavigatorConfig.AnsibleConfig.Config != "" {
-				return fmt.Errorf("invalid navigator_config.ansible_config: config is mutually exclusive with defaults/ssh_connection")
-			}
-
-			cfgContent, err := generateAnsibleCfgContent(p.config.NavigatorConfig.AnsibleConfig)
+			cfgContent, err := buildAnsibleCfgContent(ui, p.config.NavigatorConfig.AnsibleConfig)
 			if err != nil {
 				return fmt.Errorf("failed to generate ansible.cfg content: %w", err)
 			}
 			if cfgContent != "" {
-				cfgPath, err := createTempAnsibleCfgFile(cfgContent)
+				// Relative paths in ansible_config.config resolve against its
+				// directory, so the merged copy is written next to it.
+				var cfgDir string
+				if original := p.config.NavigatorConfig.AnsibleConfig.Config; original != "" {
+					cfgDir = filepath.Dir(original)
+				}
+				cfgPath, err := createTempAnsibleCfgFile(cfgDir, cfgContent)
 				if err != nil {
 					return fmt.Errorf("failed to create temporary ansible.cfg: %w", err)
 				}
@@ -1339,8 +1717,16 @@ func (p *Provisioner) executeAnsible(ui packersdk.Ui, comm packersdk.Communicato
 			}
 		}
 
-		// Generate full YAML configuration
-		yamlContent, err := GenerateNavigatorConfigYAML(p.config.NavigatorConfig, collectionsPath, p.config.AnsibleProxyHost)
+		// Generate full YAML configuration, preserving navigator_config_file settings
+		var baseYAML map[string]interface{}
+		if file := p.config.navigatorConfigFile; file != nil {
+			ui.Message(fmt.Sprintf("Using navigator_config_file %s as base configuration", p.config.NavigatorConfigFile))
+			if len(file.Migrations) > 0 {
+				ui.Message(fmt.Sprintf("Migrated navigator_config_file from Version 1 format: %s", strings.Join(file.Migrations, ", ")))
+			}
+			baseYAML = file.Raw
+		}
+		yamlContent, err := GenerateNavigatorConfigYAMLWithBase(p.config.NavigatorConfig, baseYAML, collectionsPath, p.config.AnsibleProxyHost)
 		if err != nil {
 			return fmt.Errorf("failed to generate ansible-navigator.yml: %w", err)
 		}
@@ -1363,10 +1749,26 @@ func (p *Provisioner) executeAnsible(ui packersdk.Ui, comm packersdk.Communicato
 		}
 	}
 
+	// Export the engine endpoint (e.g. DOCKER_HOST of the active Docker context)
+	var engineHostEnv string
+	if p.engine != nil {
+		if key, value := p.engine.HostEnv(); value != "" {
+			engineHostEnv = key + "=" + value
+			debugf(ui, debugEnabled, "Resolved %s from context: %s", key, value)
+		}
+	}
+
 	// Install dependencies using GalaxyManager
 	galaxyManager := NewGalaxyManager(&p.config, ui)
+	galaxyManager.engine = p.engine
 
-	if err := galaxyManager.InstallRequirements(); err != nil {
+	err := galaxyManager.InstallRequirements()
+	p.galaxyVerification = galaxyManager.verification
+	if err != nil {
+		// Record failed verifications even though no play runs.
+		if p.galaxyVerification != nil {
+			p.writeEarlySummary(ui)
+		}
 		return fmt.Errorf("failed to install requirements: %w", err)
 	}
 
@@ -1375,18 +1777,8 @@ func (p *Provisioner) executeAnsible(ui packersdk.Ui, comm packersdk.Communicato
 		return fmt.Errorf("failed to setup environment paths: %w", err)
 	}
 
-	// Resolve Docker Host if needed
-	dockerHost, err := resolveDockerHost(ui)
-	if err != nil {
-		// Log warning but proceed
-		ui.Message(fmt.Sprintf("Warning: Error resolving Docker host: %v", err))
-	}
-	if dockerHost != "" {
-		debugf(ui, debugEnabled, "Resolved DOCKER_HOST from context: %s", dockerHost)
-	}
-
 	// Execute plays (required by validation)
-	return p.executePlays(ui, comm, privKeyFile, httpAddr, navigatorConfigPath, dockerHost)
+	return p.executePlays(ui, comm, privKeyFile, httpAddr, navigatorConfigPath, engineHostEnv)
 }
 
 // buildRunCommandArgsForPlay constructs the full command arguments for a play
@@ -1445,7 +1837,7 @@ func (p *Provisioner) buildRunCommandArgsForPlay(ui packersdk.Ui, play Play, htt
 // executePlays executes multiple Ansible plays in sequence.
 // If a play fails and keep_going is false, execution stops immediately.
 // Otherwise, errors are logged and execution continues to the next play.
-func (p *Provisioner) executePlays(ui packersdk.Ui, comm packersdk.Communicator, privKeyFile string, httpAddr string, navigatorConfigPath string, dockerHost string) error {
+func (p *Provisioner) executePlays(ui packersdk.Ui, comm packersdk.Communicator, privKeyFile string, httpAddr string, navigatorConfigPath string, engineHostEnv string) error {
 	inventory := p.config.InventoryFile
 
 	debugEnabled := isPluginDebugEnabled(p.config.NavigatorConfig)
@@ -1458,8 +1850,8 @@ func (p *Provisioner) executePlays(ui packersdk.Ui, comm packersdk.Communicator,
 	if navigatorConfigPath != "" {
 		debugf(ui, debugEnabled, "YAML config: ANSIBLE_NAVIGATOR_CONFIG=%s", navigatorConfigPath)
 	}
-	if dockerHost != "" {
-		debugf(ui, debugEnabled, "Using DOCKER_HOST=%s", dockerHost)
+	if engineHostEnv != "" {
+		debugf(ui, debugEnabled, "Using %s", engineHostEnv)
 	}
 
 	for i, play := range p.config.Plays {
@@ -1529,17 +1921,17 @@ func (p *Provisioner) executePlays(ui packersdk.Ui, comm packersdk.Communicator,
 			cmd.Env = append(cmd.Env, fmt.Sprintf("ANSIBLE_NAVIGATOR_CONFIG=%s", navigatorConfigPath))
 			debugf(ui, debugEnabled, "Setting ANSIBLE_NAVIGATOR_CONFIG for %s", playName)
 		}
-		// Add DOCKER_HOST if resolved
-		if dockerHost != "" {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("DOCKER_HOST=%s", dockerHost))
+		// Add the container engine endpoint (DOCKER_HOST) if resolved
+		if engineHostEnv != "" {
+			cmd.Env = append(cmd.Env, engineHostEnv)
 		}
 		if len(envvars) > 0 {
 			cmd.Env = append(cmd.Env, envvars...)
 		}
 
-		// DEBUG-only EE/docker preflight diagnostics (no behavior changes)
+		// DEBUG-only EE/container engine preflight diagnostics (no behavior changes)
 		if debugEnabled && isExecutionEnvironmentEnabled(p.config.NavigatorConfig) {
-			emitEEDockerPreflight(ui, debugEnabled, p.config.AnsibleNavigatorPath)
+			emitEEPreflight(ui, debugEnabled, p.config.AnsibleNavigatorPath, p.engine, p.config.NavigatorConfig.ExecutionEnvironment, p.eeImage)
 		}
 
 		execErr := p.executeAnsibleCommand(ui, cmd, playName)
@@ -1590,6 +1982,10 @@ func (p *Provisioner) executeAnsibleCommand(ui packersdk.Ui, cmd *exec.Cmd, targ
 			TasksTotal:  0,
 			TasksFailed: 0,
 			FailedTasks: make([]NavigatorEvent, 0),
+			// Collection verification ran before the plays; carry it into every summary.
+			GalaxyVerification: p.galaxyVerification,
+			// The execution environment image the plays ran in.
+			ExecutionEnvironmentImage: p.eeImage,
 		}
 	}
 
@@ -1972,36 +2368,3 @@ func checkArg(argname string, args []string) bool {
 	}
 	return false
 }
-
-// resolveDockerHost attempts to detect the active Docker Context socket
-// when DOCKER_HOST is not explicitly set. This ensures compatibility with
-// Rootless Docker and non-default contexts.
-func resolveDockerHost(ui packersdk.Ui) (string, error) {
-	// If DOCKER_HOST is already set, respect it
-	if os.Getenv("DOCKER_HOST") != "" {
-		return "", nil
-	}
-
-	// Check if docker command is available
-	dockerPath, err := exec.LookPath("docker")
-	if err != nil {
-		// Docker not found, cannot resolve context
-		return "", nil
-	}
-
-	// Run docker context inspect
-	cmd := exec.Command(dockerPath, "context", "inspect", "--format", "{{.Endpoints.docker.Host}}")
-	output, err := cmd.Output()
-	if err != nil {
-		// Failed to inspect context, ignore (fail open)
-		ui.Message(fmt.Sprintf("Warning: Failed to inspect Docker context: %v", err))
-		return "", nil
-	}
-
-	host := strings.TrimSpace(string(output))
-	if host != "" {
-		return host, nil
-	}
-
-	return "", nil
-}
@@ -13,6 +13,7 @@ type FlatAnsibleConfig struct {
 	Config        *string                      `mapstructure:"config" cty:"config" hcl:"config"`
 	Defaults      *FlatAnsibleConfigDefaults   `mapstructure:"defaults" cty:"defaults" hcl:"defaults"`
 	SSHConnection *FlatAnsibleConfigConnection `mapstructure:"ssh_connection" cty:"ssh_connection" hcl:"ssh_connection"`
+	Sections      []FlatAnsibleConfigSection   `mapstructure:"section" cty:"section" hcl:"section"`
 }
 
 // FlatMapstructure returns a new FlatAnsibleConfig.
@@ -30,6 +31,7 @@ func (*FlatAnsibleConfig) HCL2Spec() map[string]hcldec.Spec {
 		"config":         &hcldec.AttrSpec{Name: "config", Type: cty.String, Required: false},
 		"defaults":       &hcldec.BlockSpec{TypeName: "defaults", Nested: hcldec.ObjectSpec((*FlatAnsibleConfigDefaults)(nil).HCL2Spec())},
 		"ssh_connection": &hcldec.BlockSpec{TypeName: "ssh_connection", Nested: hcldec.ObjectSpec((*FlatAnsibleConfigConnection)(nil).HCL2Spec())},
+		"section":        &hcldec.BlockListSpec{TypeName: "section", Nested: hcldec.ObjectSpec((*FlatAnsibleConfigSection)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -86,6 +88,60 @@ func (*FlatAnsibleConfigDefaults) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatAnsibleConfigSection is an auto-generated flat version of AnsibleConfigSection.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatAnsibleConfigSection struct {
+	Name    *string           `mapstructure:"name" required:"true" cty:"name" hcl:"name"`
+	Options map[string]string `mapstructure:"options" cty:"options" hcl:"options"`
+}
+
+// FlatMapstructure returns a new FlatAnsibleConfigSection.
+// FlatAnsibleConfigSection is an auto-generated flat version of AnsibleConfigSection.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*AnsibleConfigSection) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatAnsibleConfigSection)
+}
+
+// HCL2Spec returns the hcl spec of a AnsibleConfigSection.
+// This spec is used by HCL to read the fields of AnsibleConfigSection.
+// The decoded values from this spec will then be applied to a FlatAnsibleConfigSection.
+func (*FlatAnsibleConfigSection) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":    &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"options": &hcldec.AttrSpec{Name: "options", Type: cty.Map(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatAnsibleRunnerConfig is an auto-generated flat version of AnsibleRunnerConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatAnsibleRunnerConfig struct {
+	ArtifactDir          *string `mapstructure:"artifact_dir" cty:"artifact_dir" hcl:"artifact_dir"`
+	RotateArtifactsCount *int    `mapstructure:"rotate_artifacts_count" cty:"rotate_artifacts_count" hcl:"rotate_artifacts_count"`
+	Timeout              *int    `mapstructure:"timeout" cty:"timeout" hcl:"timeout"`
+	JobEvents            *bool   `mapstructure:"job_events" cty:"job_events" hcl:"job_events"`
+}
+
+// FlatMapstructure returns a new FlatAnsibleRunnerConfig.
+// FlatAnsibleRunnerConfig is an auto-generated flat version of AnsibleRunnerConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*AnsibleRunnerConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatAnsibleRunnerConfig)
+}
+
+// HCL2Spec returns the hcl spec of a AnsibleRunnerConfig.
+// This spec is used by HCL to read the fields of AnsibleRunnerConfig.
+// The decoded values from this spec will then be applied to a FlatAnsibleRunnerConfig.
+func (*FlatAnsibleRunnerConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"artifact_dir":           &hcldec.AttrSpec{Name: "artifact_dir", Type: cty.String, Required: false},
+		"rotate_artifacts_count": &hcldec.AttrSpec{Name: "rotate_artifacts_count", Type: cty.Number, Required: false},
+		"timeout":                &hcldec.AttrSpec{Name: "timeout", Type: cty.Number, Required: false},
+		"job_events":             &hcldec.AttrSpec{Name: "job_events", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
 // FlatCollectionDocCache is an auto-generated flat version of CollectionDocCache.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatCollectionDocCache struct {
@@ -111,55 +167,87 @@ func (*FlatCollectionDocCache) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatColorConfig is an auto-generated flat version of ColorConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatColorConfig struct {
+	Enable *bool `mapstructure:"enable" cty:"enable" hcl:"enable"`
+	Osc4   *bool `mapstructure:"osc4" cty:"osc4" hcl:"osc4"`
+}
+
+// FlatMapstructure returns a new FlatColorConfig.
+// FlatColorConfig is an auto-generated flat version of ColorConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ColorConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatColorConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ColorConfig.
+// This spec is used by HCL to read the fields of ColorConfig.
+// The decoded values from this spec will then be applied to a FlatColorConfig.
+func (*FlatColorConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"enable": &hcldec.AttrSpec{Name: "enable", Type: cty.Bool, Required: false},
+		"osc4":   &hcldec.AttrSpec{Name: "osc4", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
 // FlatConfig is an auto-generated flat version of Config.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatConfig struct {
-	PackerBuildName         *string              `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
-	PackerBuilderType       *string              `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
-	PackerCoreVersion       *string              `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
-	PackerDebug             *bool                `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
-	PackerForce             *bool                `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
-	PackerOnError           *string              `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
-	PackerUserVars          map[string]string    `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
-	PackerSensitiveVars     []string             `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
-	Command                 *string              `mapstructure:"command" cty:"command" hcl:"command"`
-	AnsibleNavigatorPath    []string             `mapstructure:"ansible_navigator_path" cty:"ansible_navigator_path" hcl:"ansible_navigator_path"`
-	KeepGoing               *bool                `mapstructure:"keep_going" cty:"keep_going" hcl:"keep_going"`
-	StructuredLogging       *bool                `mapstructure:"structured_logging" cty:"structured_logging" hcl:"structured_logging"`
-	LogOutputPath           *string              `mapstructure:"log_output_path" cty:"log_output_path" hcl:"log_output_path"`
-	VerboseTaskOutput       *bool                `mapstructure:"verbose_task_output" cty:"verbose_task_output" hcl:"verbose_task_output"`
-	Plays                   []FlatPlay           `mapstructure:"play" cty:"play" hcl:"play"`
-	RequirementsFile        *string              `mapstructure:"requirements_file" cty:"requirements_file" hcl:"requirements_file"`
-	RolesPath               *string              `mapstructure:"roles_path" cty:"roles_path" hcl:"roles_path"`
-	CollectionsPath         *string              `mapstructure:"collections_path" cty:"collections_path" hcl:"collections_path"`
-	OfflineMode             *bool                `mapstructure:"offline_mode" cty:"offline_mode" hcl:"offline_mode"`
-	GalaxyCommand           *string              `mapstructure:"galaxy_command" cty:"galaxy_command" hcl:"galaxy_command"`
-	GalaxyArgs              []string             `mapstructure:"galaxy_args" cty:"galaxy_args" hcl:"galaxy_args"`
-	GalaxyForce             *bool                `mapstructure:"galaxy_force" cty:"galaxy_force" hcl:"galaxy_force"`
-	Groups                  []string             `mapstructure:"groups" cty:"groups" hcl:"groups"`
-	EmptyGroups             []string             `mapstructure:"empty_groups" cty:"empty_groups" hcl:"empty_groups"`
-	HostAlias               *string              `mapstructure:"host_alias" cty:"host_alias" hcl:"host_alias"`
-	User                    *string              `mapstructure:"user" cty:"user" hcl:"user"`
-	LocalPort               *int                 `mapstructure:"local_port" cty:"local_port" hcl:"local_port"`
-	SSHHostKeyFile          *string              `mapstructure:"ssh_host_key_file" cty:"ssh_host_key_file" hcl:"ssh_host_key_file"`
-	SSHAuthorizedKeyFile    *string              `mapstructure:"ssh_authorized_key_file" cty:"ssh_authorized_key_file" hcl:"ssh_authorized_key_file"`
-	AdapterKeyType          *string              `mapstructure:"ansible_proxy_key_type" cty:"ansible_proxy_key_type" hcl:"ansible_proxy_key_type"`
-	AnsibleProxyBindAddress *string              `mapstructure:"ansible_proxy_bind_address" cty:"ansible_proxy_bind_address" hcl:"ansible_proxy_bind_address"`
-	AnsibleProxyHost        *string              `mapstructure:"ansible_proxy_host" cty:"ansible_proxy_host" hcl:"ansible_proxy_host"`
-	SFTPCmd                 *string              `mapstructure:"sftp_command" cty:"sftp_command" hcl:"sftp_command"`
-	SkipVersionCheck        *bool                `mapstructure:"skip_version_check" cty:"skip_version_check" hcl:"skip_version_check"`
-	VersionCheckTimeout     *string              `mapstructure:"version_check_timeout" cty:"version_check_timeout" hcl:"version_check_timeout"`
-	UseSFTP                 *bool                `mapstructure:"use_sftp" cty:"use_sftp" hcl:"use_sftp"`
-	InventoryDirectory      *string              `mapstructure:"inventory_directory" cty:"inventory_directory" hcl:"inventory_directory"`
-	InventoryFileTemplate   *string              `mapstructure:"inventory_file_template" cty:"inventory_file_template" hcl:"inventory_file_template"`
-	InventoryFile           *string              `mapstructure:"inventory_file" cty:"inventory_file" hcl:"inventory_file"`
-	Limit                   *string              `mapstructure:"limit" cty:"limit" hcl:"limit"`
-	KeepInventoryFile       *bool                `mapstructure:"keep_inventory_file" cty:"keep_inventory_file" hcl:"keep_inventory_file"`
-	GalaxyForceWithDeps     *bool                `mapstructure:"galaxy_force_with_deps" cty:"galaxy_force_with_deps" hcl:"galaxy_force_with_deps"`
-	UseProxy                *bool                `mapstructure:"use_proxy" cty:"use_proxy" hcl:"use_proxy"`
-	WinRMUseHTTP            *bool                `mapstructure:"ansible_winrm_use_http" cty:"ansible_winrm_use_http" hcl:"ansible_winrm_use_http"`
-	ShowExtraVars           *bool                `mapstructure:"show_extra_vars" cty:"show_extra_vars" hcl:"show_extra_vars"`
-	NavigatorConfig         *FlatNavigatorConfig `mapstructure:"navigator_config" cty:"navigator_config" hcl:"navigator_config"`
+	PackerBuildName                   *string              `mapstructure:"packer_build_name" cty:"packer_build_name" hcl:"packer_build_name"`
+	PackerBuilderType                 *string              `mapstructure:"packer_builder_type" cty:"packer_builder_type" hcl:"packer_builder_type"`
+	PackerCoreVersion                 *string              `mapstructure:"packer_core_version" cty:"packer_core_version" hcl:"packer_core_version"`
+	PackerDebug                       *bool                `mapstructure:"packer_debug" cty:"packer_debug" hcl:"packer_debug"`
+	PackerForce                       *bool                `mapstructure:"packer_force" cty:"packer_force" hcl:"packer_force"`
+	PackerOnError                     *string              `mapstructure:"packer_on_error" cty:"packer_on_error" hcl:"packer_on_error"`
+	PackerUserVars                    map[string]string    `mapstructure:"packer_user_variables" cty:"packer_user_variables" hcl:"packer_user_variables"`
+	PackerSensitiveVars               []string             `mapstructure:"packer_sensitive_variables" cty:"packer_sensitive_variables" hcl:"packer_sensitive_variables"`
+	Command                           *string              `mapstructure:"command" cty:"command" hcl:"command"`
+	AnsibleNavigatorPath              []string             `mapstructure:"ansible_navigator_path" cty:"ansible_navigator_path" hcl:"ansible_navigator_path"`
+	KeepGoing                         *bool                `mapstructure:"keep_going" cty:"keep_going" hcl:"keep_going"`
+	StructuredLogging                 *bool                `mapstructure:"structured_logging" cty:"structured_logging" hcl:"structured_logging"`
+	LogOutputPath                     *string              `mapstructure:"log_output_path" cty:"log_output_path" hcl:"log_output_path"`
+	VerboseTaskOutput                 *bool                `mapstructure:"verbose_task_output" cty:"verbose_task_output" hcl:"verbose_task_output"`
+	Plays                             []FlatPlay           `mapstructure:"play" cty:"play" hcl:"play"`
+	RequirementsFile                  *string              `mapstructure:"requirements_file" cty:"requirements_file" hcl:"requirements_file"`
+	RolesPath                         *string              `mapstructure:"roles_path" cty:"roles_path" hcl:"roles_path"`
+	CollectionsPath                   *string              `mapstructure:"collections_path" cty:"collections_path" hcl:"collections_path"`
+	OfflineMode                       *bool                `mapstructure:"offline_mode" cty:"offline_mode" hcl:"offline_mode"`
+	GalaxyCommand                     *string              `mapstructure:"galaxy_command" cty:"galaxy_command" hcl:"galaxy_command"`
+	GalaxyArgs                        []string             `mapstructure:"galaxy_args" cty:"galaxy_args" hcl:"galaxy_args"`
+	GalaxyForce                       *bool                `mapstructure:"galaxy_force" cty:"galaxy_force" hcl:"galaxy_force"`
+	GalaxyServers                     []FlatGalaxyServer   `mapstructure:"galaxy_server" cty:"galaxy_server" hcl:"galaxy_server"`
+	GalaxyRuntime                     *string              `mapstructure:"galaxy_runtime" cty:"galaxy_runtime" hcl:"galaxy_runtime"`
+	GalaxyParallelism                 *int                 `mapstructure:"galaxy_parallelism" cty:"galaxy_parallelism" hcl:"galaxy_parallelism"`
+	GalaxyKeyring                     *string              `mapstructure:"galaxy_keyring" cty:"galaxy_keyring" hcl:"galaxy_keyring"`
+	GalaxyRequiredValidSignatureCount *string              `mapstructure:"galaxy_required_valid_signature_count" cty:"galaxy_required_valid_signature_count" hcl:"galaxy_required_valid_signature_count"`
+	GalaxyIgnoreSignatureStatusCodes  []string             `mapstructure:"galaxy_ignore_signature_status_codes" cty:"galaxy_ignore_signature_status_codes" hcl:"galaxy_ignore_signature_status_codes"`
+	Groups                            []string             `mapstructure:"groups" cty:"groups" hcl:"groups"`
+	EmptyGroups                       []string             `mapstructure:"empty_groups" cty:"empty_groups" hcl:"empty_groups"`
+	HostAlias                         *string              `mapstructure:"host_alias" cty:"host_alias" hcl:"host_alias"`
+	User                              *string              `mapstructure:"user" cty:"user" hcl:"user"`
+	LocalPort                         *int                 `mapstructure:"local_port" cty:"local_port" hcl:"local_port"`
+	SSHHostKeyFile                    *string              `mapstructure:"ssh_host_key_file" cty:"ssh_host_key_file" hcl:"ssh_host_key_file"`
+	SSHAuthorizedKeyFile              *string              `mapstructure:"ssh_authorized_key_file" cty:"ssh_authorized_key_file" hcl:"ssh_authorized_key_file"`
+	AdapterKeyType                    *string              `mapstructure:"ansible_proxy_key_type" cty:"ansible_proxy_key_type" hcl:"ansible_proxy_key_type"`
+	AnsibleProxyBindAddress           *string              `mapstructure:"ansible_proxy_bind_address" cty:"ansible_proxy_bind_address" hcl:"ansible_proxy_bind_address"`
+	AnsibleProxyHost                  *string              `mapstructure:"ansible_proxy_host" cty:"ansible_proxy_host" hcl:"ansible_proxy_host"`
+	SFTPCmd                           *string              `mapstructure:"sftp_command" cty:"sftp_command" hcl:"sftp_command"`
+	SkipVersionCheck                  *bool                `mapstructure:"skip_version_check" cty:"skip_version_check" hcl:"skip_version_check"`
+	VersionCheckTimeout               *string              `mapstructure:"version_check_timeout" cty:"version_check_timeout" hcl:"version_check_timeout"`
+	UseSFTP                           *bool                `mapstructure:"use_sftp" cty:"use_sftp" hcl:"use_sftp"`
+	InventoryDirectory                *string              `mapstructure:"inventory_directory" cty:"inventory_directory" hcl:"inventory_directory"`
+	InventoryFileTemplate             *string              `mapstructure:"inventory_file_template" cty:"inventory_file_template" hcl:"inventory_file_template"`
+	InventoryFile                     *string              `mapstructure:"inventory_file" cty:"inventory_file" hcl:"inventory_file"`
+	Limit                             *string              `mapstructure:"limit" cty:"limit" hcl:"limit"`
+	KeepInventoryFile                 *bool                `mapstructure:"keep_inventory_file" cty:"keep_inventory_file" hcl:"keep_inventory_file"`
+	GalaxyForceWithDeps               *bool                `mapstructure:"galaxy_force_with_deps" cty:"galaxy_force_with_deps" hcl:"galaxy_force_with_deps"`
+	UseProxy                          *bool                `mapstructure:"use_proxy" cty:"use_proxy" hcl:"use_proxy"`
+	WinRMUseHTTP                      *bool                `mapstructure:"ansible_winrm_use_http" cty:"ansible_winrm_use_http" hcl:"ansible_winrm_use_http"`
+	ShowExtraVars                     *bool                `mapstructure:"show_extra_vars" cty:"show_extra_vars" hcl:"show_extra_vars"`
+	NavigatorConfig                   *FlatNavigatorConfig `mapstructure:"navigator_config" cty:"navigator_config" hcl:"navigator_config"`
+	NavigatorConfigFile               *string              `mapstructure:"navigator_config_file" cty:"navigator_config_file" hcl:"navigator_config_file"`
 }
 
 // FlatMapstructure returns a new FlatConfig.
@@ -174,52 +262,84 @@ func (*Config) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec }
 // The decoded values from this spec will then be applied to a FlatConfig.
 func (*FlatConfig) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
-		"packer_build_name":          &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
-		"packer_builder_type":        &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
-		"packer_core_version":        &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
-		"packer_debug":               &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
-		"packer_force":               &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
-		"packer_on_error":            &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
-		"packer_user_variables":      &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
-		"packer_sensitive_variables": &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
-		"command":                    &hcldec.AttrSpec{Name: "command", Type: cty.String, Required: false},
-		"ansible_navigator_path":     &hcldec.AttrSpec{Name: "ansible_navigator_path", Type: cty.List(cty.String), Required: false},
-		"keep_going":                 &hcldec.AttrSpec{Name: "keep_going", Type: cty.Bool, Required: false},
-		"structured_logging":         &hcldec.AttrSpec{Name: "structured_logging", Type: cty.Bool, Required: false},
-		"log_output_path":            &hcldec.AttrSpec{Name: "log_output_path", Type: cty.String, Required: false},
-		"verbose_task_output":        &hcldec.AttrSpec{Name: "verbose_task_output", Type: cty.Bool, Required: false},
-		"play":                       &hcldec.BlockListSpec{TypeName: "play", Nested: hcldec.ObjectSpec((*FlatPlay)(nil).HCL2Spec())},
-		"requirements_file":          &hcldec.AttrSpec{Name: "requirements_file", Type: cty.String, Required: false},
-		"roles_path":                 &hcldec.AttrSpec{Name: "roles_path", Type: cty.String, Required: false},
-		"collections_path":           &hcldec.AttrSpec{Name: "collections_path", Type: cty.String, Required: false},
-		"offline_mode":               &hcldec.AttrSpec{Name: "offline_mode", Type: cty.Bool, Required: false},
-		"galaxy_command":             &hcldec.AttrSpec{Name: "galaxy_command", Type: cty.String, Required: false},
-		"galaxy_args":                &hcldec.AttrSpec{Name: "galaxy_args", Type: cty.List(cty.String), Required: false},
-		"galaxy_force":               &hcldec.AttrSpec{Name: "galaxy_force", Type: cty.Bool, Required: false},
-		"groups":                     &hcldec.AttrSpec{Name: "groups", Type: cty.List(cty.String), Required: false},
-		"empty_groups":               &hcldec.AttrSpec{Name: "empty_groups", Type: cty.List(cty.String), Required: false},
-		"host_alias":                 &hcldec.AttrSpec{Name: "host_alias", Type: cty.String, Required: false},
-		"user":                       &hcldec.AttrSpec{Name: "user", Type: cty.String, Required: false},
-		"local_port":                 &hcldec.AttrSpec{Name: "local_port", Type: cty.Number, Required: false},
-		"ssh_host_key_file":          &hcldec.AttrSpec{Name: "ssh_host_key_file", Type: cty.String, Required: false},
-		"ssh_authorized_key_file":    &hcldec.AttrSpec{Name: "ssh_authorized_key_file", Type: cty.String, Required: false},
-		"ansible_proxy_key_type":     &hcldec.AttrSpec{Name: "ansible_proxy_key_type", Type: cty.String, Required: false},
-		"ansible_proxy_bind_address": &hcldec.AttrSpec{Name: "ansible_proxy_bind_address", Type: cty.String, Required: false},
-		"ansible_proxy_host":         &hcldec.AttrSpec{Name: "ansible_proxy_host", Type: cty.String, Required: false},
-		"sftp_command":               &hcldec.AttrSpec{Name: "sftp_command", Type: cty.String, Required: false},
-		"skip_version_check":         &hcldec.AttrSpec{Name: "skip_version_check", Type: cty.Bool, Required: false},
-		"version_check_timeout":      &hcldec.AttrSpec{Name: "version_check_timeout", Type: cty.String, Required: false},
-		"use_sftp":                   &hcldec.AttrSpec{Name: "use_sftp", Type: cty.Bool, Required: false},
-		"inventory_directory":        &hcldec.AttrSpec{Name: "inventory_directory", Type: cty.String, Required: false},
-		"inventory_file_template":    &hcldec.AttrSpec{Name: "inventory_file_template", Type: cty.String, Required: false},
-		"inventory_file":             &hcldec.AttrSpec{Name: "inventory_file", Type: cty.String, Required: false},
-		"limit":                      &hcldec.AttrSpec{Name: "limit", Type: cty.String, Required: false},
-		"keep_inventory_file":        &hcldec.AttrSpec{Name: "keep_inventory_file", Type: cty.Bool, Required: false},
-		"galaxy_force_with_deps":     &hcldec.AttrSpec{Name: "galaxy_force_with_deps", Type: cty.Bool, Required: false},
-		"use_proxy":                  &hcldec.AttrSpec{Name: "use_proxy", Type: cty.Bool, Required: false},
-		"ansible_winrm_use_http":     &hcldec.AttrSpec{Name: "ansible_winrm_use_http", Type: cty.Bool, Required: false},
-		"show_extra_vars":            &hcldec.AttrSpec{Name: "show_extra_vars", Type: cty.Bool, Required: false},
-		"navigator_config":           &hcldec.BlockSpec{TypeName: "navigator_config", Nested: hcldec.ObjectSpec((*FlatNavigatorConfig)(nil).HCL2Spec())},
+		"packer_build_name":                     &hcldec.AttrSpec{Name: "packer_build_name", Type: cty.String, Required: false},
+		"packer_builder_type":                   &hcldec.AttrSpec{Name: "packer_builder_type", Type: cty.String, Required: false},
+		"packer_core_version":                   &hcldec.AttrSpec{Name: "packer_core_version", Type: cty.String, Required: false},
+		"packer_debug":                          &hcldec.AttrSpec{Name: "packer_debug", Type: cty.Bool, Required: false},
+		"packer_force":                          &hcldec.AttrSpec{Name: "packer_force", Type: cty.Bool, Required: false},
+		"packer_on_error":                       &hcldec.AttrSpec{Name: "packer_on_error", Type: cty.String, Required: false},
+		"packer_user_variables":                 &hcldec.AttrSpec{Name: "packer_user_variables", Type: cty.Map(cty.String), Required: false},
+		"packer_sensitive_variables":            &hcldec.AttrSpec{Name: "packer_sensitive_variables", Type: cty.List(cty.String), Required: false},
+		"command":                               &hcldec.AttrSpec{Name: "command", Type: cty.String, Required: false},
+		"ansible_navigator_path":                &hcldec.AttrSpec{Name: "ansible_navigator_path", Type: cty.List(cty.String), Required: false},
+		"keep_going":                            &hcldec.AttrSpec{Name: "keep_going", Type: cty.Bool, Required: false},
+		"structured_logging":                    &hcldec.AttrSpec{Name: "structured_logging", Type: cty.Bool, Required: false},
+		"log_output_path":                       &hcldec.AttrSpec{Name: "log_output_path", Type: cty.String, Required: false},
+		"verbose_task_output":                   &hcldec.AttrSpec{Name: "verbose_task_output", Type: cty.Bool, Required: false},
+		"play":                                  &hcldec.BlockListSpec{TypeName: "play", Nested: hcldec.ObjectSpec((*FlatPlay)(nil).HCL2Spec())},
+		"requirements_file":                     &hcldec.AttrSpec{Name: "requirements_file", Type: cty.String, Required: false},
+		"roles_path":                            &hcldec.AttrSpec{Name: "roles_path", Type: cty.String, Required: false},
+		"collections_path":                      &hcldec.AttrSpec{Name: "collections_path", Type: cty.String, Required: false},
+		"offline_mode":                          &hcldec.AttrSpec{Name: "offline_mode", Type: cty.Bool, Required: false},
+		"galaxy_command":                        &hcldec.AttrSpec{Name: "galaxy_command", Type: cty.String, Required: false},
+		"galaxy_args":                           &hcldec.AttrSpec{Name: "galaxy_args", Type: cty.List(cty.String), Required: false},
+		"galaxy_force":                          &hcldec.AttrSpec{Name: "galaxy_force", Type: cty.Bool, Required: false},
+		"galaxy_server":                         &hcldec.BlockListSpec{TypeName: "galaxy_server", Nested: hcldec.ObjectSpec((*FlatGalaxyServer)(nil).HCL2Spec())},
+		"galaxy_runtime":                        &hcldec.AttrSpec{Name: "galaxy_runtime", Type: cty.String, Required: false},
+		"galaxy_parallelism":                    &hcldec.AttrSpec{Name: "galaxy_parallelism", Type: cty.Number, Required: false},
+		"galaxy_keyring":                        &hcldec.AttrSpec{Name: "galaxy_keyring", Type: cty.String, Required: false},
+		"galaxy_required_valid_signature_count": &hcldec.AttrSpec{Name: "galaxy_required_valid_signature_count", Type: cty.String, Required: false},
+		"galaxy_ignore_signature_status_codes":  &hcldec.AttrSpec{Name: "galaxy_ignore_signature_status_codes", Type: cty.List(cty.String), Required: false},
+		"groups":                                &hcldec.AttrSpec{Name: "groups", Type: cty.List(cty.String), Required: false},
+		"empty_groups":                          &hcldec.AttrSpec{Name: "empty_groups", Type: cty.List(cty.String), Required: false},
+		"host_alias":                            &hcldec.AttrSpec{Name: "host_alias", Type: cty.String, Required: false},
+		"user":                                  &hcldec.AttrSpec{Name: "user", Type: cty.String, Required: false},
+		"local_port":                            &hcldec.AttrSpec{Name: "local_port", Type: cty.Number, Required: false},
+		"ssh_host_key_file":                     &hcldec.AttrSpec{Name: "ssh_host_key_file", Type: cty.String, Required: false},
+		"ssh_authorized_key_file":               &hcldec.AttrSpec{Name: "ssh_authorized_key_file", Type: cty.String, Required: false},
+		"ansible_proxy_key_type":                &hcldec.AttrSpec{Name: "ansible_proxy_key_type", Type: cty.String, Required: false},
+		"ansible_proxy_bind_address":            &hcldec.AttrSpec{Name: "ansible_proxy_bind_address", Type: cty.String, Required: false},
+		"ansible_proxy_host":                    &hcldec.AttrSpec{Name: "ansible_proxy_host", Type: cty.String, Required: false},
+		"sftp_command":                          &hcldec.AttrSpec{Name: "sftp_command", Type: cty.String, Required: false},
+		"skip_version_check":                    &hcldec.AttrSpec{Name: "skip_version_check", Type: cty.Bool, Required: false},
+		"version_check_timeout":                 &hcldec.AttrSpec{Name: "version_check_timeout", Type: cty.String, Required: false},
+		"use_sftp":                              &hcldec.AttrSpec{Name: "use_sftp", Type: cty.Bool, Required: false},
+		"inventory_directory":                   &hcldec.AttrSpec{Name: "inventory_directory", Type: cty.String, Required: false},
+		"inventory_file_template":               &hcldec.AttrSpec{Name: "inventory_file_template", Type: cty.String, Required: false},
+		"inventory_file":                        &hcldec.AttrSpec{Name: "inventory_file", Type: cty.String, Required: false},
+		"limit":                                 &hcldec.AttrSpec{Name: "limit", Type: cty.String, Required: false},
+		"keep_inventory_file":                   &hcldec.AttrSpec{Name: "keep_inventory_file", Type: cty.Bool, Required: false},
+		"galaxy_force_with_deps":                &hcldec.AttrSpec{Name: "galaxy_force_with_deps", Type: cty.Bool, Required: false},
+		"use_proxy":                             &hcldec.AttrSpec{Name: "use_proxy", Type: cty.Bool, Required: false},
+		"ansible_winrm_use_http":                &hcldec.AttrSpec{Name: "ansible_winrm_use_http", Type: cty.Bool, Required: false},
+		"show_extra_vars":                       &hcldec.AttrSpec{Name: "show_extra_vars", Type: cty.Bool, Required: false},
+		"navigator_config":                      &hcldec.BlockSpec{TypeName: "navigator_config", Nested: hcldec.ObjectSpec((*FlatNavigatorConfig)(nil).HCL2Spec())},
+		"navigator_config_file":                 &hcldec.AttrSpec{Name: "navigator_config_file", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatEditorConfig is an auto-generated flat version of EditorConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatEditorConfig struct {
+	Command *string `mapstructure:"command" cty:"command" hcl:"command"`
+	Console *bool   `mapstructure:"console" cty:"console" hcl:"console"`
+}
+
+// FlatMapstructure returns a new FlatEditorConfig.
+// FlatEditorConfig is an auto-generated flat version of EditorConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*EditorConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatEditorConfig)
+}
+
+// HCL2Spec returns the hcl spec of a EditorConfig.
+// This spec is used by HCL to read the fields of EditorConfig.
+// The decoded values from this spec will then be applied to a FlatEditorConfig.
+func (*FlatEditorConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"command": &hcldec.AttrSpec{Name: "command", Type: cty.String, Required: false},
+		"console": &hcldec.AttrSpec{Name: "console", Type: cty.Bool, Required: false},
 	}
 	return s
 }
@@ -254,12 +374,16 @@ func (*FlatEnvironmentVariablesConfig) HCL2Spec() map[string]hcldec.Spec {
 type FlatExecutionEnvironment struct {
 	Enabled              *bool                           `mapstructure:"enabled" cty:"enabled" hcl:"enabled"`
 	Image                *string                         `mapstructure:"image" cty:"image" hcl:"image"`
+	ImageDigest          *string                         `mapstructure:"image_digest" cty:"image_digest" hcl:"image_digest"`
 	PullPolicy           *string                         `mapstructure:"pull_policy" cty:"pull_policy" hcl:"pull_policy"`
 	ContainerEngine      *string                         `mapstructure:"container_engine" cty:"container_engine" hcl:"container_engine"`
 	ContainerOptions     []string                        `mapstructure:"container_options" cty:"container_options" hcl:"container_options"`
 	PullArguments        []string                        `mapstructure:"pull_arguments" cty:"pull_arguments" hcl:"pull_arguments"`
 	EnvironmentVariables *FlatEnvironmentVariablesConfig `mapstructure:"environment_variables" cty:"environment_variables" hcl:"environment_variables"`
 	VolumeMounts         []FlatVolumeMount               `mapstructure:"volume_mounts" cty:"volume_mounts" hcl:"volume_mounts"`
+	Verify               *FlatImageVerifyConfig          `mapstructure:"verify" cty:"verify" hcl:"verify"`
+	Preflight            *string                         `mapstructure:"preflight" cty:"preflight" hcl:"preflight"`
+	Build                *FlatImageBuildConfig           `mapstructure:"build" cty:"build" hcl:"build"`
 }
 
 // FlatMapstructure returns a new FlatExecutionEnvironment.
@@ -276,12 +400,124 @@ func (*FlatExecutionEnvironment) HCL2Spec() map[string]hcldec.Spec {
 	s := map[string]hcldec.Spec{
 		"enabled":               &hcldec.AttrSpec{Name: "enabled", Type: cty.Bool, Required: false},
 		"image":                 &hcldec.AttrSpec{Name: "image", Type: cty.String, Required: false},
+		"image_digest":          &hcldec.AttrSpec{Name: "image_digest", Type: cty.String, Required: false},
 		"pull_policy":           &hcldec.AttrSpec{Name: "pull_policy", Type: cty.String, Required: false},
 		"container_engine":      &hcldec.AttrSpec{Name: "container_engine", Type: cty.String, Required: false},
 		"container_options":     &hcldec.AttrSpec{Name: "container_options", Type: cty.List(cty.String), Required: false},
 		"pull_arguments":        &hcldec.AttrSpec{Name: "pull_arguments", Type: cty.List(cty.String), Required: false},
 		"environment_variables": &hcldec.BlockSpec{TypeName: "environment_variables", Nested: hcldec.ObjectSpec((*FlatEnvironmentVariablesConfig)(nil).HCL2Spec())},
 		"volume_mounts":         &hcldec.BlockListSpec{TypeName: "volume_mounts", Nested: hcldec.ObjectSpec((*FlatVolumeMount)(nil).HCL2Spec())},
+		"verify":                &hcldec.BlockSpec{TypeName: "verify", Nested: hcldec.ObjectSpec((*FlatImageVerifyConfig)(nil).HCL2Spec())},
+		"preflight":             &hcldec.AttrSpec{Name: "preflight", Type: cty.String, Required: false},
+		"build":                 &hcldec.BlockSpec{TypeName: "build", Nested: hcldec.ObjectSpec((*FlatImageBuildConfig)(nil).HCL2Spec())},
+	}
+	return s
+}
+
+// FlatGalaxyServer is an auto-generated flat version of GalaxyServer.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatGalaxyServer struct {
+	Name          *string `mapstructure:"name" required:"true" cty:"name" hcl:"name"`
+	URL           *string `mapstructure:"url" required:"true" cty:"url" hcl:"url"`
+	AuthURL       *string `mapstructure:"auth_url" cty:"auth_url" hcl:"auth_url"`
+	Token         *string `mapstructure:"token" cty:"token" hcl:"token"`
+	ValidateCerts *bool   `mapstructure:"validate_certs" cty:"validate_certs" hcl:"validate_certs"`
+}
+
+// FlatMapstructure returns a new FlatGalaxyServer.
+// FlatGalaxyServer is an auto-generated flat version of GalaxyServer.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*GalaxyServer) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatGalaxyServer)
+}
+
+// HCL2Spec returns the hcl spec of a GalaxyServer.
+// This spec is used by HCL to read the fields of GalaxyServer.
+// The decoded values from this spec will then be applied to a FlatGalaxyServer.
+func (*FlatGalaxyServer) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"name":           &hcldec.AttrSpec{Name: "name", Type: cty.String, Required: false},
+		"url":            &hcldec.AttrSpec{Name: "url", Type: cty.String, Required: false},
+		"auth_url":       &hcldec.AttrSpec{Name: "auth_url", Type: cty.String, Required: false},
+		"token":          &hcldec.AttrSpec{Name: "token", Type: cty.String, Required: false},
+		"validate_certs": &hcldec.AttrSpec{Name: "validate_certs", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
+// FlatImageBuildConfig is an auto-generated flat version of ImageBuildConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImageBuildConfig struct {
+	File       *string  `mapstructure:"file" cty:"file" hcl:"file"`
+	Repository *string  `mapstructure:"repository" cty:"repository" hcl:"repository"`
+	Command    *string  `mapstructure:"command" cty:"command" hcl:"command"`
+	ExtraArgs  []string `mapstructure:"extra_args" cty:"extra_args" hcl:"extra_args"`
+}
+
+// FlatMapstructure returns a new FlatImageBuildConfig.
+// FlatImageBuildConfig is an auto-generated flat version of ImageBuildConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImageBuildConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImageBuildConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ImageBuildConfig.
+// This spec is used by HCL to read the fields of ImageBuildConfig.
+// The decoded values from this spec will then be applied to a FlatImageBuildConfig.
+func (*FlatImageBuildConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"file":       &hcldec.AttrSpec{Name: "file", Type: cty.String, Required: false},
+		"repository": &hcldec.AttrSpec{Name: "repository", Type: cty.String, Required: false},
+		"command":    &hcldec.AttrSpec{Name: "command", Type: cty.String, Required: false},
+		"extra_args": &hcldec.AttrSpec{Name: "extra_args", Type: cty.List(cty.String), Required: false},
+	}
+	return s
+}
+
+// FlatImageVerifyConfig is an auto-generated flat version of ImageVerifyConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImageVerifyConfig struct {
+	CosignKey *string `mapstructure:"cosign_key" cty:"cosign_key" hcl:"cosign_key"`
+	Policy    *string `mapstructure:"policy" cty:"policy" hcl:"policy"`
+}
+
+// FlatMapstructure returns a new FlatImageVerifyConfig.
+// FlatImageVerifyConfig is an auto-generated flat version of ImageVerifyConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImageVerifyConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImageVerifyConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ImageVerifyConfig.
+// This spec is used by HCL to read the fields of ImageVerifyConfig.
+// The decoded values from this spec will then be applied to a FlatImageVerifyConfig.
+func (*FlatImageVerifyConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"cosign_key": &hcldec.AttrSpec{Name: "cosign_key", Type: cty.String, Required: false},
+		"policy":     &hcldec.AttrSpec{Name: "policy", Type: cty.String, Required: false},
+	}
+	return s
+}
+
+// FlatImagesConfig is an auto-generated flat version of ImagesConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatImagesConfig struct {
+	Details []string `mapstructure:"details" cty:"details" hcl:"details"`
+}
+
+// FlatMapstructure returns a new FlatImagesConfig.
+// FlatImagesConfig is an auto-generated flat version of ImagesConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*ImagesConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatImagesConfig)
+}
+
+// HCL2Spec returns the hcl spec of a ImagesConfig.
+// This spec is used by HCL to read the fields of ImagesConfig.
+// The decoded values from this spec will then be applied to a FlatImagesConfig.
+func (*FlatImagesConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"details": &hcldec.AttrSpec{Name: "details", Type: cty.List(cty.String), Required: false},
 	}
 	return s
 }
@@ -322,6 +558,15 @@ type FlatNavigatorConfig struct {
 	Logging              *FlatLoggingConfig        `mapstructure:"logging" cty:"logging" hcl:"logging"`
 	PlaybookArtifact     *FlatPlaybookArtifact     `mapstructure:"playbook_artifact" cty:"playbook_artifact" hcl:"playbook_artifact"`
 	CollectionDocCache   *FlatCollectionDocCache   `mapstructure:"collection_doc_cache" cty:"collection_doc_cache" hcl:"collection_doc_cache"`
+	Color                *FlatColorConfig          `mapstructure:"color" cty:"color" hcl:"color"`
+	Editor               *FlatEditorConfig         `mapstructure:"editor" cty:"editor" hcl:"editor"`
+	Images               *FlatImagesConfig         `mapstructure:"images" cty:"images" hcl:"images"`
+	InventoryColumns     []string                  `mapstructure:"inventory_columns" cty:"inventory_columns" hcl:"inventory_columns"`
+	TimeZone             *string                   `mapstructure:"time_zone" cty:"time_zone" hcl:"time_zone"`
+	Format               *string                   `mapstructure:"format" cty:"format" hcl:"format"`
+	Settings             *FlatSettingsConfig       `mapstructure:"settings" cty:"settings" hcl:"settings"`
+	EnablePrompts        *bool                     `mapstructure:"enable_prompts" cty:"enable_prompts" hcl:"enable_prompts"`
+	AnsibleRunner        *FlatAnsibleRunnerConfig  `mapstructure:"ansible_runner" cty:"ansible_runner" hcl:"ansible_runner"`
 }
 
 // FlatMapstructure returns a new FlatNavigatorConfig.
@@ -342,6 +587,15 @@ func (*FlatNavigatorConfig) HCL2Spec() map[string]hcldec.Spec {
 		"logging":               &hcldec.BlockSpec{TypeName: "logging", Nested: hcldec.ObjectSpec((*FlatLoggingConfig)(nil).HCL2Spec())},
 		"playbook_artifact":     &hcldec.BlockSpec{TypeName: "playbook_artifact", Nested: hcldec.ObjectSpec((*FlatPlaybookArtifact)(nil).HCL2Spec())},
 		"collection_doc_cache":  &hcldec.BlockSpec{TypeName: "collection_doc_cache", Nested: hcldec.ObjectSpec((*FlatCollectionDocCache)(nil).HCL2Spec())},
+		"color":                 &hcldec.BlockSpec{TypeName: "color", Nested: hcldec.ObjectSpec((*FlatColorConfig)(nil).HCL2Spec())},
+		"editor":                &hcldec.BlockSpec{TypeName: "editor", Nested: hcldec.ObjectSpec((*FlatEditorConfig)(nil).HCL2Spec())},
+		"images":                &hcldec.BlockSpec{TypeName: "images", Nested: hcldec.ObjectSpec((*FlatImagesConfig)(nil).HCL2Spec())},
+		"inventory_columns":     &hcldec.AttrSpec{Name: "inventory_columns", Type: cty.List(cty.String), Required: false},
+		"time_zone":             &hcldec.AttrSpec{Name: "time_zone", Type: cty.String, Required: false},
+		"format":                &hcldec.AttrSpec{Name: "format", Type: cty.String, Required: false},
+		"settings":              &hcldec.BlockSpec{TypeName: "settings", Nested: hcldec.ObjectSpec((*FlatSettingsConfig)(nil).HCL2Spec())},
+		"enable_prompts":        &hcldec.AttrSpec{Name: "enable_prompts", Type: cty.Bool, Required: false},
+		"ansible_runner":        &hcldec.BlockSpec{TypeName: "ansible_runner", Nested: hcldec.ObjectSpec((*FlatAnsibleRunnerConfig)(nil).HCL2Spec())},
 	}
 	return s
 }
@@ -412,6 +666,35 @@ func (*FlatPlaybookArtifact) HCL2Spec() map[string]hcldec.Spec {
 	return s
 }
 
+// FlatSettingsConfig is an auto-generated flat version of SettingsConfig.
+// Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
+type FlatSettingsConfig struct {
+	Effective *bool   `mapstructure:"effective" cty:"effective" hcl:"effective"`
+	Sample    *bool   `mapstructure:"sample" cty:"sample" hcl:"sample"`
+	Schema    *string `mapstructure:"schema" cty:"schema" hcl:"schema"`
+	Sources   *bool   `mapstructure:"sources" cty:"sources" hcl:"sources"`
+}
+
+// FlatMapstructure returns a new FlatSettingsConfig.
+// FlatSettingsConfig is an auto-generated flat version of SettingsConfig.
+// Where the contents a fields with a `mapstructure:,squash` tag are bubbled up.
+func (*SettingsConfig) FlatMapstructure() interface{ HCL2Spec() map[string]hcldec.Spec } {
+	return new(FlatSettingsConfig)
+}
+
+// HCL2Spec returns the hcl spec of a SettingsConfig.
+// This spec is used by HCL to read the fields of SettingsConfig.
+// The decoded values from this spec will then be applied to a FlatSettingsConfig.
+func (*FlatSettingsConfig) HCL2Spec() map[string]hcldec.Spec {
+	s := map[string]hcldec.Spec{
+		"effective": &hcldec.AttrSpec{Name: "effective", Type: cty.Bool, Required: false},
+		"sample":    &hcldec.AttrSpec{Name: "sample", Type: cty.Bool, Required: false},
+		"schema":    &hcldec.AttrSpec{Name: "schema", Type: cty.String, Required: false},
+		"sources":   &hcldec.AttrSpec{Name: "sources", Type: cty.Bool, Required: false},
+	}
+	return s
+}
+
 // FlatVolumeMount is an auto-generated flat version of VolumeMount.
 // Where the contents of a field with a `mapstructure:,squash` tag are bubbled up.
 type FlatVolumeMount struct {